@@ -8,6 +8,8 @@ go 1.16
 
 require (
 	github.com/cenkalti/backoff v2.2.1+incompatible
+	github.com/go-kivik/kivik/v3 v3.2.3
+	github.com/google/tink/go v1.6.1-0.20210519071714-58be99b3c4d0
 	github.com/gorilla/mux v1.8.0
 	github.com/hyperledger/aries-framework-go v0.1.7-0.20210611082655-3b07e0fdc340
 	github.com/hyperledger/aries-framework-go-ext/component/storage/couchdb v0.0.0-20210426192704-553740e279e5
@@ -15,6 +17,7 @@ require (
 	github.com/hyperledger/aries-framework-go-ext/component/vdr/orb v0.1.1
 	github.com/hyperledger/aries-framework-go/component/storageutil v0.0.0-20210520055214-ae429bb89bf7
 	github.com/hyperledger/aries-framework-go/spi v0.0.0-20210520055214-ae429bb89bf7
+	github.com/piprate/json-gold v0.4.0
 	github.com/rs/cors v1.7.0
 	github.com/spf13/cobra v1.1.3
 	github.com/stretchr/testify v1.7.0
@@ -7,14 +7,22 @@ SPDX-License-Identifier: Apache-2.0
 package startcmd
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
+	"database/sql"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cenkalti/backoff"
+	"github.com/go-kivik/kivik/v3"
+	"github.com/google/tink/go/subtle/random"
 	"github.com/gorilla/mux"
 	ariescouchdbstorage "github.com/hyperledger/aries-framework-go-ext/component/storage/couchdb"
 	ariesmysql "github.com/hyperledger/aries-framework-go-ext/component/storage/mysql"
@@ -22,10 +30,12 @@ import (
 	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
 	ariesvdr "github.com/hyperledger/aries-framework-go/pkg/vdr"
 	vdrkey "github.com/hyperledger/aries-framework-go/pkg/vdr/key"
 	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/piprate/json-gold/ld"
 	"github.com/rs/cors"
 	"github.com/spf13/cobra"
 	"github.com/trustbloc/edge-core/pkg/log"
@@ -45,9 +55,24 @@ const (
 	hostURLEnvKey        = "VAULT_HOST_URL"
 
 	remoteKMSURLFlagName  = "remote-kms-url"
-	remoteKMSURLFlagUsage = "Remote KMS URL."
+	remoteKMSURLFlagUsage = "Remote KMS URL. Required if the KMS type is " + kmsTypeWeb + "."
 	remoteKMSURLEnvKey    = "VAULT_REMOTE_KMS_URL"
 
+	kmsTypeFlagName  = "kms-type"
+	kmsTypeFlagUsage = "KMS the vaults' keys are kept in." +
+		" Possible values [" + kmsTypeWeb + "] [" + kmsTypeLocal + "]. Defaults to " + kmsTypeWeb + " if not set." +
+		" With " + kmsTypeLocal + ", authorizations cannot be created, delegated zcaps are rejected." +
+		" Alternatively, this can be set with the following environment variable: " + kmsTypeEnvKey
+	kmsTypeEnvKey = "VAULT_KMS_TYPE"
+	kmsTypeWeb    = "web"
+	kmsTypeLocal  = "local"
+
+	secretLockKeyPathFlagName  = "secret-lock-key-path"
+	secretLockKeyPathFlagUsage = "Path to the file with the master key protecting the vaults' keys" +
+		" if the KMS type is " + kmsTypeLocal + ". If not set, a master key is generated and kept in the database." +
+		" Alternatively, this can be set with the following environment variable: " + secretLockKeyPathEnvKey
+	secretLockKeyPathEnvKey = "VAULT_SECRET_LOCK_KEY_PATH"
+
 	edvURLFlagName  = "edv-url"
 	edvURLFlagUsage = "EDV URL."
 	edvURLEnvKey    = "VAULT_EDV_URL"
@@ -113,21 +138,31 @@ const (
 	requestTokensFlagUsage = "Tokens used for http request " +
 		" Alternatively, this can be set with the following environment variable: " + requestTokensEnvKey
 
+	authCleanupIntervalFlagName  = "authorization-cleanup-interval"
+	authCleanupIntervalFlagUsage = "Interval in seconds between the removals of expired authorizations, 0 disables them." +
+		" Default: " + authCleanupIntervalDefault + " seconds." +
+		" Alternatively, this can be set with the following environment variable: " + authCleanupIntervalEnvKey
+	authCleanupIntervalEnvKey  = "VAULT_AUTHORIZATION_CLEANUP_INTERVAL"
+	authCleanupIntervalDefault = "3600"
+
 	splitRequestTokenLength = 2
 )
 
 var logger = log.New("vault-server")
 
 type serviceParameters struct {
-	host            string
-	remoteKMSURL    string
-	edvURL          string
-	didDomain       string
-	didMethod       string
-	tlsParams       *tlsParameters
-	dsnParams       *dsnParams
-	didAnchorOrigin string
-	requestTokens   map[string]string
+	host                string
+	kmsType             string
+	remoteKMSURL        string
+	secretLockKeyPath   string
+	edvURL              string
+	didDomain           string
+	didMethod           string
+	tlsParams           *tlsParameters
+	dsnParams           *dsnParams
+	didAnchorOrigin     string
+	requestTokens       map[string]string
+	authCleanupInterval time.Duration
 }
 
 type dsnParams struct {
@@ -156,6 +191,82 @@ var supportedStorageProviders = map[string]func(string, string) (storage.Provide
 	},
 }
 
+// keyListers list the keys of the stores, for the records saved before they could be queried by tag. The mem storage
+// is never left with such records as it starts empty.
+// nolint:gochecknoglobals
+var keyListers = map[string]func(dsn, dbPrefix string) vault.KeyLister{
+	"couchdb": couchDBKeyLister,
+	"mysql":   mySQLKeyLister,
+}
+
+func couchDBKeyLister(dsn, dbPrefix string) vault.KeyLister {
+	return func(storeName, prefix string) ([]string, error) {
+		client, err := kivik.New("couch", dsn)
+		if err != nil {
+			return nil, err
+		}
+
+		defer client.Close(context.Background()) // nolint: errcheck
+
+		// the IDs of the documents are the keys
+		rows, err := client.DB(context.Background(), strings.ToLower(dbPrefix+storeName)).AllDocs(
+			context.Background(), kivik.Options{"startkey": prefix, "endkey": prefix + "\ufff0"},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		defer rows.Close() // nolint: errcheck
+
+		var keys []string
+
+		for rows.Next() {
+			keys = append(keys, rows.ID())
+		}
+
+		return keys, rows.Err()
+	}
+}
+
+func mySQLKeyLister(dsn, dbPrefix string) vault.KeyLister {
+	return func(storeName, prefix string) ([]string, error) {
+		db, err := sql.Open("mysql", dsn)
+		if err != nil {
+			return nil, err
+		}
+
+		defer db.Close() // nolint: errcheck
+
+		name := strings.ToLower(storeName)
+		if dbPrefix != "" {
+			name = dbPrefix + "_" + name
+		}
+
+		pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+
+		rows, err := db.Query(fmt.Sprintf("SELECT `key` FROM `%s`.`%s` WHERE `key` LIKE ?", name, name), pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		defer rows.Close() // nolint: errcheck
+
+		var keys []string
+
+		for rows.Next() {
+			var key string
+
+			if err = rows.Scan(&key); err != nil {
+				return nil, err
+			}
+
+			keys = append(keys, key)
+		}
+
+		return keys, rows.Err()
+	}
+}
+
 type server interface {
 	ListenAndServe(host string, certFile, keyFile string, router http.Handler) error
 }
@@ -202,11 +313,24 @@ func getParameters(cmd *cobra.Command) (*serviceParameters, error) {
 		return nil, err
 	}
 
-	remoteKMSURL, err := cmdutils.GetUserSetVarFromString(cmd, remoteKMSURLFlagName, remoteKMSURLEnvKey, false)
+	kmsType := cmdutils.GetUserSetOptionalVarFromString(cmd, kmsTypeFlagName, kmsTypeEnvKey)
+	if kmsType == "" {
+		kmsType = kmsTypeWeb
+	}
+
+	if kmsType != kmsTypeWeb && kmsType != kmsTypeLocal {
+		return nil, fmt.Errorf("unsupported KMS type: %s", kmsType)
+	}
+
+	remoteKMSURL, err := cmdutils.GetUserSetVarFromString(cmd, remoteKMSURLFlagName, remoteKMSURLEnvKey,
+		kmsType != kmsTypeWeb)
 	if err != nil {
 		return nil, err
 	}
 
+	secretLockKeyPath := cmdutils.GetUserSetOptionalVarFromString(cmd, secretLockKeyPathFlagName,
+		secretLockKeyPathEnvKey)
+
 	edvURL, err := cmdutils.GetUserSetVarFromString(cmd, edvURLFlagName, edvURLEnvKey, false)
 	if err != nil {
 		return nil, err
@@ -236,19 +360,42 @@ func getParameters(cmd *cobra.Command) (*serviceParameters, error) {
 
 	requestTokens := getRequestTokens(cmd)
 
+	authCleanupInterval, err := getAuthCleanupInterval(cmd)
+	if err != nil {
+		return nil, err
+	}
+
 	return &serviceParameters{
-		host:            host,
-		remoteKMSURL:    remoteKMSURL,
-		didDomain:       didDomain,
-		didMethod:       didMethod,
-		edvURL:          edvURL,
-		dsnParams:       dsn,
-		tlsParams:       tlsParams,
-		didAnchorOrigin: didAnchorOrigin,
-		requestTokens:   requestTokens,
+		host:                host,
+		kmsType:             kmsType,
+		remoteKMSURL:        remoteKMSURL,
+		secretLockKeyPath:   secretLockKeyPath,
+		didDomain:           didDomain,
+		didMethod:           didMethod,
+		edvURL:              edvURL,
+		dsnParams:           dsn,
+		tlsParams:           tlsParams,
+		didAnchorOrigin:     didAnchorOrigin,
+		requestTokens:       requestTokens,
+		authCleanupInterval: authCleanupInterval,
 	}, err
 }
 
+func getAuthCleanupInterval(cmd *cobra.Command) (time.Duration, error) {
+	interval := cmdutils.GetUserSetOptionalVarFromString(cmd, authCleanupIntervalFlagName, authCleanupIntervalEnvKey)
+
+	if interval == "" {
+		interval = authCleanupIntervalDefault
+	}
+
+	seconds, err := strconv.Atoi(interval)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse authorization cleanup interval %s: %w", interval, err)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
 func getTLS(cmd *cobra.Command) (*tlsParameters, error) {
 	tlsSystemCertPoolString := cmdutils.GetUserSetOptionalVarFromString(cmd, tlsSystemCertPoolFlagName,
 		tlsSystemCertPoolEnvKey)
@@ -300,6 +447,8 @@ func getRequestTokens(cmd *cobra.Command) map[string]string {
 func createFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP(hostURLFlagName, hostURLFlagShorthand, "", hostURLFlagUsage)
 	cmd.Flags().StringP(remoteKMSURLFlagName, "", "", remoteKMSURLFlagUsage)
+	cmd.Flags().StringP(kmsTypeFlagName, "", "", kmsTypeFlagUsage)
+	cmd.Flags().StringP(secretLockKeyPathFlagName, "", "", secretLockKeyPathFlagUsage)
 	cmd.Flags().StringP(edvURLFlagName, "", "", edvURLFlagUsage)
 	cmd.Flags().StringP(tlsSystemCertPoolFlagName, "", "", tlsSystemCertPoolFlagUsage)
 	cmd.Flags().StringArrayP(tlsCACertsFlagName, "", []string{}, tlsCACertsFlagUsage)
@@ -312,10 +461,15 @@ func createFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP(didMethodFlagName, "", "key", didMethodFlagUsage)
 	cmd.Flags().StringP(didAnchorOriginFlagName, "", "", didAnchorOriginFlagUsage)
 	cmd.Flags().StringArrayP(requestTokensFlagName, "", []string{}, requestTokensFlagUsage)
+	cmd.Flags().StringP(authCleanupIntervalFlagName, "", "", authCleanupIntervalFlagUsage)
 }
 
 const (
 	keystorePrimaryKeyURI = "local-lock://keystorekms"
+	vaultKMSPrimaryKeyURI = "local-lock://vaultkms"
+	masterKeyStoreName    = "masterkey"
+	masterKeyDBKeyName    = masterKeyStoreName
+	masterKeyNumBytes     = 32
 	sleep                 = time.Second
 )
 
@@ -371,12 +525,7 @@ func startService(params *serviceParameters, srv server) error { // nolint: funl
 		return err
 	}
 
-	vaultClient, err := vault.NewClient(
-		params.remoteKMSURL,
-		params.edvURL,
-		keyManager,
-		storeProvider,
-		loader,
+	vaultOpts := []vault.Opt{
 		vault.WithRegistry(ariesvdr.New(
 			ariesvdr.WithVDR(vdrkey.New()),
 			ariesvdr.WithVDR(vdrBloc),
@@ -390,11 +539,29 @@ func startService(params *serviceParameters, srv server) error { // nolint: funl
 				TLSClientConfig: tCfg,
 			},
 		}),
-	)
+	}
+
+	if params.kmsType == kmsTypeLocal {
+		kmsProvider, errKMS := newLocalKMSProvider(params.secretLockKeyPath, storeProvider, loader)
+		if errKMS != nil {
+			return errKMS
+		}
+
+		vaultOpts = append(vaultOpts, vault.WithKMSProvider(kmsProvider))
+	}
+
+	vaultClient, err := vault.NewClient(params.remoteKMSURL, params.edvURL, keyManager, storeProvider, loader,
+		vaultOpts...)
 	if err != nil {
 		return fmt.Errorf("vault new client: %w", err)
 	}
 
+	tagLegacyAuthorizations(vaultClient, params.dsnParams)
+
+	if params.authCleanupInterval > 0 {
+		go deleteExpiredAuthorizations(vaultClient, params.authCleanupInterval)
+	}
+
 	service := operation.New(vaultClient)
 	handlers := service.GetRESTHandlers()
 
@@ -429,6 +596,103 @@ func startService(params *serviceParameters, srv server) error { // nolint: funl
 		}).Handler(router))
 }
 
+// newLocalKMSProvider creates a local KMS for the vaults' keystores. Unlike the KMS holding the vaults' DID keys,
+// its keys are protected by a master key.
+func newLocalKMSProvider(secretLockKeyPath string, storeProvider storage.Provider,
+	loader ld.DocumentLoader) (*vault.LocalKMSProvider, error) {
+	masterKeyReader, err := prepareMasterKeyReader(secretLockKeyPath, storeProvider)
+	if err != nil {
+		return nil, fmt.Errorf("prepare master key reader: %w", err)
+	}
+
+	secretLock, err := local.NewService(masterKeyReader, nil)
+	if err != nil {
+		return nil, fmt.Errorf("secret lock new: %w", err)
+	}
+
+	keyManager, err := localkms.New(vaultKMSPrimaryKeyURI, &kmsProvider{
+		storageProvider: storeProvider,
+		secretLock:      secretLock,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("localkms new: %w", err)
+	}
+
+	provider, err := vault.NewLocalKMSProvider(keyManager, storeProvider, loader)
+	if err != nil {
+		return nil, fmt.Errorf("local KMS provider new: %w", err)
+	}
+
+	return provider, nil
+}
+
+// prepareMasterKeyReader reads the master key from the file if its path is set, otherwise from the database.
+// A master key is generated when there is none in the database yet.
+func prepareMasterKeyReader(path string, storeProvider storage.Provider) (io.Reader, error) {
+	if path != "" {
+		return local.MasterKeyFromPath(path)
+	}
+
+	masterKeyStore, err := storeProvider.OpenStore(masterKeyStoreName)
+	if err != nil {
+		return nil, err
+	}
+
+	masterKey, err := masterKeyStore.Get(masterKeyDBKeyName)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		masterKeyRaw := random.GetRandomBytes(uint32(masterKeyNumBytes))
+		masterKey = []byte(base64.URLEncoding.EncodeToString(masterKeyRaw))
+
+		err = masterKeyStore.Put(masterKeyDBKeyName, masterKey)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(masterKey), nil
+}
+
+// tagLegacyAuthorizations tags the authorizations created before they could be listed, so that they are listed and
+// deleted once expired.
+func tagLegacyAuthorizations(client *vault.Client, params *dsnParams) {
+	driver, dsn, err := getDBParams(params.dsn)
+	if err != nil {
+		logger.Errorf("tag legacy authorizations: %v", err)
+
+		return
+	}
+
+	keyLister, ok := keyListers[driver]
+	if !ok {
+		return
+	}
+
+	tagged, err := client.TagLegacyAuthorizations(keyLister(dsn, params.dbPrefix))
+	if err != nil {
+		logger.Errorf("tag legacy authorizations: %v", err)
+	}
+
+	if tagged > 0 {
+		logger.Infof("tagged %d legacy authorizations", tagged)
+	}
+}
+
+func deleteExpiredAuthorizations(client *vault.Client, interval time.Duration) {
+	for range time.Tick(interval) {
+		deleted, err := client.DeleteExpiredAuthorizations()
+		if err != nil {
+			logger.Errorf("delete expired authorizations: %v", err)
+
+			continue
+		}
+
+		if deleted > 0 {
+			logger.Infof("deleted %d expired authorizations", deleted)
+		}
+	}
+}
+
 func initStore(dbURL string, timeout uint64, prefix string) (storage.Provider, error) {
 	driver, dsn, err := getDBParams(dbURL)
 	if err != nil {
@@ -8,6 +8,7 @@ package startcmd
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
@@ -157,6 +158,82 @@ func TestDSN(t *testing.T) {
 	})
 }
 
+func TestStartCmdBadAuthCleanupInterval(t *testing.T) {
+	startCmd := GetStartCmd(&mockServer{})
+
+	args := []string{
+		"--" + hostURLFlagName, "localhost:8080",
+		"--" + remoteKMSURLFlagName, "localhost:8081",
+		"--" + edvURLFlagName, "localhost:8082",
+		"--" + datasourceNameFlagName, "mem://test",
+		"--" + authCleanupIntervalFlagName, "w1",
+	}
+	startCmd.SetArgs(args)
+
+	err := startCmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to parse authorization cleanup interval")
+}
+
+func TestStartCmdKMSType(t *testing.T) {
+	t.Run("Unsupported KMS type", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs([]string{
+			"--" + hostURLFlagName, "localhost:8080",
+			"--" + edvURLFlagName, "localhost:8082",
+			"--" + datasourceNameFlagName, "mem://test",
+			"--" + kmsTypeFlagName, "aws",
+		})
+
+		err := startCmd.Execute()
+		require.EqualError(t, err, "unsupported KMS type: aws")
+	})
+
+	t.Run("Local KMS without remote KMS URL", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs([]string{
+			"--" + hostURLFlagName, "localhost:8080",
+			"--" + edvURLFlagName, "localhost:8082",
+			"--" + datasourceNameFlagName, "mem://test",
+			"--" + kmsTypeFlagName, kmsTypeLocal,
+		})
+
+		require.NoError(t, startCmd.Execute())
+	})
+
+	t.Run("Local KMS with missing master key file", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs([]string{
+			"--" + hostURLFlagName, "localhost:8080",
+			"--" + edvURLFlagName, "localhost:8082",
+			"--" + datasourceNameFlagName, "mem://test",
+			"--" + kmsTypeFlagName, kmsTypeLocal,
+			"--" + secretLockKeyPathFlagName, "/does/not/exist",
+		})
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "prepare master key reader")
+	})
+
+	t.Run("Web KMS requires remote KMS URL", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs([]string{
+			"--" + hostURLFlagName, "localhost:8080",
+			"--" + edvURLFlagName, "localhost:8082",
+			"--" + datasourceNameFlagName, "mem://test",
+		})
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "remote-kms-url")
+	})
+}
+
 func TestTLSInvalidArgs(t *testing.T) {
 	t.Run("test wrong tls cert pool flag", func(t *testing.T) {
 		startCmd := GetStartCmd(&mockServer{})
@@ -180,3 +257,28 @@ type mockServer struct{}
 func (s *mockServer) ListenAndServe(host, certPath, keyPath string, handler http.Handler) error {
 	return nil
 }
+
+func TestKeyListers(t *testing.T) {
+	t.Run("CouchDB", func(t *testing.T) {
+		couchDB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/prefix_vault/_all_docs", r.URL.Path)
+			require.Equal(t, `"authorization_"`, r.URL.Query().Get("startkey"))
+
+			w.Header().Set("Content-Type", "application/json")
+			_, err := w.Write([]byte(`{"total_rows":2,"offset":0,"rows":[` +
+				`{"id":"authorization_vid_1","key":"authorization_vid_1","value":{"rev":"1"}},` +
+				`{"id":"authorization_vid_2","key":"authorization_vid_2","value":{"rev":"1"}}]}`))
+			require.NoError(t, err)
+		}))
+		defer couchDB.Close()
+
+		keys, err := couchDBKeyLister(couchDB.URL, "Prefix_")("vault", "authorization_")
+		require.NoError(t, err)
+		require.Equal(t, []string{"authorization_vid_1", "authorization_vid_2"}, keys)
+	})
+
+	t.Run("MySQL invalid DSN", func(t *testing.T) {
+		_, err := mySQLKeyLister("invalid", "")("vault", "authorization_")
+		require.Error(t, err)
+	})
+}
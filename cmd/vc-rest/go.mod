@@ -5,6 +5,7 @@
 module github.com/trustbloc/edge-service/cmd/vc-rest
 
 require (
+	github.com/btcsuite/btcutil v1.0.2
 	github.com/google/tink/go v1.6.1-0.20210519071714-58be99b3c4d0
 	github.com/gorilla/mux v1.8.0
 	github.com/hyperledger/aries-framework-go v0.1.7-0.20210611082655-3b07e0fdc340
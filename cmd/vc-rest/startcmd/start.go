@@ -43,6 +43,10 @@ import (
 	"github.com/trustbloc/edv/pkg/client"
 
 	"github.com/trustbloc/edge-service/cmd/common"
+	challengestore "github.com/trustbloc/edge-service/pkg/challenge"
+	"github.com/trustbloc/edge-service/pkg/did"
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	verifierprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
 	"github.com/trustbloc/edge-service/pkg/jsonld"
 	restgovernance "github.com/trustbloc/edge-service/pkg/restapi/governance"
 	governanceops "github.com/trustbloc/edge-service/pkg/restapi/governance/operation"
@@ -175,6 +179,17 @@ const (
 	didAnchorOriginEnvKey    = "VC_REST_DID_ANCHOR_ORIGIN"
 	didAnchorOriginFlagUsage = "DID anchor origin" + commonEnvVarUsageText + didAnchorOriginEnvKey
 
+	profilesBackfillFlagName  = "profiles-backfill"
+	profilesBackfillEnvKey    = "VC_REST_PROFILES_BACKFILL"
+	profilesBackfillFlagUsage = "Comma-Separated list of the names of the profiles saved before profiles could be" +
+		" listed. They are tagged on startup so they are listed." + commonEnvVarUsageText + profilesBackfillEnvKey
+
+	challengeCleanupIntervalFlagName  = "challenge-cleanup-interval"
+	challengeCleanupIntervalEnvKey    = "VC_REST_CHALLENGE_CLEANUP_INTERVAL"
+	challengeCleanupIntervalFlagUsage = "Interval in seconds between the removals of expired challenges," +
+		" 0 disables them. Defaults to 600." + commonEnvVarUsageText + challengeCleanupIntervalEnvKey
+	challengeCleanupIntervalDefault = "600"
+
 	databaseTypeMemOption     = "mem"
 	databaseTypeCouchDBOption = "couchdb"
 	databaseTypeMYSQLDBOption = "mysql"
@@ -227,6 +242,9 @@ type vcRestParameters struct {
 	logLevel             string
 	governanceClaimsFile string
 	didAnchorOrigin      string
+	profilesBackfill     []string
+
+	challengeCleanupInterval time.Duration
 }
 
 type dbParameters struct {
@@ -353,6 +371,17 @@ func getVCRestParameters(cmd *cobra.Command) (*vcRestParameters, error) {
 
 	didAnchorOrigin := cmdutils.GetUserSetOptionalVarFromString(cmd, didAnchorOriginFlagName, didAnchorOriginEnvKey)
 
+	profilesBackfill, err := cmdutils.GetUserSetVarFromArrayString(cmd, profilesBackfillFlagName,
+		profilesBackfillEnvKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	challengeCleanupInterval, err := getChallengeCleanupInterval(cmd)
+	if err != nil {
+		return nil, err
+	}
+
 	return &vcRestParameters{
 		hostURL:              hostURL,
 		edvURL:               edvURL,
@@ -369,9 +398,28 @@ func getVCRestParameters(cmd *cobra.Command) (*vcRestParameters, error) {
 		logLevel:             loggingLevel,
 		governanceClaimsFile: governanceClaimsFile,
 		didAnchorOrigin:      didAnchorOrigin,
+		profilesBackfill:     profilesBackfill,
+
+		challengeCleanupInterval: challengeCleanupInterval,
 	}, nil
 }
 
+func getChallengeCleanupInterval(cmd *cobra.Command) (time.Duration, error) {
+	interval := cmdutils.GetUserSetOptionalVarFromString(cmd, challengeCleanupIntervalFlagName,
+		challengeCleanupIntervalEnvKey)
+
+	if interval == "" {
+		interval = challengeCleanupIntervalDefault
+	}
+
+	seconds, err := strconv.Atoi(interval)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse challenge cleanup interval %s: %w", interval, err)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
 func getRequestTokens(cmd *cobra.Command) (map[string]string, error) {
 	requestTokens, err := cmdutils.GetUserSetVarFromArrayString(cmd, requestTokensFlagName,
 		requestTokensEnvKey, true)
@@ -611,6 +659,8 @@ func createFlags(startCmd *cobra.Command) {
 	startCmd.Flags().StringP(common.LogLevelFlagName, common.LogLevelFlagShorthand, "", common.LogLevelPrefixFlagUsage)
 	startCmd.Flags().StringP(governanceClaimsFlagName, "", "", governanceClaimsFlagUsage)
 	startCmd.Flags().StringP(didAnchorOriginFlagName, "", "", didAnchorOriginFlagUsage)
+	startCmd.Flags().StringArrayP(profilesBackfillFlagName, "", []string{}, profilesBackfillFlagUsage)
+	startCmd.Flags().StringP(challengeCleanupIntervalFlagName, "", "", challengeCleanupIntervalFlagUsage)
 }
 
 // nolint: gocyclo,funlen,gocognit
@@ -629,15 +679,34 @@ func startEdgeService(parameters *vcRestParameters, srv server) error {
 		return err
 	}
 
+	err = tagProfiles(edgeServiceProvs.provider, parameters.profilesBackfill)
+	if err != nil {
+		return err
+	}
+
+	if parameters.challengeCleanupInterval > 0 {
+		challenges, errStore := challengestore.New(edgeServiceProvs.provider)
+		if errStore != nil {
+			return fmt.Errorf("open challenge store: %w", errStore)
+		}
+
+		go deleteExpiredChallenges(challenges, parameters.challengeCleanupInterval)
+	}
+
 	localKMS, err := createKMS(edgeServiceProvs.kmsSecretsProvider)
 	if err != nil {
 		return err
 	}
 
+	didUpdateKeys, err := did.NewUpdateKeys(localKMS, edgeServiceProvs.provider)
+	if err != nil {
+		return err
+	}
+
 	// Create VDRI
 	vdr, err := createVDRI(parameters.universalResolverURL,
 		&tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}, parameters.blocDomain,
-		parameters.requestTokens["sidetreeToken"])
+		parameters.requestTokens["sidetreeToken"], didUpdateKeys)
 	if err != nil {
 		return err
 	}
@@ -655,7 +724,7 @@ func startEdgeService(parameters *vcRestParameters, srv server) error {
 	router := mux.NewRouter()
 
 	if parameters.token != "" {
-		router.Use(authorizationMiddleware(parameters.token))
+		router.Use(authorizationMiddleware(parameters.token, issuerops.WalletEndpoints()))
 	}
 
 	loader, err := jsonld.DocumentLoader(edgeServiceProvs.provider)
@@ -678,6 +747,7 @@ func startEdgeService(parameters *vcRestParameters, srv server) error {
 		TLSConfig:       &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12},
 		RetryParameters: parameters.retryParameters,
 		DIDAnchorOrigin: parameters.didAnchorOrigin,
+		DIDUpdateKeys:   didUpdateKeys,
 		DocumentLoader:  loader,
 	})
 	if err != nil {
@@ -689,9 +759,14 @@ func startEdgeService(parameters *vcRestParameters, srv server) error {
 			RootCAs:    rootCAs,
 			MinVersion: tls.VersionTLS12,
 		},
+		EDVClient: client.New(parameters.edvURL, client.WithTLSConfig(&tls.Config{
+			RootCAs:    rootCAs,
+			MinVersion: tls.VersionTLS12,
+		})),
 		StoreProvider: edgeServiceProvs.provider, KeyManager: localKMS, Crypto: crypto,
 		VDRI: vdr, Domain: parameters.blocDomain,
 		DIDAnchorOrigin: parameters.didAnchorOrigin,
+		DIDUpdateKeys:   didUpdateKeys,
 		DocumentLoader:  loader,
 	})
 	if err != nil {
@@ -701,8 +776,10 @@ func startEdgeService(parameters *vcRestParameters, srv server) error {
 	verifierService, err := restverifier.New(&verifierops.Config{
 		StoreProvider: edgeServiceProvs.provider,
 		TLSConfig:     &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}, VDRI: vdr,
-		RequestTokens:  parameters.requestTokens,
-		DocumentLoader: loader,
+		RequestTokens:   parameters.requestTokens,
+		DocumentLoader:  loader,
+		HostURL:         externalHostURL,
+		RetryParameters: parameters.retryParameters,
 	})
 	if err != nil {
 		return err
@@ -770,7 +847,7 @@ func (k kmsProvider) SecretLock() secretlock.Service {
 }
 
 func createVDRI(universalResolver string, tlsConfig *tls.Config, blocDomain,
-	sidetreeAuthToken string) (vdrapi.Registry, error) {
+	sidetreeAuthToken string, keyRetriever orb.KeyRetriever) (vdrapi.Registry, error) {
 	var opts []vdrpkg.Option
 
 	if universalResolver != "" {
@@ -784,7 +861,7 @@ func createVDRI(universalResolver string, tlsConfig *tls.Config, blocDomain,
 		opts = append(opts, vdrpkg.WithVDR(universalResolverVDRI))
 	}
 
-	vdr, err := orb.New(nil, orb.WithDomain(blocDomain), orb.WithTLSConfig(tlsConfig),
+	vdr, err := orb.New(keyRetriever, orb.WithDomain(blocDomain), orb.WithTLSConfig(tlsConfig),
 		orb.WithAuthToken(sidetreeAuthToken))
 	if err != nil {
 		return nil, err
@@ -816,6 +893,54 @@ type edgeServiceProviders struct {
 	kmsSecretsProvider ariesstorage.Provider
 }
 
+// tagProfiles tags the profiles saved before profiles could be listed, the store can't find them by itself.
+func tagProfiles(provider ariesstorage.Provider, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	profileStore, err := vcprofile.New(provider)
+	if err != nil {
+		return fmt.Errorf("open profile store: %w", err)
+	}
+
+	tagged, err := profileStore.TagProfiles(names...)
+	if err != nil {
+		return fmt.Errorf("tag profiles: %w", err)
+	}
+
+	verifierStore, err := verifierprofile.New(provider)
+	if err != nil {
+		return fmt.Errorf("open verifier profile store: %w", err)
+	}
+
+	taggedVerifiers, err := verifierStore.TagProfiles(names...)
+	if err != nil {
+		return fmt.Errorf("tag verifier profiles: %w", err)
+	}
+
+	logger.Infof("tagged %d profiles saved before profiles could be listed", tagged+taggedVerifiers)
+
+	return nil
+}
+
+// deleteExpiredChallenges deletes the expired challenges of the issuer and verifier profiles, along with the expired
+// claims of the challenges, pre-authorized codes and access tokens used up. The replicas of the service share them.
+func deleteExpiredChallenges(challenges *challengestore.Store, interval time.Duration) {
+	for range time.Tick(interval) {
+		deleted, err := challenges.DeleteExpired()
+		if err != nil {
+			logger.Errorf("delete expired challenges: %v", err)
+
+			continue
+		}
+
+		if deleted > 0 {
+			logger.Infof("deleted %d expired challenges", deleted)
+		}
+	}
+}
+
 //nolint: gocyclo
 func createStoreProviders(parameters *vcRestParameters) (*edgeServiceProviders, error) {
 	var edgeServiceProvs edgeServiceProviders
@@ -969,10 +1094,17 @@ func validateAuthorizationBearerToken(w http.ResponseWriter, r *http.Request, to
 	return true
 }
 
-func authorizationMiddleware(token string) mux.MiddlewareFunc {
+// authorizationMiddleware requires the API token, but on the given endpoints that the wallets call on their own.
+func authorizationMiddleware(token string, unprotected []string) mux.MiddlewareFunc {
+	public := make(map[string]bool)
+
+	for _, path := range unprotected {
+		public[path] = true
+	}
+
 	middleware := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if validateAuthorizationBearerToken(w, r, token) {
+			if isPublicRoute(r, public) || validateAuthorizationBearerToken(w, r, token) {
 				next.ServeHTTP(w, r)
 			}
 		})
@@ -980,3 +1112,15 @@ func authorizationMiddleware(token string) mux.MiddlewareFunc {
 
 	return middleware
 }
+
+// isPublicRoute tells whether the request was routed to one of the public endpoints.
+func isPublicRoute(r *http.Request, public map[string]bool) bool {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return false
+	}
+
+	path, err := route.GetPathTemplate()
+
+	return err == nil && public[path]
+}
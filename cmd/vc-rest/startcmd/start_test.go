@@ -6,20 +6,32 @@ SPDX-License-Identifier: Apache-2.0
 package startcmd
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	ariesmockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/vdr/fingerprint"
 	"github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/edge-core/pkg/log"
 
 	"github.com/trustbloc/edge-service/cmd/common"
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	issuerops "github.com/trustbloc/edge-service/pkg/restapi/issuer/operation"
 )
 
 type mockServer struct{}
@@ -245,6 +257,21 @@ func TestStartCmdWithNegativeBackoffFactor(t *testing.T) {
 	require.Equal(t, errNegativeBackoffFactor, err)
 }
 
+func TestStartCmdWithInvalidChallengeCleanupInterval(t *testing.T) {
+	startCmd := GetStartCmd(&mockServer{})
+
+	args := []string{
+		"--" + hostURLFlagName, "localhost:8080", "--" + edvURLFlagName,
+		"localhost:8081", "--" + blocDomainFlagName, "domain", "--" + databaseTypeFlagName, databaseTypeMemOption,
+		"--" + kmsSecretsDatabaseTypeFlagName, databaseTypeMemOption, "--" + challengeCleanupIntervalFlagName, "1m",
+	}
+	startCmd.SetArgs(args)
+
+	err := startCmd.Execute()
+	require.EqualError(t, err, `failed to parse challenge cleanup interval 1m: `+
+		`strconv.Atoi: parsing "1m": invalid syntax`)
+}
+
 func TestStartCmdValidArgs(t *testing.T) {
 	startCmd := GetStartCmd(&mockServer{})
 
@@ -254,6 +281,7 @@ func TestStartCmdValidArgs(t *testing.T) {
 		"--" + kmsSecretsDatabaseTypeFlagName, databaseTypeMemOption, "--" + tokenFlagName, "tk1",
 		"--" + requestTokensFlagName, "token1=tk1", "--" + requestTokensFlagName, "token2=tk2",
 		"--" + requestTokensFlagName, "token2=tk2=1", "--" + common.LogLevelFlagName, log.ParseString(log.ERROR),
+		"--" + profilesBackfillFlagName, "profile1", "--" + challengeCleanupIntervalFlagName, "60",
 	}
 	startCmd.SetArgs(args)
 
@@ -329,6 +357,35 @@ func TestCreateProviders(t *testing.T) {
 	})
 }
 
+func TestTagProfiles(t *testing.T) {
+	t.Run("test tag profiles", func(t *testing.T) {
+		data := map[string]ariesmockstorage.DBEntry{
+			"profile_issuer_profile1": {Value: []byte(`{"name":"profile1"}`)},
+			"profile_profile2":        {Value: []byte(`{"id":"profile2"}`)},
+		}
+
+		provider := &ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{Store: data}}
+
+		require.NoError(t, tagProfiles(provider, []string{"profile1", "profile2"}))
+		require.NotEmpty(t, data["profile_issuer_profile1"].Tags)
+		require.NotEmpty(t, data["profile_profile2"].Tags)
+	})
+
+	t.Run("test open store error", func(t *testing.T) {
+		err := tagProfiles(&ariesmockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")},
+			[]string{"profile1"})
+		require.EqualError(t, err, "open profile store: open error")
+	})
+
+	t.Run("test tag error", func(t *testing.T) {
+		err := tagProfiles(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:  map[string]ariesmockstorage.DBEntry{},
+			ErrGet: errors.New("get error"),
+		}}, []string{"profile1"})
+		require.EqualError(t, err, "tag profiles: get issuer profile profile1: get error")
+	})
+}
+
 func TestCreateKMS(t *testing.T) {
 	t.Run("fail to open master key store", func(t *testing.T) {
 		localKMS, err := createKMS(&ariesmockstorage.MockStoreProvider{FailNamespace: "masterkey"})
@@ -352,7 +409,7 @@ func TestCreateKMS(t *testing.T) {
 
 func TestCreateVDRI(t *testing.T) {
 	t.Run("test error from create new universal resolver vdr", func(t *testing.T) {
-		v, err := createVDRI("wrong", &tls.Config{MinVersion: tls.VersionTLS12}, "", "")
+		v, err := createVDRI("wrong", &tls.Config{MinVersion: tls.VersionTLS12}, "", "", nil)
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "failed to create new universal resolver vdr")
 		require.Nil(t, v)
@@ -368,7 +425,7 @@ func TestCreateVDRI(t *testing.T) {
 	})
 
 	t.Run("test success", func(t *testing.T) {
-		v, err := createVDRI("localhost:8083", &tls.Config{MinVersion: tls.VersionTLS12}, "", "")
+		v, err := createVDRI("localhost:8083", &tls.Config{MinVersion: tls.VersionTLS12}, "", "", nil)
 		require.NoError(t, err)
 		require.NotNil(t, v)
 	})
@@ -425,6 +482,8 @@ func TestTLSSystemCertPoolInvalidArgsEnvVar(t *testing.T) {
 	defer unsetEnvVars(t)
 	require.NoError(t, os.Setenv(tlsSystemCertPoolEnvKey, "wrongvalue"))
 
+	defer func() { require.NoError(t, os.Unsetenv(tlsSystemCertPoolEnvKey)) }()
+
 	err := startCmd.Execute()
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "invalid syntax")
@@ -522,3 +581,160 @@ func checkFlagPropertiesCorrect(t *testing.T, cmd *cobra.Command, flagName, flag
 	flagAnnotations := flag.Annotations
 	require.Nil(t, flagAnnotations)
 }
+
+// handlerServer keeps the handler of the service instead of serving it.
+type handlerServer struct {
+	handler http.Handler
+}
+
+func (s *handlerServer) ListenAndServe(host string, handler http.Handler) error {
+	s.handler = handler
+
+	return nil
+}
+
+func TestOID4VCIWithAPIToken(t *testing.T) {
+	const (
+		apiToken = "tk1"
+		hostURL  = "localhost:8080"
+	)
+
+	edv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "https://edv.example.com/encrypted-data-vaults/vault1")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer edv.Close()
+
+	srv := &handlerServer{}
+	startCmd := GetStartCmd(srv)
+
+	startCmd.SetArgs([]string{
+		"--" + hostURLFlagName, hostURL, "--" + edvURLFlagName, edv.URL, "--" + blocDomainFlagName, "domain",
+		"--" + databaseTypeFlagName, databaseTypeMemOption, "--" + kmsSecretsDatabaseTypeFlagName,
+		databaseTypeMemOption, "--" + tokenFlagName, apiToken, "--" + modeFlagName, string(issuer),
+	})
+	require.NoError(t, startCmd.Execute())
+
+	serve := func(t *testing.T, method, path, authorization, contentType string, body []byte) *httptest.ResponseRecorder {
+		t.Helper()
+
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		rr := httptest.NewRecorder()
+		srv.handler.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	issuerPubKey, issuerPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	issuerDID, issuerKeyID := fingerprint.CreateDIDKey(issuerPubKey)
+
+	profileBytes, err := json.Marshal(&issuerops.ProfileRequest{
+		Name:            "issuer",
+		URI:             "https://issuer.example.com",
+		SignatureType:   "Ed25519Signature2018",
+		DID:             issuerDID,
+		DIDPrivateKey:   base58.Encode(issuerPrivKey),
+		DIDKeyID:        issuerKeyID,
+		DisableVCStatus: true,
+		CredentialTemplates: []*vcprofile.CredentialTemplate{{
+			ID:       "membership",
+			Contexts: []string{verifiable.ContextURI},
+			Types:    []string{"VerifiableCredential"},
+		}},
+	})
+	require.NoError(t, err)
+
+	// the issuer's API calls need the API token
+	rr := serve(t, http.MethodPost, "/profile", "", "", profileBytes)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = serve(t, http.MethodPost, "/profile", "Bearer "+apiToken, "", profileBytes)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	offerBytes, err := json.Marshal(&issuerops.CredentialOfferRequest{
+		ComposeCredentialRequest: issuerops.ComposeCredentialRequest{TemplateID: "membership"},
+	})
+	require.NoError(t, err)
+
+	rr = serve(t, http.MethodPost, "/issuer/oid4vci/offers", "", "", offerBytes)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = serve(t, http.MethodPost, "/issuer/oid4vci/offers", "Bearer "+apiToken, "", offerBytes)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	offer := &issuerops.CredentialOfferResponse{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), offer))
+
+	// the wallet goes through the flow without the API token
+	rr = serve(t, http.MethodGet, "/issuer/.well-known/openid-credential-issuer", "", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	metadata := &issuerops.CredentialIssuerMetadata{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), metadata))
+
+	rr = serve(t, http.MethodPost, "/issuer/oid4vci/token", "", "application/x-www-form-urlencoded",
+		[]byte(url.Values{
+			"grant_type": {"urn:ietf:params:oauth:grant-type:pre-authorized_code"},
+			"pre-authorized_code": {
+				offer.CredentialOffer.Grants["urn:ietf:params:oauth:grant-type:pre-authorized_code"].PreAuthorizedCode,
+			},
+		}.Encode()))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	token := &issuerops.TokenResponse{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), token))
+
+	walletPubKey, walletPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, walletKeyID := fingerprint.CreateDIDKey(walletPubKey)
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"aud":   metadata.CredentialIssuer,
+		"iat":   time.Now().Unix(),
+		"nonce": token.CNonce,
+	})
+	require.NoError(t, err)
+
+	jws, err := jose.NewJWS(jose.Headers{
+		jose.HeaderAlgorithm: "EdDSA",
+		jose.HeaderKeyID:     walletKeyID,
+		jose.HeaderType:      "openid4vci-proof+jwt",
+	}, nil, payload, &ed25519Signer{privKey: walletPrivKey})
+	require.NoError(t, err)
+
+	jwt, err := jws.SerializeCompact(false)
+	require.NoError(t, err)
+
+	credentialBytes, err := json.Marshal(&issuerops.CredentialRequest{
+		Format: "ldp_vc",
+		Proof:  &issuerops.CredentialRequestProof{ProofType: "jwt", JWT: jwt},
+	})
+	require.NoError(t, err)
+
+	rr = serve(t, http.MethodPost, "/issuer/oid4vci/credential", "Bearer "+token.AccessToken, "", credentialBytes)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.Contains(t, rr.Body.String(), issuerDID)
+}
+
+type ed25519Signer struct {
+	privKey ed25519.PrivateKey
+}
+
+func (s *ed25519Signer) Sign(data []byte) ([]byte, error) {
+	return ed25519.Sign(s.privKey, data), nil
+}
+
+func (s *ed25519Signer) Headers() jose.Headers {
+	return jose.Headers{jose.HeaderAlgorithm: "EdDSA"}
+}
@@ -16,6 +16,7 @@ require (
 	github.com/go-openapi/strfmt v0.20.0
 	github.com/go-openapi/swag v0.19.14
 	github.com/go-openapi/validate v0.20.2
+	github.com/golang/protobuf v1.5.1
 	github.com/google/tink/go v1.6.1-0.20210519071714-58be99b3c4d0
 	github.com/google/uuid v1.2.0
 	github.com/gorilla/mux v1.8.0
@@ -35,4 +36,5 @@ require (
 	github.com/trustbloc/edv v0.1.7-0.20210527173439-3b17690a0345
 	github.com/trustbloc/kms v0.1.7-0.20210527174658-019e1bcabd9c
 	github.com/trustbloc/trustbloc-did-method v0.1.7-0.20210514185319-4d40ab112344
+	github.com/xeipuuv/gojsonschema v1.2.0
 )
@@ -0,0 +1,219 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package challenge
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	"github.com/trustbloc/edge-service/pkg/claim"
+)
+
+const (
+	storeName = "challenge"
+
+	challengeKeyPrefix = "challenge_"
+	challengeTagKey    = "challenge"
+
+	challengeSize = 32
+)
+
+var logger = log.New("edge-service-challenge")
+
+var (
+	// ErrNotFound is returned when the challenge wasn't issued, or not for the profile and domain.
+	ErrNotFound = errors.New("unknown challenge")
+	// ErrExpired is returned when the challenge expired.
+	ErrExpired = errors.New("challenge expired")
+	// ErrUsed is returned when the challenge was already used.
+	ErrUsed = errors.New("challenge already used")
+)
+
+// Challenge is a single-use challenge issued by a verifier profile, for the holder to sign in the proof of its
+// presentation.
+type Challenge struct {
+	Challenge string    `json:"challenge"`
+	Profile   string    `json:"profile"`
+	Domain    string    `json:"domain,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Store issues and consumes the challenges. They are kept in the shared storage so that any replica can consume a
+// challenge issued by another one.
+type Store struct {
+	store  ariesstorage.Store
+	claims *claim.Store
+}
+
+// New returns a new challenge store.
+func New(provider ariesstorage.Provider) (*Store, error) {
+	store, err := provider.OpenStore(storeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open challenge store: %w", err)
+	}
+
+	claims, err := claim.New(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{store: store, claims: claims}, nil
+}
+
+// Issue issues a random challenge for the profile and domain, valid until it expires or is consumed.
+func (s *Store) Issue(profile, domain string, expiry time.Duration) (*Challenge, error) {
+	value := make([]byte, challengeSize)
+
+	if _, err := rand.Read(value); err != nil {
+		return nil, fmt.Errorf("failed to generate challenge: %w", err)
+	}
+
+	c := &Challenge{
+		Challenge: base64.RawURLEncoding.EncodeToString(value),
+		Profile:   profile,
+		Domain:    domain,
+		ExpiresAt: time.Now().Add(expiry).UTC(),
+	}
+
+	challengeBytes, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.Put(challengeKeyPrefix+c.Challenge, challengeBytes, ariesstorage.Tag{Name: challengeTagKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save challenge: %w", err)
+	}
+
+	return c, nil
+}
+
+// Consume uses up the challenge issued for the profile and domain, by claiming it in the shared storage until it
+// expires. At most one of concurrent consumers wins and the challenge is used up either way.
+func (s *Store) Consume(profile, domain, value string) error {
+	c, err := s.get(value)
+	if err != nil {
+		return err
+	}
+
+	if c.Profile != profile || c.Domain != domain {
+		return ErrNotFound
+	}
+
+	if time.Now().After(c.ExpiresAt) {
+		return ErrExpired
+	}
+
+	err = s.claims.ClaimOnce(challengeKeyPrefix+value, time.Until(c.ExpiresAt))
+	if errors.Is(err, claim.ErrClaimed) {
+		return ErrUsed
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to claim challenge: %w", err)
+	}
+
+	// the expired challenges and their claims may be deleted from now on
+	if time.Now().After(c.ExpiresAt) {
+		return ErrExpired
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes the expired challenges along with the expired claims, it returns the number of challenges
+// deleted. It is run periodically rather than on each issuance, as it goes through all the challenges.
+func (s *Store) DeleteExpired() (int, error) {
+	keys, err := s.query(challengeTagKey)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	deleted := 0
+
+	for _, key := range keys {
+		value := strings.TrimPrefix(key, challengeKeyPrefix)
+
+		c, errGet := s.get(value)
+		if errGet != nil || now.Before(c.ExpiresAt) {
+			continue
+		}
+
+		if err = s.store.Delete(key); err != nil {
+			return deleted, err
+		}
+
+		deleted++
+	}
+
+	if _, err = s.claims.DeleteExpired(); err != nil {
+		return deleted, err
+	}
+
+	return deleted, nil
+}
+
+func (s *Store) get(value string) (*Challenge, error) {
+	challengeBytes, err := s.store.Get(challengeKeyPrefix + value)
+	if errors.Is(err, ariesstorage.ErrDataNotFound) {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get challenge: %w", err)
+	}
+
+	c := &Challenge{}
+
+	if err = json.Unmarshal(challengeBytes, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// query returns the keys of the values tagged as the expression tells.
+func (s *Store) query(expression string) ([]string, error) {
+	iter, err := s.store.Query(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("failed to close iterator: %s", errClose.Error())
+		}
+	}()
+
+	var keys []string
+
+	more, err := iter.Next()
+
+	for ; err == nil && more; more, err = iter.Next() {
+		key, errKey := iter.Key()
+		if errKey != nil {
+			return nil, errKey
+		}
+
+		keys = append(keys, key)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("iterator next: %w", err)
+	}
+
+	return keys, nil
+}
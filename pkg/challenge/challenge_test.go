@@ -0,0 +1,121 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package challenge
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	ariesmockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("test open store failure", func(t *testing.T) {
+		store, err := New(&ariesmockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")})
+		require.Nil(t, store)
+		require.EqualError(t, err, "failed to open challenge store: open error")
+	})
+}
+
+func TestStore_Consume(t *testing.T) {
+	provider := ariesmemstorage.NewProvider()
+
+	store, err := New(provider)
+	require.NoError(t, err)
+
+	t.Run("test consume success", func(t *testing.T) {
+		c, err := store.Issue("verifier", "example.com", time.Minute)
+		require.NoError(t, err)
+		require.NotEmpty(t, c.Challenge)
+		require.Equal(t, "verifier", c.Profile)
+		require.Equal(t, "example.com", c.Domain)
+
+		// another replica sharing the storage consumes the challenge
+		replica, err := New(provider)
+		require.NoError(t, err)
+
+		require.NoError(t, replica.Consume("verifier", "example.com", c.Challenge))
+		require.Equal(t, ErrUsed, store.Consume("verifier", "example.com", c.Challenge))
+	})
+
+	t.Run("test unknown challenge", func(t *testing.T) {
+		require.Equal(t, ErrNotFound, store.Consume("verifier", "example.com", "unknown"))
+
+		c, err := store.Issue("verifier", "example.com", time.Minute)
+		require.NoError(t, err)
+
+		require.Equal(t, ErrNotFound, store.Consume("other", "example.com", c.Challenge))
+		require.Equal(t, ErrNotFound, store.Consume("verifier", "other.com", c.Challenge))
+		require.NoError(t, store.Consume("verifier", "example.com", c.Challenge))
+	})
+
+	t.Run("test expired challenge", func(t *testing.T) {
+		c, err := store.Issue("verifier", "", -time.Second)
+		require.NoError(t, err)
+
+		require.Equal(t, ErrExpired, store.Consume("verifier", "", c.Challenge))
+
+		deleted, err := store.DeleteExpired()
+		require.NoError(t, err)
+		require.Equal(t, 1, deleted)
+		require.Equal(t, ErrNotFound, store.Consume("verifier", "", c.Challenge))
+	})
+
+	t.Run("test concurrent consumers", func(t *testing.T) {
+		c, err := store.Issue("verifier", "", time.Minute)
+		require.NoError(t, err)
+
+		const consumers = 10
+
+		var (
+			wg    sync.WaitGroup
+			mutex sync.Mutex
+			wins  int
+		)
+
+		for i := 0; i < consumers; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				if store.Consume("verifier", "", c.Challenge) == nil {
+					mutex.Lock()
+					wins++
+					mutex.Unlock()
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		require.LessOrEqual(t, wins, 1)
+		require.Equal(t, ErrUsed, store.Consume("verifier", "", c.Challenge))
+	})
+
+	t.Run("test store errors", func(t *testing.T) {
+		s := &Store{store: &ariesmockstorage.MockStore{
+			Store:  make(map[string]ariesmockstorage.DBEntry),
+			ErrPut: errors.New("put error"),
+		}}
+
+		_, err := s.Issue("verifier", "", time.Minute)
+		require.EqualError(t, err, "failed to save challenge: put error")
+
+		s = &Store{store: &ariesmockstorage.MockStore{
+			Store:  make(map[string]ariesmockstorage.DBEntry),
+			ErrGet: errors.New("get error"),
+		}}
+
+		err = s.Consume("verifier", "", "value")
+		require.EqualError(t, err, "failed to get challenge: get error")
+	})
+}
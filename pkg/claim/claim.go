@@ -0,0 +1,230 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package claim
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+)
+
+const (
+	storeName = "claim"
+
+	claimKeyPattern = "claim_%s_%s"
+	claimTagKey     = "claim"
+)
+
+var logger = log.New("edge-service-claim")
+
+// ErrClaimed is returned when the name is claimed by someone else.
+var ErrClaimed = errors.New("claimed by someone else")
+
+// Claim is a claim of a name, held until it is released or expires.
+type Claim struct {
+	key  string
+	name string
+	tag  string
+}
+
+type claimRecord struct {
+	Name      string    `json:"name"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Store claims names for the replicas of the service to do something one at a time, like creating a resource once.
+// The claims are kept in the shared storage.
+type Store struct {
+	store ariesstorage.Store
+}
+
+// New returns a new claim store.
+func New(provider ariesstorage.Provider) (*Store, error) {
+	store, err := provider.OpenStore(storeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open claim store: %w", err)
+	}
+
+	return &Store{store: store}, nil
+}
+
+// Claim claims the name until the claim is released or expires, the claims of a replica that went away expire. A
+// claimant that doesn't hold the name withdraws its claim and gets ErrClaimed.
+func (s *Store) Claim(name string, expiry time.Duration) (*Claim, error) {
+	return s.claim(name, expiry, true)
+}
+
+// ClaimOnce claims the name for good, until the claim expires, to use up a single-use value. A claimant that doesn't
+// hold the name gets ErrClaimed but keeps its claim, so that the value is used up either way.
+func (s *Store) ClaimOnce(name string, expiry time.Duration) error {
+	_, err := s.claim(name, expiry, false)
+
+	return err
+}
+
+// claim claims the name. The storage can't compare and swap, so each claimant saves a claim of its own and then looks
+// up all the claims of the name: it only holds the name if it sees its own claim alone. At most one of concurrent
+// claimants holds the name, when they see each other's claims none does.
+func (s *Store) claim(name string, expiry time.Duration, withdraw bool) (*Claim, error) {
+	tag := base64.RawURLEncoding.EncodeToString([]byte(name))
+	c := &Claim{key: fmt.Sprintf(claimKeyPattern, tag, uuid.New().String()), name: name, tag: tag}
+
+	if err := s.save(c, expiry); err != nil {
+		return nil, err
+	}
+
+	held, err := s.heldAlone(c.key, tag)
+	if err != nil || !held {
+		if withdraw {
+			if errRelease := s.Release(c); errRelease != nil {
+				logger.Warnf("failed to withdraw claim of %s: %s", name, errRelease.Error())
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, ErrClaimed
+	}
+
+	return c, nil
+}
+
+// Renew extends the claim, for the claimant to keep it while it is busy for longer than the claim lasts.
+func (s *Store) Renew(c *Claim, expiry time.Duration) error {
+	return s.save(c, expiry)
+}
+
+// Release releases the claim.
+func (s *Store) Release(c *Claim) error {
+	if err := s.store.Delete(c.key); err != nil {
+		return fmt.Errorf("failed to release claim: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) save(c *Claim, expiry time.Duration) error {
+	recordBytes, err := json.Marshal(&claimRecord{Name: c.name, ExpiresAt: time.Now().Add(expiry).UTC()})
+	if err != nil {
+		return err
+	}
+
+	err = s.store.Put(c.key, recordBytes, ariesstorage.Tag{Name: claimTagKey, Value: c.tag})
+	if err != nil {
+		return fmt.Errorf("failed to save claim: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes the expired claims of all the names, it returns the number of claims deleted. It is run
+// periodically, as the claims of the names that aren't claimed again are kept until then.
+func (s *Store) DeleteExpired() (int, error) {
+	now := time.Now()
+
+	var expired []string
+
+	err := s.each(claimTagKey, func(key string, record *claimRecord) (bool, error) {
+		if now.After(record.ExpiresAt) {
+			expired = append(expired, key)
+		}
+
+		return true, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i, key := range expired {
+		if err = s.store.Delete(key); err != nil {
+			return i, fmt.Errorf("failed to delete expired claim: %w", err)
+		}
+	}
+
+	return len(expired), nil
+}
+
+// heldAlone tells whether the claim is the only one of the name, the expired claims are deleted.
+func (s *Store) heldAlone(key, tag string) (bool, error) {
+	alone := true
+
+	err := s.each(claimTagKey+":"+tag, func(other string, record *claimRecord) (bool, error) {
+		if other == key {
+			return true, nil
+		}
+
+		if time.Now().After(record.ExpiresAt) {
+			if err := s.store.Delete(other); err != nil {
+				logger.Warnf("failed to delete expired claim of %s: %s", record.Name, err.Error())
+			}
+
+			return true, nil
+		}
+
+		alone = false
+
+		return false, nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return alone, nil
+}
+
+// each calls f with the claims tagged as the expression tells, until it returns false.
+func (s *Store) each(expression string, f func(key string, record *claimRecord) (bool, error)) error {
+	iter, err := s.store.Query(expression)
+	if err != nil {
+		return fmt.Errorf("failed to look up claims: %w", err)
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("failed to close iterator: %s", errClose.Error())
+		}
+	}()
+
+	more, err := iter.Next()
+
+	for ; err == nil && more; more, err = iter.Next() {
+		key, errKey := iter.Key()
+		if errKey != nil {
+			return errKey
+		}
+
+		value, errValue := iter.Value()
+		if errValue != nil {
+			return errValue
+		}
+
+		record := &claimRecord{}
+
+		if errValue = json.Unmarshal(value, record); errValue != nil {
+			return errValue
+		}
+
+		next, errNext := f(key, record)
+		if errNext != nil || !next {
+			return errNext
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("iterator next: %w", err)
+	}
+
+	return nil
+}
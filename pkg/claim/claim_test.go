@@ -0,0 +1,164 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package claim
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	ariesmockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("test open store failure", func(t *testing.T) {
+		store, err := New(&ariesmockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")})
+		require.Nil(t, store)
+		require.EqualError(t, err, "failed to open claim store: open error")
+	})
+}
+
+func TestStore_Claim(t *testing.T) {
+	provider := ariesmemstorage.NewProvider()
+
+	store, err := New(provider)
+	require.NoError(t, err)
+
+	t.Run("test claim and release", func(t *testing.T) {
+		c, err := store.Claim("wallet:holder", time.Minute)
+		require.NoError(t, err)
+
+		// another replica sharing the storage claims the same name
+		replica, err := New(provider)
+		require.NoError(t, err)
+
+		_, err = replica.Claim("wallet:holder", time.Minute)
+		require.Equal(t, ErrClaimed, err)
+
+		other, err := replica.Claim("wallet:other", time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, replica.Release(other))
+
+		require.NoError(t, store.Release(c))
+
+		c, err = replica.Claim("wallet:holder", time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, replica.Release(c))
+	})
+
+	t.Run("test expired claim", func(t *testing.T) {
+		_, err := store.Claim("expired", -time.Second)
+		require.NoError(t, err)
+
+		c, err := store.Claim("expired", time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, store.Release(c))
+	})
+
+	t.Run("test concurrent claimants", func(t *testing.T) {
+		const claimants = 10
+
+		var (
+			wg    sync.WaitGroup
+			mutex sync.Mutex
+			held  []*Claim
+		)
+
+		for i := 0; i < claimants; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				if c, err := store.Claim("concurrent", time.Minute); err == nil {
+					mutex.Lock()
+					held = append(held, c)
+					mutex.Unlock()
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		require.LessOrEqual(t, len(held), 1)
+
+		for _, c := range held {
+			require.NoError(t, store.Release(c))
+		}
+
+		// the claims of the claimants that didn't hold the name are withdrawn
+		c, err := store.Claim("concurrent", time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, store.Release(c))
+	})
+
+	t.Run("test renew", func(t *testing.T) {
+		c, err := store.Claim("renewed", -time.Second)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Renew(c, time.Minute))
+
+		_, err = store.Claim("renewed", time.Minute)
+		require.Equal(t, ErrClaimed, err)
+
+		require.NoError(t, store.Release(c))
+	})
+
+	t.Run("test claim once", func(t *testing.T) {
+		require.NoError(t, store.ClaimOnce("once", time.Minute))
+		require.Equal(t, ErrClaimed, store.ClaimOnce("once", time.Minute))
+
+		_, err := store.Claim("once", time.Minute)
+		require.Equal(t, ErrClaimed, err)
+	})
+
+	t.Run("test delete expired", func(t *testing.T) {
+		s, err := New(ariesmemstorage.NewProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, s.ClaimOnce("expired", -time.Second))
+		require.NoError(t, s.ClaimOnce("live", time.Minute))
+
+		deleted, err := s.DeleteExpired()
+		require.NoError(t, err)
+		require.Equal(t, 1, deleted)
+
+		require.Equal(t, ErrClaimed, s.ClaimOnce("live", time.Minute))
+	})
+
+	t.Run("test store errors", func(t *testing.T) {
+		s := &Store{store: &ariesmockstorage.MockStore{
+			Store:  make(map[string]ariesmockstorage.DBEntry),
+			ErrPut: errors.New("put error"),
+		}}
+
+		_, err := s.Claim("name", time.Minute)
+		require.EqualError(t, err, "failed to save claim: put error")
+
+		s = &Store{store: &ariesmockstorage.MockStore{
+			Store:    make(map[string]ariesmockstorage.DBEntry),
+			ErrQuery: errors.New("query error"),
+		}}
+
+		_, err = s.Claim("name", time.Minute)
+		require.EqualError(t, err, "failed to look up claims: query error")
+
+		_, err = s.DeleteExpired()
+		require.EqualError(t, err, "failed to look up claims: query error")
+
+		s = &Store{store: &ariesmockstorage.MockStore{
+			Store:     make(map[string]ariesmockstorage.DBEntry),
+			ErrDelete: errors.New("delete error"),
+		}}
+
+		err = s.Release(&Claim{key: "key"})
+		require.EqualError(t, err, "failed to release claim: delete error")
+	})
+}
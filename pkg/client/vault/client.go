@@ -14,6 +14,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
 	"github.com/trustbloc/edge-core/pkg/log"
 
 	"github.com/trustbloc/edge-service/pkg/restapi/vault"
@@ -22,9 +23,15 @@ import (
 
 const (
 	saveDocPath              = "/vaults/%s/docs"
+	queryDocsPath            = "/vaults/%s/docs/query"
+	trackDocsPath            = "/vaults/%s/docs/track"
 	getDocMetadataPath       = "/vaults/%s/docs/%s/metadata"
 	getAuthorizationsPath    = "/vaults/%s/authorizations/%s"
 	createAuthorizationsPath = "/vaults/%s/authorizations"
+	createRecipientKeyPath   = "/vaults/%s/recipient-keys"
+	keyRotationPath          = "/vaults/%s/key-rotation"
+	exportDocsPath           = "/vaults/%s/export"
+	importDocsPath           = "/vaults/%s/import"
 )
 
 var logger = log.New("vault-client")
@@ -78,8 +85,9 @@ func (c *Client) CreateVault() (*vault.CreatedVault, error) {
 	return &result, nil
 }
 
-// SaveDoc saves a document.
-func (c *Client) SaveDoc(vaultID, id string, content interface{}) (*vault.DocumentMetadata, error) {
+// SaveDoc saves a document. Indexed attributes can be used later to query documents.
+func (c *Client) SaveDoc(vaultID, id string, content interface{},
+	indexed ...vault.IndexedAttribute) (*vault.DocumentMetadata, error) {
 	target := c.baseURL + fmt.Sprintf(saveDocPath, url.QueryEscape(vaultID))
 
 	raw, err := json.Marshal(content)
@@ -90,6 +98,7 @@ func (c *Client) SaveDoc(vaultID, id string, content interface{}) (*vault.Docume
 	src, err := json.Marshal(operation.SaveDocRequestBody{
 		ID:      id,
 		Content: raw,
+		Indexed: indexed,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("marshal: %w", err)
@@ -115,6 +124,55 @@ func (c *Client) SaveDoc(vaultID, id string, content interface{}) (*vault.Docume
 	return &result, nil
 }
 
+// QueryDocs returns metadata of the documents that have the given indexed attribute.
+func (c *Client) QueryDocs(vaultID, name, value string) ([]*vault.DocumentMetadata, error) {
+	target := c.baseURL + fmt.Sprintf(queryDocsPath, url.QueryEscape(vaultID))
+
+	src, err := json.Marshal(operation.QueryDocsBody{Name: name, Value: value})
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+
+	resp, err := c.sendHTTPRequest(req, http.StatusOK)
+	if err != nil {
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+
+	var result []*vault.DocumentMetadata
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal to DocumentMetadata: %w", err)
+	}
+
+	return result, nil
+}
+
+// TrackDocs registers the documents saved in the vault before its documents were tracked.
+func (c *Client) TrackDocs(vaultID string, docIDs []string) error {
+	target := c.baseURL + fmt.Sprintf(trackDocsPath, url.QueryEscape(vaultID))
+
+	src, err := json.Marshal(operation.TrackDocsBody{DocIDs: docIDs})
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(src))
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+
+	_, err = c.sendHTTPRequest(req, http.StatusOK)
+	if err != nil {
+		return fmt.Errorf("http request: %w", err)
+	}
+
+	return nil
+}
+
 // GetDocMetaData get doc metadata
 func (c *Client) GetDocMetaData(vaultID, docID string) (*vault.DocumentMetadata, error) { // nolint: dupl
 	target := c.baseURL + fmt.Sprintf(getDocMetadataPath, url.QueryEscape(vaultID), url.QueryEscape(docID))
@@ -168,6 +226,28 @@ func (c *Client) CreateAuthorization(vaultID, requestingParty string,
 	return &result, nil
 }
 
+// ListAuthorizations returns the vault's authorizations that have not expired yet, without tokens.
+func (c *Client) ListAuthorizations(vaultID string) ([]*vault.CreatedAuthorization, error) {
+	target := c.baseURL + fmt.Sprintf(createAuthorizationsPath, url.QueryEscape(vaultID))
+
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+
+	resp, err := c.sendHTTPRequest(req, http.StatusOK)
+	if err != nil {
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+
+	var result []*vault.CreatedAuthorization
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal to CreatedAuthorization: %w", err)
+	}
+
+	return result, nil
+}
+
 // GetAuthorization returns an authorization.
 func (c *Client) GetAuthorization(vaultID, id string) (*vault.CreatedAuthorization, error) { // nolint: dupl
 	target := c.baseURL + fmt.Sprintf(getAuthorizationsPath, url.QueryEscape(vaultID), url.QueryEscape(id))
@@ -190,6 +270,113 @@ func (c *Client) GetAuthorization(vaultID, id string) (*vault.CreatedAuthorizati
 	return &result, nil
 }
 
+// CreateRecipientKey creates a key in the vault's keystore to export documents to.
+func (c *Client) CreateRecipientKey(vaultID string) (*crypto.PublicKey, error) {
+	target := c.baseURL + fmt.Sprintf(createRecipientKeyPath, url.QueryEscape(vaultID))
+
+	req, err := http.NewRequest(http.MethodPost, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+
+	resp, err := c.sendHTTPRequest(req, http.StatusCreated)
+	if err != nil {
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+
+	var result crypto.PublicKey
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal to PublicKey: %w", err)
+	}
+
+	return &result, nil
+}
+
+// RotateKey starts a rotation of the vault's encryption key.
+func (c *Client) RotateKey(vaultID string) (*vault.KeyRotation, error) {
+	return c.keyRotation(http.MethodPost, vaultID, http.StatusAccepted)
+}
+
+// GetKeyRotation returns the progress of the vault's latest key rotation.
+func (c *Client) GetKeyRotation(vaultID string) (*vault.KeyRotation, error) {
+	return c.keyRotation(http.MethodGet, vaultID, http.StatusOK)
+}
+
+func (c *Client) keyRotation(method, vaultID string, status int) (*vault.KeyRotation, error) {
+	target := c.baseURL + fmt.Sprintf(keyRotationPath, url.QueryEscape(vaultID))
+
+	req, err := http.NewRequest(method, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+
+	resp, err := c.sendHTTPRequest(req, status)
+	if err != nil {
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+
+	var result vault.KeyRotation
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal to KeyRotation: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ExportDocs exports all documents of the vault encrypted to the given recipient key.
+func (c *Client) ExportDocs(vaultID string, recipientKey *crypto.PublicKey) (*vault.Archive, error) {
+	target := c.baseURL + fmt.Sprintf(exportDocsPath, url.QueryEscape(vaultID))
+
+	src, err := json.Marshal(operation.ExportDocsBody{RecipientKey: recipientKey})
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+
+	resp, err := c.sendHTTPRequest(req, http.StatusOK)
+	if err != nil {
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+
+	var result vault.Archive
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal to Archive: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ImportDocs imports documents from the archive into the vault.
+func (c *Client) ImportDocs(vaultID string, archive *vault.Archive) ([]*vault.DocumentMetadata, error) {
+	target := c.baseURL + fmt.Sprintf(importDocsPath, url.QueryEscape(vaultID))
+
+	src, err := json.Marshal(archive)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+
+	resp, err := c.sendHTTPRequest(req, http.StatusCreated)
+	if err != nil {
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+
+	var result []*vault.DocumentMetadata
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal to DocumentMetadata: %w", err)
+	}
+
+	return result, nil
+}
+
 func (c *Client) sendHTTPRequest(req *http.Request, status int) ([]byte, error) { // nolunt: dupl
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
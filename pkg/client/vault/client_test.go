@@ -13,9 +13,11 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/edge-service/pkg/restapi/vault"
+	"github.com/trustbloc/edge-service/pkg/restapi/vault/operation"
 )
 
 func TestClient_GetDocMetaData(t *testing.T) {
@@ -215,6 +217,84 @@ func TestClient_SaveDoc(t *testing.T) {
 	})
 }
 
+func TestClient_QueryDocs(t *testing.T) {
+	t.Run("Send request (error)", func(t *testing.T) {
+		_, err := New("").QueryDocs("vID", "name", "value")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported protocol scheme")
+	})
+
+	t.Run("Unmarshal (error)", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, err := fmt.Fprint(w, "wrongValue")
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		_, err := New(serv.URL).QueryDocs("vID", "name", "value")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal to DocumentMetadata")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body operation.QueryDocsBody
+
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "name", body.Name)
+			require.Equal(t, "value", body.Value)
+
+			w.WriteHeader(http.StatusOK)
+			_, err := fmt.Fprint(w, `[{"docID":"doc1"}]`)
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		docs, err := New(serv.URL).QueryDocs("vID", "name", "value")
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+	})
+}
+
+func TestClient_ListAuthorizations(t *testing.T) {
+	t.Run("Send request (error)", func(t *testing.T) {
+		_, err := New("").ListAuthorizations("vID")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported protocol scheme")
+	})
+
+	t.Run("Unmarshal (error)", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, err := fmt.Fprint(w, "wrongValue")
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		_, err := New(serv.URL).ListAuthorizations("vID")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal to CreatedAuthorization")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodGet, r.Method)
+
+			w.WriteHeader(http.StatusOK)
+			_, err := fmt.Fprint(w, `[{"id":"1","requestingParty":"rp","expiresAt":"2021-06-10T10:00:00Z"}]`)
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		auths, err := New(serv.URL).ListAuthorizations("vID")
+		require.NoError(t, err)
+		require.Len(t, auths, 1)
+		require.Equal(t, "rp", auths[0].RequestingParty)
+		require.NotNil(t, auths[0].ExpiresAt)
+	})
+}
+
 func TestClient_GetAuthorization(t *testing.T) {
 	t.Run("Send request (error)", func(t *testing.T) {
 		_, err := New("").GetAuthorization("vid", "id")
@@ -254,3 +334,185 @@ func TestClient_GetAuthorization(t *testing.T) {
 		require.Equal(t, ID, p.ID)
 	})
 }
+
+func TestClient_CreateRecipientKey(t *testing.T) {
+	t.Run("Send request (error)", func(t *testing.T) {
+		_, err := New("").CreateRecipientKey("vID")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported protocol scheme")
+	})
+
+	t.Run("Unmarshal (error)", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			_, err := fmt.Fprint(w, "wrongValue")
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		_, err := New(serv.URL).CreateRecipientKey("vID")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal to PublicKey")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			_, err := fmt.Fprint(w, `{"kid":"kid"}`)
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		key, err := New(serv.URL).CreateRecipientKey("vID")
+		require.NoError(t, err)
+		require.Equal(t, "kid", key.KID)
+	})
+}
+
+func TestClient_RotateKey(t *testing.T) {
+	t.Run("Send request (error)", func(t *testing.T) {
+		_, err := New("").RotateKey("vID")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported protocol scheme")
+	})
+
+	t.Run("Unmarshal (error)", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			_, err := fmt.Fprint(w, "wrongValue")
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		_, err := New(serv.URL).RotateKey("vID")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal to KeyRotation")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPost, r.Method)
+
+			w.WriteHeader(http.StatusAccepted)
+			_, err := fmt.Fprint(w, `{"status":"in-progress","total":3}`)
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		rotation, err := New(serv.URL).RotateKey("vID")
+		require.NoError(t, err)
+		require.Equal(t, vault.KeyRotationInProgress, rotation.Status)
+		require.Equal(t, 3, rotation.Total)
+	})
+}
+
+func TestClient_GetKeyRotation(t *testing.T) {
+	t.Run("Send request (error)", func(t *testing.T) {
+		_, err := New("").GetKeyRotation("vID")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported protocol scheme")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodGet, r.Method)
+
+			w.WriteHeader(http.StatusOK)
+			_, err := fmt.Fprint(w, `{"status":"completed","total":3,"done":3}`)
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		rotation, err := New(serv.URL).GetKeyRotation("vID")
+		require.NoError(t, err)
+		require.Equal(t, vault.KeyRotationCompleted, rotation.Status)
+		require.Equal(t, 3, rotation.Done)
+	})
+}
+
+func TestClient_ExportDocs(t *testing.T) {
+	t.Run("Send request (error)", func(t *testing.T) {
+		_, err := New("").ExportDocs("vID", &crypto.PublicKey{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported protocol scheme")
+	})
+
+	t.Run("Unmarshal (error)", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, err := fmt.Fprint(w, `{"vaultID":"vID","documents":[`)
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		_, err := New(serv.URL).ExportDocs("vID", &crypto.PublicKey{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal to Archive")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, err := fmt.Fprint(w, `{"vaultID":"vID","documents":[{"id":"doc1","jwe":{}}]}`)
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		archive, err := New(serv.URL).ExportDocs("vID", &crypto.PublicKey{})
+		require.NoError(t, err)
+		require.Len(t, archive.Documents, 1)
+	})
+}
+
+func TestClient_TrackDocs(t *testing.T) {
+	t.Run("Send request (error)", func(t *testing.T) {
+		err := New("").TrackDocs("vID", []string{"doc1"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported protocol scheme")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/vaults/vID/docs/track", r.URL.Path)
+
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer serv.Close()
+
+		require.NoError(t, New(serv.URL).TrackDocs("vID", []string{"doc1"}))
+	})
+}
+
+func TestClient_ImportDocs(t *testing.T) {
+	t.Run("Send request (error)", func(t *testing.T) {
+		_, err := New("").ImportDocs("vID", &vault.Archive{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported protocol scheme")
+	})
+
+	t.Run("Unmarshal (error)", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			_, err := fmt.Fprint(w, "wrongValue")
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		_, err := New(serv.URL).ImportDocs("vID", &vault.Archive{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal to DocumentMetadata")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			_, err := fmt.Fprint(w, `[{"docID":"doc1"}]`)
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		docs, err := New(serv.URL).ImportDocs("vID", &vault.Archive{})
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+	})
+}
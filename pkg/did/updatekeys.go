@@ -0,0 +1,186 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	ed25519pb "github.com/google/tink/go/proto/ed25519_go_proto"
+	"github.com/hyperledger/aries-framework-go-ext/component/vdr/orb"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+)
+
+const updateKeysStoreName = "didupdatekeys"
+
+// ErrNoUpdateKey is returned for the DIDs whose update key isn't known, the DIDs created before the update keys were
+// recorded among them. These DIDs can't be updated.
+var ErrNoUpdateKey = errors.New("no update key")
+
+// updateKeyRecord is the sidetree update key of a DID, along with the next one handed out for an update in flight.
+type updateKeyRecord struct {
+	KeyID     string `json:"keyID"`
+	NextKeyID string `json:"nextKeyID,omitempty"`
+}
+
+// UpdateKeys keeps track of the sidetree update keys of the DIDs created by the service, the keys themselves are
+// kept in the KMS. It is the orb VDR's key retriever, so that the DIDs can be updated later on.
+type UpdateKeys struct {
+	keyManager kms.KeyManager
+	store      ariesstorage.Store
+}
+
+// NewUpdateKeys returns new DID update keys instance.
+func NewUpdateKeys(keyManager kms.KeyManager, provider ariesstorage.Provider) (*UpdateKeys, error) {
+	store, err := provider.OpenStore(updateKeysStoreName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open did update keys store: %w", err)
+	}
+
+	return &UpdateKeys{keyManager: keyManager, store: store}, nil
+}
+
+// Save records the KMS key ID of the DID's update key.
+func (u *UpdateKeys) Save(didID, keyID string) error {
+	return u.put(didID, &updateKeyRecord{KeyID: keyID})
+}
+
+// Has tells whether the update key of the DID is known, so that the DID can be updated.
+func (u *UpdateKeys) Has(didID string) (bool, error) {
+	_, err := u.get(didID)
+	if errors.Is(err, ErrNoUpdateKey) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Commit makes the next update key handed out for the DID its update key, once the update went through.
+func (u *UpdateKeys) Commit(didID string) error {
+	record, err := u.get(didID)
+	if err != nil {
+		return err
+	}
+
+	if record.NextKeyID == "" {
+		return fmt.Errorf("no update in flight for did %s", didID)
+	}
+
+	return u.put(didID, &updateKeyRecord{KeyID: record.NextKeyID})
+}
+
+// GetNextRecoveryPublicKey isn't supported, the DIDs are only updated.
+func (u *UpdateKeys) GetNextRecoveryPublicKey(didID string) (crypto.PublicKey, error) {
+	return nil, fmt.Errorf("recovery of did %s isn't supported", didID)
+}
+
+// GetNextUpdatePublicKey creates the DID's next update key.
+func (u *UpdateKeys) GetNextUpdatePublicKey(didID string) (crypto.PublicKey, error) {
+	record, err := u.get(didID)
+	if err != nil {
+		return nil, err
+	}
+
+	keyID, _, err := u.keyManager.Create(kms.ED25519Type)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create next update key: %w", err)
+	}
+
+	pubKeyBytes, err := u.keyManager.ExportPubKeyBytes(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export next update key: %w", err)
+	}
+
+	record.NextKeyID = keyID
+
+	if err = u.put(didID, record); err != nil {
+		return nil, err
+	}
+
+	return ed25519.PublicKey(pubKeyBytes), nil
+}
+
+// GetSigningKey returns the DID's update key. The sidetree client signs with raw keys, the key is read from the
+// KMS key set.
+func (u *UpdateKeys) GetSigningKey(didID string, ot orb.OperationType) (crypto.PrivateKey, error) {
+	if ot != orb.Update {
+		return nil, fmt.Errorf("operation %d on did %s isn't supported", ot, didID)
+	}
+
+	record, err := u.get(didID)
+	if err != nil {
+		return nil, err
+	}
+
+	kh, err := u.keyManager.Get(record.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get update key: %w", err)
+	}
+
+	handle, ok := kh.(*keyset.Handle)
+	if !ok {
+		return nil, errors.New("update key isn't a key set handle")
+	}
+
+	ks := insecurecleartextkeyset.KeysetMaterial(handle)
+	if len(ks.Key) == 0 {
+		return nil, errors.New("update key set is empty")
+	}
+
+	privKey := &ed25519pb.Ed25519PrivateKey{}
+
+	if err = proto.Unmarshal(ks.Key[0].KeyData.Value, privKey); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal update key: %w", err)
+	}
+
+	return ed25519.NewKeyFromSeed(privKey.KeyValue), nil
+}
+
+func (u *UpdateKeys) get(didID string) (*updateKeyRecord, error) {
+	bytes, err := u.store.Get(suffix(didID))
+	if err != nil {
+		if errors.Is(err, ariesstorage.ErrDataNotFound) {
+			return nil, fmt.Errorf("%w for did %s", ErrNoUpdateKey, didID)
+		}
+
+		return nil, fmt.Errorf("failed to get update key: %w", err)
+	}
+
+	record := &updateKeyRecord{}
+
+	if err = json.Unmarshal(bytes, record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal update key: %w", err)
+	}
+
+	return record, nil
+}
+
+func (u *UpdateKeys) put(didID string, record *updateKeyRecord) error {
+	bytes, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal update key: %w", err)
+	}
+
+	if err = u.store.Put(suffix(didID), bytes); err != nil {
+		return fmt.Errorf("failed to save update key: %w", err)
+	}
+
+	return nil
+}
+
+// suffix returns the unique suffix of the DID, it doesn't change once an orb DID is anchored.
+func suffix(didID string) string {
+	return didID[strings.LastIndex(didID, ":")+1:]
+}
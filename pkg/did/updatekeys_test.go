@@ -0,0 +1,116 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did_test
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go-ext/component/vdr/orb"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/stretchr/testify/require"
+
+	did2 "github.com/trustbloc/edge-service/pkg/did"
+)
+
+func TestUpdateKeys(t *testing.T) {
+	t.Run("hands out the update keys of a did", func(t *testing.T) {
+		km := newKMS(t)
+
+		updateKeys, err := did2.NewUpdateKeys(km, mem.NewProvider())
+		require.NoError(t, err)
+
+		keyID, pubKeyBytes, err := km.CreateAndExportPubKeyBytes(kms.ED25519Type)
+		require.NoError(t, err)
+
+		// the interim and canonical forms of an orb DID share the same update key
+		require.NoError(t, updateKeys.Save("did:orb:uAAA:abc", keyID))
+
+		has, err := updateKeys.Has("did:orb:hash:abc")
+		require.NoError(t, err)
+		require.True(t, has)
+
+		signingKey, err := updateKeys.GetSigningKey("did:orb:hash:abc", orb.Update)
+		require.NoError(t, err)
+
+		privKey, ok := signingKey.(ed25519.PrivateKey)
+		require.True(t, ok)
+		require.Equal(t, ed25519.PublicKey(pubKeyBytes), privKey.Public())
+
+		nextKey, err := updateKeys.GetNextUpdatePublicKey("did:orb:hash:abc")
+		require.NoError(t, err)
+
+		require.NoError(t, updateKeys.Commit("did:orb:hash:abc"))
+
+		signingKey, err = updateKeys.GetSigningKey("did:orb:hash:abc", orb.Update)
+		require.NoError(t, err)
+
+		privKey, ok = signingKey.(ed25519.PrivateKey)
+		require.True(t, ok)
+		require.Equal(t, nextKey, privKey.Public())
+	})
+
+	t.Run("error - failed to open store", func(t *testing.T) {
+		_, err := did2.NewUpdateKeys(newKMS(t), &mockstorage.MockStoreProvider{
+			ErrOpenStoreHandle: errors.New("open error"),
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to open did update keys store: open error")
+	})
+
+	t.Run("error - no update key for did", func(t *testing.T) {
+		updateKeys, err := did2.NewUpdateKeys(newKMS(t), mem.NewProvider())
+		require.NoError(t, err)
+
+		_, err = updateKeys.GetSigningKey("did:orb:abc", orb.Update)
+		require.EqualError(t, err, "no update key for did did:orb:abc")
+
+		_, err = updateKeys.GetNextUpdatePublicKey("did:orb:abc")
+		require.EqualError(t, err, "no update key for did did:orb:abc")
+
+		require.EqualError(t, updateKeys.Commit("did:orb:abc"), "no update key for did did:orb:abc")
+		require.True(t, errors.Is(updateKeys.Commit("did:orb:abc"), did2.ErrNoUpdateKey))
+
+		has, err := updateKeys.Has("did:orb:abc")
+		require.NoError(t, err)
+		require.False(t, has)
+	})
+
+	t.Run("error - no update in flight", func(t *testing.T) {
+		updateKeys, err := did2.NewUpdateKeys(newKMS(t), mem.NewProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, updateKeys.Save("did:orb:abc", "key1"))
+		require.EqualError(t, updateKeys.Commit("did:orb:abc"), "no update in flight for did did:orb:abc")
+	})
+
+	t.Run("error - recovery isn't supported", func(t *testing.T) {
+		updateKeys, err := did2.NewUpdateKeys(newKMS(t), mem.NewProvider())
+		require.NoError(t, err)
+
+		_, err = updateKeys.GetNextRecoveryPublicKey("did:orb:abc")
+		require.EqualError(t, err, "recovery of did did:orb:abc isn't supported")
+
+		_, err = updateKeys.GetSigningKey("did:orb:abc", orb.Recover)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "isn't supported")
+	})
+
+	t.Run("error - update key not in kms", func(t *testing.T) {
+		updateKeys, err := did2.NewUpdateKeys(newKMS(t), mem.NewProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, updateKeys.Save("did:orb:abc", "unknown"))
+
+		_, err = updateKeys.GetSigningKey("did:orb:abc", orb.Update)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get update key")
+	})
+}
@@ -0,0 +1,134 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	"github.com/trustbloc/edge-service/pkg/internal/common/utils"
+)
+
+const (
+	// Context of the DID configuration resource and of its domain linkage credentials.
+	Context = "https://identity.foundation/.well-known/did-configuration/v1"
+	// WellKnownPath is where an origin publishes its DID configuration.
+	WellKnownPath = "/.well-known/did-configuration.json"
+	// LinkedDomainsServiceType is the type of the DID services that link a DID to web origins.
+	LinkedDomainsServiceType = "LinkedDomains"
+	// DomainLinkageCredentialType is the type of the credentials that attest the linkage of a DID and an origin.
+	DomainLinkageCredentialType = "DomainLinkageCredential"
+
+	vcContext = "https://www.w3.org/2018/credentials/v1"
+	vcType    = "VerifiableCredential"
+)
+
+// Configuration is the DID configuration resource an origin publishes at its well-known location.
+type Configuration struct {
+	Context    string            `json:"@context"`
+	LinkedDIDs []json.RawMessage `json:"linked_dids"`
+}
+
+// Origin returns the web origin of the URI, its scheme and host.
+func Origin(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%s is not a web origin", uri)
+	}
+
+	return u.Scheme + "://" + u.Host, nil
+}
+
+// LinkedDomains returns the origins the DID document links to with its LinkedDomains services.
+func LinkedDomains(doc *did.Doc) []string {
+	var origins []string
+
+	for _, service := range doc.Service {
+		if service.Type == LinkedDomainsServiceType && service.ServiceEndpoint != "" {
+			origins = append(origins, service.ServiceEndpoint)
+		}
+	}
+
+	return origins
+}
+
+// NewCredential returns the unsigned domain linkage credential of the DID and origin, valid from issued to expires.
+func NewCredential(didID, origin string, issued, expires time.Time) *verifiable.Credential {
+	return &verifiable.Credential{
+		Context: []string{vcContext, Context},
+		Types:   []string{vcType, DomainLinkageCredentialType},
+		Issuer:  verifiable.Issuer{ID: didID},
+		Issued:  util.NewTime(issued),
+		Expired: util.NewTime(expires),
+		Subject: []verifiable.Subject{{
+			ID:           didID,
+			CustomFields: verifiable.CustomFields{"origin": origin},
+		}},
+	}
+}
+
+// ValidateCredential checks the credential attests the linkage of the DID and origin, that it is currently valid and
+// that its linked data proofs are signed by the DID. The proofs of the credential are verified by its parsing.
+func ValidateCredential(vc *verifiable.Credential, didID, origin string) error {
+	if !utils.Contains(vc.Types, DomainLinkageCredentialType) {
+		return fmt.Errorf("credential isn't a %s", DomainLinkageCredentialType)
+	}
+
+	if vc.Issuer.ID != didID {
+		return fmt.Errorf("credential isn't issued by %s", didID)
+	}
+
+	subject, err := linkageSubject(vc)
+	if err != nil {
+		return err
+	}
+
+	if subject.ID != didID {
+		return fmt.Errorf("credential subject isn't %s", didID)
+	}
+
+	subjectOrigin, _ := subject.CustomFields["origin"].(string) // nolint: errcheck
+	if strings.TrimSuffix(subjectOrigin, "/") != strings.TrimSuffix(origin, "/") {
+		return fmt.Errorf("credential origin %s isn't %s", subjectOrigin, origin)
+	}
+
+	now := time.Now()
+
+	if vc.Issued == nil || vc.Issued.Time.After(now) {
+		return errors.New("credential isn't issued yet")
+	}
+
+	if vc.Expired == nil || vc.Expired.Time.Before(now) {
+		return errors.New("credential is expired")
+	}
+
+	for _, proof := range vc.Proofs {
+		vm, _ := proof["verificationMethod"].(string) // nolint: errcheck
+		if !strings.HasPrefix(vm, didID+"#") {
+			return fmt.Errorf("credential proof isn't signed by %s", didID)
+		}
+	}
+
+	return nil
+}
+
+func linkageSubject(vc *verifiable.Credential) (*verifiable.Subject, error) {
+	subjects, ok := vc.Subject.([]verifiable.Subject)
+	if !ok || len(subjects) != 1 {
+		return nil, errors.New("credential must have a single subject")
+	}
+
+	return &subjects[0], nil
+}
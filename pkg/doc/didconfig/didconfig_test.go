@@ -0,0 +1,139 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testDID    = "did:example:123"
+	testOrigin = "https://example.com"
+)
+
+func TestOrigin(t *testing.T) {
+	origin, err := Origin("https://example.com/credentials?x=1")
+	require.NoError(t, err)
+	require.Equal(t, testOrigin, origin)
+
+	origin, err = Origin("http://localhost:8080")
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:8080", origin)
+
+	for _, uri := range []string{"", "did:example:123", "ftp://example.com", "https://", "%"} {
+		_, err = Origin(uri)
+		require.Error(t, err, uri)
+	}
+}
+
+func TestLinkedDomains(t *testing.T) {
+	doc := &did.Doc{ID: testDID, Service: []did.Service{
+		{ID: testDID + "#agent", Type: "did-communication", ServiceEndpoint: "https://agent.example.com"},
+		{ID: testDID + "#domain-1", Type: LinkedDomainsServiceType, ServiceEndpoint: testOrigin},
+		{ID: testDID + "#domain-2", Type: LinkedDomainsServiceType, ServiceEndpoint: "https://other.example.com"},
+	}}
+
+	require.Equal(t, []string{testOrigin, "https://other.example.com"}, LinkedDomains(doc))
+	require.Empty(t, LinkedDomains(&did.Doc{ID: testDID}))
+}
+
+func TestValidateCredential(t *testing.T) {
+	now := time.Now()
+
+	newCredential := func() *verifiable.Credential {
+		vc := NewCredential(testDID, testOrigin, now.Add(-time.Hour), now.Add(time.Hour))
+		vc.Proofs = []verifiable.Proof{{"verificationMethod": testDID + "#key-1"}}
+
+		return vc
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		vc := newCredential()
+		require.Equal(t, []string{"VerifiableCredential", DomainLinkageCredentialType}, vc.Types)
+		require.Equal(t, []string{"https://www.w3.org/2018/credentials/v1", Context}, vc.Context)
+
+		require.NoError(t, ValidateCredential(vc, testDID, testOrigin))
+		require.NoError(t, ValidateCredential(vc, testDID, testOrigin+"/"))
+	})
+
+	tests := []struct {
+		name   string
+		update func(vc *verifiable.Credential)
+		err    string
+	}{
+		{
+			name:   "not a domain linkage credential",
+			update: func(vc *verifiable.Credential) { vc.Types = []string{"VerifiableCredential"} },
+			err:    "credential isn't a DomainLinkageCredential",
+		},
+		{
+			name:   "other issuer",
+			update: func(vc *verifiable.Credential) { vc.Issuer.ID = "did:example:456" },
+			err:    "credential isn't issued by " + testDID,
+		},
+		{
+			name:   "several subjects",
+			update: func(vc *verifiable.Credential) { vc.Subject = []verifiable.Subject{{ID: testDID}, {ID: testDID}} },
+			err:    "credential must have a single subject",
+		},
+		{
+			name: "other subject",
+			update: func(vc *verifiable.Credential) {
+				vc.Subject = []verifiable.Subject{{ID: "did:example:456",
+					CustomFields: verifiable.CustomFields{"origin": testOrigin}}}
+			},
+			err: "credential subject isn't " + testDID,
+		},
+		{
+			name: "other origin",
+			update: func(vc *verifiable.Credential) {
+				vc.Subject = []verifiable.Subject{{ID: testDID,
+					CustomFields: verifiable.CustomFields{"origin": "https://other.example.com"}}}
+			},
+			err: "credential origin https://other.example.com isn't " + testOrigin,
+		},
+		{
+			name:   "not issued yet",
+			update: func(vc *verifiable.Credential) { vc.Issued = util.NewTime(now.Add(time.Hour)) },
+			err:    "credential isn't issued yet",
+		},
+		{
+			name:   "no expiry",
+			update: func(vc *verifiable.Credential) { vc.Expired = nil },
+			err:    "credential is expired",
+		},
+		{
+			name:   "expired",
+			update: func(vc *verifiable.Credential) { vc.Expired = util.NewTime(now.Add(-time.Minute)) },
+			err:    "credential is expired",
+		},
+		{
+			name: "signed by another DID",
+			update: func(vc *verifiable.Credential) {
+				vc.Proofs = []verifiable.Proof{{"verificationMethod": "did:example:456#key-1"}}
+			},
+			err: "credential proof isn't signed by " + testDID,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vc := newCredential()
+			tc.update(vc)
+
+			err := ValidateCredential(vc, testDID, testOrigin)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
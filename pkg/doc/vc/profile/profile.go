@@ -7,11 +7,14 @@ package profile
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
 )
 
 const (
@@ -25,6 +28,8 @@ const (
 	governanceMode = "governance"
 )
 
+var logger = log.New("vc-profile")
+
 // New returns new credential recorder instance
 func New(provider ariesstorage.Provider) (*Profile, error) {
 	store, err := provider.OpenStore(credentialStoreName)
@@ -48,22 +53,88 @@ type DataProfile struct {
 	SignatureRepresentation verifiable.SignatureRepresentation `json:"signatureRepresentation"`
 	Creator                 string                             `json:"creator"`
 	Created                 *time.Time                         `json:"created"`
+	// KeyType is the type of the key the profile signs with, empty if it is not known.
+	KeyType string `json:"keyType,omitempty"`
+	// PreviousCreators are the keys the profile signed with before its keys were rotated, they are still in its DID.
+	PreviousCreators []string `json:"previousCreators,omitempty"`
 }
 
 // IssuerProfile struct for issuer profile
 type IssuerProfile struct {
-	URI             string          `json:"uri"`
-	EDVVaultID      string          `json:"edvVaultID"`
-	DisableVCStatus bool            `json:"disableVCStatus"`
-	OverwriteIssuer bool            `json:"overwriteIssuer"`
-	EDVCapability   json.RawMessage `json:"edvCapability,omitempty"`
-	EDVController   string          `json:"edvController"`
+	URI                    string                `json:"uri"`
+	EDVVaultID             string                `json:"edvVaultID"`
+	DisableVCStatus        bool                  `json:"disableVCStatus"`
+	OverwriteIssuer        bool                  `json:"overwriteIssuer"`
+	EDVCapability          json.RawMessage       `json:"edvCapability,omitempty"`
+	EDVController          string                `json:"edvController"`
+	StoreIssuedCredentials bool                  `json:"storeIssuedCredentials,omitempty"`
+	CredentialTemplates    []*CredentialTemplate `json:"credentialTemplates,omitempty"`
+	// RefreshPolicy enables the refresh service of the expiring credentials issued by the profile.
+	RefreshPolicy *RefreshPolicy `json:"refreshPolicy,omitempty"`
+	// IssuancePolicy restricts the credentials the profile issues.
+	IssuancePolicy *IssuancePolicy `json:"issuancePolicy,omitempty"`
+	// Deleted is set once the profile is soft deleted, it can't issue credentials anymore but its status lists are
+	// still resolvable.
+	Deleted *time.Time `json:"deleted,omitempty"`
 	*DataProfile
 }
 
+// CredentialTemplate fixes the shape of the credentials composed by an issuer profile.
+type CredentialTemplate struct {
+	ID       string   `json:"id"`
+	Contexts []string `json:"contexts,omitempty"`
+	Types    []string `json:"types"`
+	// ValidityPeriod is the number of seconds the composed credentials are valid for, they don't expire if zero.
+	ValidityPeriod int64           `json:"validityPeriod,omitempty"`
+	TermsOfUse     json.RawMessage `json:"termsOfUse,omitempty"`
+	// ClaimsSchema is the JSON Schema the subject claims must match.
+	ClaimsSchema json.RawMessage `json:"claimsSchema,omitempty"`
+}
+
+// RefreshPolicy tells how an issuer profile refreshes its expiring credentials.
+type RefreshPolicy struct {
+	// ValidityPeriod is the number of seconds the refreshed credentials are valid for, they keep the validity period
+	// of the credential they replace if zero.
+	ValidityPeriod int64 `json:"validityPeriod,omitempty"`
+	// RevokeRefreshed revokes the credential once it is replaced by the refreshed one.
+	RevokeRefreshed bool `json:"revokeRefreshed,omitempty"`
+	// RefreshWindow is the number of seconds before its expiry a credential can be refreshed, a day if zero.
+	RefreshWindow int64 `json:"refreshWindow,omitempty"`
+}
+
+// IssuancePolicy restricts the credentials an issuer profile issues, the rules left out don't apply.
+type IssuancePolicy struct {
+	// AllowedTypes are the types the credentials may have besides VerifiableCredential.
+	AllowedTypes []string `json:"allowedTypes,omitempty"`
+	// AllowedContexts are the contexts the credentials may have besides the base context of the data model.
+	AllowedContexts []string `json:"allowedContexts,omitempty"`
+	// MaxValidityPeriod is the maximum number of seconds the credentials are valid for, they must expire if set.
+	MaxValidityPeriod int64 `json:"maxValidityPeriod,omitempty"`
+	// RequiredClaims are the claims every subject must have, nested claims are dot separated paths.
+	RequiredClaims []string `json:"requiredClaims,omitempty"`
+	// ForbiddenClaims are the claims no subject may have, nested claims are dot separated paths.
+	ForbiddenClaims []string `json:"forbiddenClaims,omitempty"`
+}
+
+// CredentialTemplate returns the profile's credential template with the given ID, nil if there is none.
+func (p *IssuerProfile) CredentialTemplate(id string) *CredentialTemplate {
+	for _, template := range p.CredentialTemplates {
+		if template.ID == id {
+			return template
+		}
+	}
+
+	return nil
+}
+
 // HolderProfile struct for holder profile
 type HolderProfile struct {
 	OverwriteHolder bool `json:"overwriteHolder,omitempty"`
+	// EDVVaultID is the vault of the profile's credential wallet, it is created along with the first credential
+	// stored.
+	EDVVaultID    string          `json:"edvVaultID,omitempty"`
+	EDVCapability json.RawMessage `json:"edvCapability,omitempty"`
+	EDVController string          `json:"edvController,omitempty"`
 	*DataProfile
 }
 
@@ -79,7 +150,7 @@ func (c *Profile) SaveProfile(data *IssuerProfile) error {
 		return fmt.Errorf("save profile marshalling error: %w", err)
 	}
 
-	return c.store.Put(getDBKey(issuerMode, data.Name), bytes)
+	return c.store.Put(getDBKey(issuerMode, data.Name), bytes, getTag(issuerMode))
 }
 
 // GetProfile returns profile information for given profile name from underlying store
@@ -99,6 +170,28 @@ func (c *Profile) GetProfile(name string) (*IssuerProfile, error) {
 	return response, nil
 }
 
+// ListProfiles returns the page of issuer profiles starting at offset, sorted by name, along with the total number
+// of issuer profiles. All the profiles from offset on are returned if limit is zero.
+func (c *Profile) ListProfiles(offset, limit int) ([]*IssuerProfile, int, error) {
+	var profiles []*IssuerProfile
+
+	err := c.list(issuerMode, func(bytes []byte) error {
+		profile := &IssuerProfile{}
+		profiles = append(profiles, profile)
+
+		return json.Unmarshal(bytes, profile)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+
+	start, end := Page(len(profiles), offset, limit)
+
+	return profiles[start:end], len(profiles), nil
+}
+
 // DeleteProfile deletes the profile from the underlying store.
 func (c *Profile) DeleteProfile(name string) error {
 	return c.store.Delete(getDBKey(issuerMode, name))
@@ -111,7 +204,7 @@ func (c *Profile) SaveHolderProfile(data *HolderProfile) error {
 		return fmt.Errorf("save holder profile : %w", err)
 	}
 
-	return c.store.Put(getDBKey(holderMode, data.Name), bytes)
+	return c.store.Put(getDBKey(holderMode, data.Name), bytes, getTag(holderMode))
 }
 
 // GetHolderProfile retrieves the holder profile based on name.
@@ -131,6 +224,28 @@ func (c *Profile) GetHolderProfile(name string) (*HolderProfile, error) {
 	return response, nil
 }
 
+// ListHolderProfiles returns the page of holder profiles starting at offset, sorted by name, along with the total
+// number of holder profiles. All the profiles from offset on are returned if limit is zero.
+func (c *Profile) ListHolderProfiles(offset, limit int) ([]*HolderProfile, int, error) {
+	var profiles []*HolderProfile
+
+	err := c.list(holderMode, func(bytes []byte) error {
+		profile := &HolderProfile{}
+		profiles = append(profiles, profile)
+
+		return json.Unmarshal(bytes, profile)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+
+	start, end := Page(len(profiles), offset, limit)
+
+	return profiles[start:end], len(profiles), nil
+}
+
 // DeleteHolderProfile deletes the holder profile from the underlying store.
 func (c *Profile) DeleteHolderProfile(name string) error {
 	return c.store.Delete(getDBKey(holderMode, name))
@@ -143,7 +258,7 @@ func (c *Profile) SaveGovernanceProfile(data *GovernanceProfile) error {
 		return fmt.Errorf("save governance profile : %w", err)
 	}
 
-	return c.store.Put(getDBKey(governanceMode, data.Name), bytes)
+	return c.store.Put(getDBKey(governanceMode, data.Name), bytes, getTag(governanceMode))
 }
 
 // GetGovernanceProfile retrieves the governance profile based on name.
@@ -163,6 +278,113 @@ func (c *Profile) GetGovernanceProfile(name string) (*GovernanceProfile, error)
 	return response, nil
 }
 
+// ListGovernanceProfiles returns the page of governance profiles starting at offset, sorted by name, along with
+// the total number of governance profiles. All the profiles from offset on are returned if limit is zero.
+func (c *Profile) ListGovernanceProfiles(offset, limit int) ([]*GovernanceProfile, int, error) {
+	var profiles []*GovernanceProfile
+
+	err := c.list(governanceMode, func(bytes []byte) error {
+		profile := &GovernanceProfile{}
+		profiles = append(profiles, profile)
+
+		return json.Unmarshal(bytes, profile)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+
+	start, end := Page(len(profiles), offset, limit)
+
+	return profiles[start:end], len(profiles), nil
+}
+
+// TagProfiles tags the issuer, holder and governance profiles with the given names, so they are listed.
+// Profiles saved before they could be listed are not tagged, their names have to be given as the store can't
+// enumerate its records. Unknown names are skipped. It returns the number of tagged profiles.
+func (c *Profile) TagProfiles(names ...string) (int, error) {
+	var tagged int
+
+	for _, mode := range []string{issuerMode, holderMode, governanceMode} {
+		for _, name := range names {
+			bytes, err := c.store.Get(getDBKey(mode, name))
+			if errors.Is(err, ariesstorage.ErrDataNotFound) {
+				continue
+			}
+
+			if err != nil {
+				return tagged, fmt.Errorf("get %s profile %s: %w", mode, name, err)
+			}
+
+			err = c.store.Put(getDBKey(mode, name), bytes, getTag(mode))
+			if err != nil {
+				return tagged, fmt.Errorf("tag %s profile %s: %w", mode, name, err)
+			}
+
+			tagged++
+		}
+	}
+
+	return tagged, nil
+}
+
+// list calls unmarshal with every profile of the mode.
+func (c *Profile) list(mode string, unmarshal func([]byte) error) error {
+	iter, err := c.store.Query(getTag(mode).Name)
+	if err != nil {
+		return fmt.Errorf("query %s profiles: %w", mode, err)
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("failed to close iterator: %s", errClose.Error())
+		}
+	}()
+
+	more, err := iter.Next()
+	if err != nil {
+		return fmt.Errorf("iterator next: %w", err)
+	}
+
+	for more {
+		bytes, err := iter.Value()
+		if err != nil {
+			return fmt.Errorf("iterator value: %w", err)
+		}
+
+		if err := unmarshal(bytes); err != nil {
+			return fmt.Errorf("unmarshal %s profile: %w", mode, err)
+		}
+
+		more, err = iter.Next()
+		if err != nil {
+			return fmt.Errorf("iterator next: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Page returns the bounds of the page of n sorted items starting at offset. All the items from offset on are in
+// the page if limit is zero.
+func Page(n, offset, limit int) (int, int) {
+	if offset > n {
+		offset = n
+	}
+
+	if limit == 0 || offset+limit > n {
+		return offset, n
+	}
+
+	return offset, offset + limit
+}
+
+// getTag returns the tag of the profiles of the mode, they are listed by it.
+func getTag(mode string) ariesstorage.Tag {
+	return ariesstorage.Tag{Name: mode + "Profile"}
+}
+
 func getDBKey(mode, name string) string {
 	return fmt.Sprintf(keyPattern, profileKeyPrefix, mode, name)
 }
@@ -13,6 +13,7 @@ import (
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	ariesmockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/stretchr/testify/require"
 )
 
@@ -349,3 +350,149 @@ func TestGovernanceHolder(t *testing.T) {
 		require.Nil(t, resp)
 	})
 }
+
+func TestProfile_List(t *testing.T) {
+	t.Run("test list profiles of every mode", func(t *testing.T) {
+		profileStore, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		for _, name := range []string{"c", "a", "b"} {
+			require.NoError(t, profileStore.SaveProfile(&IssuerProfile{DataProfile: &DataProfile{Name: name}}))
+		}
+
+		require.NoError(t, profileStore.SaveHolderProfile(&HolderProfile{DataProfile: &DataProfile{Name: "holder"}}))
+		require.NoError(t, profileStore.SaveGovernanceProfile(
+			&GovernanceProfile{DataProfile: &DataProfile{Name: "governance"}}))
+
+		issuers, total, err := profileStore.ListProfiles(0, 2)
+		require.NoError(t, err)
+		require.Equal(t, 3, total)
+		require.Len(t, issuers, 2)
+		require.Equal(t, "a", issuers[0].Name)
+		require.Equal(t, "b", issuers[1].Name)
+
+		issuers, total, err = profileStore.ListProfiles(2, 2)
+		require.NoError(t, err)
+		require.Equal(t, 3, total)
+		require.Len(t, issuers, 1)
+		require.Equal(t, "c", issuers[0].Name)
+
+		issuers, _, err = profileStore.ListProfiles(5, 2)
+		require.NoError(t, err)
+		require.Empty(t, issuers)
+
+		holders, total, err := profileStore.ListHolderProfiles(0, 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, total)
+		require.Equal(t, "holder", holders[0].Name)
+
+		governance, total, err := profileStore.ListGovernanceProfiles(0, 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, total)
+		require.Equal(t, "governance", governance[0].Name)
+	})
+
+	t.Run("test list profiles - query error", func(t *testing.T) {
+		profileStore, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:    make(map[string]ariesmockstorage.DBEntry),
+			ErrQuery: fmt.Errorf("query error"),
+		}})
+		require.NoError(t, err)
+
+		_, _, err = profileStore.ListProfiles(0, 0)
+		require.EqualError(t, err, "query issuer profiles: query error")
+
+		_, _, err = profileStore.ListHolderProfiles(0, 0)
+		require.EqualError(t, err, "query holder profiles: query error")
+
+		_, _, err = profileStore.ListGovernanceProfiles(0, 0)
+		require.EqualError(t, err, "query governance profiles: query error")
+	})
+
+	t.Run("test list profiles - invalid json", func(t *testing.T) {
+		profileStore, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store: map[string]ariesmockstorage.DBEntry{
+				getDBKey(issuerMode, "issuer"): {Value: []byte("invalid-data"), Tags: []ariesstorage.Tag{getTag(issuerMode)}},
+			},
+		}})
+		require.NoError(t, err)
+
+		_, _, err = profileStore.ListProfiles(0, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal issuer profile")
+	})
+}
+
+func TestProfile_TagProfiles(t *testing.T) {
+	t.Run("test tag profiles saved before they were listed", func(t *testing.T) {
+		issuer, err := json.Marshal(&IssuerProfile{DataProfile: &DataProfile{Name: "issuer"}})
+		require.NoError(t, err)
+
+		holder, err := json.Marshal(&HolderProfile{DataProfile: &DataProfile{Name: "holder"}})
+		require.NoError(t, err)
+
+		profileStore, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store: map[string]ariesmockstorage.DBEntry{
+				getDBKey(issuerMode, "issuer"): {Value: issuer},
+				getDBKey(holderMode, "holder"): {Value: holder},
+			},
+		}})
+		require.NoError(t, err)
+
+		_, total, err := profileStore.ListProfiles(0, 0)
+		require.NoError(t, err)
+		require.Zero(t, total)
+
+		tagged, err := profileStore.TagProfiles("issuer", "holder", "unknown")
+		require.NoError(t, err)
+		require.Equal(t, 2, tagged)
+
+		issuers, _, err := profileStore.ListProfiles(0, 0)
+		require.NoError(t, err)
+		require.Len(t, issuers, 1)
+		require.Equal(t, "issuer", issuers[0].Name)
+
+		holders, _, err := profileStore.ListHolderProfiles(0, 0)
+		require.NoError(t, err)
+		require.Len(t, holders, 1)
+		require.Equal(t, "holder", holders[0].Name)
+	})
+
+	t.Run("test tag profiles - get error", func(t *testing.T) {
+		profileStore, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:  make(map[string]ariesmockstorage.DBEntry),
+			ErrGet: fmt.Errorf("get error"),
+		}})
+		require.NoError(t, err)
+
+		_, err = profileStore.TagProfiles("issuer")
+		require.EqualError(t, err, "get issuer profile issuer: get error")
+	})
+
+	t.Run("test tag profiles - put error", func(t *testing.T) {
+		profileStore, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store: map[string]ariesmockstorage.DBEntry{
+				getDBKey(issuerMode, "issuer"): {Value: []byte("{}")},
+			},
+			ErrPut: fmt.Errorf("put error"),
+		}})
+		require.NoError(t, err)
+
+		_, err = profileStore.TagProfiles("issuer")
+		require.EqualError(t, err, "tag issuer profile issuer: put error")
+	})
+}
+
+func TestPage(t *testing.T) {
+	start, end := Page(10, 0, 0)
+	require.Equal(t, []int{0, 10}, []int{start, end})
+
+	start, end = Page(10, 4, 3)
+	require.Equal(t, []int{4, 7}, []int{start, end})
+
+	start, end = Page(10, 8, 3)
+	require.Equal(t, []int{8, 10}, []int{start, end})
+
+	start, end = Page(10, 12, 3)
+	require.Equal(t, []int{10, 10}, []int{start, end})
+}
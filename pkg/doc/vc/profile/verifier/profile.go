@@ -8,9 +8,15 @@ package verifier
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 
+	"github.com/hyperledger/aries-framework-go/pkg/doc/presexch"
 	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
 )
 
 const (
@@ -18,8 +24,12 @@ const (
 	profileKeyPrefix = "profile"
 
 	storeName = "verifier"
+
+	profileTagName = "verifierProfile"
 )
 
+var logger = log.New("verifier-profile")
+
 // Profile db operation
 type Profile struct {
 	store ariesstorage.Store
@@ -31,6 +41,50 @@ type ProfileData struct {
 	Name               string   `json:"name"`
 	CredentialChecks   []string `json:"credentialChecks,omitempty"`
 	PresentationChecks []string `json:"presentationChecks,omitempty"`
+	// PresentationDefinitions are the DIF presentation definitions the verifier asks holders to submit.
+	PresentationDefinitions []*presexch.PresentationDefinition `json:"presentationDefinitions,omitempty"`
+	// ChallengeRequired requires the presentations to sign a challenge issued by the profile, used once.
+	ChallengeRequired bool `json:"challengeRequired,omitempty"`
+	// HolderBinding sets the exceptions of the holderBinding presentation check.
+	HolderBinding *HolderBinding `json:"holderBinding,omitempty"`
+	// ProofPolicy sets which proofs of the credentials and presentations must be verified.
+	ProofPolicy *ProofPolicy `json:"proofPolicy,omitempty"`
+	// DisclosurePolicy sets the claims the credentials must disclose or withhold, for the disclosure credential check.
+	DisclosurePolicy *DisclosurePolicy `json:"disclosurePolicy,omitempty"`
+	// CachedStatusLists lets the status check use the status list fetched last, up to an hour old, when it can't be
+	// fetched. The verification then warns that the status may be out of date, otherwise the status check fails.
+	CachedStatusLists bool `json:"cachedStatusLists,omitempty"`
+}
+
+// DisclosurePolicy sets the subject claims the credentials must disclose or withhold, as dot separated paths like
+// degree.type. A credential derived for selective disclosure discloses the claims it reveals, others all of them.
+type DisclosurePolicy struct {
+	RequiredAttributes  []string `json:"requiredAttributes,omitempty"`
+	ForbiddenAttributes []string `json:"forbiddenAttributes,omitempty"`
+	// NonceBound requires a BbsBlsSignatureProof2020 proof derived with the verification challenge as nonce.
+	NonceBound bool `json:"nonceBound,omitempty"`
+}
+
+// ProofPolicy sets which proofs of a credential or presentation must be verified, one of them must be the issuer's or
+// the holder's in any case.
+type ProofPolicy struct {
+	// Rule is all, by default, for all the proofs to be verified, any for one of them, or requiredSigners for the
+	// proofs of the required signers.
+	Rule string `json:"rule,omitempty"`
+	// RequiredSigners are the DIDs that must have signed the credentials, with the requiredSigners rule. All the
+	// proofs of a presentation must be verified with this rule.
+	RequiredSigners []string `json:"requiredSigners,omitempty"`
+}
+
+// HolderBinding sets which credentials a holder may present without being their subject.
+type HolderBinding struct {
+	// BearerTypes are the types of the bearer credentials, anyone may present them.
+	BearerTypes []string `json:"bearerTypes,omitempty"`
+	// AllowSubjectless allows the credentials whose subjects have no ID, bearer credentials as well.
+	AllowSubjectless bool `json:"allowSubjectless,omitempty"`
+	// RelationshipClaims are the dot separated paths of the subject claims stating the relationship of the holder to
+	// the subject, like parent or guardian. The holder may present the credential if it is the ID of such a claim.
+	RelationshipClaims []string `json:"relationshipClaims,omitempty"`
 }
 
 // New returns new credential recorder instance
@@ -50,7 +104,7 @@ func (c *Profile) SaveProfile(data *ProfileData) error {
 		return fmt.Errorf("verifier profile save - marshalling error: %w", err)
 	}
 
-	return c.store.Put(getDBKey(data.ID), bytes)
+	return c.store.Put(getDBKey(data.ID), bytes, ariesstorage.Tag{Name: profileTagName})
 }
 
 // GetProfile retrieves the profile data based on id.
@@ -70,6 +124,81 @@ func (c *Profile) GetProfile(id string) (*ProfileData, error) {
 	return response, nil
 }
 
+// ListProfiles returns the page of verifier profiles starting at offset, sorted by id, along with the total number
+// of verifier profiles. All the profiles from offset on are returned if limit is zero.
+func (c *Profile) ListProfiles(offset, limit int) ([]*ProfileData, int, error) {
+	iter, err := c.store.Query(profileTagName)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query verifier profiles: %w", err)
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("failed to close iterator: %s", errClose.Error())
+		}
+	}()
+
+	var profiles []*ProfileData
+
+	more, err := iter.Next()
+	if err != nil {
+		return nil, 0, fmt.Errorf("iterator next: %w", err)
+	}
+
+	for more {
+		bytes, err := iter.Value()
+		if err != nil {
+			return nil, 0, fmt.Errorf("iterator value: %w", err)
+		}
+
+		profile := &ProfileData{}
+
+		if err := json.Unmarshal(bytes, profile); err != nil {
+			return nil, 0, fmt.Errorf("unmarshal verifier profile: %w", err)
+		}
+
+		profiles = append(profiles, profile)
+
+		more, err = iter.Next()
+		if err != nil {
+			return nil, 0, fmt.Errorf("iterator next: %w", err)
+		}
+	}
+
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].ID < profiles[j].ID })
+
+	start, end := vcprofile.Page(len(profiles), offset, limit)
+
+	return profiles[start:end], len(profiles), nil
+}
+
+// TagProfiles tags the verifier profiles with the given ids, so they are listed. Profiles saved before they could
+// be listed are not tagged, their ids have to be given as the store can't enumerate its records. Unknown ids are
+// skipped. It returns the number of tagged profiles.
+func (c *Profile) TagProfiles(ids ...string) (int, error) {
+	var tagged int
+
+	for _, id := range ids {
+		bytes, err := c.store.Get(getDBKey(id))
+		if errors.Is(err, ariesstorage.ErrDataNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return tagged, fmt.Errorf("get verifier profile %s: %w", id, err)
+		}
+
+		err = c.store.Put(getDBKey(id), bytes, ariesstorage.Tag{Name: profileTagName})
+		if err != nil {
+			return tagged, fmt.Errorf("tag verifier profile %s: %w", id, err)
+		}
+
+		tagged++
+	}
+
+	return tagged, nil
+}
+
 // DeleteProfile deletes the verifier profile from underlying store
 func (c *Profile) DeleteProfile(name string) error {
 	return c.store.Delete(getDBKey(name))
@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	ariesmockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/stretchr/testify/require"
 )
 
@@ -128,3 +129,99 @@ func TestDeleteProfile(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestProfile_ListProfiles(t *testing.T) {
+	t.Run("test list profiles - success", func(t *testing.T) {
+		record, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		for _, id := range []string{"c", "a", "b"} {
+			require.NoError(t, record.SaveProfile(&ProfileData{ID: id, Name: "Verifier " + id}))
+		}
+
+		profiles, total, err := record.ListProfiles(0, 0)
+		require.NoError(t, err)
+		require.Equal(t, 3, total)
+		require.Len(t, profiles, 3)
+		require.Equal(t, "a", profiles[0].ID)
+		require.Equal(t, "c", profiles[2].ID)
+
+		profiles, total, err = record.ListProfiles(1, 1)
+		require.NoError(t, err)
+		require.Equal(t, 3, total)
+		require.Len(t, profiles, 1)
+		require.Equal(t, "b", profiles[0].ID)
+	})
+
+	t.Run("test list profiles - query error", func(t *testing.T) {
+		record, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:    map[string]ariesmockstorage.DBEntry{},
+			ErrQuery: errors.New("query error"),
+		}})
+		require.NoError(t, err)
+
+		_, _, err = record.ListProfiles(0, 0)
+		require.EqualError(t, err, "query verifier profiles: query error")
+	})
+
+	t.Run("test list profiles - invalid data", func(t *testing.T) {
+		record, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store: map[string]ariesmockstorage.DBEntry{
+				getDBKey("a"): {Value: []byte("{"), Tags: []ariesstorage.Tag{{Name: profileTagName}}},
+			},
+		}})
+		require.NoError(t, err)
+
+		_, _, err = record.ListProfiles(0, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal verifier profile")
+	})
+}
+
+func TestProfile_TagProfiles(t *testing.T) {
+	t.Run("test tag profiles saved before they were listed", func(t *testing.T) {
+		record, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store: map[string]ariesmockstorage.DBEntry{
+				getDBKey("a"): {Value: []byte(`{"id":"a","name":"Verifier a"}`)},
+			},
+		}})
+		require.NoError(t, err)
+
+		_, total, err := record.ListProfiles(0, 0)
+		require.NoError(t, err)
+		require.Zero(t, total)
+
+		tagged, err := record.TagProfiles("a", "unknown")
+		require.NoError(t, err)
+		require.Equal(t, 1, tagged)
+
+		profiles, _, err := record.ListProfiles(0, 0)
+		require.NoError(t, err)
+		require.Len(t, profiles, 1)
+		require.Equal(t, "a", profiles[0].ID)
+	})
+
+	t.Run("test tag profiles - get error", func(t *testing.T) {
+		record, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:  map[string]ariesmockstorage.DBEntry{},
+			ErrGet: errors.New("get error"),
+		}})
+		require.NoError(t, err)
+
+		_, err = record.TagProfiles("a")
+		require.EqualError(t, err, "get verifier profile a: get error")
+	})
+
+	t.Run("test tag profiles - put error", func(t *testing.T) {
+		record, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store: map[string]ariesmockstorage.DBEntry{
+				getDBKey("a"): {Value: []byte(`{}`)},
+			},
+			ErrPut: errors.New("put error"),
+		}})
+		require.NoError(t, err)
+
+		_, err = record.TagProfiles("a")
+		require.EqualError(t, err, "tag verifier profile a: put error")
+	})
+}
@@ -0,0 +1,273 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+)
+
+const (
+	storeName = "issuedcredential"
+
+	keyPattern    = "issued_%s_%s"
+	profileTagKey = "issuerProfile"
+
+	storedKeyPattern    = "stored_%s_%s"
+	storedProfileTagKey = "storingProfile"
+)
+
+var logger = log.New("issued-credential-registry")
+
+// Registry keeps track of the credentials issued by issuer profiles.
+type Registry struct {
+	store ariesstorage.Store
+}
+
+// Record describes an issued credential.
+type Record struct {
+	ID             string              `json:"id"`
+	Profile        string              `json:"profile"`
+	Subject        string              `json:"subject,omitempty"`
+	Types          []string            `json:"types"`
+	Status         *verifiable.TypedID `json:"credentialStatus,omitempty"`
+	Revoked        bool                `json:"revoked"`
+	Suspended      bool                `json:"suspended,omitempty"`
+	StoredInEDV    bool                `json:"storedInEDV,omitempty"`
+	IssuanceDate   *time.Time          `json:"issuanceDate,omitempty"`
+	ExpirationDate *time.Time          `json:"expirationDate,omitempty"`
+	Created        time.Time           `json:"created"`
+	StatusUpdated  *time.Time          `json:"statusUpdated,omitempty"`
+	// RefreshedBy is the ID of the credential the refresh service issued in place of this one.
+	RefreshedBy string     `json:"refreshedBy,omitempty"`
+	RefreshedAt *time.Time `json:"refreshedAt,omitempty"`
+}
+
+// New returns new issued credential registry instance.
+func New(provider ariesstorage.Provider) (*Registry, error) {
+	store, err := provider.OpenStore(storeName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Registry{store: store}, nil
+}
+
+// NewRecord builds the record of a credential issued by the profile.
+func NewRecord(profile string, vc *verifiable.Credential) *Record {
+	record := &Record{
+		ID:      vc.ID,
+		Profile: profile,
+		Types:   vc.Types,
+		Status:  vc.Status,
+		Created: time.Now().UTC(),
+	}
+
+	if subjectID, err := verifiable.SubjectID(vc.Subject); err == nil {
+		record.Subject = subjectID
+	}
+
+	if vc.Issued != nil {
+		issued := vc.Issued.Time
+		record.IssuanceDate = &issued
+	}
+
+	if vc.Expired != nil {
+		expired := vc.Expired.Time
+		record.ExpirationDate = &expired
+	}
+
+	return record
+}
+
+// Save saves the record of an issued credential.
+func (r *Registry) Save(record *Record) error {
+	bytes, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("save issued credential marshalling error: %w", err)
+	}
+
+	return r.store.Put(getDBKey(record.Profile, record.ID), bytes, ariesstorage.Tag{
+		Name:  profileTagKey,
+		Value: tagValue(record.Profile),
+	})
+}
+
+// Get returns the record of the credential issued by the profile.
+func (r *Registry) Get(profile, id string) (*Record, error) {
+	bytes, err := r.store.Get(getDBKey(profile, id))
+	if err != nil {
+		return nil, err
+	}
+
+	record := &Record{}
+
+	err = json.Unmarshal(bytes, record)
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Delete deletes the record of the credential issued by the profile.
+func (r *Registry) Delete(profile, id string) error {
+	return r.store.Delete(getDBKey(profile, id))
+}
+
+// List returns the records of all credentials issued by the profile, oldest first, then by ID.
+func (r *Registry) List(profile string) ([]*Record, error) {
+	values, err := r.query(profileTagKey + ":" + tagValue(profile))
+	if err != nil {
+		return nil, fmt.Errorf("query issued credentials: %w", err)
+	}
+
+	records := []*Record{}
+
+	for _, bytes := range values {
+		record := &Record{}
+
+		err = json.Unmarshal(bytes, record)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal issued credential: %w", err)
+		}
+
+		records = append(records, record)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Created.Equal(records[j].Created) {
+			return records[i].ID < records[j].ID
+		}
+
+		return records[i].Created.Before(records[j].Created)
+	})
+
+	return records, nil
+}
+
+// ListPage returns a page of the records listed by List, and the total number of records.
+func (r *Registry) ListPage(profile string, offset, limit int) ([]*Record, int, error) {
+	records, err := r.List(profile)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(records)
+
+	if offset >= total {
+		return []*Record{}, total, nil
+	}
+
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	return records[offset:end], total, nil
+}
+
+// storedCredential is the record of a credential the profile stored in its vault without issuing it.
+type storedCredential struct {
+	ID      string `json:"id"`
+	Profile string `json:"profile"`
+}
+
+// SaveStored notes that the profile stored the credential in its vault. Only the credentials the profile did not
+// issue are noted this way, the records of the issued ones tell whether they are stored.
+func (r *Registry) SaveStored(profile, id string) error {
+	bytes, err := json.Marshal(&storedCredential{ID: id, Profile: profile})
+	if err != nil {
+		return fmt.Errorf("save stored credential marshalling error: %w", err)
+	}
+
+	return r.store.Put(fmt.Sprintf(storedKeyPattern, profile, id), bytes, ariesstorage.Tag{
+		Name:  storedProfileTagKey,
+		Value: tagValue(profile),
+	})
+}
+
+// ListStored returns the IDs of the credentials the profile stored in its vault without issuing them.
+func (r *Registry) ListStored(profile string) ([]string, error) {
+	values, err := r.query(storedProfileTagKey + ":" + tagValue(profile))
+	if err != nil {
+		return nil, fmt.Errorf("query stored credentials: %w", err)
+	}
+
+	ids := make([]string, 0, len(values))
+
+	for _, bytes := range values {
+		stored := &storedCredential{}
+
+		err = json.Unmarshal(bytes, stored)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal stored credential: %w", err)
+		}
+
+		ids = append(ids, stored.ID)
+	}
+
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+// DeleteStored deletes the note that the profile stored the credential in its vault.
+func (r *Registry) DeleteStored(profile, id string) error {
+	return r.store.Delete(fmt.Sprintf(storedKeyPattern, profile, id))
+}
+
+func (r *Registry) query(expression string) ([][]byte, error) {
+	iter, err := r.store.Query(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("failed to close iterator: %s", errClose.Error())
+		}
+	}()
+
+	var values [][]byte
+
+	more, err := iter.Next()
+	if err != nil {
+		return nil, fmt.Errorf("iterator next: %w", err)
+	}
+
+	for more {
+		value, errValue := iter.Value()
+		if errValue != nil {
+			return nil, fmt.Errorf("iterator value: %w", errValue)
+		}
+
+		values = append(values, value)
+
+		more, err = iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterator next: %w", err)
+		}
+	}
+
+	return values, nil
+}
+
+func getDBKey(profile, id string) string {
+	return fmt.Sprintf(keyPattern, profile, id)
+}
+
+// tagValue encodes the profile name to be used as a tag value, tag values can't contain colons.
+func tagValue(profile string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(profile))
+}
@@ -0,0 +1,212 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package registry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	ariesmockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("test open store failure", func(t *testing.T) {
+		registry, err := New(&ariesmockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")})
+		require.Nil(t, registry)
+		require.EqualError(t, err, "open error")
+	})
+}
+
+func TestNewRecord(t *testing.T) {
+	issued := time.Now().UTC().Truncate(time.Second)
+	expired := issued.Add(time.Hour)
+
+	record := NewRecord("issuer", &verifiable.Credential{
+		ID:      "http://example.edu/credentials/1872",
+		Types:   []string{"VerifiableCredential", "UniversityDegreeCredential"},
+		Subject: "did:example:ebfeb1f712ebc6f1c276e12ec21",
+		Status:  &verifiable.TypedID{ID: "https://example.com/status/1#0", Type: "RevocationList2020Status"},
+		Issued:  util.NewTime(issued),
+		Expired: util.NewTime(expired),
+	})
+
+	require.Equal(t, "http://example.edu/credentials/1872", record.ID)
+	require.Equal(t, "issuer", record.Profile)
+	require.Equal(t, "did:example:ebfeb1f712ebc6f1c276e12ec21", record.Subject)
+	require.Equal(t, []string{"VerifiableCredential", "UniversityDegreeCredential"}, record.Types)
+	require.Equal(t, "https://example.com/status/1#0", record.Status.ID)
+	require.Equal(t, issued, *record.IssuanceDate)
+	require.Equal(t, expired, *record.ExpirationDate)
+	require.False(t, record.Created.IsZero())
+}
+
+func TestRegistry_Get(t *testing.T) {
+	t.Run("test get success", func(t *testing.T) {
+		registry, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		record := NewRecord("issuer", &verifiable.Credential{
+			ID:    "http://example.edu/credentials/1872",
+			Types: []string{"VerifiableCredential"},
+		})
+
+		require.NoError(t, registry.Save(record))
+
+		found, err := registry.Get("issuer", record.ID)
+		require.NoError(t, err)
+		require.Equal(t, record.ID, found.ID)
+		require.Equal(t, record.Types, found.Types)
+	})
+
+	t.Run("test get failure due to other profile", func(t *testing.T) {
+		registry, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, registry.Save(NewRecord("issuer", &verifiable.Credential{ID: "vc1"})))
+
+		_, err = registry.Get("issuer2", "vc1")
+		require.True(t, errors.Is(err, ariesstorage.ErrDataNotFound))
+	})
+
+	t.Run("test get failure due to invalid data", func(t *testing.T) {
+		store := &ariesmockstorage.MockStore{Store: map[string]ariesmockstorage.DBEntry{
+			getDBKey("issuer", "vc1"): {Value: []byte("{")},
+		}}
+
+		registry, err := New(&ariesmockstorage.MockStoreProvider{Store: store})
+		require.NoError(t, err)
+
+		_, err = registry.Get("issuer", "vc1")
+		require.Error(t, err)
+	})
+}
+
+func TestRegistry_Delete(t *testing.T) {
+	registry, err := New(ariesmockstorage.NewMockStoreProvider())
+	require.NoError(t, err)
+
+	require.NoError(t, registry.Save(NewRecord("issuer", &verifiable.Credential{ID: "vc1"})))
+	require.NoError(t, registry.Delete("issuer", "vc1"))
+
+	_, err = registry.Get("issuer", "vc1")
+	require.True(t, errors.Is(err, ariesstorage.ErrDataNotFound))
+}
+
+func TestRegistry_List(t *testing.T) {
+	t.Run("test list success", func(t *testing.T) {
+		registry, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		first := NewRecord("did:example:issuer", &verifiable.Credential{ID: "vc1"})
+		second := NewRecord("did:example:issuer", &verifiable.Credential{ID: "vc2"})
+		second.Created = first.Created.Add(time.Second)
+
+		require.NoError(t, registry.Save(second))
+		require.NoError(t, registry.Save(first))
+		require.NoError(t, registry.Save(NewRecord("other", &verifiable.Credential{ID: "vc3"})))
+
+		records, err := registry.List("did:example:issuer")
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		require.Equal(t, "vc1", records[0].ID)
+		require.Equal(t, "vc2", records[1].ID)
+	})
+
+	t.Run("test list page", func(t *testing.T) {
+		registry, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		created := time.Now().UTC()
+
+		for _, id := range []string{"vc3", "vc1", "vc2"} {
+			record := NewRecord("issuer", &verifiable.Credential{ID: id})
+			record.Created = created
+
+			require.NoError(t, registry.Save(record))
+		}
+
+		records, total, err := registry.ListPage("issuer", 1, 1)
+		require.NoError(t, err)
+		require.Equal(t, 3, total)
+		require.Len(t, records, 1)
+		require.Equal(t, "vc2", records[0].ID)
+
+		records, total, err = registry.ListPage("issuer", 1, 5)
+		require.NoError(t, err)
+		require.Equal(t, 3, total)
+		require.Len(t, records, 2)
+
+		records, total, err = registry.ListPage("issuer", 3, 5)
+		require.NoError(t, err)
+		require.Equal(t, 3, total)
+		require.Empty(t, records)
+	})
+
+	t.Run("test list empty", func(t *testing.T) {
+		registry, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		records, err := registry.List("issuer")
+		require.NoError(t, err)
+		require.Empty(t, records)
+	})
+
+	t.Run("test list failure due to query error", func(t *testing.T) {
+		registry, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:    map[string]ariesmockstorage.DBEntry{},
+			ErrQuery: errors.New("query error"),
+		}})
+		require.NoError(t, err)
+
+		_, err = registry.List("issuer")
+		require.EqualError(t, err, "query issued credentials: query error")
+
+		_, _, err = registry.ListPage("issuer", 0, 1)
+		require.EqualError(t, err, "query issued credentials: query error")
+	})
+}
+
+func TestRegistry_Stored(t *testing.T) {
+	t.Run("test save, list and delete stored credentials", func(t *testing.T) {
+		registry, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, registry.Save(NewRecord("issuer", &verifiable.Credential{ID: "vc1"})))
+		require.NoError(t, registry.SaveStored("issuer", "vc3"))
+		require.NoError(t, registry.SaveStored("issuer", "vc2"))
+		require.NoError(t, registry.SaveStored("other", "vc4"))
+
+		ids, err := registry.ListStored("issuer")
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc2", "vc3"}, ids)
+
+		records, err := registry.List("issuer")
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+
+		require.NoError(t, registry.DeleteStored("issuer", "vc2"))
+
+		ids, err = registry.ListStored("issuer")
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc3"}, ids)
+	})
+
+	t.Run("test list stored failure due to query error", func(t *testing.T) {
+		registry, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:    map[string]ariesmockstorage.DBEntry{},
+			ErrQuery: errors.New("query error"),
+		}})
+		require.NoError(t, err)
+
+		_, err = registry.ListStored("issuer")
+		require.EqualError(t, err, "query stored credentials: query error")
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
@@ -60,6 +61,7 @@ type crypto interface {
 
 // CredentialStatusManager implement spec https://w3c-ccg.github.io/vc-status-rl-2020/
 type CredentialStatusManager struct {
+	mutex          sync.Mutex
 	store          ariesstorage.Store
 	listSize       int
 	crypto         crypto
@@ -95,46 +97,75 @@ func New(provider ariesstorage.Provider, listSize int, c crypto,
 // CreateStatusID create status id
 func (c *CredentialStatusManager) CreateStatusID(profile *vcprofile.DataProfile,
 	url string) (*verifiable.TypedID, error) {
-	cslWrapper, err := c.getLatestCSL(profile, url)
+	statusIDs, err := c.CreateStatusIDs(profile, url, 1)
 	if err != nil {
 		return nil, err
 	}
 
-	revocationListIndex := strconv.FormatInt(int64(cslWrapper.RevocationListIndex), 10)
+	return statusIDs[0], nil
+}
 
-	cslWrapper.Size++
-	cslWrapper.RevocationListIndex++
+// CreateStatusIDs reserves n status ids at once, each status list is stored once for all the ids reserved in it.
+func (c *CredentialStatusManager) CreateStatusIDs(profile *vcprofile.DataProfile,
+	url string, n int) ([]*verifiable.TypedID, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
-	if err := c.storeCSL(cslWrapper); err != nil {
-		return nil, err
-	}
+	statusIDs := make([]*verifiable.TypedID, 0, n)
 
-	if cslWrapper.Size == c.listSize {
-		id, err := strconv.Atoi(cslWrapper.ListID)
+	for len(statusIDs) < n {
+		cslWrapper, err := c.getLatestCSL(profile, url)
 		if err != nil {
 			return nil, err
 		}
 
-		id++
+		for len(statusIDs) < n {
+			revocationListIndex := strconv.FormatInt(int64(cslWrapper.RevocationListIndex), 10)
+
+			statusIDs = append(statusIDs, &verifiable.TypedID{
+				ID:   cslWrapper.VC.ID + "#" + revocationListIndex,
+				Type: RevocationList2020Status, CustomFields: verifiable.CustomFields{
+					RevocationListIndex:      revocationListIndex,
+					RevocationListCredential: cslWrapper.VC.ID,
+				},
+			})
+
+			cslWrapper.Size++
+			cslWrapper.RevocationListIndex++
+
+			if cslWrapper.Size >= c.listSize {
+				break
+			}
+		}
+
+		if err := c.storeCSL(cslWrapper); err != nil {
+			return nil, err
+		}
+
+		if cslWrapper.Size >= c.listSize {
+			id, err := strconv.Atoi(cslWrapper.ListID)
+			if err != nil {
+				return nil, err
+			}
+
+			id++
 
-		if err := c.store.Put(latestListID, []byte(strconv.FormatInt(int64(id), 10))); err != nil {
-			return nil, fmt.Errorf("failed to store latest list ID in store: %w", err)
+			if err := c.store.Put(latestListID, []byte(strconv.FormatInt(int64(id), 10))); err != nil {
+				return nil, fmt.Errorf("failed to store latest list ID in store: %w", err)
+			}
 		}
 	}
 
-	return &verifiable.TypedID{
-		ID:   cslWrapper.VC.ID + "#" + revocationListIndex,
-		Type: RevocationList2020Status, CustomFields: verifiable.CustomFields{
-			RevocationListIndex:      revocationListIndex,
-			RevocationListCredential: cslWrapper.VC.ID,
-		},
-	}, nil
+	return statusIDs, nil
 }
 
 // UpdateVC update vc
 //nolint: gocyclo, funlen
 func (c *CredentialStatusManager) UpdateVC(v *verifiable.Credential,
 	profile *vcprofile.DataProfile, status bool) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
 	// validate vc status
 	if err := c.validateVCStatus(v.Status); err != nil {
 		return err
@@ -217,6 +248,65 @@ func (c *CredentialStatusManager) validateVCStatus(vcStatus *verifiable.TypedID)
 	return nil
 }
 
+// DeleteStatusLists deletes the status lists published at the given url. List IDs are shared by all urls, so every
+// list up to the latest one is looked up.
+func (c *CredentialStatusManager) DeleteStatusLists(url string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	latest, err := c.getLatestListID()
+	if err != nil {
+		return err
+	}
+
+	for i := 1; i <= latest; i++ {
+		if err := c.store.Delete(url + "/" + strconv.Itoa(i)); err != nil {
+			return fmt.Errorf("failed to delete csl from store: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// HasStatusLists tells whether status lists are published at the given url, that is whether status ids were created
+// for it. List IDs are shared by all urls, so every list up to the latest one is looked up.
+func (c *CredentialStatusManager) HasStatusLists(url string) (bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	latest, err := c.getLatestListID()
+	if err != nil {
+		return false, err
+	}
+
+	for i := 1; i <= latest; i++ {
+		_, err := c.store.Get(url + "/" + strconv.Itoa(i))
+		if err == nil {
+			return true, nil
+		}
+
+		if !errors.Is(err, ariesstorage.ErrDataNotFound) {
+			return false, fmt.Errorf("failed to get csl from store: %w", err)
+		}
+	}
+
+	return false, nil
+}
+
+// getLatestListID returns the ID of the latest list, 0 if no list was created yet.
+func (c *CredentialStatusManager) getLatestListID() (int, error) {
+	id, err := c.store.Get(latestListID)
+	if err != nil {
+		if errors.Is(err, ariesstorage.ErrDataNotFound) {
+			return 0, nil
+		}
+
+		return 0, fmt.Errorf("failed to get latestListID from store: %w", err)
+	}
+
+	return strconv.Atoi(string(id))
+}
+
 // GetRevocationListVC get revocation list vc
 func (c *CredentialStatusManager) GetRevocationListVC(id string) ([]byte, error) {
 	cslWrapper, err := c.getCSLWrapper(id)
@@ -108,6 +108,43 @@ func validateVCStatus(t *testing.T, s *CredentialStatusManager, id string, index
 	require.False(t, bitSet)
 }
 
+func TestCredentialStatusList_CreateStatusIDs(t *testing.T) {
+	t.Run("test success", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		statusIDs, err := s.CreateStatusIDs(getTestProfile(), "localhost:8080/status", 3)
+		require.NoError(t, err)
+		require.Len(t, statusIDs, 3)
+
+		require.Equal(t, "localhost:8080/status/1#0", statusIDs[0].ID)
+		require.Equal(t, "localhost:8080/status/1#1", statusIDs[1].ID)
+		require.Equal(t, "localhost:8080/status/2#0", statusIDs[2].ID)
+		require.Equal(t, "localhost:8080/status/2", statusIDs[2].CustomFields[RevocationListCredential])
+
+		// the reservations are stored, next ids follow them
+		validateVCStatus(t, s, "localhost:8080/status/2", 1)
+		validateVCStatus(t, s, "localhost:8080/status/3", 0)
+	})
+
+	t.Run("test error from store csl", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store: make(map[string]ariesmockstorage.DBEntry), ErrPut: fmt.Errorf("put error"),
+		}}, 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		statusIDs, err := s.CreateStatusIDs(getTestProfile(), "localhost:8080/status", 3)
+		require.Error(t, err)
+		require.Nil(t, statusIDs)
+	})
+}
+
 func TestCredentialStatusList_CreateStatusID(t *testing.T) {
 	t.Run("test success", func(t *testing.T) {
 		loader := testutil.DocumentLoader(t)
@@ -199,6 +236,123 @@ func TestCredentialStatusList_CreateStatusID(t *testing.T) {
 	})
 }
 
+func TestCredentialStatusList_DeleteStatusLists(t *testing.T) {
+	t.Run("test success", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		// nothing to delete yet
+		require.NoError(t, s.DeleteStatusLists("localhost:8080/status"))
+
+		_, err = s.CreateStatusIDs(getTestProfile(), "localhost:8080/status", 3)
+		require.NoError(t, err)
+
+		_, err = s.CreateStatusIDs(getTestProfile(), "localhost:8080/other/status", 1)
+		require.NoError(t, err)
+
+		require.NoError(t, s.DeleteStatusLists("localhost:8080/status"))
+
+		_, err = s.GetRevocationListVC("localhost:8080/status/1")
+		require.Error(t, err)
+
+		_, err = s.GetRevocationListVC("localhost:8080/status/2")
+		require.Error(t, err)
+
+		_, err = s.GetRevocationListVC("localhost:8080/other/status/2")
+		require.NoError(t, err)
+	})
+
+	t.Run("test error from delete csl", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:     map[string]ariesmockstorage.DBEntry{latestListID: {Value: []byte("1")}},
+			ErrDelete: fmt.Errorf("delete error"),
+		}}, 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{}, &vdrmock.MockVDRegistry{}, loader), loader)
+		require.NoError(t, err)
+
+		err = s.DeleteStatusLists("localhost:8080/status")
+		require.EqualError(t, err, "failed to delete csl from store: delete error")
+	})
+
+	t.Run("test error getting latest list id", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(&storeProvider{store: &mockStore{getFunc: func(k string) (bytes []byte, err error) {
+			return nil, fmt.Errorf("get error")
+		}}}, 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{}, &vdrmock.MockVDRegistry{}, loader), loader)
+		require.NoError(t, err)
+
+		err = s.DeleteStatusLists("localhost:8080/status")
+		require.EqualError(t, err, "failed to get latestListID from store: get error")
+	})
+}
+
+func TestCredentialStatusList_HasStatusLists(t *testing.T) {
+	t.Run("test success", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		has, err := s.HasStatusLists("localhost:8080/status")
+		require.NoError(t, err)
+		require.False(t, has)
+
+		_, err = s.CreateStatusIDs(getTestProfile(), "localhost:8080/other/status", 3)
+		require.NoError(t, err)
+
+		has, err = s.HasStatusLists("localhost:8080/status")
+		require.NoError(t, err)
+		require.False(t, has)
+
+		_, err = s.CreateStatusID(getTestProfile(), "localhost:8080/status")
+		require.NoError(t, err)
+
+		has, err = s.HasStatusLists("localhost:8080/status")
+		require.NoError(t, err)
+		require.True(t, has)
+
+		require.NoError(t, s.DeleteStatusLists("localhost:8080/status"))
+
+		has, err = s.HasStatusLists("localhost:8080/status")
+		require.NoError(t, err)
+		require.False(t, has)
+	})
+
+	t.Run("test error getting csl from store", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(&storeProvider{store: &mockStore{getFunc: func(k string) (bytes []byte, err error) {
+			if k == latestListID {
+				return []byte("1"), nil
+			}
+
+			return nil, fmt.Errorf("get error")
+		}}}, 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{}, &vdrmock.MockVDRegistry{}, loader), loader)
+		require.NoError(t, err)
+
+		_, err = s.HasStatusLists("localhost:8080/status")
+		require.EqualError(t, err, "failed to get csl from store: get error")
+	})
+
+	t.Run("test error getting latest list id", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(&storeProvider{store: &mockStore{getFunc: func(k string) (bytes []byte, err error) {
+			return nil, fmt.Errorf("get error")
+		}}}, 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{}, &vdrmock.MockVDRegistry{}, loader), loader)
+		require.NoError(t, err)
+
+		_, err = s.HasStatusLists("localhost:8080/status")
+		require.EqualError(t, err, "failed to get latestListID from store: get error")
+	})
+}
+
 func TestCredentialStatusList_GetRevocationListVC(t *testing.T) {
 	t.Run("test error getting csl from store", func(t *testing.T) {
 		loader := testutil.DocumentLoader(t)
@@ -0,0 +1,91 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/presexch"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+)
+
+// CredentialSubjects returns the claims of the credential's subjects, a subject with its ID alone has the id claim.
+func CredentialSubjects(vc *verifiable.Credential) ([]map[string]interface{}, error) {
+	subjectBytes, err := json.Marshal(vc.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("marshal credential subject: %w", err)
+	}
+
+	var value interface{}
+
+	if err = json.Unmarshal(subjectBytes, &value); err != nil {
+		return nil, fmt.Errorf("unmarshal credential subject: %w", err)
+	}
+
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	}
+
+	subjects := make([]map[string]interface{}, 0, len(values))
+
+	for _, v := range values {
+		switch subject := v.(type) {
+		case map[string]interface{}:
+			subjects = append(subjects, subject)
+		case string:
+			subjects = append(subjects, map[string]interface{}{"id": subject})
+		default:
+			return nil, errors.New("credential subject has no claims")
+		}
+	}
+
+	return subjects, nil
+}
+
+// SatisfiesDescriptor tells whether the credential satisfies the schemas and field constraints of the input
+// descriptor, by asking a definition of the single descriptor to select it. The predicates of the fields are left
+// out, like the disclosure limits they only shape the credential a holder submits.
+func SatisfiesDescriptor(descriptor *presexch.InputDescriptor, vc *verifiable.Credential,
+	opts ...verifiable.CredentialOpt) (bool, error) {
+	single := &presexch.PresentationDefinition{
+		ID: descriptor.ID,
+		InputDescriptors: []*presexch.InputDescriptor{{
+			ID:     descriptor.ID,
+			Schema: descriptor.Schema,
+		}},
+	}
+
+	if descriptor.Constraints != nil {
+		constraints := &presexch.Constraints{
+			SubjectIsIssuer: descriptor.Constraints.SubjectIsIssuer,
+			IsHolder:        descriptor.Constraints.IsHolder,
+		}
+
+		for _, field := range descriptor.Constraints.Fields {
+			f := *field
+			f.Predicate = nil
+
+			constraints.Fields = append(constraints.Fields, &f)
+		}
+
+		single.InputDescriptors[0].Constraints = constraints
+	}
+
+	_, err := single.CreateVP([]*verifiable.Credential{vc}, opts...)
+	if errors.Is(err, presexch.ErrNoCredentials) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/presexch"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+const (
+	degreeDescriptor = `{
+		"id": "degree_input",
+		"schema": [{"uri": "https://www.w3.org/2018/credentials/examples/v1#%s"}],
+		"constraints": {
+			"fields": [{
+				"path": ["$.credentialSubject.degree.type"],
+				"filter": {"type": "string", "const": "BachelorDegree"},
+				"predicate": "required"
+			}]
+		}
+	}`
+
+	degreeVC = `{
+		"@context": [
+			"https://www.w3.org/2018/credentials/v1",
+			"https://www.w3.org/2018/credentials/examples/v1"
+		],
+		"id": "http://example.edu/credentials/1872",
+		"type": ["VerifiableCredential", "UniversityDegreeCredential"],
+		"credentialSubject": {
+			"id": "did:example:ebfeb1f712ebc6f1c276e12ec21",
+			"degree": {"type": "%s", "name": "Bachelor of Science"}
+		},
+		"issuer": "did:example:76e12ec712ebc6f1c221ebfeb1f",
+		"issuanceDate": "2010-01-01T19:23:24Z"
+	}`
+)
+
+func TestContains(t *testing.T) {
+	require.True(t, Contains([]string{"a", "b"}, "b"))
+	require.False(t, Contains([]string{"a", "b"}, "c"))
+	require.False(t, Contains(nil, "a"))
+}
+
+func TestCredentialSubjects(t *testing.T) {
+	t.Run("test single subject", func(t *testing.T) {
+		subjects, err := CredentialSubjects(&verifiable.Credential{Subject: []verifiable.Subject{{
+			ID:           "did:example:1",
+			CustomFields: verifiable.CustomFields{"name": "John Doe"},
+		}}})
+		require.NoError(t, err)
+		require.Equal(t, []map[string]interface{}{{"id": "did:example:1", "name": "John Doe"}}, subjects)
+	})
+
+	t.Run("test multiple subjects", func(t *testing.T) {
+		subjects, err := CredentialSubjects(&verifiable.Credential{Subject: []verifiable.Subject{
+			{ID: "did:example:1"}, {ID: "did:example:2"},
+		}})
+		require.NoError(t, err)
+		require.Len(t, subjects, 2)
+		require.Equal(t, "did:example:2", subjects[1]["id"])
+	})
+
+	t.Run("test subject ID", func(t *testing.T) {
+		subjects, err := CredentialSubjects(&verifiable.Credential{Subject: "did:example:1"})
+		require.NoError(t, err)
+		require.Equal(t, []map[string]interface{}{{"id": "did:example:1"}}, subjects)
+	})
+
+	t.Run("test subject without claims", func(t *testing.T) {
+		_, err := CredentialSubjects(&verifiable.Credential{Subject: 1})
+		require.EqualError(t, err, "credential subject has no claims")
+
+		_, err = CredentialSubjects(&verifiable.Credential{Subject: make(chan int)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "marshal credential subject")
+	})
+}
+
+func TestSatisfiesDescriptor(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	descriptor := func(t *testing.T, credentialType string) *presexch.InputDescriptor {
+		t.Helper()
+
+		d := &presexch.InputDescriptor{}
+		require.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(degreeDescriptor, credentialType)), d))
+
+		return d
+	}
+
+	credential := func(t *testing.T, degreeType string) *verifiable.Credential {
+		t.Helper()
+
+		vc, err := verifiable.ParseCredential([]byte(fmt.Sprintf(degreeVC, degreeType)),
+			verifiable.WithDisabledProofCheck(), verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+
+		return vc
+	}
+
+	opts := []verifiable.CredentialOpt{verifiable.WithDisabledProofCheck(), verifiable.WithJSONLDDocumentLoader(loader)}
+
+	t.Run("test satisfied", func(t *testing.T) {
+		ok, err := SatisfiesDescriptor(descriptor(t, "UniversityDegreeCredential"), credential(t, "BachelorDegree"),
+			opts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("test field constraint not satisfied", func(t *testing.T) {
+		ok, err := SatisfiesDescriptor(descriptor(t, "UniversityDegreeCredential"), credential(t, "MasterDegree"),
+			opts...)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("test schema not satisfied", func(t *testing.T) {
+		ok, err := SatisfiesDescriptor(descriptor(t, "PermanentResidentCard"), credential(t, "BachelorDegree"),
+			opts...)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("test without constraints", func(t *testing.T) {
+		d := descriptor(t, "UniversityDegreeCredential")
+		d.Constraints = nil
+
+		ok, err := SatisfiesDescriptor(d, credential(t, "MasterDegree"), opts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+}
@@ -22,6 +22,8 @@ type Client struct {
 	readDocumentCalledAtLeastOnce     bool
 	QueryVaultReturnValue             []string
 	CreateVaultError                  error
+	DeleteDocumentError               error
+	DeletedDocuments                  []string
 }
 
 // NewMockEDVClient is the mock version of edv client
@@ -66,3 +68,14 @@ func (c *Client) ReadDocument(vaultID, docID string, opts ...client.ReqOption) (
 func (c *Client) QueryVault(vaultID, name, value string, opts ...client.ReqOption) ([]string, error) {
 	return c.QueryVaultReturnValue, nil
 }
+
+// DeleteDocument mocks a document deletion call, it keeps track of the deleted documents.
+func (c *Client) DeleteDocument(vaultID, docID string, opts ...client.ReqOption) error {
+	if c.DeleteDocumentError != nil {
+		return c.DeleteDocumentError
+	}
+
+	c.DeletedDocuments = append(c.DeletedDocuments, docID)
+
+	return nil
+}
@@ -29,6 +29,8 @@ var (
 	governance []byte
 	//go:embed contexts/lds-jws2020-v1.jsonld
 	jws2020 []byte
+	//go:embed contexts/did-configuration-v1.jsonld
+	didConfiguration []byte
 )
 
 // DocumentLoader returns a document loader with preloaded test contexts.
@@ -62,6 +64,10 @@ func DocumentLoader(t *testing.T) *jsonld.DocumentLoader {
 				URL:     "https://w3c-ccg.github.io/lds-jws2020/contexts/lds-jws2020-v1.json",
 				Content: jws2020,
 			},
+			jsonld.ContextDocument{
+				URL:     "https://identity.foundation/.well-known/did-configuration/v1",
+				Content: didConfiguration,
+			},
 		),
 	)
 	require.NoError(t, err)
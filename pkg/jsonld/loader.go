@@ -21,6 +21,8 @@ var (
 	jws2020V1Vocab []byte
 	//go:embed contexts/governance.jsonld
 	governanceVocab []byte
+	//go:embed contexts/did-configuration-v1.jsonld
+	didConfigurationVocab []byte
 )
 
 var embedContexts = []jsonld.ContextDocument{ //nolint:gochecknoglobals
@@ -32,6 +34,10 @@ var embedContexts = []jsonld.ContextDocument{ //nolint:gochecknoglobals
 		URL:     "https://trustbloc.github.io/context/governance/context.jsonld",
 		Content: governanceVocab,
 	},
+	{
+		URL:     "https://identity.foundation/.well-known/did-configuration/v1",
+		Content: didConfigurationVocab,
+	},
 }
 
 // DocumentLoader returns a JSON-LD document loader with preloaded contexts.
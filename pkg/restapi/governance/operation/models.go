@@ -9,6 +9,7 @@ package operation
 import (
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
 )
 
@@ -24,6 +25,42 @@ type GovernanceProfileRequest struct {
 	UNIRegistrar            model.UNIRegistrar                 `json:"uniRegistrar,omitempty"`
 }
 
+// UpdateGovernanceProfileRequest updates the mutable fields of a governance profile. PUT replaces them all, the fields
+// left out are reset, whereas PATCH only updates the fields set.
+type UpdateGovernanceProfileRequest struct {
+	SignatureType           *string                             `json:"signatureType,omitempty"`
+	SignatureRepresentation *verifiable.SignatureRepresentation `json:"signatureRepresentation,omitempty"`
+}
+
+// apply returns a copy of the profile updated by the request.
+func (r *UpdateGovernanceProfileRequest) apply(profile *vcprofile.GovernanceProfile,
+	replace bool) *vcprofile.GovernanceProfile {
+	dataProfile := *profile.DataProfile
+	updated := &vcprofile.GovernanceProfile{DataProfile: &dataProfile}
+
+	switch {
+	case r.SignatureType != nil:
+		updated.SignatureType = *r.SignatureType
+	case replace:
+		updated.SignatureType = ""
+	}
+
+	switch {
+	case r.SignatureRepresentation != nil:
+		updated.SignatureRepresentation = *r.SignatureRepresentation
+	case replace:
+		updated.SignatureRepresentation = verifiable.SignatureProofValue
+	}
+
+	return updated
+}
+
+// ListGovernanceProfilesResponse is a page of governance profiles.
+type ListGovernanceProfilesResponse struct {
+	Profiles []*vcprofile.GovernanceProfile `json:"profiles"`
+	Total    int                            `json:"total"`
+}
+
 // IssueCredentialRequest request for issuing credential.
 type IssueCredentialRequest struct {
 	DID string `json:"did,omitempty"`
@@ -34,6 +34,43 @@ type governanceProfileReq struct { // nolint: unused,deadcode
 	Params GovernanceProfileRequest
 }
 
+// listGovernanceProfilesReq model
+//
+// swagger:parameters listGovernanceProfilesReq
+type listGovernanceProfilesReq struct { // nolint: unused,deadcode
+	// index of the first profile listed
+	//
+	// in: query
+	Offset int `json:"offset"`
+
+	// maximum number of profiles listed, 100 by default
+	//
+	// in: query
+	Limit int `json:"limit"`
+}
+
+// listGovernanceProfilesRes model
+//
+// swagger:response listGovernanceProfilesRes
+type listGovernanceProfilesRes struct { // nolint: unused,deadcode
+	// in: body
+	ListGovernanceProfilesResponse
+}
+
+// updateGovernanceProfileReq model
+//
+// swagger:parameters updateGovernanceProfileReq
+type updateGovernanceProfileReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params UpdateGovernanceProfileRequest
+}
+
 // issueGovernanceCredentialReq model
 //
 // swagger:parameters issueGovernanceCredentialReq
@@ -43,9 +43,10 @@ const (
 	profileIDPathParam = "profileID"
 
 	// governance endpoints
-	governanceProfileEndpoint = "/governance/profile"
-	issueCredentialHandler    = "/governance/" + "{" + profileIDPathParam + "}" + "/issueCredential"
-	credentialStatus          = "/governance/status"
+	governanceProfileEndpoint    = "/governance/profile"
+	getGovernanceProfileEndpoint = governanceProfileEndpoint + "/" + "{" + profileIDPathParam + "}"
+	issueCredentialHandler       = "/governance/" + "{" + profileIDPathParam + "}" + "/issueCredential"
+	credentialStatus             = "/governance/status"
 
 	invalidRequestErrMsg = "Invalid request"
 
@@ -149,6 +150,9 @@ func (o *Operation) GetRESTHandlers() []Handler {
 	return []Handler{
 		// governance profile
 		support.NewHTTPHandler(governanceProfileEndpoint, http.MethodPost, o.createGovernanceProfileHandler),
+		support.NewHTTPHandler(governanceProfileEndpoint, http.MethodGet, o.listGovernanceProfilesHandler),
+		support.NewHTTPHandler(getGovernanceProfileEndpoint, http.MethodPut, o.replaceGovernanceProfileHandler),
+		support.NewHTTPHandler(getGovernanceProfileEndpoint, http.MethodPatch, o.updateGovernanceProfileHandler),
 		support.NewHTTPHandler(issueCredentialHandler, http.MethodPost, o.issueCredentialHandler),
 		// JSON-LD context API
 		support.NewHTTPHandler(jsonldcontextrest.AddContextPath, http.MethodPost, o.addJSONLDContextHandler),
@@ -208,6 +212,93 @@ func (o *Operation) createGovernanceProfileHandler(rw http.ResponseWriter, req *
 	commhttp.WriteResponse(rw, profile)
 }
 
+// ListGovernanceProfiles swagger:route GET /governance/profile governance listGovernanceProfilesReq
+//
+// Lists governance profiles, sorted by name.
+//
+// Responses:
+//    default: genericError
+//        200: listGovernanceProfilesRes
+func (o *Operation) listGovernanceProfilesHandler(rw http.ResponseWriter, req *http.Request) {
+	offset, limit, err := commhttp.ParsePage(req)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	profiles, total, err := o.profileStore.ListGovernanceProfiles(offset, limit)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to list governance profiles: %s", err.Error()))
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, &ListGovernanceProfilesResponse{Profiles: profiles, Total: total})
+}
+
+// ReplaceGovernanceProfile swagger:route PUT /governance/profile/{id} governance updateGovernanceProfileReq
+//
+// Replaces the mutable fields of the governance profile, the fields left out are reset.
+//
+// Responses:
+//    default: genericError
+//        200: governanceProfileRes
+func (o *Operation) replaceGovernanceProfileHandler(rw http.ResponseWriter, req *http.Request) {
+	o.updateGovernanceProfile(rw, req, true)
+}
+
+// UpdateGovernanceProfile swagger:route PATCH /governance/profile/{id} governance updateGovernanceProfileReq
+//
+// Updates the given mutable fields of the governance profile.
+//
+// Responses:
+//    default: genericError
+//        200: governanceProfileRes
+func (o *Operation) updateGovernanceProfileHandler(rw http.ResponseWriter, req *http.Request) {
+	o.updateGovernanceProfile(rw, req, false)
+}
+
+func (o *Operation) updateGovernanceProfile(rw http.ResponseWriter, req *http.Request, replace bool) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.profileStore.GetGovernanceProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid governance profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	data := UpdateGovernanceProfileRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	updated := data.apply(profile, replace)
+
+	err = vcutil.ValidateProfileSignatureUpdate(profile.DataProfile, updated.SignatureType,
+		updated.SignatureRepresentation)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	if err = o.profileStore.SaveGovernanceProfile(updated); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to save governance profile: %s", err.Error()))
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, updated)
+}
+
 // IssueCredential swagger:route POST /{id}/issueCredential governance issueGovernanceCredentialReq
 //
 // Issues a credential.
@@ -294,6 +385,7 @@ func (o *Operation) createGovernanceProfile(pr *GovernanceProfileRequest) (*vcpr
 			SignatureType:           pr.SignatureType,
 			SignatureRepresentation: pr.SignatureRepresentation,
 			Creator:                 publicKeyID,
+			KeyType:                 vcutil.ProfileKeyType(pr.DIDKeyType, pr.DID, pr.DIDPrivateKey),
 		},
 	}, nil
 }
@@ -12,6 +12,7 @@ import (
 	"crypto/rand"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -57,7 +58,7 @@ func TestCreateGovernanceProfile(t *testing.T) {
 	op.commonDID = &mockCommonDID{}
 
 	endpoint := governanceProfileEndpoint
-	handler := getHandler(t, op, endpoint)
+	handler := getHandler(t, op, endpoint, http.MethodPost)
 
 	t.Run("create profile - success", func(t *testing.T) {
 		vReq := &GovernanceProfileRequest{
@@ -134,7 +135,7 @@ func TestCreateGovernanceProfile(t *testing.T) {
 
 		op.commonDID = &mockCommonDID{}
 
-		handler := getHandler(t, op, governanceProfileEndpoint)
+		handler := getHandler(t, op, governanceProfileEndpoint, http.MethodPost)
 
 		vReq := &GovernanceProfileRequest{
 			Name:          "test1",
@@ -167,7 +168,7 @@ func TestCreateGovernanceProfile(t *testing.T) {
 
 		op.commonDID = &mockCommonDID{}
 
-		handler := getHandler(t, op, endpoint)
+		handler := getHandler(t, op, endpoint, http.MethodPost)
 
 		vReq := &GovernanceProfileRequest{
 			Name:          "test1",
@@ -200,7 +201,7 @@ func TestCreateGovernanceProfile(t *testing.T) {
 		vReqBytes, err := json.Marshal(vReq)
 		require.NoError(t, err)
 
-		handler := getHandler(t, ops, endpoint)
+		handler := getHandler(t, ops, endpoint, http.MethodPost)
 
 		rr := serveHTTP(t, handler.Handle(), http.MethodPost, endpoint, vReqBytes)
 
@@ -209,6 +210,132 @@ func TestCreateGovernanceProfile(t *testing.T) {
 	})
 }
 
+func TestListGovernanceProfilesHandler(t *testing.T) {
+	op, err := New(&Config{
+		StoreProvider: ariesmemstorage.NewProvider(),
+		VDRI:          &vdrmock.MockVDRegistry{},
+	})
+	require.NoError(t, err)
+
+	for _, name := range []string{"b", "a"} {
+		require.NoError(t, op.profileStore.SaveGovernanceProfile(&vcprofile.GovernanceProfile{
+			DataProfile: &vcprofile.DataProfile{Name: name, SignatureType: vccrypto.Ed25519Signature2018},
+		}))
+	}
+
+	handler := getHandler(t, op, governanceProfileEndpoint, http.MethodGet)
+
+	t.Run("list profiles - success", func(t *testing.T) {
+		rr := serveHTTP(t, handler.Handle(), http.MethodGet, governanceProfileEndpoint, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		resp := &ListGovernanceProfilesResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, 2, resp.Total)
+		require.Len(t, resp.Profiles, 2)
+		require.Equal(t, "a", resp.Profiles[0].Name)
+	})
+
+	t.Run("list profiles - invalid page", func(t *testing.T) {
+		rr := serveHTTP(t, handler.Handle(), http.MethodGet, governanceProfileEndpoint+"?offset=-1", nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid offset -1")
+	})
+
+	t.Run("list profiles - store error", func(t *testing.T) {
+		op, err := New(&Config{
+			StoreProvider: &ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+				Store:    make(map[string]ariesmockstorage.DBEntry),
+				ErrQuery: errors.New("query error"),
+			}},
+			VDRI: &vdrmock.MockVDRegistry{},
+		})
+		require.NoError(t, err)
+
+		rr := serveHTTP(t, getHandler(t, op, governanceProfileEndpoint, http.MethodGet).Handle(), http.MethodGet,
+			governanceProfileEndpoint, nil)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to list governance profiles")
+	})
+}
+
+func TestUpdateGovernanceProfileHandler(t *testing.T) {
+	op, err := New(&Config{
+		StoreProvider: ariesmemstorage.NewProvider(),
+		VDRI:          &vdrmock.MockVDRegistry{},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, op.profileStore.SaveGovernanceProfile(&vcprofile.GovernanceProfile{
+		DataProfile: &vcprofile.DataProfile{
+			Name: "test", DID: "did:test:abc", SignatureType: vccrypto.Ed25519Signature2018,
+			SignatureRepresentation: verifiable.SignatureJWS,
+		},
+	}))
+
+	update := func(t *testing.T, method, profileID string, req interface{}) (int, *vcprofile.GovernanceProfile,
+		string) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, getGovernanceProfileEndpoint, method), reqBytes,
+			map[string]string{profileIDPathParam: profileID})
+
+		profile := &vcprofile.GovernanceProfile{}
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), profile))
+		}
+
+		return rr.Code, profile, rr.Body.String()
+	}
+
+	signatureType := vccrypto.JSONWebSignature2020
+
+	t.Run("patch profile - updates the fields set", func(t *testing.T) {
+		code, updated, body := update(t, http.MethodPatch, "test", &UpdateGovernanceProfileRequest{
+			SignatureType: &signatureType,
+		})
+		require.Equal(t, http.StatusOK, code, body)
+		require.Equal(t, signatureType, updated.SignatureType)
+		require.Equal(t, verifiable.SignatureJWS, updated.SignatureRepresentation)
+		require.Equal(t, "did:test:abc", updated.DID)
+
+		saved, err := op.profileStore.GetGovernanceProfile("test")
+		require.NoError(t, err)
+		require.Equal(t, updated, saved)
+	})
+
+	t.Run("put profile - resets the fields left out", func(t *testing.T) {
+		code, updated, body := update(t, http.MethodPut, "test", &UpdateGovernanceProfileRequest{
+			SignatureType: &signatureType,
+		})
+		require.Equal(t, http.StatusOK, code, body)
+		require.Equal(t, verifiable.SignatureProofValue, updated.SignatureRepresentation)
+	})
+
+	t.Run("patch profile - key doesn't support the signature type", func(t *testing.T) {
+		bbs := vccrypto.BbsBlsSignature2020
+
+		code, _, body := update(t, http.MethodPatch, "test", &UpdateGovernanceProfileRequest{SignatureType: &bbs})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "signature type can't be changed")
+	})
+
+	t.Run("patch profile - invalid profile", func(t *testing.T) {
+		code, _, body := update(t, http.MethodPatch, "unknown", &UpdateGovernanceProfileRequest{})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid governance profile")
+	})
+
+	t.Run("patch profile - invalid request", func(t *testing.T) {
+		code, _, body := update(t, http.MethodPatch, "test", "invalid")
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, invalidRequestErrMsg)
+	})
+}
+
 func TestIssueCredential(t *testing.T) {
 	customKMS := createKMS(t)
 
@@ -265,7 +392,7 @@ func TestIssueCredential(t *testing.T) {
 		err = ops.profileStore.SaveGovernanceProfile(vReq)
 		require.NoError(t, err)
 
-		issueCredentialHandler := getHandler(t, ops, issueCredentialHandler)
+		issueCredentialHandler := getHandler(t, ops, issueCredentialHandler, http.MethodPost)
 
 		require.NoError(t, err)
 
@@ -312,7 +439,7 @@ func TestIssueCredential(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		issueCredentialHandler := getHandler(t, ops, issueCredentialHandler)
+		issueCredentialHandler := getHandler(t, ops, issueCredentialHandler, http.MethodPost)
 
 		require.NoError(t, err)
 
@@ -344,7 +471,7 @@ func TestIssueCredential(t *testing.T) {
 		err = ops.profileStore.SaveGovernanceProfile(vReq)
 		require.NoError(t, err)
 
-		issueCredentialHandler := getHandler(t, ops, issueCredentialHandler)
+		issueCredentialHandler := getHandler(t, ops, issueCredentialHandler, http.MethodPost)
 
 		require.NoError(t, err)
 
@@ -376,7 +503,7 @@ func TestIssueCredential(t *testing.T) {
 		err = ops.profileStore.SaveGovernanceProfile(vReq)
 		require.NoError(t, err)
 
-		issueCredentialHandler := getHandler(t, ops, issueCredentialHandler)
+		issueCredentialHandler := getHandler(t, ops, issueCredentialHandler, http.MethodPost)
 
 		require.NoError(t, err)
 
@@ -405,26 +532,26 @@ func (m *mockCommonDID) CreateDID(keyType, signatureType, didID, privateKey, key
 	return m.createDIDValue, m.createDIDKeyID, m.createDIDErr
 }
 
-func getHandler(t *testing.T, op *Operation, lookup string) Handler {
+func getHandler(t *testing.T, op *Operation, lookup, methodToLookup string) Handler {
 	t.Helper()
 
-	return getHandlerWithError(t, op, lookup)
+	return getHandlerWithError(t, op, lookup, methodToLookup)
 }
 
-func getHandlerWithError(t *testing.T, op *Operation, lookup string) Handler {
+func getHandlerWithError(t *testing.T, op *Operation, lookup, methodToLookup string) Handler {
 	t.Helper()
 
-	return handlerLookup(t, op, lookup)
+	return handlerLookup(t, op, lookup, methodToLookup)
 }
 
-func handlerLookup(t *testing.T, op *Operation, lookup string) Handler {
+func handlerLookup(t *testing.T, op *Operation, lookup, methodToLookup string) Handler {
 	t.Helper()
 
 	handlers := op.GetRESTHandlers()
 	require.NotEmpty(t, handlers)
 
 	for _, h := range handlers {
-		if h.Path() == lookup {
+		if h.Path() == lookup && h.Method() == methodToLookup {
 			return h
 		}
 	}
@@ -0,0 +1,368 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/presexch"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/wallet"
+
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/internal/common/utils"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+)
+
+const (
+	composePresentationEndpoint = signPresentationEndpoint + "/compose"
+
+	// Verifiable Presentation Request query types
+	queryByExample    = "QueryByExample"
+	queryByFrame      = "QueryByFrame"
+	didAuth           = "DIDAuth"
+	didAuthentication = "DIDAuthentication"
+
+	submissionProperty = "presentation_submission"
+	ldpVCFormat        = "ldp_vc"
+)
+
+var errNoCandidateCredentials = errors.New("no candidate credentials")
+
+// ComposePresentation swagger:route POST /{id}/prove/presentations/compose holder composePresentationReq
+//
+// Composes and signs a presentation of the candidate credentials that satisfy a presentation definition, along with
+// its presentation submission, or the queries of a Verifiable Presentation Request. The credentials of the input
+// descriptors that limit disclosure are derived with BBS+ selective disclosure.
+//
+// Responses:
+//    default: genericError
+//        201: signPresentationRes
+func (o *Operation) composePresentationHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.profileStore.GetHolderProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid holder profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	data := ComposePresentationRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	if (data.PresentationDefinition == nil) == (len(data.Query) == 0) {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			"either a presentation definition or a query is required")
+
+		return
+	}
+
+	candidates, status, err := o.candidateCredentials(profile, &data)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, status, err.Error())
+
+		return
+	}
+
+	var presentation *verifiable.Presentation
+
+	if data.PresentationDefinition != nil {
+		presentation, err = o.composeForDefinition(data.PresentationDefinition, candidates)
+	} else {
+		presentation, err = o.composeForQueries(data.Query, candidates)
+	}
+
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	updateHolder(presentation, profile)
+
+	signedVP, err := o.crypto.SignPresentation(profile, presentation, getPresentationSigningOpts(data.Opts)...)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to sign presentation:"+
+			" %s", err.Error()))
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusCreated)
+	commhttp.WriteResponse(rw, signedVP)
+}
+
+// candidateCredentials returns the credentials of the request, their proofs are verified, and the stored credentials
+// it refers to. All the credentials of the profile's wallet are candidates if the request doesn't give any.
+func (o *Operation) candidateCredentials(profile *vcprofile.HolderProfile,
+	data *ComposePresentationRequest) ([]*verifiable.Credential, int, error) {
+	candidates := make([]*verifiable.Credential, 0, len(data.Credentials)+len(data.CredentialIDs))
+
+	for _, vcBytes := range data.Credentials {
+		vc, err := verifiable.ParseCredential(vcBytes,
+			verifiable.WithPublicKeyFetcher(verifiable.NewVDRKeyResolver(o.vdr).PublicKeyFetcher()),
+			verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("failed to parse credential: %w", err)
+		}
+
+		candidates = append(candidates, vc)
+	}
+
+	if o.edvClient == nil {
+		if len(data.CredentialIDs) != 0 {
+			return nil, http.StatusBadRequest, errWalletNotConfigured
+		}
+
+		return candidates, http.StatusOK, nil
+	}
+
+	var (
+		stored []json.RawMessage
+		err    error
+	)
+
+	switch {
+	case len(data.CredentialIDs) != 0:
+		stored, err = o.walletCredentials(profile, data.CredentialIDs...)
+	case len(data.Credentials) == 0:
+		stored, err = o.queryWalletCredentials(profile, "", "")
+	}
+
+	if errors.Is(err, errCredentialNotFound) {
+		return nil, http.StatusBadRequest, err
+	}
+
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	for _, vcBytes := range stored {
+		// the proofs of the stored credentials were verified when they were stored
+		vc, errParse := verifiable.ParseCredential(vcBytes, verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+		if errParse != nil {
+			return nil, http.StatusInternalServerError, fmt.Errorf("failed to parse stored credential: %w", errParse)
+		}
+
+		candidates = append(candidates, vc)
+	}
+
+	return candidates, http.StatusOK, nil
+}
+
+// composeForDefinition composes the presentation of the credentials that satisfy the presentation definition. The
+// presentation submission maps every input descriptor submitted to the credentials of the presentation that
+// satisfy it.
+func (o *Operation) composeForDefinition(definition *presexch.PresentationDefinition,
+	candidates []*verifiable.Credential) (*verifiable.Presentation, error) {
+	if len(candidates) == 0 {
+		return nil, errNoCandidateCredentials
+	}
+
+	// the public keys verify the BBS+ signatures of the credentials derived for the descriptors that limit disclosure
+	vp, err := definition.CreateVP(candidates,
+		verifiable.WithPublicKeyFetcher(verifiable.NewVDRKeyResolver(o.vdr).PublicKeyFetcher()),
+		verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+	if err != nil {
+		return nil, fmt.Errorf("failed to match presentation definition: %w", err)
+	}
+
+	credentials := make([]*verifiable.Credential, 0, len(vp.Credentials()))
+
+	for _, c := range vp.Credentials() {
+		vc, ok := c.(*verifiable.Credential)
+		if !ok {
+			return nil, errors.New("unexpected credential in the presentation")
+		}
+
+		// disclosure is only limited by deriving credentials, the others lose their proofs when they are limited
+		if len(vc.Proofs) == 0 {
+			return nil, fmt.Errorf("credential %s has no proof, disclosure can only be limited for BBS+ credentials",
+				vc.ID)
+		}
+
+		credentials = append(credentials, vc)
+	}
+
+	submission, ok := vp.CustomFields[submissionProperty].(*presexch.PresentationSubmission)
+	if !ok {
+		return nil, errors.New("presentation has no presentation submission")
+	}
+
+	descriptorMap, err := o.descriptorMap(definition, submission, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	vp.CustomFields[submissionProperty] = &presexch.PresentationSubmission{
+		ID:            uuid.New().String(),
+		DefinitionID:  definition.ID,
+		DescriptorMap: descriptorMap,
+	}
+
+	return vp, nil
+}
+
+// descriptorMap maps the input descriptors submitted to the credentials of the presentation that satisfy them.
+func (o *Operation) descriptorMap(definition *presexch.PresentationDefinition,
+	submission *presexch.PresentationSubmission,
+	credentials []*verifiable.Credential) ([]*presexch.InputDescriptorMapping, error) {
+	var descriptorMap []*presexch.InputDescriptorMapping
+
+	submitted := make(map[string]bool)
+
+	for _, mapping := range submission.DescriptorMap {
+		if submitted[mapping.ID] {
+			continue
+		}
+
+		submitted[mapping.ID] = true
+
+		descriptor := inputDescriptor(definition, mapping.ID)
+		if descriptor == nil {
+			return nil, fmt.Errorf("unknown input descriptor %s", mapping.ID)
+		}
+
+		var found bool
+
+		for i, vc := range credentials {
+			ok, err := utils.SatisfiesDescriptor(descriptor, vc, verifiable.WithDisabledProofCheck(),
+				verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+			if err != nil {
+				return nil, fmt.Errorf("check input descriptor %s: %w", descriptor.ID, err)
+			}
+
+			if ok {
+				found = true
+
+				descriptorMap = append(descriptorMap, &presexch.InputDescriptorMapping{
+					ID:     descriptor.ID,
+					Format: ldpVCFormat,
+					Path:   "$.verifiableCredential[" + strconv.Itoa(i) + "]",
+				})
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("no credential of the presentation satisfies input descriptor %s", descriptor.ID)
+		}
+	}
+
+	return descriptorMap, nil
+}
+
+// composeForQueries composes the presentation of the credentials that match the queries of a Verifiable
+// Presentation Request. A DID authentication query only asks for the holder's proof.
+func (o *Operation) composeForQueries(queries []*VPRQuery,
+	candidates []*verifiable.Credential) (*verifiable.Presentation, error) {
+	var params []*wallet.QueryParams
+
+	for _, query := range queries {
+		switch query.Type {
+		case didAuth, didAuthentication:
+			continue
+		case queryByExample, queryByFrame:
+		default:
+			return nil, fmt.Errorf("unsupported query type %s", query.Type)
+		}
+
+		credentialQueries, err := query.credentialQueries()
+		if err != nil {
+			return nil, err
+		}
+
+		params = append(params, &wallet.QueryParams{Type: query.Type, Query: credentialQueries})
+	}
+
+	presentation, err := verifiable.NewPresentation()
+	if err != nil || len(params) == 0 {
+		return presentation, err
+	}
+
+	if len(candidates) == 0 {
+		return nil, errNoCandidateCredentials
+	}
+
+	raws := make(map[string]json.RawMessage, len(candidates))
+
+	for i, vc := range candidates {
+		vcBytes, errMarshal := vc.MarshalJSON()
+		if errMarshal != nil {
+			return nil, errMarshal
+		}
+
+		raws[strconv.Itoa(i)] = vcBytes
+	}
+
+	results, err := wallet.NewQuery(verifiable.NewVDRKeyResolver(o.vdr).PublicKeyFetcher(), o.documentLoader,
+		params...).PerformQuery(raws)
+	if errors.Is(err, wallet.ErrQueryNoResultFound) {
+		return nil, errors.New("no credentials match the query")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credentials: %w", err)
+	}
+
+	for _, result := range results {
+		for _, c := range result.Credentials() {
+			vc, ok := c.(*verifiable.Credential)
+			if !ok {
+				return nil, errors.New("unexpected credential in the query result")
+			}
+
+			presentation.AddCredentials(vc)
+		}
+	}
+
+	return presentation, nil
+}
+
+// credentialQueries returns the credential queries of the query, a single one or an array of them.
+func (q *VPRQuery) credentialQueries() ([]json.RawMessage, error) {
+	if len(q.CredentialQuery) == 0 {
+		return nil, fmt.Errorf("%s query has no credential query", q.Type)
+	}
+
+	if !strings.HasPrefix(string(bytes.TrimSpace(q.CredentialQuery)), "[") {
+		return []json.RawMessage{q.CredentialQuery}, nil
+	}
+
+	var credentialQueries []json.RawMessage
+
+	if err := json.Unmarshal(q.CredentialQuery, &credentialQueries); err != nil {
+		return nil, fmt.Errorf("invalid credential query: %w", err)
+	}
+
+	return credentialQueries, nil
+}
+
+func inputDescriptor(definition *presexch.PresentationDefinition, id string) *presexch.InputDescriptor {
+	for _, descriptor := range definition.InputDescriptors {
+		if descriptor.ID == id {
+			return descriptor
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,402 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/presexch"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/vdr/key"
+	"github.com/stretchr/testify/require"
+
+	vccrypto "github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+const (
+	composeDefinition = `{
+		"id": "definition-1",
+		"input_descriptors": [{
+			"id": "residence",
+			"schema": [{"uri": "https://w3id.org/citizenship/v1#PermanentResidentCard"}],
+			"constraints": {
+				"limit_disclosure": "required",
+				"fields": [{"path": ["$.credentialSubject.givenName"]}]
+			}
+		}, {
+			"id": "degree",
+			"schema": [{"uri": "https://www.w3.org/2018/credentials/examples/v1#UniversityDegreeCredential"}]
+		}]
+	}`
+
+	queryByExampleDegree = `{
+		"reason": "degree",
+		"example": {
+			"@context": ["https://www.w3.org/2018/credentials/v1", "https://www.w3.org/2018/credentials/examples/v1"],
+			"type": "UniversityDegreeCredential"
+		}
+	}`
+
+	composeHolder = "did:test:holder"
+)
+
+func TestComposePresentation(t *testing.T) {
+	customKMS := createKMS(t)
+
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	keyID, pubKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ED25519Type)
+	require.NoError(t, err)
+
+	loader := testutil.DocumentLoader(t)
+
+	residence, err := verifiable.ParseCredential([]byte(vcForDerive), verifiable.WithJSONLDDocumentLoader(loader))
+	require.NoError(t, err)
+
+	didKey := signVCWithBBS(t, residence)
+
+	residenceBytes, err := residence.MarshalJSON()
+	require.NoError(t, err)
+
+	edv := newMemEDVClient()
+
+	op, err := New(&Config{
+		StoreProvider: ariesmemstorage.NewProvider(),
+		EDVClient:     edv,
+		KeyManager:    customKMS,
+		Crypto:        customCrypto,
+		VDRI: &vdrmock.MockVDRegistry{
+			ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+				if didID == didKey {
+					return key.New().Read(didKey)
+				}
+
+				return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, keyID, pubKey)}, nil
+			},
+		},
+		DocumentLoader: loader,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, op.profileStore.SaveHolderProfile(&vcprofile.HolderProfile{
+		DataProfile: &vcprofile.DataProfile{
+			Name:          testProfileID,
+			DID:           composeHolder,
+			SignatureType: vccrypto.Ed25519Signature2018,
+			Creator:       composeHolder + "#" + keyID,
+		},
+	}))
+
+	signVC := func(t *testing.T, id string) json.RawMessage {
+		t.Helper()
+
+		vc, errParse := verifiable.ParseCredential(
+			[]byte(fmt.Sprintf(walletVC, id, "UniversityDegreeCredential", testIssuer)),
+			verifiable.WithDisabledProofCheck(), verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, errParse)
+
+		signed, errSign := op.crypto.SignCredential(&vcprofile.DataProfile{
+			Name: "issuer", DID: testIssuer, SignatureType: vccrypto.Ed25519Signature2018,
+			Creator: testIssuer + "#" + keyID,
+		}, vc)
+		require.NoError(t, errSign)
+
+		vcBytes, errMarshal := signed.MarshalJSON()
+		require.NoError(t, errMarshal)
+
+		return vcBytes
+	}
+
+	degree := signVC(t, "http://example.edu/credentials/1")
+
+	definition := &presexch.PresentationDefinition{}
+	require.NoError(t, json.Unmarshal([]byte(composeDefinition), definition))
+
+	urlVars := map[string]string{profileIDPathParam: testProfileID}
+
+	compose := func(t *testing.T, req interface{}) (int, []byte) {
+		t.Helper()
+
+		reqBytes, errMarshal := json.Marshal(req)
+		require.NoError(t, errMarshal)
+
+		rr := serveHTTPMux(t, getHandler(t, op, composePresentationEndpoint, http.MethodPost),
+			"/"+testProfileID+"/prove/presentations/compose", reqBytes, urlVars)
+
+		return rr.Code, rr.Body.Bytes()
+	}
+
+	parseVP := func(t *testing.T, vpBytes []byte) *verifiable.Presentation {
+		t.Helper()
+
+		vp, errParse := verifiable.ParsePresentation(vpBytes,
+			verifiable.WithPresPublicKeyFetcher(verifiable.NewVDRKeyResolver(op.vdr).PublicKeyFetcher()),
+			verifiable.WithPresJSONLDDocumentLoader(loader))
+		require.NoError(t, errParse)
+		require.Equal(t, composeHolder, vp.Holder)
+		require.Len(t, vp.Proofs, 1)
+
+		return vp
+	}
+
+	t.Run("compose presentation for definition", func(t *testing.T) {
+		code, body := compose(t, &ComposePresentationRequest{
+			PresentationDefinition: definition,
+			Credentials:            []json.RawMessage{degree, residenceBytes},
+			Opts:                   &SignPresentationOptions{Challenge: challenge, Domain: domain},
+		})
+		require.Equal(t, http.StatusCreated, code, string(body))
+
+		vp := parseVP(t, body)
+		require.Equal(t, challenge, vp.Proofs[0]["challenge"])
+
+		vcs, err := vp.MarshalledCredentials()
+		require.NoError(t, err)
+		require.Len(t, vcs, 2)
+
+		submission := &presexch.PresentationSubmission{}
+		submissionBytes, err := json.Marshal(vp.CustomFields[submissionProperty])
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(submissionBytes, submission))
+		require.Equal(t, definition.ID, submission.DefinitionID)
+		require.NotEmpty(t, submission.ID)
+		require.Len(t, submission.DescriptorMap, 2)
+
+		paths := map[string]string{}
+
+		for _, mapping := range submission.DescriptorMap {
+			require.Equal(t, ldpVCFormat, mapping.Format)
+
+			paths[mapping.ID] = mapping.Path
+		}
+
+		for i, vcBytes := range vcs {
+			vc := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal(vcBytes, &vc))
+
+			path := fmt.Sprintf("$.verifiableCredential[%d]", i)
+
+			if strings.Contains(string(vcBytes), "PermanentResidentCard") {
+				require.Equal(t, path, paths["residence"])
+
+				// the residence card is derived to disclose the given name only
+				subject, ok := vc["credentialSubject"].(map[string]interface{})
+				require.True(t, ok)
+				require.Equal(t, "JOHN", subject["givenName"])
+				require.NotContains(t, subject, "familyName")
+				require.Contains(t, string(vcBytes), "BbsBlsSignatureProof2020")
+
+				continue
+			}
+
+			require.Equal(t, path, paths["degree"])
+			require.JSONEq(t, string(degree), string(vcBytes))
+		}
+	})
+
+	t.Run("compose presentation for query", func(t *testing.T) {
+		code, body := compose(t, &ComposePresentationRequest{
+			Query:       []*VPRQuery{{Type: queryByExample, CredentialQuery: json.RawMessage(queryByExampleDegree)}},
+			Credentials: []json.RawMessage{degree, residenceBytes},
+		})
+		require.Equal(t, http.StatusCreated, code, string(body))
+
+		vcs, err := parseVP(t, body).MarshalledCredentials()
+		require.NoError(t, err)
+		require.Len(t, vcs, 1)
+		require.JSONEq(t, string(degree), string(vcs[0]))
+
+		// the credential queries may be an array
+		code, body = compose(t, &ComposePresentationRequest{
+			Query: []*VPRQuery{{
+				Type:            queryByFrame,
+				CredentialQuery: json.RawMessage(`[{"reason": "residence", "frame": ` + sampleFrame + `}]`),
+			}},
+			Credentials: []json.RawMessage{degree, residenceBytes},
+		})
+		require.Equal(t, http.StatusCreated, code, string(body))
+
+		vcs, err = parseVP(t, body).MarshalledCredentials()
+		require.NoError(t, err)
+		require.Len(t, vcs, 1)
+		require.Contains(t, string(vcs[0]), "BbsBlsSignatureProof2020")
+		require.NotContains(t, string(vcs[0]), "birthCountry")
+
+		// DID authentication only asks for the holder's proof
+		code, body = compose(t, &ComposePresentationRequest{Query: []*VPRQuery{{Type: didAuthentication}}})
+		require.Equal(t, http.StatusCreated, code, string(body))
+		require.Empty(t, parseVP(t, body).Credentials())
+	})
+
+	t.Run("compose presentation of stored credentials", func(t *testing.T) {
+		reqBytes, err := json.Marshal(&StoreCredentialRequest{Credential: degree})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, walletCredentialsEndpoint, http.MethodPost),
+			"/"+testProfileID+"/wallet/credentials", reqBytes, urlVars)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		// all the stored credentials are candidates
+		code, body := compose(t, &ComposePresentationRequest{
+			Query: []*VPRQuery{{Type: queryByExample, CredentialQuery: json.RawMessage(queryByExampleDegree)}},
+		})
+		require.Equal(t, http.StatusCreated, code, string(body))
+		require.Len(t, parseVP(t, body).Credentials(), 1)
+
+		// along with the given ones
+		code, body = compose(t, &ComposePresentationRequest{
+			PresentationDefinition: definition,
+			Credentials:            []json.RawMessage{residenceBytes},
+			CredentialIDs:          []string{"http://example.edu/credentials/1"},
+		})
+		require.Equal(t, http.StatusCreated, code, string(body))
+		require.Len(t, parseVP(t, body).Credentials(), 2)
+
+		code, body = compose(t, &ComposePresentationRequest{
+			PresentationDefinition: definition,
+			CredentialIDs:          []string{"http://example.edu/credentials/9"},
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "credential not found: http://example.edu/credentials/9")
+	})
+
+	t.Run("compose presentation errors", func(t *testing.T) {
+		limitDegree := &presexch.PresentationDefinition{}
+		require.NoError(t, json.Unmarshal([]byte(composeDefinition), limitDegree))
+		limitDegree.InputDescriptors[1].Constraints = &presexch.Constraints{
+			LimitDisclosure: limitDegree.InputDescriptors[0].Constraints.LimitDisclosure,
+			Fields:          []*presexch.Field{{Path: []string{"$.id"}}},
+		}
+
+		tests := []struct {
+			name string
+			req  interface{}
+			err  string
+		}{
+			{
+				name: "invalid request",
+				req:  "invalid",
+				err:  invalidRequestErrMsg,
+			},
+			{
+				name: "neither definition nor query",
+				req:  &ComposePresentationRequest{Credentials: []json.RawMessage{degree}},
+				err:  "either a presentation definition or a query is required",
+			},
+			{
+				name: "both definition and query",
+				req: &ComposePresentationRequest{
+					PresentationDefinition: definition,
+					Query:                  []*VPRQuery{{Type: didAuth}},
+				},
+				err: "either a presentation definition or a query is required",
+			},
+			{
+				name: "invalid credential",
+				req: &ComposePresentationRequest{
+					PresentationDefinition: definition,
+					Credentials:            []json.RawMessage{json.RawMessage(`{}`)},
+				},
+				err: "failed to parse credential",
+			},
+			{
+				name: "credentials don't satisfy definition",
+				req: &ComposePresentationRequest{
+					PresentationDefinition: definition,
+					Credentials:            []json.RawMessage{degree},
+				},
+				err: "failed to match presentation definition",
+			},
+			{
+				name: "limited disclosure of credential without BBS+ proof",
+				req: &ComposePresentationRequest{
+					PresentationDefinition: limitDegree,
+					Credentials:            []json.RawMessage{degree, residenceBytes},
+				},
+				err: "credential http://example.edu/credentials/1 has no proof",
+			},
+			{
+				name: "unsupported query type",
+				req: &ComposePresentationRequest{
+					Query:       []*VPRQuery{{Type: "PresentationExchange"}},
+					Credentials: []json.RawMessage{degree},
+				},
+				err: "unsupported query type PresentationExchange",
+			},
+			{
+				name: "no credential query",
+				req: &ComposePresentationRequest{
+					Query:       []*VPRQuery{{Type: queryByExample}},
+					Credentials: []json.RawMessage{degree},
+				},
+				err: "QueryByExample query has no credential query",
+			},
+			{
+				name: "invalid credential query",
+				req: &ComposePresentationRequest{
+					Query:       []*VPRQuery{{Type: queryByExample, CredentialQuery: json.RawMessage(`{}`)}},
+					Credentials: []json.RawMessage{degree},
+				},
+				err: "failed to query credentials",
+			},
+			{
+				name: "no credentials match query",
+				req: &ComposePresentationRequest{
+					Query: []*VPRQuery{{
+						Type:            queryByExample,
+						CredentialQuery: json.RawMessage(queryByExampleDegree),
+					}},
+					Credentials: []json.RawMessage{residenceBytes},
+				},
+				err: "no credentials match the query",
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				code, body := compose(t, tc.req)
+				require.Equal(t, http.StatusBadRequest, code)
+				require.Contains(t, string(body), tc.err)
+			})
+		}
+	})
+
+	t.Run("invalid profile", func(t *testing.T) {
+		rr := serveHTTPMux(t, getHandler(t, op, composePresentationEndpoint, http.MethodPost),
+			"/unknown/prove/presentations/compose", nil, map[string]string{profileIDPathParam: "unknown"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid holder profile - id=unknown")
+	})
+
+	t.Run("no candidate credentials", func(t *testing.T) {
+		noWallet, err := New(&Config{StoreProvider: ariesmemstorage.NewProvider(), VDRI: &vdrmock.MockVDRegistry{}})
+		require.NoError(t, err)
+
+		saveTestProfile(t, noWallet)
+
+		reqBytes, err := json.Marshal(&ComposePresentationRequest{PresentationDefinition: definition})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, noWallet, composePresentationEndpoint, http.MethodPost),
+			"/"+testProfileID+"/prove/presentations/compose", reqBytes, urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), errNoCandidateCredentials.Error())
+	})
+}
@@ -10,8 +10,10 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/hyperledger/aries-framework-go/pkg/doc/presexch"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
 )
 
@@ -28,10 +30,65 @@ type HolderProfileRequest struct {
 	OverwriteHolder         bool                               `json:"overwriteHolder,omitempty"`
 }
 
+// RotateKeysRequest rotates the signing key of a profile. The DIDs created by the service get a new key, for the
+// others the key must be added to the DID beforehand and its private key given.
+type RotateKeysRequest struct {
+	DIDKeyType    string `json:"didKeyType,omitempty"`
+	DIDPrivateKey string `json:"didPrivateKey,omitempty"`
+	DIDKeyID      string `json:"didKeyID,omitempty"`
+}
+
+// UpdateHolderProfileRequest updates the mutable fields of a holder profile. PUT replaces them all, the fields left
+// out are reset, whereas PATCH only updates the fields set.
+type UpdateHolderProfileRequest struct {
+	SignatureType           *string                             `json:"signatureType,omitempty"`
+	SignatureRepresentation *verifiable.SignatureRepresentation `json:"signatureRepresentation,omitempty"`
+	OverwriteHolder         *bool                               `json:"overwriteHolder,omitempty"`
+}
+
+// apply returns a copy of the profile updated by the request.
+func (r *UpdateHolderProfileRequest) apply(profile *vcprofile.HolderProfile, replace bool) *vcprofile.HolderProfile {
+	updated := *profile
+	dataProfile := *profile.DataProfile
+	updated.DataProfile = &dataProfile
+
+	switch {
+	case r.SignatureType != nil:
+		updated.SignatureType = *r.SignatureType
+	case replace:
+		updated.SignatureType = ""
+	}
+
+	switch {
+	case r.SignatureRepresentation != nil:
+		updated.SignatureRepresentation = *r.SignatureRepresentation
+	case replace:
+		updated.SignatureRepresentation = verifiable.SignatureProofValue
+	}
+
+	switch {
+	case r.OverwriteHolder != nil:
+		updated.OverwriteHolder = *r.OverwriteHolder
+	case replace:
+		updated.OverwriteHolder = false
+	}
+
+	return &updated
+}
+
+// ListHolderProfilesResponse is a page of holder profiles.
+type ListHolderProfilesResponse struct {
+	Profiles []*vcprofile.HolderProfile `json:"profiles"`
+	Total    int                        `json:"total"`
+}
+
 // SignPresentationRequest request for signing a presentation.
 type SignPresentationRequest struct {
-	Presentation json.RawMessage          `json:"presentation,omitempty"`
-	Opts         *SignPresentationOptions `json:"options,omitempty"`
+	Presentation json.RawMessage `json:"presentation,omitempty"`
+	// CredentialIDs are the IDs of credentials stored in the profile's wallet to add to the presentation, which is
+	// created if it isn't given.
+	CredentialIDs []string                 `json:"credentialIDs,omitempty"`
+	Opts          *SignPresentationOptions `json:"options,omitempty"`
 }
 
 // SignPresentationOptions options for signing a presentation.
@@ -63,3 +120,37 @@ type DeriveCredentialOptions struct {
 	// Nonce to prove uniqueness or freshness of the proof.
 	Nonce *string `json:"nonce"`
 }
+
+// StoreCredentialRequest request for storing a credential in the wallet of a holder profile.
+type StoreCredentialRequest struct {
+	Credential json.RawMessage `json:"credential"`
+}
+
+// StoreCredentialResponse is the ID of the credential stored in the wallet of a holder profile.
+type StoreCredentialResponse struct {
+	ID string `json:"id"`
+}
+
+// WalletCredentialsResponse are the credentials of the wallet of a holder profile.
+type WalletCredentialsResponse struct {
+	Credentials []json.RawMessage `json:"credentials"`
+}
+
+// ComposePresentationRequest request for composing and signing a presentation of the credentials that satisfy either
+// a presentation definition or the queries of a Verifiable Presentation Request.
+type ComposePresentationRequest struct {
+	PresentationDefinition *presexch.PresentationDefinition `json:"presentationDefinition,omitempty"`
+	Query                  []*VPRQuery                      `json:"query,omitempty"`
+	// Credentials are the candidate credentials, along with the ones stored in the profile's wallet with the IDs
+	// CredentialIDs. All the stored credentials are candidates if neither is given.
+	Credentials   []json.RawMessage        `json:"credentials,omitempty"`
+	CredentialIDs []string                 `json:"credentialIDs,omitempty"`
+	Opts          *SignPresentationOptions `json:"options,omitempty"`
+}
+
+// VPRQuery is a query of a Verifiable Presentation Request, its credential query is either a single query or an
+// array of them.
+type VPRQuery struct {
+	Type            string          `json:"type"`
+	CredentialQuery json.RawMessage `json:"credentialQuery,omitempty"`
+}
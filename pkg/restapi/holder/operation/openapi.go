@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package operation
 
 import (
+	"encoding/json"
+
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
 )
 
@@ -45,6 +47,57 @@ type holderProfileReq struct { // nolint: unused,deadcode
 	Params HolderProfileRequest
 }
 
+// listHolderProfilesReq model
+//
+// swagger:parameters listHolderProfilesReq
+type listHolderProfilesReq struct { // nolint: unused,deadcode
+	// index of the first profile listed
+	//
+	// in: query
+	Offset int `json:"offset"`
+
+	// maximum number of profiles listed, 100 by default
+	//
+	// in: query
+	Limit int `json:"limit"`
+}
+
+// listHolderProfilesRes model
+//
+// swagger:response listHolderProfilesRes
+type listHolderProfilesRes struct { // nolint: unused,deadcode
+	// in: body
+	ListHolderProfilesResponse
+}
+
+// updateHolderProfileReq model
+//
+// swagger:parameters updateHolderProfileReq
+type updateHolderProfileReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params UpdateHolderProfileRequest
+}
+
+// rotateHolderKeysReq model
+//
+// swagger:parameters rotateHolderKeysReq
+type rotateHolderKeysReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params RotateKeysRequest
+}
+
 // deleteHolderProfileReq model
 //
 // swagger:parameters deleteHolderProfileReq
@@ -70,6 +123,20 @@ type signPresentationReq struct { // nolint: unused,deadcode
 	Params SignPresentationRequest
 }
 
+// composePresentationReq model
+//
+// swagger:parameters composePresentationReq
+type composePresentationReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params ComposePresentationRequest
+}
+
 // verifiableCredentialRes model
 //
 // swagger:response verifiableCredentialRes
@@ -108,3 +175,79 @@ type deriveCredentialRes struct { // nolint: unused,deadcode
 	// in: body
 	Body DeriveCredentialResponse
 }
+
+// storeWalletCredentialReq model
+//
+// swagger:parameters storeWalletCredentialReq
+type storeWalletCredentialReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params StoreCredentialRequest
+}
+
+// storeWalletCredentialRes model
+//
+// swagger:response storeWalletCredentialRes
+type storeWalletCredentialRes struct { // nolint: unused,deadcode
+	// in: body
+	StoreCredentialResponse
+}
+
+// listWalletCredentialsReq model
+//
+// swagger:parameters listWalletCredentialsReq
+type listWalletCredentialsReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// only the credentials of the type
+	//
+	// in: query
+	Type string `json:"type"`
+
+	// only the credentials of the issuer
+	//
+	// in: query
+	Issuer string `json:"issuer"`
+}
+
+// listWalletCredentialsRes model
+//
+// swagger:response listWalletCredentialsRes
+type listWalletCredentialsRes struct { // nolint: unused,deadcode
+	// in: body
+	WalletCredentialsResponse
+}
+
+// walletCredentialReq model
+//
+// swagger:parameters walletCredentialReq
+type walletCredentialReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ProfileID string `json:"profileID"`
+
+	// credential ID
+	//
+	// in: query
+	// required: true
+	CredentialID string `json:"id"`
+}
+
+// walletCredentialRes model
+//
+// swagger:response walletCredentialRes
+type walletCredentialRes struct { // nolint: unused,deadcode
+	// in: body
+	Credential json.RawMessage
+}
@@ -16,20 +16,25 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/google/tink/go/keyset"
 	"github.com/gorilla/mux"
 	jsonldcontextrest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/jsonld/context"
 	ariescrypto "github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/piprate/json-gold/ld"
+	"github.com/trustbloc/edge-core/pkg/log"
 
+	"github.com/trustbloc/edge-service/pkg/claim"
 	"github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
 	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
 	"github.com/trustbloc/edge-service/pkg/internal/common/support"
 	commondid "github.com/trustbloc/edge-service/pkg/restapi/internal/common/did"
 	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+	"github.com/trustbloc/edge-service/pkg/restapi/internal/common/vcutil"
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
 )
 
@@ -42,10 +47,13 @@ const (
 	deleteHolderProfileEndpoint = holderProfileEndpoint + "/" + "{" + profileIDPathParam + "}"
 	signPresentationEndpoint    = "/" + "{" + profileIDPathParam + "}" + "/prove/presentations"
 	deriveCredentialsEndpoint   = "/" + "{" + profileIDPathParam + "}" + "/credentials/derive"
+	rotateHolderKeysEndpoint    = getHolderProfileEndpoint + "/rotateKeys"
 
 	invalidRequestErrMsg = "Invalid request"
 )
 
+var logger = log.New("edge-service-holder-restapi")
+
 // Handler http handler for each controller API endpoint
 type Handler interface {
 	Path() string
@@ -56,6 +64,13 @@ type Handler interface {
 type commonDID interface {
 	CreateDID(keyType, signatureType, did, privateKey, keyID, purpose string,
 		registrar model.UNIRegistrar) (string, string, error)
+	RotateKey(didID, keyType, signatureType, privateKey, keyID string) (string, error)
+}
+
+type didUpdateKeys interface {
+	Save(didID, keyID string) error
+	Has(didID string) (bool, error)
+	Commit(didID string) error
 }
 
 // New returns CreateCredential instance
@@ -76,25 +91,33 @@ func New(config *Config) (*Operation, error) {
 		commonDID: commondid.New(&commondid.Config{
 			VDRI: config.VDRI, KeyManager: config.KeyManager,
 			Domain: config.Domain, TLSConfig: config.TLSConfig,
-			DIDAnchorOrigin: config.DIDAnchorOrigin,
+			DIDAnchorOrigin: config.DIDAnchorOrigin, DIDUpdateKeys: config.DIDUpdateKeys,
 		}),
 		crypto:                  crypto.New(config.KeyManager, config.Crypto, config.VDRI, config.DocumentLoader),
 		documentLoader:          config.DocumentLoader,
 		addJSONLDContextHandler: contextOp.Add,
 	}
 
+	if config.EDVClient != nil {
+		if err = svc.prepareWallet(config); err != nil {
+			return nil, err
+		}
+	}
+
 	return svc, nil
 }
 
 // Config defines configuration for vcs operations
 type Config struct {
 	StoreProvider   ariesstorage.Provider
+	EDVClient       EDVClient
 	KeyManager      keyManager
 	VDRI            vdrapi.Registry
 	Domain          string
 	TLSConfig       *tls.Config
 	Crypto          ariescrypto.Crypto
 	DIDAnchorOrigin string
+	DIDUpdateKeys   didUpdateKeys
 	DocumentLoader  ld.DocumentLoader
 }
 
@@ -110,6 +133,16 @@ type Operation struct {
 	vdr                     vdrapi.Registry
 	documentLoader          ld.DocumentLoader
 	addJSONLDContextHandler http.HandlerFunc
+	edvClient               EDVClient
+	authService             authService
+	jweEncrypter            jose.Encrypter
+	jweDecrypter            jose.Decrypter
+	macKeyHandle            *keyset.Handle
+	macCrypto               ariescrypto.Crypto
+	vcIDIndexNameEncoded    string
+	typeIndexNameEncoded    string
+	issuerIndexNameEncoded  string
+	walletClaims            *claim.Store
 }
 
 // GetRESTHandlers get all controller API handler available for this service
@@ -119,8 +152,18 @@ func (o *Operation) GetRESTHandlers() []Handler {
 		support.NewHTTPHandler(holderProfileEndpoint, http.MethodPost, o.createHolderProfileHandler),
 		support.NewHTTPHandler(getHolderProfileEndpoint, http.MethodGet, o.getHolderProfileHandler),
 		support.NewHTTPHandler(deleteHolderProfileEndpoint, http.MethodDelete, o.deleteHolderProfileHandler),
+		support.NewHTTPHandler(holderProfileEndpoint, http.MethodGet, o.listHolderProfilesHandler),
+		support.NewHTTPHandler(getHolderProfileEndpoint, http.MethodPut, o.replaceHolderProfileHandler),
+		support.NewHTTPHandler(getHolderProfileEndpoint, http.MethodPatch, o.updateHolderProfileHandler),
+		support.NewHTTPHandler(rotateHolderKeysEndpoint, http.MethodPost, o.rotateHolderProfileKeysHandler),
 		support.NewHTTPHandler(signPresentationEndpoint, http.MethodPost, o.signPresentationHandler),
+		support.NewHTTPHandler(composePresentationEndpoint, http.MethodPost, o.composePresentationHandler),
 		support.NewHTTPHandler(deriveCredentialsEndpoint, http.MethodPost, o.deriveCredentialsHandler),
+		// credential wallet
+		support.NewHTTPHandler(walletCredentialsEndpoint, http.MethodPost, o.storeWalletCredentialHandler),
+		support.NewHTTPHandler(walletCredentialsEndpoint, http.MethodGet, o.listWalletCredentialsHandler),
+		support.NewHTTPHandler(walletCredentialEndpoint, http.MethodGet, o.retrieveWalletCredentialHandler),
+		support.NewHTTPHandler(walletCredentialEndpoint, http.MethodDelete, o.deleteWalletCredentialHandler),
 		// JSON-LD context API
 		support.NewHTTPHandler(jsonldcontextrest.AddContextPath, http.MethodPost, o.addJSONLDContextHandler),
 	}
@@ -203,7 +246,7 @@ func (o *Operation) getHolderProfileHandler(rw http.ResponseWriter, req *http.Re
 
 // DeleteHolderProfile swagger:route DELETE /holder/profile/{id} holder deleteHolderProfileReq
 //
-// Deletes holder profile.
+// Deletes holder profile along with the credentials of its wallet.
 //
 // Responses:
 // 		default: genericError
@@ -211,7 +254,22 @@ func (o *Operation) getHolderProfileHandler(rw http.ResponseWriter, req *http.Re
 func (o *Operation) deleteHolderProfileHandler(rw http.ResponseWriter, req *http.Request) {
 	profileID := mux.Vars(req)[profileIDPathParam]
 
-	err := o.profileStore.DeleteHolderProfile(profileID)
+	profile, err := o.profileStore.GetHolderProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid holder profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	if err = o.deleteWalletCredentials(profile); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to delete wallet credentials: %s", err.Error()))
+
+		return
+	}
+
+	err = o.profileStore.DeleteHolderProfile(profileID)
 	if err != nil {
 		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
 
@@ -219,9 +277,151 @@ func (o *Operation) deleteHolderProfileHandler(rw http.ResponseWriter, req *http
 	}
 }
 
+// ListHolderProfiles swagger:route GET /holder/profile holder listHolderProfilesReq
+//
+// Lists holder profiles, sorted by name.
+//
+// Responses:
+//    default: genericError
+//        200: listHolderProfilesRes
+func (o *Operation) listHolderProfilesHandler(rw http.ResponseWriter, req *http.Request) {
+	offset, limit, err := commhttp.ParsePage(req)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	profiles, total, err := o.profileStore.ListHolderProfiles(offset, limit)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to list holder profiles: %s", err.Error()))
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, &ListHolderProfilesResponse{Profiles: profiles, Total: total})
+}
+
+// ReplaceHolderProfile swagger:route PUT /holder/profile/{id} holder updateHolderProfileReq
+//
+// Replaces the mutable fields of the holder profile, the fields left out are reset.
+//
+// Responses:
+//    default: genericError
+//        200: holderProfileRes
+func (o *Operation) replaceHolderProfileHandler(rw http.ResponseWriter, req *http.Request) {
+	o.updateHolderProfile(rw, req, true)
+}
+
+// UpdateHolderProfile swagger:route PATCH /holder/profile/{id} holder updateHolderProfileReq
+//
+// Updates the given mutable fields of the holder profile.
+//
+// Responses:
+//    default: genericError
+//        200: holderProfileRes
+func (o *Operation) updateHolderProfileHandler(rw http.ResponseWriter, req *http.Request) {
+	o.updateHolderProfile(rw, req, false)
+}
+
+func (o *Operation) updateHolderProfile(rw http.ResponseWriter, req *http.Request, replace bool) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.profileStore.GetHolderProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid holder profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	data := UpdateHolderProfileRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	updated := data.apply(profile, replace)
+
+	err = vcutil.ValidateProfileSignatureUpdate(profile.DataProfile, updated.SignatureType,
+		updated.SignatureRepresentation)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	if err = o.profileStore.SaveHolderProfile(updated); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to save holder profile: %s", err.Error()))
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, updated)
+}
+
+// RotateHolderProfileKeys swagger:route POST /holder/profile/{id}/rotateKeys holder rotateHolderKeysReq
+//
+// Rotates the signing key of the holder profile. A new key is added to the profile's DID and the profile signs with
+// it from then on, the previous keys are kept in the DID so the presentations already signed still verify.
+//
+// Responses:
+//    default: genericError
+//        200: holderProfileRes
+func (o *Operation) rotateHolderProfileKeysHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.profileStore.GetHolderProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid holder profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	data := RotateKeysRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	if data.DIDKeyType == "" {
+		data.DIDKeyType = crypto.Ed25519KeyType
+	}
+
+	creator, err := o.commonDID.RotateKey(profile.DID, data.DIDKeyType, profile.SignatureType, data.DIDPrivateKey,
+		data.DIDKeyID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to rotate profile keys: %s", err.Error()))
+
+		return
+	}
+
+	profile.PreviousCreators = append(profile.PreviousCreators, profile.Creator)
+	profile.Creator = creator
+	profile.KeyType = vcutil.ProfileKeyType(data.DIDKeyType, "", data.DIDPrivateKey)
+
+	if err = o.profileStore.SaveHolderProfile(profile); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to save holder profile: %s", err.Error()))
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, profile)
+}
+
 // SignPresentation swagger:route POST /{id}/prove/presentations holder signPresentationReq
 //
-// Signs a presentation.
+// Signs a presentation. The presentation may refer to credentials stored in the wallet of the holder profile by
+// their IDs, they are added to it.
 //
 // Responses:
 //    default: genericError
@@ -248,10 +448,9 @@ func (o *Operation) signPresentationHandler(rw http.ResponseWriter, req *http.Re
 		return
 	}
 
-	presentation, err := verifiable.ParsePresentation(presReq.Presentation, verifiable.WithPresDisabledProofCheck(),
-		verifiable.WithPresJSONLDDocumentLoader(o.documentLoader))
+	presentation, status, err := o.presentationToSign(profile, &presReq)
 	if err != nil {
-		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+		commhttp.WriteErrorResponse(rw, status, err.Error())
 
 		return
 	}
@@ -389,6 +588,53 @@ func getPresentationSigningOpts(opts *SignPresentationOptions) []crypto.SigningO
 	return signingOpts
 }
 
+// presentationToSign parses the presentation of the request and adds the stored credentials it refers to, the
+// presentation is created if the request only refers to stored credentials.
+func (o *Operation) presentationToSign(profile *vcprofile.HolderProfile,
+	presReq *SignPresentationRequest) (*verifiable.Presentation, int, error) {
+	var (
+		presentation *verifiable.Presentation
+		err          error
+	)
+
+	if len(presReq.Presentation) != 0 || len(presReq.CredentialIDs) == 0 {
+		presentation, err = verifiable.ParsePresentation(presReq.Presentation, verifiable.WithPresDisabledProofCheck(),
+			verifiable.WithPresJSONLDDocumentLoader(o.documentLoader))
+	} else {
+		presentation, err = verifiable.NewPresentation()
+	}
+
+	if err != nil || len(presReq.CredentialIDs) == 0 {
+		return presentation, http.StatusBadRequest, err
+	}
+
+	if o.edvClient == nil {
+		return nil, http.StatusBadRequest, errWalletNotConfigured
+	}
+
+	stored, err := o.walletCredentials(profile, presReq.CredentialIDs...)
+	if errors.Is(err, errCredentialNotFound) {
+		return nil, http.StatusBadRequest, err
+	}
+
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	for _, vcBytes := range stored {
+		// the proofs of the stored credentials were verified when they were stored
+		vc, errParse := verifiable.ParseCredential(vcBytes, verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+		if errParse != nil {
+			return nil, http.StatusInternalServerError, fmt.Errorf("failed to parse stored credential: %w", errParse)
+		}
+
+		presentation.AddCredentials(vc)
+	}
+
+	return presentation, http.StatusOK, nil
+}
+
 // updateHolder overrides presentation holder form profile.
 func updateHolder(presentation *verifiable.Presentation, profile *vcprofile.HolderProfile) {
 	if profile.OverwriteHolder || presentation.Holder == "" {
@@ -415,6 +661,7 @@ func (o *Operation) createHolderProfile(pr *HolderProfileRequest) (*vcprofile.Ho
 			SignatureType:           pr.SignatureType,
 			SignatureRepresentation: pr.SignatureRepresentation,
 			Creator:                 publicKeyID,
+			KeyType:                 vcutil.ProfileKeyType(pr.DIDKeyType, pr.DID, pr.DIDPrivateKey),
 		},
 		OverwriteHolder: pr.OverwriteHolder,
 	}, nil
@@ -310,6 +310,194 @@ func TestDeleteHolderProfileHandler(t *testing.T) {
 	})
 }
 
+func TestListHolderProfilesHandler(t *testing.T) {
+	op, err := New(&Config{
+		StoreProvider: ariesmemstorage.NewProvider(),
+		VDRI:          &vdrmock.MockVDRegistry{},
+	})
+	require.NoError(t, err)
+
+	for _, name := range []string{"c", "a", "b"} {
+		require.NoError(t, op.profileStore.SaveHolderProfile(&vcprofile.HolderProfile{
+			DataProfile: &vcprofile.DataProfile{Name: name, SignatureType: vccrypto.Ed25519Signature2018},
+		}))
+	}
+
+	handler := getHandler(t, op, holderProfileEndpoint, http.MethodGet)
+
+	t.Run("list profiles - success", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, holderProfileEndpoint+"?limit=2", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		resp := &ListHolderProfilesResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, 3, resp.Total)
+		require.Len(t, resp.Profiles, 2)
+		require.Equal(t, "a", resp.Profiles[0].Name)
+		require.Equal(t, "b", resp.Profiles[1].Name)
+	})
+
+	t.Run("list profiles - invalid page", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, holderProfileEndpoint+"?offset=x", nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid offset")
+	})
+
+	t.Run("list profiles - store error", func(t *testing.T) {
+		op, err := New(&Config{
+			StoreProvider: &ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+				Store:    make(map[string]ariesmockstorage.DBEntry),
+				ErrQuery: errors.New("query error"),
+			}},
+			VDRI: &vdrmock.MockVDRegistry{},
+		})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, holderProfileEndpoint, http.MethodGet), holderProfileEndpoint, nil, nil)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to list holder profiles")
+	})
+}
+
+func TestUpdateHolderProfileHandler(t *testing.T) {
+	op, err := New(&Config{
+		StoreProvider: ariesmemstorage.NewProvider(),
+		VDRI:          &vdrmock.MockVDRegistry{},
+	})
+	require.NoError(t, err)
+
+	update := func(t *testing.T, method, profileID string, req interface{}) (int, *vcprofile.HolderProfile, string) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, getHolderProfileEndpoint, method), "/holder/profile/"+profileID,
+			reqBytes, map[string]string{profileIDPathParam: profileID})
+
+		profile := &vcprofile.HolderProfile{}
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), profile))
+		}
+
+		return rr.Code, profile, rr.Body.String()
+	}
+
+	signatureType := vccrypto.JSONWebSignature2020
+	jws := verifiable.SignatureJWS
+	overwrite := true
+
+	t.Run("patch profile - updates the fields set", func(t *testing.T) {
+		saveTestProfile(t, op)
+
+		code, updated, body := update(t, http.MethodPatch, testProfileID, &UpdateHolderProfileRequest{
+			SignatureType: &signatureType, OverwriteHolder: &overwrite,
+		})
+		require.Equal(t, http.StatusOK, code, body)
+		require.Equal(t, signatureType, updated.SignatureType)
+		require.True(t, updated.OverwriteHolder)
+
+		saved, err := op.profileStore.GetHolderProfile(testProfileID)
+		require.NoError(t, err)
+		require.Equal(t, updated, saved)
+	})
+
+	t.Run("put profile - resets the fields left out", func(t *testing.T) {
+		code, updated, body := update(t, http.MethodPut, testProfileID, &UpdateHolderProfileRequest{
+			SignatureType: &signatureType, SignatureRepresentation: &jws,
+		})
+		require.Equal(t, http.StatusOK, code, body)
+		require.Equal(t, verifiable.SignatureJWS, updated.SignatureRepresentation)
+		require.False(t, updated.OverwriteHolder)
+	})
+
+	t.Run("put profile - missing signature type", func(t *testing.T) {
+		code, _, body := update(t, http.MethodPut, testProfileID, &UpdateHolderProfileRequest{})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "unsupported signature type")
+	})
+
+	t.Run("patch profile - invalid profile", func(t *testing.T) {
+		code, _, body := update(t, http.MethodPatch, "unknown", &UpdateHolderProfileRequest{})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid holder profile")
+	})
+
+	t.Run("patch profile - invalid request", func(t *testing.T) {
+		code, _, body := update(t, http.MethodPatch, testProfileID, "invalid")
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, invalidRequestErrMsg)
+	})
+}
+
+func TestRotateHolderProfileKeysHandler(t *testing.T) {
+	op, err := New(&Config{
+		StoreProvider: ariesmemstorage.NewProvider(),
+		VDRI:          &vdrmock.MockVDRegistry{},
+	})
+	require.NoError(t, err)
+
+	commonDID := &mockCommonDID{}
+	op.commonDID = commonDID
+
+	rotateKeys := func(t *testing.T, profileID string, req interface{}) (int, string) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, rotateHolderKeysEndpoint, http.MethodPost),
+			"/holder/profile/"+profileID+"/rotateKeys", reqBytes, map[string]string{profileIDPathParam: profileID})
+
+		return rr.Code, rr.Body.String()
+	}
+
+	t.Run("rotate keys - success", func(t *testing.T) {
+		saveTestProfile(t, op)
+
+		profile, err := op.profileStore.GetHolderProfile(testProfileID)
+		require.NoError(t, err)
+
+		profile.Creator = "did:test:abc#key1"
+		require.NoError(t, op.profileStore.SaveHolderProfile(profile))
+
+		commonDID.rotateKeyValue = "did:test:abc#key2"
+
+		code, body := rotateKeys(t, testProfileID, &RotateKeysRequest{})
+		require.Equal(t, http.StatusOK, code, body)
+
+		profile, err = op.profileStore.GetHolderProfile(testProfileID)
+		require.NoError(t, err)
+		require.Equal(t, "did:test:abc#key2", profile.Creator)
+		require.Equal(t, []string{"did:test:abc#key1"}, profile.PreviousCreators)
+	})
+
+	t.Run("rotate keys - invalid profile", func(t *testing.T) {
+		code, body := rotateKeys(t, "unknown", &RotateKeysRequest{})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid holder profile")
+	})
+
+	t.Run("rotate keys - invalid request", func(t *testing.T) {
+		saveTestProfile(t, op)
+
+		code, body := rotateKeys(t, testProfileID, "invalid")
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, invalidRequestErrMsg)
+	})
+
+	t.Run("rotate keys - failed to rotate key", func(t *testing.T) {
+		saveTestProfile(t, op)
+
+		commonDID.rotateKeyErr = errors.New("rotate error")
+		defer func() { commonDID.rotateKeyErr = nil }()
+
+		code, body := rotateKeys(t, testProfileID, &RotateKeysRequest{})
+		require.Equal(t, http.StatusInternalServerError, code)
+		require.Contains(t, body, "failed to rotate profile keys: rotate error")
+	})
+}
+
 func TestDeriveCredentials(t *testing.T) {
 	endpoint := "/test/credentials/derive"
 
@@ -826,6 +1014,8 @@ type mockCommonDID struct {
 	createDIDValue string
 	createDIDKeyID string
 	createDIDErr   error
+	rotateKeyValue string
+	rotateKeyErr   error
 }
 
 func (m *mockCommonDID) CreateDID(keyType, signatureType, didID, privateKey, keyID, purpose string,
@@ -833,6 +1023,10 @@ func (m *mockCommonDID) CreateDID(keyType, signatureType, didID, privateKey, key
 	return m.createDIDValue, m.createDIDKeyID, m.createDIDErr
 }
 
+func (m *mockCommonDID) RotateKey(didID, keyType, signatureType, privateKey, keyID string) (string, error) {
+	return m.rotateKeyValue, m.rotateKeyErr
+}
+
 func getHandler(t *testing.T, op *Operation, lookupPath, methodToLookup string) Handler {
 	t.Helper()
 
@@ -901,7 +1095,9 @@ func saveTestProfile(t *testing.T, op *Operation) {
 
 	vReq := &vcprofile.HolderProfile{
 		DataProfile: &vcprofile.DataProfile{
-			Name: testProfileID,
+			Name:          testProfileID,
+			SignatureType: vccrypto.Ed25519Signature2018,
+			KeyType:       vccrypto.Ed25519KeyType,
 		},
 	}
 
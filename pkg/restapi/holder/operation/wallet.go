@@ -0,0 +1,609 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/trustbloc/edv/pkg/client"
+	"github.com/trustbloc/edv/pkg/restapi/models"
+
+	zcapsvc "github.com/trustbloc/edge-service/pkg/auth/zcapld"
+	"github.com/trustbloc/edge-service/pkg/claim"
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/internal/cryptosetup"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+	"github.com/trustbloc/edge-service/pkg/restapi/internal/common/vcutil"
+)
+
+const (
+	walletCredentialsEndpoint = "/" + "{" + profileIDPathParam + "}" + "/wallet/credentials"
+	walletCredentialEndpoint  = "/" + "{" + profileIDPathParam + "}" + "/wallet/credential"
+
+	// the names of the indexes of the wallet credentials besides their IDs, their MACs index the vault documents
+	typeIndex   = "type"
+	issuerIndex = "issuer"
+
+	vcType = "VerifiableCredential"
+
+	// walletVaultClaimExpiry bounds how long a replica creates the vault of a wallet, its claim expires after that
+	walletVaultClaimExpiry = time.Minute
+)
+
+var (
+	errWalletNotConfigured = errors.New("credential wallet isn't configured")
+	errCredentialNotFound  = errors.New("credential not found")
+	errWalletVaultPending  = errors.New("wallet vault is being created, try again")
+)
+
+// EDVClient interface to interact with edv client
+type EDVClient interface {
+	CreateDataVault(config *models.DataVaultConfiguration, opts ...client.ReqOption) (string, []byte, error)
+	CreateDocument(vaultID string, document *models.EncryptedDocument, opts ...client.ReqOption) (string, error)
+	ReadDocument(vaultID, docID string, opts ...client.ReqOption) (*models.EncryptedDocument, error)
+	QueryVault(vaultID, name, value string, opts ...client.ReqOption) ([]string, error)
+	DeleteDocument(vaultID, docID string, opts ...client.ReqOption) error
+}
+
+type authService interface {
+	CreateDIDKey() (string, error)
+	SignHeader(req *http.Request, capabilityBytes []byte, verificationMethod string) (*http.Header, error)
+}
+
+// prepareWallet prepares the crypto of the credential wallets: the credentials are encrypted in the vaults of the
+// holder profiles and indexed by the MACs of their IDs, types and issuers.
+func (o *Operation) prepareWallet(config *Config) error {
+	jweEncrypter, jweDecrypter, err := cryptosetup.PrepareJWECrypto(config.KeyManager, config.StoreProvider,
+		config.Crypto, jose.A256GCM, kms.NISTP256ECDHKWType)
+	if err != nil {
+		return err
+	}
+
+	kh, vcIDIndexNameMACEncoded, err :=
+		cryptosetup.PrepareMACCrypto(config.KeyManager, config.StoreProvider, config.Crypto, kms.HMACSHA256Tag256Type)
+	if err != nil {
+		return err
+	}
+
+	o.edvClient = config.EDVClient
+	o.authService = zcapsvc.New(config.KeyManager, config.Crypto)
+	o.jweEncrypter = jweEncrypter
+	o.jweDecrypter = jweDecrypter
+	o.macKeyHandle = kh
+	o.macCrypto = config.Crypto
+	o.vcIDIndexNameEncoded = vcIDIndexNameMACEncoded
+
+	if o.walletClaims, err = claim.New(config.StoreProvider); err != nil {
+		return err
+	}
+
+	if o.typeIndexNameEncoded, err = o.computeMAC(typeIndex); err != nil {
+		return err
+	}
+
+	o.issuerIndexNameEncoded, err = o.computeMAC(issuerIndex)
+
+	return err
+}
+
+// StoreWalletCredential swagger:route POST /{id}/wallet/credentials holder storeWalletCredentialReq
+//
+// Stores a credential in the wallet of the holder profile, encrypted in the profile's vault. The credential must
+// have an ID, its proofs are verified.
+//
+// Responses:
+//    default: genericError
+//        201: storeWalletCredentialRes
+func (o *Operation) storeWalletCredentialHandler(rw http.ResponseWriter, req *http.Request) {
+	profile, status, err := o.getWalletProfile(req)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, status, err.Error())
+
+		return
+	}
+
+	data := StoreCredentialRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	vc, err := verifiable.ParseCredential(data.Credential,
+		verifiable.WithPublicKeyFetcher(verifiable.NewVDRKeyResolver(o.vdr).PublicKeyFetcher()),
+		verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("failed to parse credential: %s",
+			err.Error()))
+
+		return
+	}
+
+	if vc.ID == "" {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, "credential has no id")
+
+		return
+	}
+
+	if err = o.createWalletVault(profile); err != nil {
+		commhttp.WriteErrorResponse(rw, walletErrorStatus(err),
+			fmt.Sprintf("failed to create wallet vault: %s", err.Error()))
+
+		return
+	}
+
+	docURLs, err := o.queryWallet(profile, o.vcIDIndexNameEncoded, vc.ID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	if len(docURLs) != 0 {
+		commhttp.WriteErrorResponse(rw, http.StatusConflict, fmt.Sprintf("credential %s is already stored", vc.ID))
+
+		return
+	}
+
+	if err = o.storeCredential(profile, vc, data.Credential); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to store credential: %s", err.Error()))
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusCreated)
+	commhttp.WriteResponse(rw, &StoreCredentialResponse{ID: vc.ID})
+}
+
+// ListWalletCredentials swagger:route GET /{id}/wallet/credentials holder listWalletCredentialsReq
+//
+// Lists the credentials in the wallet of the holder profile, optionally only those of the given type and issuer.
+//
+// Responses:
+//    default: genericError
+//        200: listWalletCredentialsRes
+func (o *Operation) listWalletCredentialsHandler(rw http.ResponseWriter, req *http.Request) {
+	profile, status, err := o.getWalletProfile(req)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, status, err.Error())
+
+		return
+	}
+
+	credentials, err := o.queryWalletCredentials(profile, req.URL.Query().Get(typeIndex),
+		req.URL.Query().Get(issuerIndex))
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to query wallet credentials: %s", err.Error()))
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, &WalletCredentialsResponse{Credentials: credentials})
+}
+
+// RetrieveWalletCredential swagger:route GET /{profileID}/wallet/credential holder walletCredentialReq
+//
+// Retrieves a credential from the wallet of the holder profile.
+//
+// Responses:
+//    default: genericError
+//        200: walletCredentialRes
+func (o *Operation) retrieveWalletCredentialHandler(rw http.ResponseWriter, req *http.Request) {
+	profile, status, err := o.getWalletProfile(req)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, status, err.Error())
+
+		return
+	}
+
+	vcID := req.URL.Query().Get("id")
+	if vcID == "" {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, "missing credential id")
+
+		return
+	}
+
+	credentials, err := o.walletCredentials(profile, vcID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, walletErrorStatus(err), err.Error())
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, credentials[0])
+}
+
+// DeleteWalletCredential swagger:route DELETE /{profileID}/wallet/credential holder walletCredentialReq
+//
+// Deletes a credential from the wallet of the holder profile.
+//
+// Responses:
+//    default: genericError
+//        200: emptyRes
+func (o *Operation) deleteWalletCredentialHandler(rw http.ResponseWriter, req *http.Request) {
+	profile, status, err := o.getWalletProfile(req)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, status, err.Error())
+
+		return
+	}
+
+	vcID := req.URL.Query().Get("id")
+	if vcID == "" {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, "missing credential id")
+
+		return
+	}
+
+	docURLs, err := o.findWalletDocuments(profile, vcID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, walletErrorStatus(err), err.Error())
+
+		return
+	}
+
+	if err = o.deleteWalletDocuments(profile, docURLs); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to delete credential: %s", err.Error()))
+
+		return
+	}
+}
+
+func (o *Operation) getWalletProfile(req *http.Request) (*vcprofile.HolderProfile, int, error) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.profileStore.GetHolderProfile(profileID)
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid holder profile - id=%s: err=%s", profileID,
+			err.Error())
+	}
+
+	if o.edvClient == nil {
+		return nil, http.StatusNotImplemented, errWalletNotConfigured
+	}
+
+	return profile, http.StatusOK, nil
+}
+
+// createWalletVault creates the vault of the profile's wallet, unless it already has one. The replicas of the service
+// claim the creation of the vault in the shared storage, the others are told to try again meanwhile.
+func (o *Operation) createWalletVault(profile *vcprofile.HolderProfile) error {
+	if profile.EDVVaultID != "" {
+		return nil
+	}
+
+	c, err := o.walletClaims.Claim("walletVault_"+profile.Name, walletVaultClaimExpiry)
+	if errors.Is(err, claim.ErrClaimed) {
+		return errWalletVaultPending
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if errRelease := o.walletClaims.Release(c); errRelease != nil {
+			logger.Warnf("failed to release wallet vault claim of profile %s: %s", profile.Name, errRelease.Error())
+		}
+	}()
+
+	// the vault may have been created since the profile was read
+	stored, err := o.profileStore.GetHolderProfile(profile.Name)
+	if err != nil {
+		return err
+	}
+
+	if stored.EDVVaultID == "" {
+		didKey, errKey := o.authService.CreateDIDKey()
+		if errKey != nil {
+			return errKey
+		}
+
+		dataVaultConfig := &models.DataVaultConfiguration{
+			Sequence: 0, Controller: didKey, ReferenceID: uuid.New().String(),
+			KEK:  models.IDTypePair{ID: uuid.New().URN(), Type: "X25519KeyAgreementKey2019"},
+			HMAC: models.IDTypePair{ID: uuid.New().URN(), Type: "Sha256HmacKey2019"},
+		}
+
+		vaultLocationURL, capability, errVault := o.edvClient.CreateDataVault(dataVaultConfig)
+		if errVault != nil {
+			return fmt.Errorf("fail to create vault in EDV: %w", errVault)
+		}
+
+		stored.EDVVaultID = vcutil.GetVaultIDFromURL(vaultLocationURL)
+		stored.EDVCapability = capability
+		stored.EDVController = didKey
+
+		if err = o.profileStore.SaveHolderProfile(stored); err != nil {
+			return err
+		}
+	}
+
+	profile.EDVVaultID = stored.EDVVaultID
+	profile.EDVCapability = stored.EDVCapability
+	profile.EDVController = stored.EDVController
+
+	return nil
+}
+
+// storeCredential stores the credential in the profile's vault, indexed by its ID, types and issuer.
+func (o *Operation) storeCredential(profile *vcprofile.HolderProfile, vc *verifiable.Credential,
+	vcBytes []byte) error {
+	doc, err := vcutil.BuildStructuredDocForStorage(vcBytes)
+	if err != nil {
+		return err
+	}
+
+	marshalledStructuredDoc, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	jwe, err := o.jweEncrypter.Encrypt(marshalledStructuredDoc)
+	if err != nil {
+		return err
+	}
+
+	encryptedStructuredDoc, err := jwe.FullSerialize(json.Marshal)
+	if err != nil {
+		return err
+	}
+
+	indexedAttributes, err := o.credentialIndexes(vc)
+	if err != nil {
+		return err
+	}
+
+	_, err = o.edvClient.CreateDocument(profile.EDVVaultID, &models.EncryptedDocument{
+		ID:  doc.ID,
+		JWE: []byte(encryptedStructuredDoc),
+		IndexedAttributeCollections: []models.IndexedAttributeCollection{{
+			IndexedAttributes: indexedAttributes,
+		}},
+	}, o.walletRequestHeader(profile))
+
+	return err
+}
+
+func (o *Operation) credentialIndexes(vc *verifiable.Credential) ([]models.IndexedAttribute, error) {
+	vcIDMAC, err := o.computeMAC(vc.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	indexedAttributes := []models.IndexedAttribute{{Name: o.vcIDIndexNameEncoded, Value: vcIDMAC, Unique: true}}
+
+	for _, t := range vc.Types {
+		typeMAC, errMAC := o.computeMAC(t)
+		if errMAC != nil {
+			return nil, errMAC
+		}
+
+		indexedAttributes = append(indexedAttributes, models.IndexedAttribute{
+			Name: o.typeIndexNameEncoded, Value: typeMAC,
+		})
+	}
+
+	issuerMAC, err := o.computeMAC(vc.Issuer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(indexedAttributes, models.IndexedAttribute{Name: o.issuerIndexNameEncoded, Value: issuerMAC}), nil
+}
+
+// queryWalletCredentials returns the credentials in the profile's wallet of the type and issuer, if they are given.
+func (o *Operation) queryWalletCredentials(profile *vcprofile.HolderProfile, vcType,
+	issuer string) ([]json.RawMessage, error) {
+	if profile.EDVVaultID == "" {
+		return []json.RawMessage{}, nil
+	}
+
+	docURLs, err := o.queryWalletDocuments(profile, vcType, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]json.RawMessage, 0, len(docURLs))
+
+	for _, docURL := range docURLs {
+		vcBytes, errRead := o.readWalletDocument(profile, docURL)
+		if errRead != nil {
+			return nil, errRead
+		}
+
+		credentials = append(credentials, vcBytes)
+	}
+
+	return credentials, nil
+}
+
+// queryWalletDocuments returns the documents of the profile's vault that match both the type and issuer, all the
+// credentials have the VerifiableCredential type.
+func (o *Operation) queryWalletDocuments(profile *vcprofile.HolderProfile, credentialType,
+	issuer string) ([]string, error) {
+	if credentialType == "" {
+		credentialType = vcType
+	}
+
+	docURLs, err := o.queryWallet(profile, o.typeIndexNameEncoded, credentialType)
+	if err != nil || issuer == "" {
+		return docURLs, err
+	}
+
+	issuerDocURLs, err := o.queryWallet(profile, o.issuerIndexNameEncoded, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	issued := make(map[string]bool, len(issuerDocURLs))
+
+	for _, docURL := range issuerDocURLs {
+		issued[vcutil.GetDocIDFromURL(docURL)] = true
+	}
+
+	var matches []string
+
+	for _, docURL := range docURLs {
+		if issued[vcutil.GetDocIDFromURL(docURL)] {
+			matches = append(matches, docURL)
+		}
+	}
+
+	return matches, nil
+}
+
+// walletCredentials returns the credentials of the profile's wallet with the given IDs.
+func (o *Operation) walletCredentials(profile *vcprofile.HolderProfile, vcIDs ...string) ([]json.RawMessage, error) {
+	credentials := make([]json.RawMessage, 0, len(vcIDs))
+
+	for _, vcID := range vcIDs {
+		docURLs, err := o.findWalletDocuments(profile, vcID)
+		if err != nil {
+			return nil, err
+		}
+
+		vcBytes, err := o.readWalletDocument(profile, docURLs[0])
+		if err != nil {
+			return nil, err
+		}
+
+		credentials = append(credentials, vcBytes)
+	}
+
+	return credentials, nil
+}
+
+// findWalletDocuments returns the documents of the profile's vault that store the credential, there may be several
+// copies of it as the vault is eventually consistent.
+func (o *Operation) findWalletDocuments(profile *vcprofile.HolderProfile, vcID string) ([]string, error) {
+	if profile.EDVVaultID == "" {
+		return nil, fmt.Errorf("%w: %s", errCredentialNotFound, vcID)
+	}
+
+	docURLs, err := o.queryWallet(profile, o.vcIDIndexNameEncoded, vcID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(docURLs) == 0 {
+		return nil, fmt.Errorf("%w: %s", errCredentialNotFound, vcID)
+	}
+
+	return docURLs, nil
+}
+
+func (o *Operation) queryWallet(profile *vcprofile.HolderProfile, indexName, value string) ([]string, error) {
+	valueMAC, err := o.computeMAC(value)
+	if err != nil {
+		return nil, err
+	}
+
+	docURLs, err := o.edvClient.QueryVault(profile.EDVVaultID, indexName, valueMAC, o.walletRequestHeader(profile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vault: %w", err)
+	}
+
+	return docURLs, nil
+}
+
+func (o *Operation) readWalletDocument(profile *vcprofile.HolderProfile, docURL string) (json.RawMessage, error) {
+	document, err := o.edvClient.ReadDocument(profile.EDVVaultID, vcutil.GetDocIDFromURL(docURL),
+		o.walletRequestHeader(profile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document: %w", err)
+	}
+
+	encryptedJWE, err := jose.Deserialize(string(document.JWE))
+	if err != nil {
+		return nil, err
+	}
+
+	decryptedDocBytes, err := o.jweDecrypter.Decrypt(encryptedJWE)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting document failed: %w", err)
+	}
+
+	decryptedDoc := models.StructuredDocument{}
+
+	if err = json.Unmarshal(decryptedDocBytes, &decryptedDoc); err != nil {
+		return nil, fmt.Errorf("decrypted structured document unmarshalling failed: %w", err)
+	}
+
+	return json.Marshal(decryptedDoc.Content["message"])
+}
+
+// deleteWalletCredentials deletes all the credentials of the profile's wallet.
+func (o *Operation) deleteWalletCredentials(profile *vcprofile.HolderProfile) error {
+	if o.edvClient == nil || profile.EDVVaultID == "" {
+		return nil
+	}
+
+	docURLs, err := o.queryWallet(profile, o.typeIndexNameEncoded, vcType)
+	if err != nil {
+		return err
+	}
+
+	return o.deleteWalletDocuments(profile, docURLs)
+}
+
+func (o *Operation) deleteWalletDocuments(profile *vcprofile.HolderProfile, docURLs []string) error {
+	for _, docURL := range docURLs {
+		err := o.edvClient.DeleteDocument(profile.EDVVaultID, vcutil.GetDocIDFromURL(docURL),
+			o.walletRequestHeader(profile))
+		if err != nil {
+			return fmt.Errorf("failed to delete document: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (o *Operation) walletRequestHeader(profile *vcprofile.HolderProfile) client.ReqOption {
+	return client.WithRequestHeader(func(req *http.Request) (*http.Header, error) {
+		if len(profile.EDVCapability) != 0 {
+			return o.authService.SignHeader(req, profile.EDVCapability, profile.EDVController)
+		}
+
+		return nil, nil
+	})
+}
+
+func (o *Operation) computeMAC(value string) (string, error) {
+	mac, err := o.macCrypto.ComputeMAC([]byte(value), o.macKeyHandle)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.URLEncoding.EncodeToString(mac), nil
+}
+
+func walletErrorStatus(err error) int {
+	if errors.Is(err, errCredentialNotFound) {
+		return http.StatusNotFound
+	}
+
+	if errors.Is(err, errWalletVaultPending) {
+		return http.StatusConflict
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,447 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/edv/pkg/client"
+	"github.com/trustbloc/edv/pkg/restapi/models"
+
+	vccrypto "github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+const (
+	walletVC = `{
+		"@context": [
+			"https://www.w3.org/2018/credentials/v1",
+			"https://www.w3.org/2018/credentials/examples/v1"
+		],
+		"id": "%s",
+		"type": ["VerifiableCredential", "%s"],
+		"issuer": "%s",
+		"issuanceDate": "2010-01-01T19:23:24Z",
+		"credentialSubject": {"id": "did:example:ebfeb1f712ebc6f1c276e12ec21"}
+	}`
+
+	testIssuer      = "did:test:issuer"
+	testOtherIssuer = "did:test:other-issuer"
+)
+
+func TestWalletCredentials(t *testing.T) {
+	customKMS := createKMS(t)
+
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	keyID, pubKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ED25519Type)
+	require.NoError(t, err)
+
+	edv := newMemEDVClient()
+
+	op, err := New(&Config{
+		StoreProvider: ariesmemstorage.NewProvider(),
+		EDVClient:     edv,
+		KeyManager:    customKMS,
+		Crypto:        customCrypto,
+		VDRI: &vdrmock.MockVDRegistry{
+			ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+				return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, keyID, pubKey)}, nil
+			},
+		},
+		DocumentLoader: testutil.DocumentLoader(t),
+	})
+	require.NoError(t, err)
+
+	saveTestProfile(t, op)
+
+	signVC := func(t *testing.T, id, vcType, issuer string) json.RawMessage {
+		t.Helper()
+
+		vc, err := verifiable.ParseCredential([]byte(fmt.Sprintf(walletVC, id, vcType, issuer)),
+			verifiable.WithDisabledProofCheck(), verifiable.WithJSONLDDocumentLoader(op.documentLoader))
+		require.NoError(t, err)
+
+		signed, err := op.crypto.SignCredential(&vcprofile.DataProfile{
+			Name: "issuer", DID: issuer, SignatureType: vccrypto.Ed25519Signature2018, Creator: issuer + "#" + keyID,
+		}, vc)
+		require.NoError(t, err)
+
+		vcBytes, err := signed.MarshalJSON()
+		require.NoError(t, err)
+
+		return vcBytes
+	}
+
+	urlVars := map[string]string{profileIDPathParam: testProfileID}
+
+	store := func(t *testing.T, vc json.RawMessage) (int, string) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(&StoreCredentialRequest{Credential: vc})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, walletCredentialsEndpoint, http.MethodPost),
+			"/"+testProfileID+"/wallet/credentials", reqBytes, urlVars)
+
+		return rr.Code, rr.Body.String()
+	}
+
+	list := func(t *testing.T, query string) []string {
+		t.Helper()
+
+		rr := serveHTTPMux(t, getHandler(t, op, walletCredentialsEndpoint, http.MethodGet),
+			"/"+testProfileID+"/wallet/credentials?"+query, nil, urlVars)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		resp := &WalletCredentialsResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+
+		ids := make([]string, 0, len(resp.Credentials))
+
+		for _, vc := range resp.Credentials {
+			cred := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal(vc, &cred))
+
+			ids = append(ids, cred["id"].(string))
+		}
+
+		return ids
+	}
+
+	credentialEndpoint := func(id string) string {
+		return "/" + testProfileID + "/wallet/credential?id=" + url.QueryEscape(id)
+	}
+
+	degree := signVC(t, "http://example.edu/credentials/1", "UniversityDegreeCredential", testIssuer)
+	otherDegree := signVC(t, "http://example.edu/credentials/2", "UniversityDegreeCredential", testOtherIssuer)
+	relationship := signVC(t, "http://example.edu/credentials/3", "RelationshipCredential", testIssuer)
+
+	t.Run("empty wallet", func(t *testing.T) {
+		require.Empty(t, list(t, ""))
+
+		rr := serveHTTPMux(t, getHandler(t, op, walletCredentialEndpoint, http.MethodGet),
+			credentialEndpoint("http://example.edu/credentials/1"), nil, urlVars)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("vault being created by another replica", func(t *testing.T) {
+		c, err := op.walletClaims.Claim("walletVault_"+testProfileID, time.Minute)
+		require.NoError(t, err)
+
+		code, body := store(t, degree)
+		require.Equal(t, http.StatusConflict, code)
+		require.Contains(t, body, "wallet vault is being created, try again")
+		require.Empty(t, edv.vaults)
+
+		require.NoError(t, op.walletClaims.Release(c))
+	})
+
+	t.Run("store credentials", func(t *testing.T) {
+		for _, vc := range []json.RawMessage{degree, otherDegree, relationship} {
+			code, body := store(t, vc)
+			require.Equal(t, http.StatusCreated, code, body)
+		}
+
+		require.Len(t, edv.vaults, 1)
+
+		profile, err := op.profileStore.GetHolderProfile(testProfileID)
+		require.NoError(t, err)
+		require.NotEmpty(t, profile.EDVVaultID)
+		require.NotEmpty(t, profile.EDVController)
+
+		// the credentials are encrypted in the vault
+		for _, doc := range edv.vaults[profile.EDVVaultID] {
+			require.NotContains(t, string(doc.JWE), "UniversityDegreeCredential")
+			require.NotContains(t, string(doc.JWE), "RelationshipCredential")
+		}
+	})
+
+	t.Run("store credential twice", func(t *testing.T) {
+		code, body := store(t, degree)
+		require.Equal(t, http.StatusConflict, code)
+		require.Contains(t, body, "credential http://example.edu/credentials/1 is already stored")
+	})
+
+	t.Run("store invalid credentials", func(t *testing.T) {
+		noID := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(walletVC, "", "UniversityDegreeCredential", testIssuer)),
+			&noID))
+		delete(noID, "id")
+
+		noIDBytes, err := json.Marshal(noID)
+		require.NoError(t, err)
+
+		code, body := store(t, noIDBytes)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "credential has no id")
+
+		tampered := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(relationship, &tampered))
+		tampered["issuanceDate"] = "2020-01-01T19:23:24Z"
+
+		tamperedBytes, err := json.Marshal(tampered)
+		require.NoError(t, err)
+
+		code, body = store(t, tamperedBytes)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "failed to parse credential")
+	})
+
+	t.Run("list and query credentials", func(t *testing.T) {
+		require.ElementsMatch(t, []string{
+			"http://example.edu/credentials/1", "http://example.edu/credentials/2", "http://example.edu/credentials/3",
+		}, list(t, ""))
+
+		require.ElementsMatch(t, []string{"http://example.edu/credentials/1", "http://example.edu/credentials/2"},
+			list(t, "type=UniversityDegreeCredential"))
+
+		require.ElementsMatch(t, []string{"http://example.edu/credentials/1", "http://example.edu/credentials/3"},
+			list(t, "issuer="+testIssuer))
+
+		require.Equal(t, []string{"http://example.edu/credentials/2"},
+			list(t, "type=UniversityDegreeCredential&issuer="+testOtherIssuer))
+
+		require.Empty(t, list(t, "type=RelationshipCredential&issuer="+testOtherIssuer))
+	})
+
+	t.Run("retrieve credential", func(t *testing.T) {
+		rr := serveHTTPMux(t, getHandler(t, op, walletCredentialEndpoint, http.MethodGet),
+			credentialEndpoint("http://example.edu/credentials/3"), nil, urlVars)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.JSONEq(t, string(relationship), rr.Body.String())
+
+		rr = serveHTTPMux(t, getHandler(t, op, walletCredentialEndpoint, http.MethodGet),
+			"/"+testProfileID+"/wallet/credential", nil, urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "missing credential id")
+	})
+
+	t.Run("sign presentation of stored credentials", func(t *testing.T) {
+		profile, err := op.profileStore.GetHolderProfile(testProfileID)
+		require.NoError(t, err)
+
+		profile.DID = "did:test:holder"
+		profile.SignatureType = vccrypto.Ed25519Signature2018
+		profile.Creator = profile.DID + "#" + keyID
+		require.NoError(t, op.profileStore.SaveHolderProfile(profile))
+
+		sign := func(t *testing.T, req *SignPresentationRequest) (int, []byte) {
+			t.Helper()
+
+			reqBytes, err := json.Marshal(req)
+			require.NoError(t, err)
+
+			rr := serveHTTPMux(t, getHandler(t, op, signPresentationEndpoint, http.MethodPost),
+				"/"+testProfileID+"/prove/presentations", reqBytes, urlVars)
+
+			return rr.Code, rr.Body.Bytes()
+		}
+
+		code, body := sign(t, &SignPresentationRequest{
+			CredentialIDs: []string{"http://example.edu/credentials/1", "http://example.edu/credentials/3"},
+		})
+		require.Equal(t, http.StatusCreated, code, string(body))
+
+		vp, err := verifiable.ParsePresentation(body,
+			verifiable.WithPresPublicKeyFetcher(verifiable.NewVDRKeyResolver(op.vdr).PublicKeyFetcher()),
+			verifiable.WithPresJSONLDDocumentLoader(op.documentLoader))
+		require.NoError(t, err)
+		require.Equal(t, "did:test:holder", vp.Holder)
+		require.Len(t, vp.Credentials(), 2)
+		require.Len(t, vp.Proofs, 1)
+
+		// the stored credentials are added to the given presentation
+		code, body = sign(t, &SignPresentationRequest{
+			Presentation:  json.RawMessage(vpWithoutProof),
+			CredentialIDs: []string{"http://example.edu/credentials/2"},
+		})
+		require.Equal(t, http.StatusCreated, code, string(body))
+
+		vp, err = verifiable.ParsePresentation(body, verifiable.WithPresDisabledProofCheck(),
+			verifiable.WithPresJSONLDDocumentLoader(op.documentLoader))
+		require.NoError(t, err)
+		require.Len(t, vp.Credentials(), 2)
+
+		code, body = sign(t, &SignPresentationRequest{CredentialIDs: []string{"http://example.edu/credentials/9"}})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "credential not found: http://example.edu/credentials/9")
+	})
+
+	t.Run("delete credential", func(t *testing.T) {
+		handler := getHandler(t, op, walletCredentialEndpoint, http.MethodDelete)
+
+		rr := serveHTTPMux(t, handler, credentialEndpoint("http://example.edu/credentials/2"), nil, urlVars)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		require.ElementsMatch(t, []string{"http://example.edu/credentials/1", "http://example.edu/credentials/3"},
+			list(t, ""))
+
+		rr = serveHTTPMux(t, handler, credentialEndpoint("http://example.edu/credentials/2"), nil, urlVars)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential not found: http://example.edu/credentials/2")
+	})
+
+	t.Run("vault errors", func(t *testing.T) {
+		edv.err = errors.New("edv error")
+		defer func() { edv.err = nil }()
+
+		rr := serveHTTPMux(t, getHandler(t, op, walletCredentialsEndpoint, http.MethodGet),
+			"/"+testProfileID+"/wallet/credentials", nil, urlVars)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Contains(t, rr.Body.String(), "edv error")
+
+		code, body := store(t, signVC(t, "http://example.edu/credentials/4", "UniversityDegreeCredential",
+			testIssuer))
+		require.Equal(t, http.StatusInternalServerError, code)
+		require.Contains(t, body, "edv error")
+	})
+
+	t.Run("invalid profile", func(t *testing.T) {
+		rr := serveHTTPMux(t, getHandler(t, op, walletCredentialsEndpoint, http.MethodGet),
+			"/unknown/wallet/credentials", nil, map[string]string{profileIDPathParam: "unknown"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid holder profile - id=unknown")
+	})
+
+	t.Run("delete profile along with its credentials", func(t *testing.T) {
+		profile, err := op.profileStore.GetHolderProfile(testProfileID)
+		require.NoError(t, err)
+		require.Len(t, edv.vaults[profile.EDVVaultID], 2)
+
+		rr := serveHTTPMux(t, getHandler(t, op, deleteHolderProfileEndpoint, http.MethodDelete),
+			holderProfileEndpoint+"/"+testProfileID, nil, urlVars)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Empty(t, edv.vaults[profile.EDVVaultID])
+	})
+
+	t.Run("wallet not configured", func(t *testing.T) {
+		noWallet, err := New(&Config{StoreProvider: ariesmemstorage.NewProvider(), VDRI: &vdrmock.MockVDRegistry{}})
+		require.NoError(t, err)
+
+		saveTestProfile(t, noWallet)
+
+		rr := serveHTTPMux(t, getHandler(t, noWallet, walletCredentialsEndpoint, http.MethodGet),
+			"/"+testProfileID+"/wallet/credentials", nil, urlVars)
+		require.Equal(t, http.StatusNotImplemented, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential wallet isn't configured")
+	})
+}
+
+// memEDVClient is an in-memory stand-in for the EDV server.
+type memEDVClient struct {
+	vaults map[string]map[string]*models.EncryptedDocument
+	err    error
+}
+
+func newMemEDVClient() *memEDVClient {
+	return &memEDVClient{vaults: map[string]map[string]*models.EncryptedDocument{}}
+}
+
+func (c *memEDVClient) CreateDataVault(_ *models.DataVaultConfiguration, _ ...client.ReqOption) (string, []byte,
+	error) {
+	if c.err != nil {
+		return "", nil, c.err
+	}
+
+	vaultID := uuid.New().String()
+	c.vaults[vaultID] = map[string]*models.EncryptedDocument{}
+
+	return "https://edv.example.com/encrypted-data-vaults/" + vaultID, nil, nil
+}
+
+func (c *memEDVClient) CreateDocument(vaultID string, document *models.EncryptedDocument,
+	_ ...client.ReqOption) (string, error) {
+	vault, err := c.vault(vaultID)
+	if err != nil {
+		return "", err
+	}
+
+	vault[document.ID] = document
+
+	return c.documentURL(vaultID, document.ID), nil
+}
+
+func (c *memEDVClient) ReadDocument(vaultID, docID string, _ ...client.ReqOption) (*models.EncryptedDocument, error) {
+	vault, err := c.vault(vaultID)
+	if err != nil {
+		return nil, err
+	}
+
+	document, ok := vault[docID]
+	if !ok {
+		return nil, errors.New("document not found")
+	}
+
+	return document, nil
+}
+
+func (c *memEDVClient) QueryVault(vaultID, name, value string, _ ...client.ReqOption) ([]string, error) {
+	vault, err := c.vault(vaultID)
+	if err != nil {
+		return nil, err
+	}
+
+	var docURLs []string
+
+	for docID, document := range vault {
+		for _, collection := range document.IndexedAttributeCollections {
+			for _, attribute := range collection.IndexedAttributes {
+				if attribute.Name == name && attribute.Value == value {
+					docURLs = append(docURLs, c.documentURL(vaultID, docID))
+				}
+			}
+		}
+	}
+
+	return docURLs, nil
+}
+
+func (c *memEDVClient) DeleteDocument(vaultID, docID string, _ ...client.ReqOption) error {
+	vault, err := c.vault(vaultID)
+	if err != nil {
+		return err
+	}
+
+	delete(vault, docID)
+
+	return nil
+}
+
+func (c *memEDVClient) vault(vaultID string) (map[string]*models.EncryptedDocument, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	vault, ok := c.vaults[vaultID]
+	if !ok {
+		return nil, fmt.Errorf("vault %s not found", vaultID)
+	}
+
+	return vault, nil
+}
+
+func (c *memEDVClient) documentURL(vaultID, docID string) string {
+	return "https://edv.example.com/encrypted-data-vaults/" + vaultID + "/documents/" + docID
+}
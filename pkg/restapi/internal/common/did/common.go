@@ -12,6 +12,7 @@ import (
 	"crypto/elliptic"
 	"crypto/tls"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -46,6 +47,7 @@ type CommonDID struct {
 	domain             string
 	createKey          func(keyType kms.KeyType, keyManager keyManager) (string, []byte, error)
 	didAnchorOrigin    string
+	updateKeys         updateKeys
 }
 
 // Config defines configuration for vcs operations
@@ -55,6 +57,7 @@ type Config struct {
 	Domain          string
 	TLSConfig       *tls.Config
 	DIDAnchorOrigin string
+	DIDUpdateKeys   updateKeys
 }
 
 type uniRegistrarClient interface {
@@ -65,6 +68,12 @@ type keyManager interface {
 	kms.KeyManager
 }
 
+type updateKeys interface {
+	Save(didID, keyID string) error
+	Has(didID string) (bool, error)
+	Commit(didID string) error
+}
+
 // New return new instance of common DID
 func New(config *Config) *CommonDID {
 	return &CommonDID{uniRegistrarClient: uniregistrar.New(uniregistrar.WithTLSConfig(config.TLSConfig)),
@@ -73,6 +82,7 @@ func New(config *Config) *CommonDID {
 		vdr:             config.VDRI,
 		createKey:       createKey,
 		didAnchorOrigin: config.DIDAnchorOrigin,
+		updateKeys:      config.DIDUpdateKeys,
 	}
 }
 
@@ -242,7 +252,7 @@ func (o *CommonDID) createDID(keyType, signatureType string) (string, string, er
 		return "", "", err
 	}
 
-	_, updatePubKey, err := o.createKey(kms.ED25519Type, o.keyManager)
+	updateKeyID, updatePubKey, err := o.createKey(kms.ED25519Type, o.keyManager)
 	if err != nil {
 		return "", "", err
 	}
@@ -259,9 +269,160 @@ func (o *CommonDID) createDID(keyType, signatureType string) (string, string, er
 
 	docID := docResolution.DIDDocument.ID
 
+	if o.updateKeys != nil {
+		if err = o.updateKeys.Save(docID, updateKeyID); err != nil {
+			return "", "", fmt.Errorf("failed to save did update key: %w", err)
+		}
+	}
+
 	return docID, docID + "#" + selectedKeyID, nil
 }
 
+// RotateKey adds a new signing key to the DID and returns its ID, the keys already in the DID are kept so that the
+// credentials signed with them still verify. The DIDs created by the service are updated with a new key from the
+// KMS, for the others the key must be added to the DID by its controller and its private key given to import.
+func (o *CommonDID) RotateKey(didID, keyType, signatureType, privateKey, keyID string) (string, error) {
+	docResolution, err := o.vdr.Resolve(didID)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve did: %w", err)
+	}
+
+	if privateKey != "" {
+		return o.importRotatedKey(docResolution.DIDDocument, keyType, privateKey, keyID)
+	}
+
+	if o.updateKeys == nil {
+		return "", errors.New("did updates aren't supported")
+	}
+
+	hasUpdateKey, err := o.updateKeys.Has(docResolution.DIDDocument.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get did update key: %w", err)
+	}
+
+	// the update keys of the DIDs created before they were recorded are lost, these DIDs can't be updated anymore
+	if !hasUpdateKey {
+		return "", fmt.Errorf("did %s can't be updated, it was created before the did update keys were recorded: "+
+			"create a profile with a new did to rotate its key", didID)
+	}
+
+	vm, err := o.createVerificationMethod(keyType, signatureType)
+	if err != nil {
+		return "", fmt.Errorf("failed to create did public key: %w", err)
+	}
+
+	err = o.vdr.Update(rotatedDoc(docResolution.DIDDocument, vm))
+	if err != nil {
+		return "", fmt.Errorf("failed to update did doc: %w", err)
+	}
+
+	if err = o.updateKeys.Commit(docResolution.DIDDocument.ID); err != nil {
+		return "", fmt.Errorf("failed to commit did update key: %w", err)
+	}
+
+	return didID + "#" + vm.ID, nil
+}
+
+func (o *CommonDID) importRotatedKey(didDoc *did.Doc, keyType, privateKey, keyID string) (string, error) {
+	if !strings.Contains(keyID, "#") {
+		return "", fmt.Errorf("invalid key id %s", keyID)
+	}
+
+	fragment := keyID[strings.Index(keyID, "#"):]
+
+	found := false
+
+	for _, vm := range didDoc.VerificationMethod {
+		if strings.HasSuffix(vm.ID, fragment) {
+			found = true
+
+			break
+		}
+	}
+
+	if !found {
+		return "", fmt.Errorf("key %s not found in did %s", keyID, didDoc.ID)
+	}
+
+	kmsKeyType := kms.ED25519Type
+
+	if keyType == kms.BLS12381G2 {
+		kmsKeyType = kms.BLS12381G2Type
+	}
+
+	if err := o.importKey(keyID, kmsKeyType, base58.Decode(privateKey)); err != nil {
+		return "", err
+	}
+
+	return keyID, nil
+}
+
+// createVerificationMethod creates a key matching the key and signature types, with the same rules as the keys
+// created along with the DID.
+func (o *CommonDID) createVerificationMethod(keyType, signatureType string) (*did.VerificationMethod, error) {
+	var (
+		kmsKeyType kms.KeyType
+		vmType     string
+	)
+
+	switch {
+	case keyType == crypto.Ed25519KeyType && signatureKeyTypeMap[signatureType] == doc.Ed25519VerificationKey2018:
+		kmsKeyType, vmType = kms.ED25519Type, doc.Ed25519VerificationKey2018
+	case keyType == crypto.Ed25519KeyType && signatureKeyTypeMap[signatureType] == crypto.JSONWebKey2020:
+		kmsKeyType, vmType = kms.ED25519Type, crypto.JSONWebKey2020
+	case keyType == crypto.P256KeyType && signatureKeyTypeMap[signatureType] == crypto.JSONWebKey2020:
+		kmsKeyType, vmType = kms.ECDSAP256IEEEP1363, crypto.JSONWebKey2020
+	default:
+		return nil, fmt.Errorf("no key found to match key type:%s and signature type:%s", keyType, signatureType)
+	}
+
+	keyID, pubKeyBytes, err := o.createKey(kmsKeyType, o.keyManager)
+	if err != nil {
+		return nil, err
+	}
+
+	var pubKey interface{} = ed25519.PublicKey(pubKeyBytes)
+
+	if kmsKeyType == kms.ECDSAP256IEEEP1363 {
+		x, y := elliptic.Unmarshal(elliptic.P256(), pubKeyBytes)
+		pubKey = &ecdsa.PublicKey{X: x, Y: y, Curve: elliptic.P256()}
+	}
+
+	jwk, err := jose.JWKFromKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return did.NewVerificationMethodFromJWK(keyID, vmType, "", jwk)
+}
+
+// rotatedDoc returns the DID doc to update the DID with, its keys along with the new one. The sidetree update takes
+// the keys from the verification relationships only, with relative IDs.
+func rotatedDoc(didDoc *did.Doc, vm *did.VerificationMethod) *did.Doc {
+	rotated := &did.Doc{ID: didDoc.ID, Service: didDoc.Service}
+
+	relative := func(verifications []did.Verification) []did.Verification {
+		result := make([]did.Verification, len(verifications))
+
+		for i, v := range verifications {
+			v.VerificationMethod.ID = v.VerificationMethod.ID[strings.Index(v.VerificationMethod.ID, "#")+1:]
+			result[i] = v
+		}
+
+		return result
+	}
+
+	rotated.Authentication = append(relative(didDoc.Authentication),
+		*did.NewReferencedVerification(vm, did.Authentication))
+	rotated.AssertionMethod = append(relative(didDoc.AssertionMethod),
+		*did.NewReferencedVerification(vm, did.AssertionMethod))
+	rotated.CapabilityDelegation = relative(didDoc.CapabilityDelegation)
+	rotated.CapabilityInvocation = relative(didDoc.CapabilityInvocation)
+	rotated.KeyAgreement = relative(didDoc.KeyAgreement)
+
+	return rotated
+}
+
 // nolint:funlen,gocyclo
 func (o *CommonDID) createPublicKeys(keyType, signatureType string) (*did.Doc,
 	[]*didmethodoperation.PublicKey, string, error) {
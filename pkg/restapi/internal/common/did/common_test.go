@@ -16,6 +16,7 @@ import (
 
 	"github.com/btcsuite/btcutil/base58"
 	ariesdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
@@ -145,6 +146,43 @@ func TestCommonDID_CreateDID(t *testing.T) {
 		require.Empty(t, did)
 	})
 
+	t.Run("test success - update key saved", func(t *testing.T) {
+		updateKeys := &mockUpdateKeys{}
+
+		c := New(&Config{DIDUpdateKeys: updateKeys, VDRI: &vdr.MockVDRegistry{
+			CreateFunc: func(s string, doc *ariesdid.Doc,
+				option ...vdrapi.DIDMethodOption) (*ariesdid.DocResolution, error) {
+				return &ariesdid.DocResolution{DIDDocument: &ariesdid.Doc{ID: "did:orb:123"}}, nil
+			}}})
+
+		c.createKey = func(keyType kms.KeyType, keyManager keyManager) (string, []byte, error) {
+			if keyType == kms.ED25519Type {
+				v, _, err := ed25519.GenerateKey(rand.Reader)
+				require.NoError(t, err)
+
+				return key1, v, nil
+			}
+
+			ecPrivKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			require.NoError(t, err)
+
+			return key1, elliptic.Marshal(ecPrivKey.Curve, ecPrivKey.X, ecPrivKey.Y), nil
+		}
+
+		did, _, err := c.CreateDID(crypto.Ed25519KeyType, crypto.Ed25519Signature2018, "", "",
+			"", crypto.Authentication, model.UNIRegistrar{})
+		require.NoError(t, err)
+		require.Equal(t, "did:orb:123", did)
+		require.Equal(t, map[string]string{"did:orb:123": key1}, updateKeys.saved)
+
+		updateKeys.saveErr = fmt.Errorf("save error")
+
+		_, _, err = c.CreateDID(crypto.Ed25519KeyType, crypto.Ed25519Signature2018, "", "",
+			"", crypto.Authentication, model.UNIRegistrar{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to save did update key: save error")
+	})
+
 	t.Run("test error - create did failed", func(t *testing.T) {
 		c := New(&Config{VDRI: &vdr.MockVDRegistry{
 			CreateFunc: func(s string, doc *ariesdid.Doc,
@@ -380,6 +418,168 @@ func TestCommonDID_CreateDIDUniRegistrar(t *testing.T) {
 	})
 }
 
+func TestCommonDID_RotateKey(t *testing.T) {
+	createKey := func(t *testing.T) func(kms.KeyType, keyManager) (string, []byte, error) {
+		t.Helper()
+
+		return func(keyType kms.KeyType, keyManager keyManager) (string, []byte, error) {
+			if keyType == kms.ED25519Type {
+				v, _, err := ed25519.GenerateKey(rand.Reader)
+				require.NoError(t, err)
+
+				return "key2", v, nil
+			}
+
+			ecPrivKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			require.NoError(t, err)
+
+			return "key2", elliptic.Marshal(ecPrivKey.Curve, ecPrivKey.X, ecPrivKey.Y), nil
+		}
+	}
+
+	didDoc := func(t *testing.T) *ariesdid.Doc {
+		t.Helper()
+
+		pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		jwk, err := jose.JWKFromKey(pubKey)
+		require.NoError(t, err)
+
+		vm, err := ariesdid.NewVerificationMethodFromJWK("did:orb:abc#key1", crypto.JSONWebKey2020, "did:orb:abc",
+			jwk)
+		require.NoError(t, err)
+
+		return &ariesdid.Doc{
+			ID:                 "did:orb:abc",
+			VerificationMethod: []ariesdid.VerificationMethod{*vm},
+			Authentication:     []ariesdid.Verification{*ariesdid.NewReferencedVerification(vm, ariesdid.Authentication)},
+			AssertionMethod:    []ariesdid.Verification{*ariesdid.NewReferencedVerification(vm, ariesdid.AssertionMethod)},
+		}
+	}
+
+	t.Run("test success - did updated with a new key", func(t *testing.T) {
+		var updated *ariesdid.Doc
+
+		updateKeys := &mockUpdateKeys{}
+
+		c := New(&Config{DIDUpdateKeys: updateKeys, VDRI: &vdr.MockVDRegistry{
+			ResolveValue: didDoc(t),
+			UpdateFunc: func(doc *ariesdid.Doc, opts ...vdrapi.DIDMethodOption) error {
+				updated = doc
+
+				return nil
+			},
+		}})
+		c.createKey = createKey(t)
+
+		for _, keyType := range []string{crypto.Ed25519KeyType, crypto.P256KeyType} {
+			keyID, err := c.RotateKey("did:orb:abc", keyType, crypto.JSONWebSignature2020, "", "")
+			require.NoError(t, err)
+			require.Equal(t, "did:orb:abc#key2", keyID)
+
+			require.Empty(t, updated.VerificationMethod)
+			require.Len(t, updated.Authentication, 2)
+			require.Len(t, updated.AssertionMethod, 2)
+			require.Equal(t, "key1", updated.AssertionMethod[0].VerificationMethod.ID)
+			require.Equal(t, "key2", updated.AssertionMethod[1].VerificationMethod.ID)
+			require.Equal(t, crypto.JSONWebKey2020, updated.AssertionMethod[1].VerificationMethod.Type)
+			require.Equal(t, "did:orb:abc", updateKeys.committed)
+		}
+	})
+
+	t.Run("test success - key added to the did by its controller imported", func(t *testing.T) {
+		c := New(&Config{KeyManager: &mockkms.KeyManager{}, VDRI: &vdr.MockVDRegistry{ResolveValue: didDoc(t)}})
+
+		_, privKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		keyID, err := c.RotateKey("did:orb:abc", crypto.Ed25519KeyType, crypto.JSONWebSignature2020,
+			base58.Encode(privKey), "did:orb:abc#key1")
+		require.NoError(t, err)
+		require.Equal(t, "did:orb:abc#key1", keyID)
+
+		_, err = c.RotateKey("did:orb:abc", crypto.Ed25519KeyType, crypto.JSONWebSignature2020,
+			base58.Encode(privKey), "did:orb:abc#key3")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "key did:orb:abc#key3 not found in did did:orb:abc")
+
+		_, err = c.RotateKey("did:orb:abc", crypto.Ed25519KeyType, crypto.JSONWebSignature2020,
+			base58.Encode(privKey), "key1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid key id key1")
+	})
+
+	t.Run("test error - resolve DID", func(t *testing.T) {
+		c := New(&Config{VDRI: &vdr.MockVDRegistry{ResolveErr: fmt.Errorf("resolve error")}})
+
+		_, err := c.RotateKey("did:orb:abc", crypto.Ed25519KeyType, crypto.JSONWebSignature2020, "", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to resolve did: resolve error")
+	})
+
+	t.Run("test error - did updates not supported", func(t *testing.T) {
+		c := New(&Config{VDRI: &vdr.MockVDRegistry{ResolveValue: didDoc(t)}})
+
+		_, err := c.RotateKey("did:orb:abc", crypto.Ed25519KeyType, crypto.JSONWebSignature2020, "", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "did updates aren't supported")
+	})
+
+	t.Run("test error - did created before its update key was recorded", func(t *testing.T) {
+		c := New(&Config{DIDUpdateKeys: &mockUpdateKeys{missing: true}, VDRI: &vdr.MockVDRegistry{
+			ResolveValue: didDoc(t),
+		}})
+		c.createKey = createKey(t)
+
+		_, err := c.RotateKey("did:orb:abc", crypto.Ed25519KeyType, crypto.Ed25519Signature2018, "", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "did did:orb:abc can't be updated, it was created before the did update keys "+
+			"were recorded")
+
+		c = New(&Config{DIDUpdateKeys: &mockUpdateKeys{hasErr: fmt.Errorf("get error")}, VDRI: &vdr.MockVDRegistry{
+			ResolveValue: didDoc(t),
+		}})
+
+		_, err = c.RotateKey("did:orb:abc", crypto.Ed25519KeyType, crypto.Ed25519Signature2018, "", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get did update key: get error")
+	})
+
+	t.Run("test error - no key matching key and signature types", func(t *testing.T) {
+		c := New(&Config{DIDUpdateKeys: &mockUpdateKeys{}, VDRI: &vdr.MockVDRegistry{ResolveValue: didDoc(t)}})
+		c.createKey = createKey(t)
+
+		_, err := c.RotateKey("did:orb:abc", crypto.P256KeyType, crypto.Ed25519Signature2018, "", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no key found to match key type:P256 and signature type:Ed25519Signature2018")
+	})
+
+	t.Run("test error - update DID", func(t *testing.T) {
+		c := New(&Config{DIDUpdateKeys: &mockUpdateKeys{}, VDRI: &vdr.MockVDRegistry{
+			ResolveValue: didDoc(t),
+			UpdateFunc: func(doc *ariesdid.Doc, opts ...vdrapi.DIDMethodOption) error {
+				return fmt.Errorf("update error")
+			},
+		}})
+		c.createKey = createKey(t)
+
+		_, err := c.RotateKey("did:orb:abc", crypto.Ed25519KeyType, crypto.Ed25519Signature2018, "", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to update did doc: update error")
+	})
+
+	t.Run("test error - commit update key", func(t *testing.T) {
+		c := New(&Config{DIDUpdateKeys: &mockUpdateKeys{commitErr: fmt.Errorf("commit error")},
+			VDRI: &vdr.MockVDRegistry{ResolveValue: didDoc(t)}})
+		c.createKey = createKey(t)
+
+		_, err := c.RotateKey("did:orb:abc", crypto.Ed25519KeyType, crypto.Ed25519Signature2018, "", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to commit did update key: commit error")
+	})
+}
+
 func TestCommonDID_CreateKey(t *testing.T) {
 	t.Run("test error - export public key failed", func(t *testing.T) {
 		c := New(&Config{KeyManager: &mockkms.KeyManager{ExportPubKeyBytesErr: fmt.Errorf("failed export public key")}})
@@ -398,6 +598,35 @@ func TestCommonDID_ImportKey(t *testing.T) {
 	})
 }
 
+type mockUpdateKeys struct {
+	saved     map[string]string
+	committed string
+	saveErr   error
+	commitErr error
+	missing   bool
+	hasErr    error
+}
+
+func (m *mockUpdateKeys) Has(didID string) (bool, error) {
+	return !m.missing, m.hasErr
+}
+
+func (m *mockUpdateKeys) Save(didID, keyID string) error {
+	if m.saved == nil {
+		m.saved = map[string]string{}
+	}
+
+	m.saved[didID] = keyID
+
+	return m.saveErr
+}
+
+func (m *mockUpdateKeys) Commit(didID string) error {
+	m.committed = didID
+
+	return m.commitErr
+}
+
 type mockUNIRegistrarClient struct {
 	CreateDIDValue string
 	CreateDIDKeys  []didmethodoperation.Key
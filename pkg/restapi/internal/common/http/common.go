@@ -8,12 +8,21 @@ package http
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/trustbloc/edge-core/pkg/log"
 )
 
+const (
+	// DefaultPageSize is the number of items listed when the request doesn't set a limit.
+	DefaultPageSize = 100
+	// MaxPageSize is the maximum number of items listed at once.
+	MaxPageSize = 1000
+)
+
 var logger = log.New("edge-service-restapi-common-http")
 
 // ErrorResponse to send error message in the response
@@ -41,3 +50,40 @@ func WriteResponse(rw io.Writer, v interface{}) {
 		logger.Errorf("Unable to send error response, %s", err)
 	}
 }
+
+// ParsePage parses the offset and limit query parameters that select the page of the listed items.
+func ParsePage(req *http.Request) (int, int, error) {
+	offset, err := queryInt(req, "offset", 0)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	limit, err := queryInt(req, "limit", DefaultPageSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if offset < 0 {
+		return 0, 0, fmt.Errorf("invalid offset %d", offset)
+	}
+
+	if limit < 1 || limit > MaxPageSize {
+		return 0, 0, fmt.Errorf("limit must be between 1 and %d", MaxPageSize)
+	}
+
+	return offset, limit, nil
+}
+
+func queryInt(req *http.Request, name string, defaultValue int) (int, error) {
+	value := req.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	return i, nil
+}
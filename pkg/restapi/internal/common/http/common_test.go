@@ -0,0 +1,43 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePage(t *testing.T) {
+	offset, limit, err := ParsePage(httptest.NewRequest("GET", "/profile", nil))
+	require.NoError(t, err)
+	require.Equal(t, 0, offset)
+	require.Equal(t, DefaultPageSize, limit)
+
+	offset, limit, err = ParsePage(httptest.NewRequest("GET", "/profile?offset=20&limit=10", nil))
+	require.NoError(t, err)
+	require.Equal(t, 20, offset)
+	require.Equal(t, 10, limit)
+
+	_, _, err = ParsePage(httptest.NewRequest("GET", "/profile?offset=a", nil))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid offset")
+
+	_, _, err = ParsePage(httptest.NewRequest("GET", "/profile?limit=a", nil))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid limit")
+
+	_, _, err = ParsePage(httptest.NewRequest("GET", "/profile?offset=-1", nil))
+	require.EqualError(t, err, "invalid offset -1")
+
+	_, _, err = ParsePage(httptest.NewRequest("GET", "/profile?limit=0", nil))
+	require.EqualError(t, err, "limit must be between 1 and 1000")
+
+	_, _, err = ParsePage(httptest.NewRequest("GET", "/profile?limit=1001", nil))
+	require.EqualError(t, err, "limit must be between 1 and 1000")
+}
@@ -13,11 +13,13 @@ import (
 	"strings"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/trustbloc/edv/pkg/edvutils"
 	"github.com/trustbloc/edv/pkg/restapi/models"
 
 	"github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
 	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/internal/common/utils"
 )
 
 const (
@@ -128,14 +130,30 @@ func UpdateIssuer(credential *verifiable.Credential, profile *vcprofile.IssuerPr
 // UpdateSignatureTypeContext updates context for JSONWebSignature2020
 func UpdateSignatureTypeContext(credential *verifiable.Credential, profile *vcprofile.IssuerProfile) {
 	if profile.SignatureType == crypto.JSONWebSignature2020 {
-		credential.Context = append(credential.Context, jsonWebSignature2020Context)
+		credential.Context = appendContext(credential.Context, jsonWebSignature2020Context)
 	}
 
 	if profile.SignatureType == crypto.BbsBlsSignature2020 {
-		credential.Context = append(credential.Context, bbsBlsSignature2020Context)
+		credential.Context = appendContext(credential.Context, bbsBlsSignature2020Context)
 	}
 }
 
+// IsSignatureTypeContext tells whether the context is one UpdateSignatureTypeContext adds for a signature type.
+func IsSignatureTypeContext(context string) bool {
+	return context == jsonWebSignature2020Context || context == bbsBlsSignature2020Context
+}
+
+// appendContext appends the context unless the credential has it already, like a refreshed credential does.
+func appendContext(contexts []string, context string) []string {
+	for _, c := range contexts {
+		if c == context {
+			return contexts
+		}
+	}
+
+	return append(contexts, context)
+}
+
 // GetDocIDFromURL Given an EDV document URL, returns just the document ID
 func GetDocIDFromURL(docURL string) string {
 	splitBySlashes := strings.Split(docURL, `/`)
@@ -150,3 +168,75 @@ func GetVaultIDFromURL(vaultLocationURL string) string {
 
 	return vaultLocationURLSplitUp[len(vaultLocationURLSplitUp)-1]
 }
+
+// ValidateProfileSignatureUpdate checks that a profile can sign with the updated signature type and representation.
+// Profiles keep their signing key, the signature type can only change to one its key type supports.
+func ValidateProfileSignatureUpdate(profile *vcprofile.DataProfile, signatureType string,
+	representation verifiable.SignatureRepresentation) error {
+	switch signatureType {
+	case crypto.Ed25519Signature2018, crypto.JSONWebSignature2020, crypto.BbsBlsSignature2020:
+	default:
+		return fmt.Errorf("unsupported signature type %s", signatureType)
+	}
+
+	if profile.SignatureType != signatureType {
+		keyType := profileKeyType(profile)
+		if keyType == "" {
+			return fmt.Errorf("signature type can't be changed from %s to %s, the profile's key type is unknown",
+				profile.SignatureType, signatureType)
+		}
+
+		if !utils.Contains(keySignatureTypes[keyType], signatureType) {
+			return fmt.Errorf("signature type can't be changed from %s to %s, the profile's key doesn't support it",
+				profile.SignatureType, signatureType)
+		}
+	}
+
+	switch representation {
+	case verifiable.SignatureProofValue, verifiable.SignatureJWS:
+	default:
+		return fmt.Errorf("unsupported signature representation %d", representation)
+	}
+
+	return nil
+}
+
+// ProfileKeyType returns the type of the key a profile signs with, from the key type, DID and private key given
+// when the profile was created or its keys rotated. Imported private keys are Ed25519 keys unless they are BLS
+// keys. The type is not known when the profile signs with a key of its existing DID that was not imported.
+func ProfileKeyType(keyType, didID, privateKey string) string {
+	switch {
+	case privateKey != "" && keyType == kms.BLS12381G2:
+		return kms.BLS12381G2
+	case privateKey != "":
+		return crypto.Ed25519KeyType
+	case didID != "":
+		return ""
+	default:
+		return keyType
+	}
+}
+
+// nolint: gochecknoglobals
+var keySignatureTypes = map[string][]string{
+	crypto.Ed25519KeyType: {crypto.Ed25519Signature2018, crypto.JSONWebSignature2020},
+	crypto.P256KeyType:    {crypto.JSONWebSignature2020},
+	kms.BLS12381G2:        {crypto.BbsBlsSignature2020},
+}
+
+// profileKeyType returns the type of the profile's key. The profiles created before their key type was recorded
+// only tell it by the signature types that need a given key type.
+func profileKeyType(profile *vcprofile.DataProfile) string {
+	if profile.KeyType != "" {
+		return profile.KeyType
+	}
+
+	switch profile.SignatureType {
+	case crypto.Ed25519Signature2018:
+		return crypto.Ed25519KeyType
+	case crypto.BbsBlsSignature2020:
+		return kms.BLS12381G2
+	default:
+		return ""
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
@@ -248,3 +249,44 @@ func TestGetDocIDFromURL(t *testing.T) {
 func stringToRaw(s string) json.RawMessage {
 	return json.RawMessage([]byte(s))
 }
+
+func TestValidateProfileSignatureUpdate(t *testing.T) {
+	profile := &vcprofile.DataProfile{SignatureType: crypto.Ed25519Signature2018}
+
+	require.NoError(t, ValidateProfileSignatureUpdate(profile, crypto.JSONWebSignature2020, verifiable.SignatureJWS))
+	require.NoError(t, ValidateProfileSignatureUpdate(profile, crypto.Ed25519Signature2018,
+		verifiable.SignatureProofValue))
+
+	err := ValidateProfileSignatureUpdate(profile, "unknown", verifiable.SignatureJWS)
+	require.EqualError(t, err, "unsupported signature type unknown")
+
+	err = ValidateProfileSignatureUpdate(profile, crypto.BbsBlsSignature2020, verifiable.SignatureProofValue)
+	require.EqualError(t, err, "signature type can't be changed from Ed25519Signature2018 to BbsBlsSignature2020, "+
+		"the profile's key doesn't support it")
+
+	err = ValidateProfileSignatureUpdate(&vcprofile.DataProfile{SignatureType: crypto.BbsBlsSignature2020},
+		crypto.JSONWebSignature2020, verifiable.SignatureProofValue)
+	require.Error(t, err)
+
+	err = ValidateProfileSignatureUpdate(&vcprofile.DataProfile{
+		SignatureType: crypto.JSONWebSignature2020,
+		KeyType:       crypto.P256KeyType,
+	}, crypto.Ed25519Signature2018, verifiable.SignatureProofValue)
+	require.EqualError(t, err, "signature type can't be changed from JsonWebSignature2020 to Ed25519Signature2018, "+
+		"the profile's key doesn't support it")
+
+	err = ValidateProfileSignatureUpdate(&vcprofile.DataProfile{SignatureType: crypto.JSONWebSignature2020},
+		crypto.Ed25519Signature2018, verifiable.SignatureProofValue)
+	require.EqualError(t, err, "signature type can't be changed from JsonWebSignature2020 to Ed25519Signature2018, "+
+		"the profile's key type is unknown")
+
+	err = ValidateProfileSignatureUpdate(profile, crypto.Ed25519Signature2018, 5)
+	require.EqualError(t, err, "unsupported signature representation 5")
+}
+
+func TestProfileKeyType(t *testing.T) {
+	require.Equal(t, crypto.P256KeyType, ProfileKeyType(crypto.P256KeyType, "", ""))
+	require.Equal(t, crypto.Ed25519KeyType, ProfileKeyType(crypto.P256KeyType, "", "privateKey"))
+	require.Equal(t, kms.BLS12381G2, ProfileKeyType(kms.BLS12381G2, "", "privateKey"))
+	require.Empty(t, ProfileKeyType(crypto.Ed25519KeyType, "did:example:123", ""))
+}
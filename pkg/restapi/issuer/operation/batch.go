@@ -0,0 +1,173 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	cslstatus "github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+)
+
+const (
+	batchIssueCredentialPath = credentialsBasePath + "/issueBatch"
+
+	maxBatchSize = 1000
+	// batchSigningParallelism bounds the number of credentials of a batch signed at the same time.
+	batchSigningParallelism = 10
+)
+
+// batchItem is a credential of the batch ready to be issued.
+type batchItem struct {
+	index      int
+	credential *verifiable.Credential
+	opts       []crypto.SigningOpts
+}
+
+// BatchIssueCredential swagger:route POST /{id}/credentials/issueBatch issuer batchIssueCredentialReq
+//
+// Issues a batch of credentials. Every item is issued on its own, the results tell which items failed and why.
+//
+// Responses:
+//    default: genericError
+//        200: batchIssueCredentialRes
+func (o *Operation) batchIssueCredentialHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.getActiveProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	batch := BatchIssueCredentialRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&batch); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	if len(batch.Credentials) == 0 {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, "missing credentials to issue")
+
+		return
+	}
+
+	if len(batch.Credentials) > maxBatchSize {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("batch size exceeds the maximum of %d credentials", maxBatchSize))
+
+		return
+	}
+
+	results := make([]*BatchIssueCredentialResult, len(batch.Credentials))
+
+	var items []*batchItem
+
+	for i, c := range batch.Credentials {
+		results[i] = &BatchIssueCredentialResult{}
+
+		credential, opts, errPrepare := o.prepareBatchItem(profile, c)
+		if errPrepare != nil {
+			results[i].Error = errPrepare.Error()
+
+			continue
+		}
+
+		items = append(items, &batchItem{index: i, credential: credential, opts: opts})
+	}
+
+	if !profile.DisableVCStatus && len(items) != 0 {
+		// reserve the status list entries of the whole batch at once
+		statusIDs, errStatus := o.vcStatusManager.CreateStatusIDs(profile.DataProfile,
+			o.hostURL+"/"+profileID+credentialStatus, len(items))
+		if errStatus != nil {
+			commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to add credential status:"+
+				" %s", errStatus.Error()))
+
+			return
+		}
+
+		for i, item := range items {
+			item.credential.Status = statusIDs[i]
+			item.credential.Context = append(item.credential.Context, cslstatus.Context)
+		}
+	}
+
+	o.issueBatch(profile, items, results)
+
+	commhttp.WriteResponse(rw, &BatchIssueCredentialResponse{Results: results})
+}
+
+// prepareBatchItem builds the credential of the batch item along with its signing options.
+func (o *Operation) prepareBatchItem(profile *vcprofile.IssuerProfile,
+	item *BatchIssueCredentialItem) (*verifiable.Credential, []crypto.SigningOpts, error) {
+	if item == nil {
+		return nil, nil, errors.New("missing credential or compose request")
+	}
+
+	switch {
+	case len(item.Credential) != 0 && item.Compose != nil:
+		return nil, nil, errors.New("either credential or compose request must be set, not both")
+	case item.Compose != nil:
+		return composeCredential(profile, item.Compose)
+	case len(item.Credential) != 0:
+		credential, err := o.parseCredentialToIssue(profile,
+			&IssueCredentialRequest{Credential: item.Credential, Opts: item.Opts})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return credential, getIssuerSigningOpts(item.Opts), nil
+	default:
+		return nil, nil, errors.New("missing credential or compose request")
+	}
+}
+
+// issueBatch signs and records the credentials of the batch concurrently, with bounded parallelism.
+func (o *Operation) issueBatch(profile *vcprofile.IssuerProfile, items []*batchItem,
+	results []*BatchIssueCredentialResult) {
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, batchSigningParallelism)
+
+	for _, item := range items {
+		wg.Add(1)
+
+		sem <- struct{}{}
+
+		go func(item *batchItem) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			signedVC, err := o.issue(profile, item.credential, item.opts)
+			if err != nil {
+				results[item.index].Error = err.Error()
+
+				return
+			}
+
+			results[item.index].Credential = signedVC
+		}(item)
+	}
+
+	wg.Wait()
+}
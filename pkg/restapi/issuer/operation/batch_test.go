@@ -0,0 +1,160 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	cslstatus "github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+func TestBatchIssueCredential(t *testing.T) {
+	customKMS := createKMS(t)
+
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	keyID, pubKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ED25519Type)
+	require.NoError(t, err)
+
+	op, err := New(&Config{
+		StoreProvider:      ariesmemstorage.NewProvider(),
+		KMSSecretsProvider: ariesmemstorage.NewProvider(),
+		KeyManager:         customKMS,
+		Crypto:             customCrypto,
+		VDRI: &vdrmock.MockVDRegistry{
+			ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+				return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, keyID, pubKey)}, nil
+			},
+		},
+		HostURL:        "https://issuer.example.com",
+		DocumentLoader: testutil.DocumentLoader(t),
+	})
+	require.NoError(t, err)
+
+	profile := getTestProfile()
+	profile.Creator = "did:test:abc#" + keyID
+
+	require.NoError(t, op.profileStore.SaveProfile(profile))
+
+	issueBatch := func(t *testing.T, profileID string, req interface{}) (int, []byte) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, batchIssueCredentialPath, http.MethodPost),
+			"/"+profileID+"/credentials/issueBatch", reqBytes, map[string]string{profileIDPathParam: profileID})
+
+		return rr.Code, rr.Body.Bytes()
+	}
+
+	t.Run("issue batch - success with failed items", func(t *testing.T) {
+		code, body := issueBatch(t, profile.Name, &BatchIssueCredentialRequest{
+			Credentials: []*BatchIssueCredentialItem{
+				{Credential: []byte(validVC)},
+				{Credential: []byte(`{"id":"invalid"}`)},
+				{Compose: &ComposeCredentialRequest{Subject: "did:example:oleh394sqwnlk223823ln"}},
+				{},
+				{Credential: []byte(validVC), Compose: &ComposeCredentialRequest{}},
+				{Compose: &ComposeCredentialRequest{TemplateID: "unknown"}},
+			},
+		})
+		require.Equal(t, http.StatusOK, code, string(body))
+
+		var resp struct {
+			Results []struct {
+				Credential map[string]interface{} `json:"credential"`
+				Error      string                 `json:"error"`
+			} `json:"results"`
+		}
+
+		require.NoError(t, json.Unmarshal(body, &resp))
+		require.Len(t, resp.Results, 6)
+
+		require.Empty(t, resp.Results[0].Error)
+		require.Equal(t, "http://example.edu/credentials/1872", resp.Results[0].Credential["id"])
+		require.NotEmpty(t, resp.Results[0].Credential["proof"])
+
+		require.Contains(t, resp.Results[1].Error, "failed to validate credential")
+		require.Nil(t, resp.Results[1].Credential)
+
+		require.Empty(t, resp.Results[2].Error)
+		require.NotEmpty(t, resp.Results[2].Credential["proof"])
+
+		require.Equal(t, "missing credential or compose request", resp.Results[3].Error)
+		require.Equal(t, "either credential or compose request must be set, not both", resp.Results[4].Error)
+		require.Contains(t, resp.Results[5].Error, "credential template unknown not found")
+
+		// the status list entries are reserved once for the whole batch
+		status0, ok := resp.Results[0].Credential["credentialStatus"].(map[string]interface{})
+		require.True(t, ok)
+
+		status2, ok := resp.Results[2].Credential["credentialStatus"].(map[string]interface{})
+		require.True(t, ok)
+
+		require.Equal(t, cslstatus.RevocationList2020Status, status0["type"])
+		require.NotEqual(t, status0[cslstatus.RevocationListIndex], status2[cslstatus.RevocationListIndex])
+		require.Equal(t, status0[cslstatus.RevocationListCredential], status2[cslstatus.RevocationListCredential])
+
+		records, err := op.registry.List(profile.Name)
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+	})
+
+	t.Run("issue batch - invalid profile", func(t *testing.T) {
+		code, body := issueBatch(t, "unknown", &BatchIssueCredentialRequest{})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "invalid issuer profile")
+	})
+
+	t.Run("issue batch - invalid request", func(t *testing.T) {
+		code, body := issueBatch(t, profile.Name, "invalid")
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), invalidRequestErrMsg)
+	})
+
+	t.Run("issue batch - empty batch", func(t *testing.T) {
+		code, body := issueBatch(t, profile.Name, &BatchIssueCredentialRequest{})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "missing credentials to issue")
+	})
+
+	t.Run("issue batch - batch too large", func(t *testing.T) {
+		code, body := issueBatch(t, profile.Name, &BatchIssueCredentialRequest{
+			Credentials: make([]*BatchIssueCredentialItem, maxBatchSize+1),
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), fmt.Sprintf("batch size exceeds the maximum of %d", maxBatchSize))
+	})
+
+	t.Run("issue batch - failed to reserve status list entries", func(t *testing.T) {
+		statusManager := op.vcStatusManager
+		defer func() { op.vcStatusManager = statusManager }()
+
+		op.vcStatusManager = &mockVCStatusManager{createStatusIDErr: errors.New("status error")}
+
+		code, body := issueBatch(t, profile.Name, &BatchIssueCredentialRequest{
+			Credentials: []*BatchIssueCredentialItem{{Credential: []byte(validVC)}},
+		})
+		require.Equal(t, http.StatusInternalServerError, code)
+		require.Contains(t, string(body), "failed to add credential status: status error")
+	})
+}
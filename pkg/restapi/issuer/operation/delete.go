@@ -0,0 +1,210 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	"github.com/hyperledger/aries-framework-go/pkg/store/wrapper/prefix"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edv/pkg/client"
+
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/restapi/internal/common/vcutil"
+)
+
+const (
+	softDeleteQueryParam  = "soft"
+	destroyKeysQueryParam = "destroyKeys"
+)
+
+// deleteOptions are the options of a profile deletion, given as query parameters.
+type deleteOptions struct {
+	soft        bool
+	destroyKeys bool
+}
+
+func parseDeleteOptions(req *http.Request) (*deleteOptions, error) {
+	opts := &deleteOptions{}
+
+	for name, value := range map[string]*bool{
+		softDeleteQueryParam:  &opts.soft,
+		destroyKeysQueryParam: &opts.destroyKeys,
+	} {
+		param := req.URL.Query().Get(name)
+		if param == "" {
+			continue
+		}
+
+		b, err := strconv.ParseBool(param)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+
+		*value = b
+	}
+
+	if opts.soft && opts.destroyKeys {
+		return nil, errors.New("keys can't be destroyed on soft delete, the status lists are signed with them")
+	}
+
+	return opts, nil
+}
+
+// softDeleteProfile marks the profile as deleted. It keeps its name, keys and status lists, so the credentials it
+// issued can still be verified and revoked.
+func (o *Operation) softDeleteProfile(profile *vcprofile.IssuerProfile) error {
+	if profile.Deleted != nil {
+		return nil
+	}
+
+	deleted := time.Now().UTC()
+	profile.Deleted = &deleted
+
+	return o.profileStore.SaveProfile(profile)
+}
+
+// deleteProfileData deletes the credentials the profile stored in its vault, whether it issued them or was given
+// them to store, its status lists, the records of the credentials, its webhooks and, if asked for, its signing key.
+func (o *Operation) deleteProfileData(profile *vcprofile.IssuerProfile, destroyKeys bool) error {
+	records, err := o.registry.List(profile.Name)
+	if err != nil {
+		return fmt.Errorf("failed to list issued credentials: %w", err)
+	}
+
+	storedIDs, err := o.registry.ListStored(profile.Name)
+	if err != nil {
+		return fmt.Errorf("failed to list stored credentials: %w", err)
+	}
+
+	vaultIDs := append([]string{}, storedIDs...)
+
+	for _, record := range records {
+		if record.StoredInEDV {
+			vaultIDs = append(vaultIDs, record.ID)
+		}
+	}
+
+	for _, vcID := range vaultIDs {
+		if err = o.deleteVaultDocuments(profile, vcID); err != nil {
+			return fmt.Errorf("failed to delete credential %s from vault: %w", vcID, err)
+		}
+	}
+
+	err = o.vcStatusManager.DeleteStatusLists(o.hostURL + "/" + profile.Name + credentialStatus)
+	if err != nil {
+		return fmt.Errorf("failed to delete status lists: %w", err)
+	}
+
+	for _, record := range records {
+		if err = o.registry.Delete(profile.Name, record.ID); err != nil {
+			return fmt.Errorf("failed to delete issued credential %s: %w", record.ID, err)
+		}
+	}
+
+	for _, vcID := range storedIDs {
+		if err = o.registry.DeleteStored(profile.Name, vcID); err != nil {
+			return fmt.Errorf("failed to delete stored credential %s: %w", vcID, err)
+		}
+	}
+
+	if err = o.webhooks.DeleteProfile(profile.Name); err != nil {
+		return fmt.Errorf("failed to delete webhooks: %w", err)
+	}
+
+	if destroyKeys {
+		if err = o.destroySigningKey(profile); err != nil {
+			return fmt.Errorf("failed to destroy signing key: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// deleteVaultDocuments deletes the documents of the credential from the profile's vault, using the vault's
+// capability. The EDV server has no API to delete a vault, emptying it is the best that can be done.
+func (o *Operation) deleteVaultDocuments(profile *vcprofile.IssuerProfile, vcID string) error {
+	vcIDMAC, err := o.macCrypto.ComputeMAC([]byte(vcID), o.macKeyHandle)
+	if err != nil {
+		return err
+	}
+
+	signHeader := client.WithRequestHeader(func(req *http.Request) (*http.Header, error) {
+		if len(profile.EDVCapability) != 0 {
+			return o.authService.SignHeader(req, profile.EDVCapability, profile.EDVController)
+		}
+
+		return nil, nil
+	})
+
+	docURLs, err := o.edvClient.QueryVault(profile.EDVVaultID, o.vcIDIndexNameEncoded,
+		base64.URLEncoding.EncodeToString(vcIDMAC), signHeader)
+	if err != nil {
+		return err
+	}
+
+	for _, docURL := range docURLs {
+		err = o.edvClient.DeleteDocument(profile.EDVVaultID, vcutil.GetDocIDFromURL(docURL), signHeader)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// destroySigningKey deletes the profile's signing keys, the current one and the ones it was rotated from, from the
+// local KMS store. The key IDs are the fragments of the profile's creators.
+func (o *Operation) destroySigningKey(profile *vcprofile.IssuerProfile) error {
+	if o.kmsStore == nil {
+		return errors.New("kms secrets store isn't configured")
+	}
+
+	for _, creator := range append(profile.PreviousCreators, profile.Creator) {
+		parts := strings.Split(creator, "#")
+		if len(parts) != 2 || parts[1] == "" {
+			return fmt.Errorf("invalid creator %s", creator)
+		}
+
+		if err := o.kmsStore.Delete(parts[1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// openKMSStore opens the store the local KMS keeps its keys in.
+func openKMSStore(provider ariesstorage.Provider) (ariesstorage.Store, error) {
+	store, err := provider.OpenStore(localkms.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return prefix.NewPrefixStoreWrapper(store, prefix.StorageKIDPrefix)
+}
+
+// getActiveProfile returns the profile unless it is soft deleted.
+func (o *Operation) getActiveProfile(profileID string) (*vcprofile.IssuerProfile, error) {
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile.Deleted != nil {
+		return nil, fmt.Errorf("profile %s is deleted", profileID)
+	}
+
+	return profile, nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/trustbloc/edge-service/pkg/doc/didconfig"
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+	"github.com/trustbloc/edge-service/pkg/restapi/internal/common/vcutil"
+)
+
+const (
+	didConfigurationEndpoint = "/" + "{" + profileIDPathParam + "}" + "/did-configuration"
+
+	// domainLinkageValidity is how long the domain linkage credential of a DID configuration is valid.
+	domainLinkageValidity = 365 * 24 * time.Hour
+)
+
+// DIDConfiguration swagger:route GET /{id}/did-configuration issuer didConfigurationReq
+//
+// Returns the DID configuration which links the profile's DID to the origin of its URI, with a domain linkage
+// credential signed by the profile. The origin publishes it at /.well-known/did-configuration.json for verifiers to
+// check the linkage.
+//
+// Responses:
+//    default: genericError
+//        200: didConfigurationRes
+func (o *Operation) didConfigurationHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.getActiveProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	origin, err := didconfig.Origin(profile.URI)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("issuer profile %s has no web origin: %s", profileID, err.Error()))
+
+		return
+	}
+
+	config, err := o.didConfiguration(profile, origin)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to create did configuration: %s", err.Error()))
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, config)
+}
+
+// didConfiguration returns the DID configuration of the profile's DID and the origin, signed by the profile.
+func (o *Operation) didConfiguration(profile *vcprofile.IssuerProfile,
+	origin string) (*didconfig.Configuration, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	credential := didconfig.NewCredential(profile.DID, origin, now, now.Add(domainLinkageValidity))
+
+	vcutil.UpdateSignatureTypeContext(credential, profile)
+
+	signedVC, err := o.crypto.SignCredential(profile.DataProfile, credential)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign domain linkage credential: %w", err)
+	}
+
+	vcBytes, err := signedVC.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	return &didconfig.Configuration{
+		Context:    didconfig.Context,
+		LinkedDIDs: []json.RawMessage{vcBytes},
+	}, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/doc/didconfig"
+)
+
+func TestDIDConfiguration(t *testing.T) {
+	op, keyID := newWalletTestOperation(t, newMockWallet(t))
+
+	profile := getTestProfile()
+	profile.Creator = "did:test:abc#" + keyID
+	saveTestProfile(t, op, profile)
+
+	getDIDConfiguration := func(t *testing.T, profileID string) (int, []byte) {
+		t.Helper()
+
+		rr := serveHTTPMux(t, getHandler(t, op, didConfigurationEndpoint, http.MethodGet),
+			"/"+profileID+"/did-configuration", nil, map[string]string{profileIDPathParam: profileID})
+
+		return rr.Code, rr.Body.Bytes()
+	}
+
+	t.Run("success", func(t *testing.T) {
+		code, body := getDIDConfiguration(t, "test")
+		require.Equal(t, http.StatusOK, code, string(body))
+
+		config := &didconfig.Configuration{}
+		require.NoError(t, json.Unmarshal(body, config))
+		require.Equal(t, didconfig.Context, config.Context)
+		require.Len(t, config.LinkedDIDs, 1)
+
+		vc := parseTestCredential(t, op, config.LinkedDIDs[0])
+		require.Equal(t, []string{"VerifiableCredential", didconfig.DomainLinkageCredentialType}, vc.Types)
+		require.WithinDuration(t, time.Now().Add(domainLinkageValidity), vc.Expired.Time, time.Minute)
+		require.NoError(t, didconfig.ValidateCredential(vc, "did:test:abc", "https://test.com"))
+	})
+
+	t.Run("invalid profile", func(t *testing.T) {
+		code, body := getDIDConfiguration(t, "unknown")
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "invalid issuer profile - id=unknown")
+	})
+
+	t.Run("profile without web origin", func(t *testing.T) {
+		noOrigin := getTestProfile()
+		noOrigin.Name = "no-origin"
+		noOrigin.URI = "did:test:abc"
+		saveTestProfile(t, op, noOrigin)
+
+		code, body := getDIDConfiguration(t, "no-origin")
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "issuer profile no-origin has no web origin")
+	})
+}
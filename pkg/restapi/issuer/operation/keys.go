@@ -0,0 +1,77 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+	"github.com/trustbloc/edge-service/pkg/restapi/internal/common/vcutil"
+)
+
+const rotateKeysEndpoint = getProfileEndpoint + "/rotateKeys"
+
+// RotateIssuerProfileKeys swagger:route POST /profile/{id}/rotateKeys issuer rotateKeysReq
+//
+// Rotates the signing key of the issuer profile. A new key is added to the profile's DID and the profile signs with
+// it from then on, the previous keys are kept in the DID so the credentials already issued still verify.
+//
+// Responses:
+//    default: genericError
+//        200: issuerProfileRes
+func (o *Operation) rotateIssuerProfileKeysHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)["id"]
+
+	profile, err := o.getActiveProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	data := RotateKeysRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	if data.DIDKeyType == "" {
+		data.DIDKeyType = crypto.Ed25519KeyType
+	}
+
+	creator, err := o.commonDID.RotateKey(profile.DID, data.DIDKeyType, profile.SignatureType, data.DIDPrivateKey,
+		data.DIDKeyID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to rotate profile keys: %s", err.Error()))
+
+		return
+	}
+
+	profile.PreviousCreators = append(profile.PreviousCreators, profile.Creator)
+	profile.Creator = creator
+	profile.KeyType = vcutil.ProfileKeyType(data.DIDKeyType, "", data.DIDPrivateKey)
+
+	if err = o.profileStore.SaveProfile(profile); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to save issuer profile: %s", err.Error()))
+
+		return
+	}
+
+	o.notifyProfileChanged(profile.Name, profileKeysRotated)
+
+	commhttp.WriteResponse(rw, profile)
+}
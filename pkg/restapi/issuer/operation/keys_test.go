@@ -0,0 +1,136 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/stretchr/testify/require"
+
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+)
+
+func TestRotateIssuerProfileKeys(t *testing.T) {
+	kmsSecretsProvider := ariesmemstorage.NewProvider()
+
+	customKMS, err := localkms.New("local-lock://custom/primary/key/",
+		mockkms.NewProviderForKMS(kmsSecretsProvider, &noop.NoLock{}))
+	require.NoError(t, err)
+
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	op, err := New(&Config{
+		StoreProvider:      ariesmemstorage.NewProvider(),
+		KMSSecretsProvider: kmsSecretsProvider,
+		KeyManager:         customKMS,
+		Crypto:             customCrypto,
+		VDRI:               &vdrmock.MockVDRegistry{},
+	})
+	require.NoError(t, err)
+
+	commonDID := &mockCommonDID{}
+	op.commonDID = commonDID
+
+	rotateKeys := func(t *testing.T, profileID string, req interface{}) (int, []byte) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, rotateKeysEndpoint, http.MethodPost),
+			"/profile/"+profileID+"/rotateKeys", reqBytes, map[string]string{"id": profileID})
+
+		return rr.Code, rr.Body.Bytes()
+	}
+
+	t.Run("rotate keys - success", func(t *testing.T) {
+		saveTestProfile(t, op, getTestProfile())
+
+		commonDID.rotateKeyValue = "did:test:abc#key2"
+
+		code, body := rotateKeys(t, "test", &RotateKeysRequest{})
+		require.Equal(t, http.StatusOK, code, string(body))
+
+		profile := &vcprofile.IssuerProfile{}
+		require.NoError(t, json.Unmarshal(body, profile))
+		require.Equal(t, "did:test:abc#key2", profile.Creator)
+		require.Equal(t, []string{"did:test:abc#key1"}, profile.PreviousCreators)
+
+		commonDID.rotateKeyValue = "did:test:abc#key3"
+
+		code, body = rotateKeys(t, "test", &RotateKeysRequest{})
+		require.Equal(t, http.StatusOK, code, string(body))
+
+		profile, err = op.profileStore.GetProfile("test")
+		require.NoError(t, err)
+		require.Equal(t, "did:test:abc#key3", profile.Creator)
+		require.Equal(t, []string{"did:test:abc#key1", "did:test:abc#key2"}, profile.PreviousCreators)
+	})
+
+	t.Run("rotate keys - invalid profile", func(t *testing.T) {
+		code, body := rotateKeys(t, "unknown", &RotateKeysRequest{})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "invalid issuer profile")
+	})
+
+	t.Run("rotate keys - invalid request", func(t *testing.T) {
+		saveTestProfile(t, op, getTestProfile())
+
+		code, body := rotateKeys(t, "test", "invalid")
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), invalidRequestErrMsg)
+	})
+
+	t.Run("rotate keys - failed to rotate key", func(t *testing.T) {
+		saveTestProfile(t, op, getTestProfile())
+
+		commonDID.rotateKeyErr = errors.New("rotate error")
+		defer func() { commonDID.rotateKeyErr = nil }()
+
+		code, body := rotateKeys(t, "test", &RotateKeysRequest{})
+		require.Equal(t, http.StatusInternalServerError, code)
+		require.Contains(t, string(body), "failed to rotate profile keys: rotate error")
+
+		profile, err := op.profileStore.GetProfile("test")
+		require.NoError(t, err)
+		require.Equal(t, "did:test:abc#key1", profile.Creator)
+	})
+
+	t.Run("delete profile - destroys the rotated keys too", func(t *testing.T) {
+		previousKeyID, _, err := customKMS.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		keyID, _, err := customKMS.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		profile := getTestProfile()
+		profile.Creator = "did:test:abc#" + keyID
+		profile.PreviousCreators = []string{"did:test:abc#" + previousKeyID}
+		saveTestProfile(t, op, profile)
+
+		rr := serveHTTPMux(t, getHandler(t, op, deleteProfileEndpoint, http.MethodDelete),
+			"/profile/test?destroyKeys=true", nil, map[string]string{"id": "test"})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		_, err = customKMS.Get(keyID)
+		require.Error(t, err)
+
+		_, err = customKMS.Get(previousKeyID)
+		require.Error(t, err)
+	})
+}
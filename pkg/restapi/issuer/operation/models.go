@@ -13,7 +13,10 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/registry"
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
+	"github.com/trustbloc/edge-service/pkg/webhook"
 )
 
 // CreateCredentialRequest input data for edge service issuer rest api
@@ -35,6 +38,8 @@ type UpdateCredentialStatusRequest struct {
 type CredentialStatus struct {
 	Type   string `json:"type"`
 	Status string `json:"status"`
+	// StatusPurpose is either revocation, the default, or suspension.
+	StatusPurpose string `json:"statusPurpose,omitempty"`
 }
 
 // StoreVCRequest stores the credential with profile name
@@ -56,6 +61,104 @@ type ProfileRequest struct {
 	UNIRegistrar            model.UNIRegistrar                 `json:"uniRegistrar,omitempty"`
 	DisableVCStatus         bool                               `json:"disableVCStatus"`
 	OverwriteIssuer         bool                               `json:"overwriteIssuer,omitempty"`
+	StoreIssuedCredentials  bool                               `json:"storeIssuedCredentials,omitempty"`
+	CredentialTemplates     []*vcprofile.CredentialTemplate    `json:"credentialTemplates,omitempty"`
+	RefreshPolicy           *vcprofile.RefreshPolicy           `json:"refreshPolicy,omitempty"`
+	IssuancePolicy          *vcprofile.IssuancePolicy          `json:"issuancePolicy,omitempty"`
+}
+
+// RotateKeysRequest rotates the signing key of a profile. The DIDs created by the service get a new key, for the
+// others the key must be added to the DID beforehand and its private key given.
+type RotateKeysRequest struct {
+	DIDKeyType    string `json:"didKeyType,omitempty"`
+	DIDPrivateKey string `json:"didPrivateKey,omitempty"`
+	DIDKeyID      string `json:"didKeyID,omitempty"`
+}
+
+// UpdateProfileRequest updates the mutable fields of an issuer profile. PUT replaces them all, the fields left out
+// are reset, whereas PATCH only updates the fields set.
+type UpdateProfileRequest struct {
+	URI                     *string                             `json:"uri,omitempty"`
+	SignatureType           *string                             `json:"signatureType,omitempty"`
+	SignatureRepresentation *verifiable.SignatureRepresentation `json:"signatureRepresentation,omitempty"`
+	DisableVCStatus         *bool                               `json:"disableVCStatus,omitempty"`
+	OverwriteIssuer         *bool                               `json:"overwriteIssuer,omitempty"`
+	StoreIssuedCredentials  *bool                               `json:"storeIssuedCredentials,omitempty"`
+	RefreshPolicy           *vcprofile.RefreshPolicy            `json:"refreshPolicy,omitempty"`
+	IssuancePolicy          *vcprofile.IssuancePolicy           `json:"issuancePolicy,omitempty"`
+}
+
+// apply returns a copy of the profile updated by the request.
+func (r *UpdateProfileRequest) apply(profile *vcprofile.IssuerProfile, replace bool) *vcprofile.IssuerProfile {
+	updated := *profile
+	dataProfile := *profile.DataProfile
+	updated.DataProfile = &dataProfile
+
+	switch {
+	case r.URI != nil:
+		updated.URI = *r.URI
+	case replace:
+		updated.URI = ""
+	}
+
+	switch {
+	case r.SignatureType != nil:
+		updated.SignatureType = *r.SignatureType
+	case replace:
+		updated.SignatureType = ""
+	}
+
+	switch {
+	case r.SignatureRepresentation != nil:
+		updated.SignatureRepresentation = *r.SignatureRepresentation
+	case replace:
+		updated.SignatureRepresentation = verifiable.SignatureProofValue
+	}
+
+	updated.DisableVCStatus = updateBool(profile.DisableVCStatus, r.DisableVCStatus, replace)
+	updated.OverwriteIssuer = updateBool(profile.OverwriteIssuer, r.OverwriteIssuer, replace)
+	updated.StoreIssuedCredentials = updateBool(profile.StoreIssuedCredentials, r.StoreIssuedCredentials, replace)
+
+	switch {
+	case r.RefreshPolicy != nil:
+		updated.RefreshPolicy = r.RefreshPolicy
+	case replace:
+		updated.RefreshPolicy = nil
+	}
+
+	switch {
+	case r.IssuancePolicy != nil:
+		updated.IssuancePolicy = r.IssuancePolicy
+	case replace:
+		updated.IssuancePolicy = nil
+	}
+
+	return &updated
+}
+
+// ListProfilesResponse is a page of issuer profiles.
+type ListProfilesResponse struct {
+	Profiles []*vcprofile.IssuerProfile `json:"profiles"`
+	Total    int                        `json:"total"`
+}
+
+// ListIssuedCredentialsResponse is a page of the records of the credentials issued by an issuer profile.
+type ListIssuedCredentialsResponse struct {
+	Credentials []*registry.Record `json:"credentials"`
+	Total       int                `json:"total"`
+}
+
+// WebhookRequest subscribes a URL to the events of an issuer profile.
+type WebhookRequest struct {
+	URL string `json:"url"`
+	// Events are the types of the events delivered, all of them if empty.
+	Events []string `json:"events,omitempty"`
+}
+
+// ListWebhookDeliveriesResponse is a page of the webhook delivery log of an issuer profile.
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []*webhook.Delivery `json:"deliveries"`
+	Total      int                 `json:"total"`
 }
 
 // IssueCredentialRequest request for issuing credential.
@@ -104,6 +207,126 @@ type ComposeCredentialRequest struct {
 	ProofFormat             string          `json:"proofFormat,omitempty"`
 	CredentialFormatOptions json.RawMessage `json:"credentialFormatOptions,omitempty"`
 	ProofFormatOptions      json.RawMessage `json:"proofFormatOptions,omitempty"`
+	// TemplateID refers to the issuer profile's credential template the credential is composed from.
+	TemplateID string `json:"templateID,omitempty"`
+}
+
+// BatchIssueCredentialRequest request for issuing a batch of credentials.
+type BatchIssueCredentialRequest struct {
+	Credentials []*BatchIssueCredentialItem `json:"credentials"`
+}
+
+// BatchIssueCredentialItem is a credential of the batch, either a credential to issue along with its options or
+// a request to compose it.
+type BatchIssueCredentialItem struct {
+	Credential json.RawMessage           `json:"credential,omitempty"`
+	Opts       *IssueCredentialOptions   `json:"options,omitempty"`
+	Compose    *ComposeCredentialRequest `json:"compose,omitempty"`
+}
+
+// BatchIssueCredentialResponse contains the results of the batch items, in the order of the request.
+type BatchIssueCredentialResponse struct {
+	Results []*BatchIssueCredentialResult `json:"results"`
+}
+
+// BatchIssueCredentialResult is either the issued credential or the error that prevented issuing it.
+type BatchIssueCredentialResult struct {
+	Credential *verifiable.Credential `json:"credential,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// CredentialOfferRequest is the request for an OpenID for Verifiable Credential Issuance credential offer. The
+// credential is composed as for ComposeCredentialRequest, the subject is left out since it is the wallet's DID.
+type CredentialOfferRequest struct {
+	ComposeCredentialRequest
+	// ExpiresIn is the number of seconds the pre-authorized code is valid for, 10 minutes if zero.
+	ExpiresIn int64 `json:"expiresIn,omitempty"`
+}
+
+// CredentialOfferResponse contains the credential offer, as is and as the URI to hand over to the wallet.
+type CredentialOfferResponse struct {
+	CredentialOffer    *CredentialOffer `json:"credential_offer"`
+	CredentialOfferURI string           `json:"credential_offer_uri"`
+}
+
+// CredentialOffer is the OpenID for Verifiable Credential Issuance credential offer.
+type CredentialOffer struct {
+	CredentialIssuer string `json:"credential_issuer"`
+	// Credentials are either the IDs of supported credentials or OfferedCredential objects.
+	Credentials []interface{}                      `json:"credentials"`
+	Grants      map[string]*PreAuthorizedCodeGrant `json:"grants"`
+}
+
+// OfferedCredential describes an offered credential that isn't composed from a credential template.
+type OfferedCredential struct {
+	Format string   `json:"format"`
+	Types  []string `json:"types"`
+}
+
+// PreAuthorizedCodeGrant is the pre-authorized code grant of a credential offer.
+type PreAuthorizedCodeGrant struct {
+	PreAuthorizedCode string `json:"pre-authorized_code"`
+	UserPinRequired   bool   `json:"user_pin_required"`
+}
+
+// CredentialIssuerMetadata is the OpenID for Verifiable Credential Issuance metadata of an issuer profile.
+type CredentialIssuerMetadata struct {
+	CredentialIssuer     string                 `json:"credential_issuer"`
+	TokenEndpoint        string                 `json:"token_endpoint"`
+	CredentialEndpoint   string                 `json:"credential_endpoint"`
+	CredentialsSupported []*SupportedCredential `json:"credentials_supported"`
+}
+
+// SupportedCredential is a credential the issuer profile issues, one per credential template.
+type SupportedCredential struct {
+	Format                      string   `json:"format"`
+	ID                          string   `json:"id"`
+	Context                     []string `json:"@context"`
+	Types                       []string `json:"types"`
+	CryptographicBindingMethods []string `json:"cryptographic_binding_methods_supported"`
+	CryptographicSuites         []string `json:"cryptographic_suites_supported"`
+}
+
+// TokenResponse is the access token response of the OID4VCI token endpoint.
+type TokenResponse struct {
+	AccessToken     string `json:"access_token"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int64  `json:"expires_in"`
+	CNonce          string `json:"c_nonce"`
+	CNonceExpiresIn int64  `json:"c_nonce_expires_in"`
+}
+
+// CredentialRequest is the request of the OID4VCI credential endpoint.
+type CredentialRequest struct {
+	Format string                  `json:"format,omitempty"`
+	Proof  *CredentialRequestProof `json:"proof,omitempty"`
+}
+
+// CredentialRequestProof is the wallet's proof of possession of the key the credential is bound to.
+type CredentialRequestProof struct {
+	ProofType string `json:"proof_type"`
+	JWT       string `json:"jwt"`
+}
+
+// CredentialResponse is the response of the OID4VCI credential endpoint.
+type CredentialResponse struct {
+	Format     string                 `json:"format"`
+	Credential *verifiable.Credential `json:"credential"`
+}
+
+// OAuthErrorResponse is the error response of the OID4VCI token and credential endpoints.
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+	CNonce           string `json:"c_nonce,omitempty"`
+	CNonceExpiresIn  int64  `json:"c_nonce_expires_in,omitempty"`
+}
+
+// RefreshCredentialRequest is the request of the refresh service, the presentation holds the credential to refresh
+// and is signed by its subject with an authentication proof over a challenge issued by the profile, whose domain is
+// the issuer profile's URL.
+type RefreshCredentialRequest struct {
+	Presentation json.RawMessage `json:"presentation"`
 }
 
 // GenerateKeyPairRequest is request for generating key pair
@@ -116,3 +339,15 @@ type GenerateKeyPairResponse struct {
 	PublicKey string `json:"publicKey,omitempty"`
 	KeyID     string `json:"keyID,omitempty"`
 }
+
+// updateBool returns the updated value of a flag, flags left out of a replacement are reset.
+func updateBool(current bool, value *bool, replace bool) bool {
+	switch {
+	case value != nil:
+		return *value
+	case replace:
+		return false
+	default:
+		return current
+	}
+}
@@ -0,0 +1,614 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/edge-service/pkg/claim"
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	cslstatus "github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+)
+
+const (
+	oid4vciStoreName = "oid4vci"
+
+	oid4vciBasePath          = "/" + "{" + profileIDPathParam + "}" + "/oid4vci"
+	oid4vciMetadataEndpoint  = "/" + "{" + profileIDPathParam + "}" + "/.well-known/openid-credential-issuer"
+	oid4vciOffersEndpoint    = oid4vciBasePath + "/offers"
+	oid4vciTokenEndpoint     = oid4vciBasePath + "/token"
+	oid4vciCredentialPath    = oid4vciBasePath + "/credential"
+	credentialOfferURIScheme = "openid-credential-offer://"
+
+	preAuthorizedCodeGrantType = "urn:ietf:params:oauth:grant-type:pre-authorized_code"
+	ldpVCFormat                = "ldp_vc"
+	jwtProofType               = "jwt"
+	proofJWTType               = "openid4vci-proof+jwt"
+	bearerAuthPrefix           = "Bearer "
+
+	// defaultOfferExpiry is how long a pre-authorized code is valid for when the offer doesn't tell.
+	defaultOfferExpiry = 10 * time.Minute
+	// accessTokenExpiry is how long the wallet has to request the credential once it redeemed the code.
+	accessTokenExpiry = 5 * time.Minute
+	// proofMaxAge bounds how old the wallet's proof of possession can be.
+	proofMaxAge = 5 * time.Minute
+
+	secretSize = 32
+)
+
+// OAuth error codes returned by the token and credential endpoints.
+const (
+	oauthInvalidRequest       = "invalid_request"
+	oauthInvalidGrant         = "invalid_grant"
+	oauthUnsupportedGrantType = "unsupported_grant_type"
+	oauthInvalidToken         = "invalid_token"
+	oauthUnsupportedFormat    = "unsupported_credential_format"
+	oauthInvalidProof         = "invalid_or_missing_proof"
+	oauthServerError          = "server_error"
+)
+
+var errOID4VCISessionNotFound = errors.New("unknown or expired session")
+
+// oid4vciSession is the state of an OID4VCI issuance, from the credential offer to the credential request.
+type oid4vciSession struct {
+	ProfileID  string                    `json:"profileID"`
+	Credential *ComposeCredentialRequest `json:"credential"`
+	ExpiresAt  time.Time                 `json:"expiresAt"`
+	CNonce     string                    `json:"cNonce,omitempty"`
+}
+
+// WalletEndpoints returns the paths of the endpoints the wallets call to be issued credentials. They aren't behind the
+// API token of the service, the credential endpoint takes the wallet's access token in its Authorization header.
+func WalletEndpoints() []string {
+	return []string{oid4vciMetadataEndpoint, oid4vciTokenEndpoint, oid4vciCredentialPath}
+}
+
+// CredentialIssuerMetadata swagger:route GET /{id}/.well-known/openid-credential-issuer issuer issuerMetadataReq
+//
+// Returns the OpenID for Verifiable Credential Issuance metadata of the issuer profile.
+//
+// Responses:
+//    default: genericError
+//        200: issuerMetadataRes
+func (o *Operation) credentialIssuerMetadataHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.getActiveProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	issuer := o.credentialIssuerID(profileID)
+
+	metadata := &CredentialIssuerMetadata{
+		CredentialIssuer:     issuer,
+		TokenEndpoint:        issuer + "/oid4vci/token",
+		CredentialEndpoint:   issuer + "/oid4vci/credential",
+		CredentialsSupported: []*SupportedCredential{},
+	}
+
+	for _, template := range profile.CredentialTemplates {
+		contexts := template.Contexts
+		if len(contexts) == 0 {
+			contexts = []string{verifiable.ContextURI}
+		}
+
+		metadata.CredentialsSupported = append(metadata.CredentialsSupported, &SupportedCredential{
+			Format:                      ldpVCFormat,
+			ID:                          template.ID,
+			Context:                     contexts,
+			Types:                       template.Types,
+			CryptographicBindingMethods: []string{"did"},
+			CryptographicSuites:         []string{profile.SignatureType},
+		})
+	}
+
+	commhttp.WriteResponse(rw, metadata)
+}
+
+// CreateCredentialOffer swagger:route POST /{id}/oid4vci/offers issuer credentialOfferReq
+//
+// Creates an OpenID for Verifiable Credential Issuance credential offer with a pre-authorized code. The credential
+// is composed as the compose request tells, its subject is the DID of the wallet that redeems the offer.
+//
+// Responses:
+//    default: genericError
+//        201: credentialOfferRes
+func (o *Operation) createCredentialOfferHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.getActiveProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	data := CredentialOfferRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	if err = validateCredentialOffer(profile, &data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	expiry := defaultOfferExpiry
+	if data.ExpiresIn > 0 {
+		expiry = time.Duration(data.ExpiresIn) * time.Second
+	}
+
+	code, err := newSecret()
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	err = o.saveOID4VCISession(preAuthorizedCodeKey(code), &oid4vciSession{
+		ProfileID:  profileID,
+		Credential: &data.ComposeCredentialRequest,
+		ExpiresAt:  time.Now().Add(expiry),
+	})
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to save credential offer: %s", err.Error()))
+
+		return
+	}
+
+	offer := &CredentialOffer{
+		CredentialIssuer: o.credentialIssuerID(profileID),
+		Credentials:      []interface{}{offeredCredential(&data.ComposeCredentialRequest)},
+		Grants: map[string]*PreAuthorizedCodeGrant{
+			preAuthorizedCodeGrantType: {PreAuthorizedCode: code},
+		},
+	}
+
+	offerBytes, err := json.Marshal(offer)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusCreated)
+	commhttp.WriteResponse(rw, &CredentialOfferResponse{
+		CredentialOffer:    offer,
+		CredentialOfferURI: credentialOfferURIScheme + "?credential_offer=" + url.QueryEscape(string(offerBytes)),
+	})
+}
+
+// OID4VCIToken swagger:route POST /{id}/oid4vci/token issuer oid4vciTokenReq
+//
+// Exchanges a pre-authorized code for the access token of the credential endpoint. The code can be used once.
+//
+// Responses:
+//    default: oauthErrorRes
+//        200: oid4vciTokenRes
+func (o *Operation) oid4vciTokenHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	if err := req.ParseForm(); err != nil {
+		writeOAuthError(rw, http.StatusBadRequest, &OAuthErrorResponse{Error: oauthInvalidRequest,
+			ErrorDescription: err.Error()})
+
+		return
+	}
+
+	if req.PostForm.Get("grant_type") != preAuthorizedCodeGrantType {
+		writeOAuthError(rw, http.StatusBadRequest, &OAuthErrorResponse{Error: oauthUnsupportedGrantType})
+
+		return
+	}
+
+	code := req.PostForm.Get("pre-authorized_code")
+	if code == "" {
+		writeOAuthError(rw, http.StatusBadRequest, &OAuthErrorResponse{Error: oauthInvalidRequest,
+			ErrorDescription: "missing pre-authorized_code"})
+
+		return
+	}
+
+	session, err := o.takeOID4VCISession(preAuthorizedCodeKey(code))
+	if err != nil || session.ProfileID != profileID {
+		writeOAuthError(rw, http.StatusBadRequest, &OAuthErrorResponse{Error: oauthInvalidGrant})
+
+		return
+	}
+
+	token, err := newSecret()
+	if err != nil {
+		writeOAuthError(rw, http.StatusInternalServerError, &OAuthErrorResponse{Error: oauthServerError})
+
+		return
+	}
+
+	session.CNonce, err = newSecret()
+	if err != nil {
+		writeOAuthError(rw, http.StatusInternalServerError, &OAuthErrorResponse{Error: oauthServerError})
+
+		return
+	}
+
+	session.ExpiresAt = time.Now().Add(accessTokenExpiry)
+
+	if err = o.saveOID4VCISession(accessTokenKey(token), session); err != nil {
+		logger.Errorf("failed to save oid4vci access token: %s", err)
+
+		writeOAuthError(rw, http.StatusInternalServerError, &OAuthErrorResponse{Error: oauthServerError})
+
+		return
+	}
+
+	rw.Header().Set("Cache-Control", "no-store")
+	commhttp.WriteResponse(rw, &TokenResponse{
+		AccessToken:     token,
+		TokenType:       "Bearer",
+		ExpiresIn:       int64(accessTokenExpiry.Seconds()),
+		CNonce:          session.CNonce,
+		CNonceExpiresIn: int64(accessTokenExpiry.Seconds()),
+	})
+}
+
+// OID4VCICredential swagger:route POST /{id}/oid4vci/credential issuer oid4vciCredentialReq
+//
+// Issues the offered credential to the wallet holding the access token. The wallet proves the possession of its
+// DID with a JWT signed over the c_nonce, the credential subject is bound to that DID.
+//
+// Responses:
+//    default: oauthErrorRes
+//        200: oid4vciCredentialRes
+func (o *Operation) oid4vciCredentialHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	authorization := req.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, bearerAuthPrefix) {
+		writeOAuthError(rw, http.StatusUnauthorized, &OAuthErrorResponse{Error: oauthInvalidToken})
+
+		return
+	}
+
+	tokenKey := accessTokenKey(strings.TrimPrefix(authorization, bearerAuthPrefix))
+
+	session, err := o.getOID4VCISession(tokenKey)
+	if err != nil || session.ProfileID != profileID {
+		writeOAuthError(rw, http.StatusUnauthorized, &OAuthErrorResponse{Error: oauthInvalidToken})
+
+		return
+	}
+
+	data := CredentialRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		writeOAuthError(rw, http.StatusBadRequest, &OAuthErrorResponse{Error: oauthInvalidRequest,
+			ErrorDescription: err.Error()})
+
+		return
+	}
+
+	if data.Format != "" && data.Format != ldpVCFormat {
+		writeOAuthError(rw, http.StatusBadRequest, &OAuthErrorResponse{Error: oauthUnsupportedFormat})
+
+		return
+	}
+
+	walletDID, err := o.verifyProofOfPossession(data.Proof, o.credentialIssuerID(profileID), session.CNonce)
+	if err != nil {
+		writeOAuthError(rw, http.StatusBadRequest, &OAuthErrorResponse{Error: oauthInvalidProof,
+			ErrorDescription: err.Error(), CNonce: session.CNonce, CNonceExpiresIn: secondsUntil(session.ExpiresAt)})
+
+		return
+	}
+
+	// the access token is good for one credential, like the pre-authorized code it was exchanged for
+	if _, err = o.takeOID4VCISession(tokenKey); err != nil {
+		writeOAuthError(rw, http.StatusUnauthorized, &OAuthErrorResponse{Error: oauthInvalidToken})
+
+		return
+	}
+
+	signedVC, err := o.issueOfferedCredential(profileID, session.Credential, walletDID)
+	if err != nil {
+		logger.Errorf("failed to issue oid4vci credential of profile %s: %s", profileID, err)
+
+		writeOAuthError(rw, http.StatusInternalServerError, &OAuthErrorResponse{Error: oauthServerError,
+			ErrorDescription: err.Error()})
+
+		return
+	}
+
+	rw.Header().Set("Cache-Control", "no-store")
+	commhttp.WriteResponse(rw, &CredentialResponse{Format: ldpVCFormat, Credential: signedVC})
+}
+
+// issueOfferedCredential composes the offered credential for the wallet's DID, then signs and records it.
+func (o *Operation) issueOfferedCredential(profileID string, composeCredReq *ComposeCredentialRequest,
+	walletDID string) (*verifiable.Credential, error) {
+	profile, err := o.getActiveProfile(profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	composeCredReq.Subject = walletDID
+
+	credential, opts, err := composeCredential(profile, composeCredReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if !profile.DisableVCStatus {
+		credential.Status, err = o.vcStatusManager.CreateStatusID(profile.DataProfile,
+			o.hostURL+"/"+profileID+credentialStatus)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add credential status: %w", err)
+		}
+
+		credential.Context = append(credential.Context, cslstatus.Context)
+	}
+
+	return o.issue(profile, credential, opts)
+}
+
+// verifyProofOfPossession checks the wallet signed the proof JWT for this issuer and c_nonce with a key of its DID,
+// it returns that DID.
+func (o *Operation) verifyProofOfPossession(proof *CredentialRequestProof, issuer, cNonce string) (string, error) {
+	if proof == nil || proof.ProofType != jwtProofType || proof.JWT == "" {
+		return "", errors.New("missing jwt proof")
+	}
+
+	var walletDID string
+
+	jws, err := jose.ParseJWS(proof.JWT, jose.SignatureVerifierFunc(
+		func(headers jose.Headers, _, signingInput, signature []byte) error {
+			var errVerify error
+
+			walletDID, errVerify = o.verifyProofSignature(headers, signingInput, signature)
+
+			return errVerify
+		}))
+	if err != nil {
+		return "", fmt.Errorf("invalid proof: %w", err)
+	}
+
+	claims := struct {
+		Issuer   string `json:"iss,omitempty"`
+		Audience string `json:"aud"`
+		IssuedAt int64  `json:"iat"`
+		Nonce    string `json:"nonce"`
+	}{}
+
+	if err = json.Unmarshal(jws.Payload, &claims); err != nil {
+		return "", fmt.Errorf("invalid proof claims: %w", err)
+	}
+
+	if claims.Audience != issuer {
+		return "", fmt.Errorf("proof audience %s isn't the credential issuer", claims.Audience)
+	}
+
+	if claims.Nonce != cNonce {
+		return "", errors.New("proof nonce isn't the c_nonce")
+	}
+
+	issued := time.Unix(claims.IssuedAt, 0)
+	if claims.IssuedAt == 0 || time.Since(issued) > proofMaxAge || time.Until(issued) > time.Minute {
+		return "", errors.New("proof isn't issued recently")
+	}
+
+	return walletDID, nil
+}
+
+// verifyProofSignature verifies the proof JWT with the DID key its kid refers to, it returns the DID.
+func (o *Operation) verifyProofSignature(headers jose.Headers, signingInput, signature []byte) (string, error) {
+	if typ, _ := headers.Type(); typ != proofJWTType {
+		return "", fmt.Errorf("proof type must be %s", proofJWTType)
+	}
+
+	kid, _ := headers.KeyID()
+
+	kidParts := strings.Split(kid, "#")
+	if len(kidParts) != 2 || !strings.HasPrefix(kidParts[0], "did:") {
+		return "", errors.New("proof kid must be a DID URL")
+	}
+
+	var sigVerifier interface {
+		Verify(pubKey *verifier.PublicKey, msg, signature []byte) error
+	}
+
+	switch alg, _ := headers.Algorithm(); alg {
+	case "EdDSA":
+		sigVerifier = verifier.NewEd25519SignatureVerifier()
+	case "ES256":
+		sigVerifier = verifier.NewECDSAES256SignatureVerifier()
+	default:
+		return "", fmt.Errorf("unsupported proof algorithm %s", alg)
+	}
+
+	docResolution, err := o.vdr.Resolve(kidParts[0])
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve proof key: %w", err)
+	}
+
+	vm := walletProofKey(docResolution.DIDDocument, kid)
+	if vm == nil {
+		return "", fmt.Errorf("proof key %s is not an authentication or assertion method of the DID", kid)
+	}
+
+	pubKey := &verifier.PublicKey{Type: vm.Type, Value: vm.Value, JWK: vm.JSONWebKey()}
+
+	if err = sigVerifier.Verify(pubKey, signingInput, signature); err != nil {
+		return "", err
+	}
+
+	return kidParts[0], nil
+}
+
+// walletProofKey returns the verification method of the DID the wallet proves its possession with. The DID controller
+// must have authorized the key to authenticate or to make assertions, any other key of the DID won't do.
+func walletProofKey(didDoc *did.Doc, kid string) *did.VerificationMethod {
+	methods := didDoc.VerificationMethods(did.Authentication, did.AssertionMethod)
+
+	for _, rel := range []did.VerificationRelationship{did.Authentication, did.AssertionMethod} {
+		for _, verification := range methods[rel] {
+			vm := verification.VerificationMethod
+
+			if vm.ID == kid || didDoc.ID+vm.ID == kid {
+				return &vm
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateCredentialOffer(profile *vcprofile.IssuerProfile, offer *CredentialOfferRequest) error {
+	if offer.Subject != "" {
+		return errors.New("the subject of an offered credential is the wallet's DID")
+	}
+
+	if offer.ExpiresIn < 0 {
+		return errors.New("invalid offer expiry")
+	}
+
+	// compose the credential up front so the issuer finds out about a bad offer rather than the wallet
+	_, _, err := composeCredential(profile, &offer.ComposeCredentialRequest)
+
+	return err
+}
+
+// offeredCredential describes the offered credential to the wallet, by the ID of the profile's credential template
+// it is composed from or by its types.
+func offeredCredential(composeCredReq *ComposeCredentialRequest) interface{} {
+	if composeCredReq.TemplateID != "" {
+		return composeCredReq.TemplateID
+	}
+
+	types := composeCredReq.Types
+	if len(types) == 0 {
+		types = []string{"VerifiableCredential"}
+	}
+
+	return &OfferedCredential{Format: ldpVCFormat, Types: types}
+}
+
+func (o *Operation) credentialIssuerID(profileID string) string {
+	return o.hostURL + "/" + profileID
+}
+
+func (o *Operation) saveOID4VCISession(key string, session *oid4vciSession) error {
+	sessionBytes, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	return o.oid4vciStore.Put(key, sessionBytes)
+}
+
+func (o *Operation) getOID4VCISession(key string) (*oid4vciSession, error) {
+	sessionBytes, err := o.oid4vciStore.Get(key)
+	if err != nil {
+		if errors.Is(err, ariesstorage.ErrDataNotFound) {
+			return nil, errOID4VCISessionNotFound
+		}
+
+		return nil, err
+	}
+
+	session := &oid4vciSession{}
+
+	if err = json.Unmarshal(sessionBytes, session); err != nil {
+		return nil, err
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		return nil, errOID4VCISessionNotFound
+	}
+
+	return session, nil
+}
+
+// takeOID4VCISession gets the session and uses up its code or token, by claiming it in the shared storage until the
+// session expires, so that it can't be used twice across the replicas of the service. The session is then deleted.
+func (o *Operation) takeOID4VCISession(key string) (*oid4vciSession, error) {
+	session, err := o.getOID4VCISession(key)
+	if err != nil {
+		return nil, err
+	}
+
+	err = o.claims.ClaimOnce(oid4vciStoreName+"_"+key, time.Until(session.ExpiresAt))
+	if errors.Is(err, claim.ErrClaimed) {
+		return nil, errOID4VCISessionNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = o.oid4vciStore.Delete(key); err != nil {
+		logger.Warnf("failed to delete used oid4vci session: %s", err)
+	}
+
+	return session, nil
+}
+
+func writeOAuthError(rw http.ResponseWriter, status int, errResp *OAuthErrorResponse) {
+	rw.Header().Set("Cache-Control", "no-store")
+
+	if status == http.StatusUnauthorized {
+		rw.Header().Set("WWW-Authenticate", `Bearer error="`+errResp.Error+`"`)
+	}
+
+	rw.WriteHeader(status)
+	commhttp.WriteResponse(rw, errResp)
+}
+
+func secondsUntil(t time.Time) int64 {
+	return int64(time.Until(t).Seconds())
+}
+
+func newSecret() (string, error) {
+	secret := make([]byte, secretSize)
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func preAuthorizedCodeKey(code string) string {
+	return "code_" + code
+}
+
+func accessTokenKey(token string) string {
+	return "token_" + token
+}
@@ -0,0 +1,434 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+const (
+	testHostURL   = "https://issuer.example.com"
+	testWalletDID = "did:example:wallet"
+)
+
+func TestOID4VCI(t *testing.T) {
+	w := newMockWallet(t)
+	op, keyID := newWalletTestOperation(t, w)
+
+	profile := getTestProfile()
+	profile.Creator = "did:test:abc#" + keyID
+	profile.CredentialTemplates = []*vcprofile.CredentialTemplate{{
+		ID:       "degree",
+		Contexts: []string{verifiable.ContextURI, "https://www.w3.org/2018/credentials/examples/v1"},
+		Types:    []string{"VerifiableCredential", "UniversityDegreeCredential"},
+	}}
+	saveTestProfile(t, op, profile)
+
+	issuer := testHostURL + "/test"
+
+	t.Run("issuer metadata", func(t *testing.T) {
+		rr := oid4vciRequest(t, op, oid4vciMetadataEndpoint, "/test/.well-known/openid-credential-issuer",
+			"test", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		metadata := &CredentialIssuerMetadata{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), metadata))
+		require.Equal(t, issuer, metadata.CredentialIssuer)
+		require.Equal(t, issuer+"/oid4vci/token", metadata.TokenEndpoint)
+		require.Equal(t, issuer+"/oid4vci/credential", metadata.CredentialEndpoint)
+		require.Len(t, metadata.CredentialsSupported, 1)
+		require.Equal(t, "degree", metadata.CredentialsSupported[0].ID)
+		require.Equal(t, ldpVCFormat, metadata.CredentialsSupported[0].Format)
+		require.Equal(t, []string{"Ed25519Signature2018"}, metadata.CredentialsSupported[0].CryptographicSuites)
+	})
+
+	t.Run("pre-authorized code flow - success", func(t *testing.T) {
+		offer := createOffer(t, op, &CredentialOfferRequest{
+			ComposeCredentialRequest: ComposeCredentialRequest{
+				TemplateID: "degree",
+				Claims:     json.RawMessage(`{"name":"John Doe"}`),
+			},
+		})
+		require.Equal(t, issuer, offer.CredentialOffer.CredentialIssuer)
+		require.Equal(t, []interface{}{"degree"}, offer.CredentialOffer.Credentials)
+
+		// the wallet reads the offer from the URI
+		offerURI, err := url.Parse(offer.CredentialOfferURI)
+		require.NoError(t, err)
+		require.Equal(t, "openid-credential-offer", offerURI.Scheme)
+
+		walletOffer := &CredentialOffer{}
+		require.NoError(t, json.Unmarshal([]byte(offerURI.Query().Get("credential_offer")), walletOffer))
+
+		code := walletOffer.Grants[preAuthorizedCodeGrantType].PreAuthorizedCode
+
+		token := w.redeem(t, op, code)
+		require.Equal(t, "Bearer", token.TokenType)
+
+		code2, body := w.requestCredential(t, op, token.AccessToken, w.proof(t, "", issuer, token.CNonce))
+		require.Equal(t, http.StatusOK, code2, string(body))
+
+		resp := &struct {
+			Format     string          `json:"format"`
+			Credential json.RawMessage `json:"credential"`
+		}{}
+		require.NoError(t, json.Unmarshal(body, resp))
+		require.Equal(t, ldpVCFormat, resp.Format)
+
+		vc, err := verifiable.ParseCredential(resp.Credential,
+			verifiable.WithPublicKeyFetcher(verifiable.NewVDRKeyResolver(op.vdr).PublicKeyFetcher()),
+			verifiable.WithJSONLDDocumentLoader(op.documentLoader))
+		require.NoError(t, err)
+		require.Equal(t, []string{"VerifiableCredential", "UniversityDegreeCredential"}, vc.Types)
+		require.Equal(t, profile.DID, vc.Issuer.ID)
+
+		subjects, ok := vc.Subject.([]verifiable.Subject)
+		require.True(t, ok)
+		require.Equal(t, testWalletDID, subjects[0].ID)
+		require.Equal(t, "John Doe", subjects[0].CustomFields["name"])
+
+		// the code and the token are good for one credential
+		rr := w.tokenRequest(t, op, url.Values{"grant_type": {preAuthorizedCodeGrantType},
+			"pre-authorized_code": {code}})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), oauthInvalidGrant)
+
+		code2, body = w.requestCredential(t, op, token.AccessToken, w.proof(t, "", issuer, token.CNonce))
+		require.Equal(t, http.StatusUnauthorized, code2)
+		require.Contains(t, string(body), oauthInvalidToken)
+	})
+
+	t.Run("create offer - errors", func(t *testing.T) {
+		rr := oid4vciRequest(t, op, oid4vciOffersEndpoint, "/unknown/oid4vci/offers", "unknown",
+			[]byte("{}"), nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid issuer profile")
+
+		rr = oid4vciRequest(t, op, oid4vciOffersEndpoint, "/test/oid4vci/offers", "test", []byte("invalid"), nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), invalidRequestErrMsg)
+
+		for _, req := range []*CredentialOfferRequest{
+			{ComposeCredentialRequest: ComposeCredentialRequest{Subject: "did:example:abc"}},
+			{ComposeCredentialRequest: ComposeCredentialRequest{TemplateID: "unknown"}},
+			{ExpiresIn: -1},
+		} {
+			reqBytes, err := json.Marshal(req)
+			require.NoError(t, err)
+
+			rr = oid4vciRequest(t, op, oid4vciOffersEndpoint, "/test/oid4vci/offers", "test", reqBytes, nil)
+			require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("token - errors", func(t *testing.T) {
+		rr := w.tokenRequest(t, op, url.Values{"grant_type": {"authorization_code"}, "code": {"abc"}})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), oauthUnsupportedGrantType)
+
+		rr = w.tokenRequest(t, op, url.Values{"grant_type": {preAuthorizedCodeGrantType}})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), oauthInvalidRequest)
+
+		rr = w.tokenRequest(t, op, url.Values{"grant_type": {preAuthorizedCodeGrantType},
+			"pre-authorized_code": {"unknown"}})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), oauthInvalidGrant)
+
+		require.NoError(t, op.saveOID4VCISession(preAuthorizedCodeKey("expired"), &oid4vciSession{
+			ProfileID: "test", Credential: &ComposeCredentialRequest{}, ExpiresAt: time.Now().Add(-time.Minute),
+		}))
+
+		rr = w.tokenRequest(t, op, url.Values{"grant_type": {preAuthorizedCodeGrantType},
+			"pre-authorized_code": {"expired"}})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), oauthInvalidGrant)
+	})
+
+	t.Run("code and token taken by another replica", func(t *testing.T) {
+		offer := createOffer(t, op, &CredentialOfferRequest{
+			ComposeCredentialRequest: ComposeCredentialRequest{TemplateID: "degree"},
+		})
+
+		code := offer.CredentialOffer.Grants[preAuthorizedCodeGrantType].PreAuthorizedCode
+
+		// another replica redeemed the code and is yet to delete its session
+		require.NoError(t, op.claims.ClaimOnce(oid4vciStoreName+"_"+preAuthorizedCodeKey(code), time.Minute))
+
+		rr := w.tokenRequest(t, op, url.Values{"grant_type": {preAuthorizedCodeGrantType},
+			"pre-authorized_code": {code}})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), oauthInvalidGrant)
+
+		offer = createOffer(t, op, &CredentialOfferRequest{
+			ComposeCredentialRequest: ComposeCredentialRequest{TemplateID: "degree"},
+		})
+
+		token := w.redeem(t, op, offer.CredentialOffer.Grants[preAuthorizedCodeGrantType].PreAuthorizedCode)
+
+		require.NoError(t, op.claims.ClaimOnce(oid4vciStoreName+"_"+accessTokenKey(token.AccessToken),
+			time.Minute))
+
+		status, body := w.requestCredential(t, op, token.AccessToken, w.proof(t, "", issuer, token.CNonce))
+		require.Equal(t, http.StatusUnauthorized, status)
+		require.Contains(t, string(body), oauthInvalidToken)
+	})
+
+	t.Run("credential - errors", func(t *testing.T) {
+		offer := createOffer(t, op, &CredentialOfferRequest{
+			ComposeCredentialRequest: ComposeCredentialRequest{TemplateID: "degree"},
+		})
+
+		token := w.redeem(t, op, offer.CredentialOffer.Grants[preAuthorizedCodeGrantType].PreAuthorizedCode)
+
+		code, body := w.requestCredential(t, op, "unknown", w.proof(t, "", issuer, token.CNonce))
+		require.Equal(t, http.StatusUnauthorized, code)
+		require.Contains(t, string(body), oauthInvalidToken)
+
+		tests := []struct {
+			name string
+			jwt  string
+		}{
+			{name: "missing proof", jwt: ""},
+			{name: "wrong nonce", jwt: w.proof(t, "", issuer, "nonce")},
+			{name: "wrong audience", jwt: w.proof(t, "", "https://other.example.com", token.CNonce)},
+			{name: "wrong type", jwt: w.proof(t, "JWT", issuer, token.CNonce)},
+			{name: "other key", jwt: newMockWallet(t).proof(t, "", issuer, token.CNonce)},
+		}
+
+		for _, tc := range tests {
+			code, body = w.requestCredential(t, op, token.AccessToken, tc.jwt)
+			require.Equal(t, http.StatusBadRequest, code, tc.name)
+			require.Contains(t, string(body), oauthInvalidProof, tc.name)
+			require.Contains(t, string(body), token.CNonce, tc.name)
+		}
+
+		// a key of the wallet's DID the controller didn't authorize to authenticate or to make assertions
+		walletVDR := op.vdr
+		op.vdr = &vdrmock.MockVDRegistry{
+			ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+				doc := createDIDDocWithKeyID(didID, "key-1", w.pubKey)
+				doc.Authentication = nil
+				doc.AssertionMethod = nil
+
+				return &did.DocResolution{DIDDocument: doc}, nil
+			},
+		}
+
+		code, body = w.requestCredential(t, op, token.AccessToken, w.proof(t, "", issuer, token.CNonce))
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), oauthInvalidProof)
+
+		op.vdr = walletVDR
+
+		rr := oid4vciRequest(t, op, oid4vciCredentialPath, "/test/oid4vci/credential", "test",
+			[]byte(`{"format":"jwt_vc_json"}`), map[string]string{"Authorization": "Bearer " + token.AccessToken})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), oauthUnsupportedFormat)
+
+		// the failed requests leave the token usable
+		code, body = w.requestCredential(t, op, token.AccessToken, w.proof(t, "", issuer, token.CNonce))
+		require.Equal(t, http.StatusOK, code, string(body))
+	})
+}
+
+// newWalletTestOperation returns an operation whose VDR resolves the DIDs of the issuer profile and of the wallet,
+// along with the ID of the key the profile signs with.
+func newWalletTestOperation(t *testing.T, w *mockWallet) (*Operation, string) {
+	t.Helper()
+
+	customKMS := createKMS(t)
+
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	keyID, pubKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ED25519Type)
+	require.NoError(t, err)
+
+	op, err := New(&Config{
+		StoreProvider:      ariesmemstorage.NewProvider(),
+		KMSSecretsProvider: ariesmemstorage.NewProvider(),
+		KeyManager:         customKMS,
+		Crypto:             customCrypto,
+		HostURL:            testHostURL,
+		VDRI: &vdrmock.MockVDRegistry{
+			ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+				if didID == testWalletDID {
+					return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, "key-1", w.pubKey)}, nil
+				}
+
+				return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, keyID, pubKey)}, nil
+			},
+		},
+		DocumentLoader: testutil.DocumentLoader(t),
+	})
+	require.NoError(t, err)
+
+	op.vcStatusManager = &mockVCStatusManager{createStatusIDValue: &verifiable.TypedID{
+		ID:   "https://issuer.example.com/test/status/1#1",
+		Type: "RevocationList2020Status",
+		CustomFields: verifiable.CustomFields{
+			"revocationListIndex":      "1",
+			"revocationListCredential": "https://issuer.example.com/test/status/1",
+		},
+	}}
+
+	return op, keyID
+}
+
+type mockWallet struct {
+	pubKey  ed25519.PublicKey
+	privKey ed25519.PrivateKey
+}
+
+func newMockWallet(t *testing.T) *mockWallet {
+	t.Helper()
+
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return &mockWallet{pubKey: pubKey, privKey: privKey}
+}
+
+// proof signs the proof of possession JWT, with the OID4VCI proof type unless typ is set.
+func (w *mockWallet) proof(t *testing.T, typ, aud, nonce string) string {
+	t.Helper()
+
+	if typ == "" {
+		typ = proofJWTType
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"aud":   aud,
+		"iat":   time.Now().Unix(),
+		"nonce": nonce,
+	})
+	require.NoError(t, err)
+
+	jws, err := jose.NewJWS(jose.Headers{
+		jose.HeaderAlgorithm: "EdDSA",
+		jose.HeaderKeyID:     testWalletDID + "#key-1",
+		jose.HeaderType:      typ,
+	}, nil, payload, &walletSigner{privKey: w.privKey})
+	require.NoError(t, err)
+
+	jwt, err := jws.SerializeCompact(false)
+	require.NoError(t, err)
+
+	return jwt
+}
+
+func (w *mockWallet) tokenRequest(t *testing.T, op *Operation, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+
+	return oid4vciRequest(t, op, oid4vciTokenEndpoint, "/test/oid4vci/token", "test", []byte(form.Encode()),
+		map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+}
+
+func (w *mockWallet) redeem(t *testing.T, op *Operation, code string) *TokenResponse {
+	t.Helper()
+
+	rr := w.tokenRequest(t, op, url.Values{"grant_type": {preAuthorizedCodeGrantType},
+		"pre-authorized_code": {code}})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	token := &TokenResponse{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), token))
+
+	return token
+}
+
+func (w *mockWallet) requestCredential(t *testing.T, op *Operation, accessToken, jwt string) (int, []byte) {
+	t.Helper()
+
+	req := &CredentialRequest{Format: ldpVCFormat}
+	if jwt != "" {
+		req.Proof = &CredentialRequestProof{ProofType: jwtProofType, JWT: jwt}
+	}
+
+	reqBytes, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	rr := oid4vciRequest(t, op, oid4vciCredentialPath, "/test/oid4vci/credential", "test", reqBytes,
+		map[string]string{"Authorization": "Bearer " + accessToken})
+
+	return rr.Code, rr.Body.Bytes()
+}
+
+type walletSigner struct {
+	privKey ed25519.PrivateKey
+}
+
+func (s *walletSigner) Sign(data []byte) ([]byte, error) {
+	return ed25519.Sign(s.privKey, data), nil
+}
+
+func (s *walletSigner) Headers() jose.Headers {
+	return jose.Headers{jose.HeaderAlgorithm: "EdDSA"}
+}
+
+func createOffer(t *testing.T, op *Operation, req *CredentialOfferRequest) *CredentialOfferResponse {
+	t.Helper()
+
+	reqBytes, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	rr := oid4vciRequest(t, op, oid4vciOffersEndpoint, "/test/oid4vci/offers", "test", reqBytes, nil)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	offer := &CredentialOfferResponse{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), offer))
+
+	return offer
+}
+
+func oid4vciRequest(t *testing.T, op *Operation, path, endpoint, profileID string, body []byte,
+	headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	method := http.MethodPost
+	if body == nil {
+		method = http.MethodGet
+	}
+
+	handler := getHandler(t, op, path, method)
+
+	r, err := http.NewRequest(method, endpoint, bytes.NewBuffer(body))
+	require.NoError(t, err)
+
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler.Handle().ServeHTTP(rr, mux.SetURLVars(r, map[string]string{profileIDPathParam: profileID}))
+
+	return rr
+}
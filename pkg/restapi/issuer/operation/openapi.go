@@ -7,7 +7,11 @@ SPDX-License-Identifier: Apache-2.0
 package operation
 
 import (
+	challengestore "github.com/trustbloc/edge-service/pkg/challenge"
+	"github.com/trustbloc/edge-service/pkg/doc/didconfig"
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
+	"github.com/trustbloc/edge-service/pkg/webhook"
 )
 
 // genericError model
@@ -46,6 +50,67 @@ type deleteIssuerProfileReq struct { // nolint: unused,deadcode
 	// in: path
 	// required: true
 	ID string `json:"id"`
+
+	// keep the profile, its keys and status lists, and only stop it from issuing credentials
+	//
+	// in: query
+	Soft bool `json:"soft"`
+
+	// destroy the profile's signing key
+	//
+	// in: query
+	DestroyKeys bool `json:"destroyKeys"`
+}
+
+// listProfilesReq model
+//
+// swagger:parameters listProfilesReq
+type listProfilesReq struct { // nolint: unused,deadcode
+	// index of the first profile listed
+	//
+	// in: query
+	Offset int `json:"offset"`
+
+	// maximum number of profiles listed, 100 by default
+	//
+	// in: query
+	Limit int `json:"limit"`
+}
+
+// listProfilesRes model
+//
+// swagger:response listProfilesRes
+type listProfilesRes struct { // nolint: unused,deadcode
+	// in: body
+	ListProfilesResponse
+}
+
+// updateIssuerProfileReq model
+//
+// swagger:parameters updateIssuerProfileReq
+type updateIssuerProfileReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params UpdateProfileRequest
+}
+
+// rotateKeysReq model
+//
+// swagger:parameters rotateKeysReq
+type rotateKeysReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params RotateKeysRequest
 }
 
 // issuerProfileRes model
@@ -144,6 +209,96 @@ type updateCredentialStatusReq struct { // nolint: unused,deadcode
 	Params UpdateCredentialStatusRequest
 }
 
+// credentialTemplateReq model
+//
+// swagger:parameters credentialTemplateReq
+type credentialTemplateReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params vcprofile.CredentialTemplate
+}
+
+// credentialTemplateRes model
+//
+// swagger:response credentialTemplateRes
+type credentialTemplateRes struct { // nolint: unused,deadcode
+	// in: body
+	vcprofile.CredentialTemplate
+}
+
+// deleteCredentialTemplateReq model
+//
+// swagger:parameters deleteCredentialTemplateReq
+type deleteCredentialTemplateReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// credential template
+	//
+	// in: path
+	// required: true
+	TemplateID string `json:"templateID"`
+}
+
+// batchIssueCredentialReq model
+//
+// swagger:parameters batchIssueCredentialReq
+type batchIssueCredentialReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params BatchIssueCredentialRequest
+}
+
+// batchIssueCredentialRes model
+//
+// swagger:response batchIssueCredentialRes
+type batchIssueCredentialRes struct { // nolint: unused,deadcode
+	// in: body
+	BatchIssueCredentialResponse
+}
+
+// listIssuedCredentialsReq model
+//
+// swagger:parameters listIssuedCredentialsReq
+type listIssuedCredentialsReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// index of the first credential listed
+	//
+	// in: query
+	Offset int `json:"offset"`
+
+	// maximum number of credentials listed, 100 by default
+	//
+	// in: query
+	Limit int `json:"limit"`
+}
+
+// listIssuedCredentialsResp model
+//
+// swagger:response listIssuedCredentialsResp
+type listIssuedCredentialsResp struct { // nolint: unused,deadcode
+	// in: body
+	ListIssuedCredentialsResponse
+}
+
 // retrieveCredentialStatusReq model
 //
 // swagger:parameters retrieveCredentialStatusReq
@@ -167,3 +322,250 @@ type retrieveCredentialStatusReq struct { // nolint: unused,deadcode
 type retrieveCredentialStatusResp struct { // nolint: unused,deadcode
 	// in: body
 }
+
+// issuerMetadataReq model
+//
+// swagger:parameters issuerMetadataReq
+type issuerMetadataReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+}
+
+// issuerMetadataRes model
+//
+// swagger:response issuerMetadataRes
+type issuerMetadataRes struct { // nolint: unused,deadcode
+	// in: body
+	CredentialIssuerMetadata
+}
+
+// credentialOfferReq model
+//
+// swagger:parameters credentialOfferReq
+type credentialOfferReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params CredentialOfferRequest
+}
+
+// credentialOfferRes model
+//
+// swagger:response credentialOfferRes
+type credentialOfferRes struct { // nolint: unused,deadcode
+	// in: body
+	CredentialOfferResponse
+}
+
+// oid4vciTokenReq model
+//
+// swagger:parameters oid4vciTokenReq
+type oid4vciTokenReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// must be urn:ietf:params:oauth:grant-type:pre-authorized_code
+	//
+	// in: formData
+	// required: true
+	GrantType string `json:"grant_type"`
+
+	// pre-authorized code of the credential offer
+	//
+	// in: formData
+	// required: true
+	PreAuthorizedCode string `json:"pre-authorized_code"`
+}
+
+// oid4vciTokenRes model
+//
+// swagger:response oid4vciTokenRes
+type oid4vciTokenRes struct { // nolint: unused,deadcode
+	// in: body
+	TokenResponse
+}
+
+// oid4vciCredentialReq model
+//
+// swagger:parameters oid4vciCredentialReq
+type oid4vciCredentialReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// bearer access token
+	//
+	// in: header
+	// required: true
+	Authorization string `json:"Authorization"`
+
+	// in: body
+	Params CredentialRequest
+}
+
+// oid4vciCredentialRes model
+//
+// swagger:response oid4vciCredentialRes
+type oid4vciCredentialRes struct { // nolint: unused,deadcode
+	// in: body
+	CredentialResponse
+}
+
+// oauthErrorRes model
+//
+// swagger:response oauthErrorRes
+type oauthErrorRes struct { // nolint: unused,deadcode
+	// in: body
+	OAuthErrorResponse
+}
+
+// refreshCredentialReq model
+//
+// swagger:parameters refreshCredentialReq
+type refreshCredentialReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params RefreshCredentialRequest
+}
+
+// refreshChallengeReq model
+//
+// swagger:parameters refreshChallengeReq
+type refreshChallengeReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+}
+
+// refreshChallengeRes model
+//
+// swagger:response refreshChallengeRes
+type refreshChallengeRes struct { // nolint: unused,deadcode
+	// in: body
+	challengestore.Challenge
+}
+
+// webhookReq model
+//
+// swagger:parameters webhookReq
+type webhookReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params WebhookRequest
+}
+
+// webhookRes model
+//
+// swagger:response webhookRes
+type webhookRes struct { // nolint: unused,deadcode
+	// in: body
+	webhook.Subscription
+}
+
+// listWebhooksReq model
+//
+// swagger:parameters listWebhooksReq
+type listWebhooksReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+}
+
+// listWebhooksRes model
+//
+// swagger:response listWebhooksRes
+type listWebhooksRes struct { // nolint: unused,deadcode
+	// in: body
+	Webhooks []webhook.Subscription
+}
+
+// deleteWebhookReq model
+//
+// swagger:parameters deleteWebhookReq
+type deleteWebhookReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// webhook
+	//
+	// in: path
+	// required: true
+	WebhookID string `json:"webhookID"`
+}
+
+// listWebhookDeliveriesReq model
+//
+// swagger:parameters listWebhookDeliveriesReq
+type listWebhookDeliveriesReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// index of the first delivery listed
+	//
+	// in: query
+	Offset int `json:"offset"`
+
+	// maximum number of deliveries listed, 100 by default
+	//
+	// in: query
+	Limit int `json:"limit"`
+}
+
+// listWebhookDeliveriesRes model
+//
+// swagger:response listWebhookDeliveriesRes
+type listWebhookDeliveriesRes struct { // nolint: unused,deadcode
+	// in: body
+	ListWebhookDeliveriesResponse
+}
+
+// didConfigurationReq model
+//
+// swagger:parameters didConfigurationReq
+type didConfigurationReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+}
+
+// didConfigurationRes model
+//
+// swagger:response didConfigurationRes
+type didConfigurationRes struct { // nolint: unused,deadcode
+	// in: body
+	didconfig.Configuration
+}
@@ -39,8 +39,11 @@ import (
 	"github.com/trustbloc/edv/pkg/restapi/models"
 
 	zcapsvc "github.com/trustbloc/edge-service/pkg/auth/zcapld"
+	challengestore "github.com/trustbloc/edge-service/pkg/challenge"
+	"github.com/trustbloc/edge-service/pkg/claim"
 	"github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
 	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/registry"
 	cslstatus "github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
 	"github.com/trustbloc/edge-service/pkg/internal/common/support"
 	"github.com/trustbloc/edge-service/pkg/internal/cryptosetup"
@@ -48,6 +51,7 @@ import (
 	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
 	"github.com/trustbloc/edge-service/pkg/restapi/internal/common/vcutil"
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
+	"github.com/trustbloc/edge-service/pkg/webhook"
 )
 
 const (
@@ -81,6 +85,9 @@ const (
 
 	splitAssertionMethodLength = 2
 
+	// webhookTimeout bounds each delivery attempt, so that a slow subscriber doesn't hold up the deliveries.
+	webhookTimeout = 10 * time.Second
+
 	defaultKeyType = kms.ED25519Type
 )
 
@@ -104,8 +111,11 @@ type Handler interface {
 
 type vcStatusManager interface {
 	CreateStatusID(profile *vcprofile.DataProfile, url string) (*verifiable.TypedID, error)
+	CreateStatusIDs(profile *vcprofile.DataProfile, url string, n int) ([]*verifiable.TypedID, error)
 	UpdateVC(v *verifiable.Credential, profile *vcprofile.DataProfile, status bool) error
 	GetRevocationListVC(id string) ([]byte, error)
+	DeleteStatusLists(url string) error
+	HasStatusLists(url string) (bool, error)
 }
 
 // EDVClient interface to interact with edv client
@@ -114,6 +124,7 @@ type EDVClient interface {
 	CreateDocument(vaultID string, document *models.EncryptedDocument, opts ...client.ReqOption) (string, error)
 	ReadDocument(vaultID, docID string, opts ...client.ReqOption) (*models.EncryptedDocument, error)
 	QueryVault(vaultID, name, value string, opts ...client.ReqOption) ([]string, error)
+	DeleteDocument(vaultID, docID string, opts ...client.ReqOption) error
 }
 
 type authService interface {
@@ -128,6 +139,13 @@ type keyManager interface {
 type commonDID interface {
 	CreateDID(keyType, signatureType, did, privateKey, keyID, purpose string,
 		registrar model.UNIRegistrar) (string, string, error)
+	RotateKey(didID, keyType, signatureType, privateKey, keyID string) (string, error)
+}
+
+type didUpdateKeys interface {
+	Save(didID, keyID string) error
+	Has(didID string) (bool, error)
+	Commit(didID string) error
 }
 
 // New returns CreateCredential instance
@@ -156,16 +174,55 @@ func New(config *Config) (*Operation, error) {
 		return nil, err
 	}
 
+	credentialRegistry, err := registry.New(config.StoreProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate issued credential registry: %w", err)
+	}
+
 	contextOp, err := jsonldcontextrest.New(&storeProvider{config.StoreProvider})
 	if err != nil {
 		return nil, fmt.Errorf("create jsonld context operation: %w", err)
 	}
 
+	oid4vciStore, err := config.StoreProvider.OpenStore(oid4vciStoreName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open oid4vci store: %w", err)
+	}
+
+	claims, err := claim.New(config.StoreProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	challenges, err := challengestore.New(config.StoreProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks, err := webhook.New(config.StoreProvider, &http.Client{
+		Transport: &http.Transport{TLSClientConfig: config.TLSConfig},
+		Timeout:   webhookTimeout,
+	}, config.RetryParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	var kmsStore ariesstorage.Store
+
+	if config.KMSSecretsProvider != nil {
+		kmsStore, err = openKMSStore(config.KMSSecretsProvider)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open kms store: %w", err)
+		}
+	}
+
 	svc := &Operation{
 		authService:          zcapsvc.New(config.KeyManager, config.Crypto),
 		profileStore:         p,
+		registry:             credentialRegistry,
 		edvClient:            config.EDVClient,
 		kms:                  config.KeyManager,
+		kmsStore:             kmsStore,
 		vdr:                  config.VDRI,
 		crypto:               c,
 		jweEncrypter:         jweEncrypter,
@@ -179,11 +236,15 @@ func New(config *Config) (*Operation, error) {
 		commonDID: commondid.New(&commondid.Config{
 			VDRI: config.VDRI, KeyManager: config.KeyManager,
 			Domain: config.Domain, TLSConfig: config.TLSConfig,
-			DIDAnchorOrigin: config.DIDAnchorOrigin,
+			DIDAnchorOrigin: config.DIDAnchorOrigin, DIDUpdateKeys: config.DIDUpdateKeys,
 		}),
 		retryParameters:         config.RetryParameters,
 		documentLoader:          config.DocumentLoader,
 		addJSONLDContextHandler: contextOp.Add,
+		oid4vciStore:            oid4vciStore,
+		claims:                  claims,
+		challenges:              challenges,
+		webhooks:                webhooks,
 	}
 
 	return svc, nil
@@ -202,14 +263,17 @@ type Config struct {
 	Crypto             ariescrypto.Crypto
 	RetryParameters    *retry.Params
 	DIDAnchorOrigin    string
+	DIDUpdateKeys      didUpdateKeys
 	DocumentLoader     ld.DocumentLoader
 }
 
 // Operation defines handlers for Edge service
 type Operation struct {
 	profileStore            *vcprofile.Profile
+	registry                *registry.Registry
 	edvClient               EDVClient
 	kms                     keyManager
+	kmsStore                ariesstorage.Store
 	vdr                     vdrapi.Registry
 	crypto                  *crypto.Crypto
 	jweEncrypter            jose.Encrypter
@@ -225,6 +289,10 @@ type Operation struct {
 	authService             authService
 	documentLoader          ld.DocumentLoader
 	addJSONLDContextHandler http.HandlerFunc
+	oid4vciStore            ariesstorage.Store
+	claims                  *claim.Store
+	challenges              *challengestore.Store
+	webhooks                *webhook.Notifier
 }
 
 // GetRESTHandlers get all controller API handler available for this service
@@ -234,11 +302,27 @@ func (o *Operation) GetRESTHandlers() []Handler {
 		support.NewHTTPHandler(createProfileEndpoint, http.MethodPost, o.createIssuerProfileHandler),
 		support.NewHTTPHandler(getProfileEndpoint, http.MethodGet, o.getIssuerProfileHandler),
 		support.NewHTTPHandler(deleteProfileEndpoint, http.MethodDelete, o.deleteIssuerProfileHandler),
+		support.NewHTTPHandler(createProfileEndpoint, http.MethodGet, o.listIssuerProfilesHandler),
+		support.NewHTTPHandler(getProfileEndpoint, http.MethodPut, o.replaceIssuerProfileHandler),
+		support.NewHTTPHandler(getProfileEndpoint, http.MethodPatch, o.updateIssuerProfileHandler),
+		support.NewHTTPHandler(rotateKeysEndpoint, http.MethodPost, o.rotateIssuerProfileKeysHandler),
+		support.NewHTTPHandler(credentialTemplatesEndpoint, http.MethodPost, o.addCredentialTemplateHandler),
+		support.NewHTTPHandler(credentialTemplateEndpoint, http.MethodDelete, o.deleteCredentialTemplateHandler),
+		support.NewHTTPHandler(didConfigurationEndpoint, http.MethodGet, o.didConfigurationHandler),
+
+		// issuer profile webhooks
+		support.NewHTTPHandler(webhooksEndpoint, http.MethodPost, o.createWebhookHandler),
+		support.NewHTTPHandler(webhooksEndpoint, http.MethodGet, o.listWebhooksHandler),
+		support.NewHTTPHandler(webhookDeliveriesEndpoint, http.MethodGet, o.listWebhookDeliveriesHandler),
+		support.NewHTTPHandler(webhookEndpoint, http.MethodDelete, o.deleteWebhookHandler),
 
 		// verifiable credential store
 		support.NewHTTPHandler(storeCredentialEndpoint, http.MethodPost, o.storeCredentialHandler),
 		support.NewHTTPHandler(retrieveCredentialEndpoint, http.MethodGet, o.retrieveCredentialHandler),
 
+		// issued credentials
+		support.NewHTTPHandler(credentialsBasePath, http.MethodGet, o.listIssuedCredentialsHandler),
+
 		// verifiable credential status
 		support.NewHTTPHandler(updateCredentialStatusEndpoint, http.MethodPost, o.updateCredentialStatusHandler),
 		support.NewHTTPHandler(credentialStatusEndpoint, http.MethodGet, o.retrieveCredentialStatus),
@@ -246,7 +330,16 @@ func (o *Operation) GetRESTHandlers() []Handler {
 		// issuer apis
 		support.NewHTTPHandler(generateKeypairPath, http.MethodGet, o.generateKeypairHandler),
 		support.NewHTTPHandler(issueCredentialPath, http.MethodPost, o.issueCredentialHandler),
+		support.NewHTTPHandler(batchIssueCredentialPath, http.MethodPost, o.batchIssueCredentialHandler),
 		support.NewHTTPHandler(composeAndIssueCredentialPath, http.MethodPost, o.composeAndIssueCredentialHandler),
+		support.NewHTTPHandler(refreshChallengesPath, http.MethodPost, o.issueRefreshChallengeHandler),
+		support.NewHTTPHandler(refreshCredentialPath, http.MethodPost, o.refreshCredentialHandler),
+
+		// OpenID for Verifiable Credential Issuance
+		support.NewHTTPHandler(oid4vciMetadataEndpoint, http.MethodGet, o.credentialIssuerMetadataHandler),
+		support.NewHTTPHandler(oid4vciOffersEndpoint, http.MethodPost, o.createCredentialOfferHandler),
+		support.NewHTTPHandler(oid4vciTokenEndpoint, http.MethodPost, o.oid4vciTokenHandler),
+		support.NewHTTPHandler(oid4vciCredentialPath, http.MethodPost, o.oid4vciCredentialHandler),
 
 		// JSON-LD contexts API
 		support.NewHTTPHandler(jsonldcontextrest.AddContextPath, http.MethodPost, o.addJSONLDContextHandler),
@@ -317,48 +410,134 @@ func (o *Operation) updateCredentialStatusHandler(rw http.ResponseWriter, req *h
 		return
 	}
 
-	docURLs, err := o.queryVault(profile.EDVVaultID, profile.EDVCapability, profile.EDVController, data.CredentialID)
+	purpose := data.CredentialStatus.StatusPurpose
+	if purpose != "" && purpose != statusPurposeRevocation && purpose != statusPurposeSuspension {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("status purpose %s not supported", purpose))
+
+		return
+	}
+
+	vc, record, status, err := o.credentialForStatusUpdate(profile, data.CredentialID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, status, err.Error())
+
+		return
+	}
+
+	statusValue, err := strconv.ParseBool(data.CredentialStatus.Status)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("failed to parse status: %s", err.Error()))
+
+		return
+	}
+
+	if err := o.vcStatusManager.UpdateVC(vc, profile.DataProfile, statusValue); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("failed to update vc status: %s", err.Error()))
+		return
+	}
+
+	if record == nil {
+		// the credential isn't issued by the profile, its record only describes the event
+		record = registry.NewRecord(profile.Name, vc)
+		event := statusRecord(record, statusValue, purpose)
+
+		o.webhooks.Notify(profile.Name, event, record)
+		rw.WriteHeader(http.StatusOK)
+
+		return
+	}
+
+	updated := time.Now().UTC()
+	event := statusRecord(record, statusValue, purpose)
+	record.StatusUpdated = &updated
+
+	if err := o.registry.Save(record); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to update issued credential record: %s", err.Error()))
+
+		return
+	}
+
+	o.webhooks.Notify(profile.Name, event, record)
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// credentialForStatusUpdate returns the credential whose status is to be updated. Credentials issued by the profile
+// are found in the issued credential registry, along with their record; others are read from the profile's vault.
+func (o *Operation) credentialForStatusUpdate(profile *vcprofile.IssuerProfile,
+	vcID string) (*verifiable.Credential, *registry.Record, int, error) {
+	record, err := o.registry.Get(profile.Name, vcID)
+	if err == nil {
+		return &verifiable.Credential{ID: record.ID, Status: record.Status}, record, http.StatusOK, nil
+	}
+
+	if !errors.Is(err, ariesstorage.ErrDataNotFound) {
+		return nil, nil, http.StatusInternalServerError,
+			fmt.Errorf("failed to get issued credential record: %w", err)
+	}
+
+	docURLs, err := o.queryVault(profile.EDVVaultID, profile.EDVCapability, profile.EDVController, vcID)
 	if err != nil {
 		// The case where no docs match the given query is handled in o.retrieveCredential.
 		// Any other error is unexpected and is handled here.
 		if !errors.Is(err, errNoDocsMatchQuery) {
-			commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
-			return
+			return nil, nil, http.StatusInternalServerError, err
 		}
 	}
 
-	vcBytes, status, err := o.retrieveCredential(profileID, profile.EDVVaultID, docURLs,
+	vcBytes, status, err := o.retrieveCredential(profile.Name, profile.EDVVaultID, docURLs,
 		profile.EDVCapability, profile.EDVController)
 	if err != nil {
-		commhttp.WriteErrorResponse(rw, status, err.Error())
-
-		return
+		return nil, nil, status, err
 	}
 
 	vc, err := verifiable.ParseCredential(vcBytes, verifiable.WithDisabledProofCheck(),
 		verifiable.WithJSONLDDocumentLoader(o.documentLoader))
 	if err != nil {
-		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
-			fmt.Sprintf("failed to parse credential: %s", err.Error()))
+		return nil, nil, http.StatusBadRequest, fmt.Errorf("failed to parse credential: %w", err)
+	}
+
+	return vc, nil, http.StatusOK, nil
+}
+
+// ListIssuedCredentials swagger:route GET /{id}/credentials issuer listIssuedCredentialsReq
+//
+// Lists the credentials issued by the profile, oldest first.
+//
+// Responses:
+//    default: genericError
+//        200: listIssuedCredentialsResp
+func (o *Operation) listIssuedCredentialsHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	offset, limit, err := commhttp.ParsePage(req)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
 
 		return
 	}
 
-	statusValue, err := strconv.ParseBool(data.CredentialStatus.Status)
+	_, err = o.profileStore.GetProfile(profileID)
 	if err != nil {
-		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
-			fmt.Sprintf("failed to parse status: %s", err.Error()))
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
 
 		return
 	}
 
-	if err := o.vcStatusManager.UpdateVC(vc, profile.DataProfile, statusValue); err != nil {
-		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
-			fmt.Sprintf("failed to update vc status: %s", err.Error()))
+	records, total, err := o.registry.ListPage(profileID, offset, limit)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to list issued credentials: %s", err.Error()))
+
 		return
 	}
 
-	rw.WriteHeader(http.StatusOK)
+	commhttp.WriteResponse(rw, &ListIssuedCredentialsResponse{Credentials: records, Total: total})
 }
 
 // CreateIssuerProfile swagger:route POST /profile issuer issuerProfileReq
@@ -442,7 +621,8 @@ func (o *Operation) getIssuerProfileHandler(rw http.ResponseWriter, req *http.Re
 
 // DeleteIssuerProfile swagger:route DELETE /profile/{id} issuer deleteIssuerProfileReq
 //
-// Deletes issuer profile.
+// Deletes issuer profile along with the credentials stored in its vault, its status lists and, optionally, its
+// signing key. A soft delete only disables the profile, the credentials it issued can still be verified.
 //
 // Responses:
 // 		default: genericError
@@ -450,9 +630,45 @@ func (o *Operation) getIssuerProfileHandler(rw http.ResponseWriter, req *http.Re
 func (o *Operation) deleteIssuerProfileHandler(rw http.ResponseWriter, req *http.Request) {
 	profileID := mux.Vars(req)["id"]
 
-	// TODO: https://github.com/trustbloc/edge-service/issues/508 delete the edv vault
+	opts, err := parseDeleteOptions(req)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	if opts.soft {
+		if err = o.softDeleteProfile(profile); err != nil {
+			commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+				fmt.Sprintf("failed to save issuer profile: %s", err.Error()))
+
+			return
+		}
+
+		o.notifyProfileChanged(profile.Name, profileDisabled)
+
+		return
+	}
+
+	// the webhooks are notified before they are deleted along with the profile data
+	o.notifyProfileChanged(profile.Name, profileDeleted)
 
-	err := o.profileStore.DeleteProfile(profileID)
+	if err = o.deleteProfileData(profile, opts.destroyKeys); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to delete issuer profile data: %s", err.Error()))
+
+		return
+	}
+
+	err = o.profileStore.DeleteProfile(profileID)
 	if err != nil {
 		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
 
@@ -460,6 +676,139 @@ func (o *Operation) deleteIssuerProfileHandler(rw http.ResponseWriter, req *http
 	}
 }
 
+// ListIssuerProfiles swagger:route GET /profile issuer listProfilesReq
+//
+// Lists issuer profiles, sorted by name.
+//
+// Responses:
+//    default: genericError
+//        200: listProfilesRes
+func (o *Operation) listIssuerProfilesHandler(rw http.ResponseWriter, req *http.Request) {
+	offset, limit, err := commhttp.ParsePage(req)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	profiles, total, err := o.profileStore.ListProfiles(offset, limit)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to list issuer profiles: %s", err.Error()))
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, &ListProfilesResponse{Profiles: profiles, Total: total})
+}
+
+// ReplaceIssuerProfile swagger:route PUT /profile/{id} issuer updateIssuerProfileReq
+//
+// Replaces the mutable fields of the issuer profile, the fields left out are reset.
+//
+// Responses:
+//    default: genericError
+//        200: issuerProfileRes
+func (o *Operation) replaceIssuerProfileHandler(rw http.ResponseWriter, req *http.Request) {
+	o.updateIssuerProfile(rw, req, true)
+}
+
+// UpdateIssuerProfile swagger:route PATCH /profile/{id} issuer updateIssuerProfileReq
+//
+// Updates the given mutable fields of the issuer profile.
+//
+// Responses:
+//    default: genericError
+//        200: issuerProfileRes
+func (o *Operation) updateIssuerProfileHandler(rw http.ResponseWriter, req *http.Request) {
+	o.updateIssuerProfile(rw, req, false)
+}
+
+func (o *Operation) updateIssuerProfile(rw http.ResponseWriter, req *http.Request, replace bool) {
+	profileID := mux.Vars(req)["id"]
+
+	profile, err := o.getActiveProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	data := UpdateProfileRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	updated := data.apply(profile, replace)
+
+	if err = o.validateProfileUpdate(profile, updated); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	if err = o.profileStore.SaveProfile(updated); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to save issuer profile: %s", err.Error()))
+
+		return
+	}
+
+	action := profileUpdated
+	if replace {
+		action = profileReplaced
+	}
+
+	o.notifyProfileChanged(updated.Name, action)
+
+	commhttp.WriteResponse(rw, updated)
+}
+
+// validateProfileUpdate checks the updated profile, credentials already issued must keep a consistent status.
+func (o *Operation) validateProfileUpdate(profile, updated *vcprofile.IssuerProfile) error {
+	if updated.URI == "" {
+		return fmt.Errorf("missing URI information")
+	}
+
+	if _, err := url.Parse(updated.URI); err != nil {
+		return fmt.Errorf("invalid uri: %w", err)
+	}
+
+	err := vcutil.ValidateProfileSignatureUpdate(profile.DataProfile, updated.SignatureType,
+		updated.SignatureRepresentation)
+	if err != nil {
+		return err
+	}
+
+	if err = validateRefreshPolicy(updated.RefreshPolicy, updated.IssuancePolicy); err != nil {
+		return err
+	}
+
+	if err = validateIssuancePolicy(updated.IssuancePolicy); err != nil {
+		return err
+	}
+
+	if profile.DisableVCStatus || !updated.DisableVCStatus {
+		return nil
+	}
+
+	// the status of the credentials issued with a status list entry couldn't be updated anymore
+	hasStatusLists, err := o.vcStatusManager.HasStatusLists(o.hostURL + "/" + profile.Name + credentialStatus)
+	if err != nil {
+		return fmt.Errorf("failed to look up the status lists: %w", err)
+	}
+
+	if hasStatusLists {
+		return errors.New("vc status can't be disabled, the profile issued credentials with a status")
+	}
+
+	return nil
+}
+
 // StoreVerifiableCredential swagger:route POST /store issuer storeCredentialReq
 //
 // Stores a credential.
@@ -512,14 +861,31 @@ func (o *Operation) storeVC(data *StoreVCRequest, vc *verifiable.Credential, rw
 		return
 	}
 
-	profile, err := o.profileStore.GetProfile(data.Profile)
+	profile, err := o.getActiveProfile(data.Profile)
 	if err != nil {
 		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
 
 		return
 	}
 
-	_, err = o.edvClient.CreateDocument(profile.EDVVaultID, &encryptedDocument, client.WithRequestHeader(
+	err = o.createEDVDocument(profile, &encryptedDocument)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	if err = o.markStoredInEDV(profile.Name, vc.ID); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	o.webhooks.Notify(profile.Name, webhook.CredentialStored, registry.NewRecord(profile.Name, vc))
+}
+
+func (o *Operation) createEDVDocument(profile *vcprofile.IssuerProfile, doc *models.EncryptedDocument) error {
+	_, err := o.edvClient.CreateDocument(profile.EDVVaultID, doc, client.WithRequestHeader(
 		func(req *http.Request) (*http.Header, error) {
 			if len(profile.EDVCapability) != 0 {
 				return o.authService.SignHeader(req, profile.EDVCapability, profile.EDVController)
@@ -527,11 +893,83 @@ func (o *Operation) storeVC(data *StoreVCRequest, vc *verifiable.Credential, rw
 
 			return nil, nil
 		}))
+
+	return err
+}
+
+// recordCredential records the credential issued by the profile in the issued credential registry and, if the
+// profile asks for it, stores it in the profile's vault.
+func (o *Operation) recordCredential(profile *vcprofile.IssuerProfile, vc *verifiable.Credential) error {
+	record := registry.NewRecord(profile.Name, vc)
+
+	if vc.ID == "" {
+		logger.Debugf("issued credential has no ID, it is not recorded in the registry")
+
+		o.webhooks.Notify(profile.Name, webhook.CredentialIssued, record)
+
+		return nil
+	}
+
+	if profile.StoreIssuedCredentials {
+		vcBytes, err := vc.MarshalJSON()
+		if err != nil {
+			return fmt.Errorf("marshal credential: %w", err)
+		}
+
+		doc, err := vcutil.BuildStructuredDocForStorage(vcBytes)
+		if err != nil {
+			return err
+		}
+
+		encryptedDocument, err := o.buildEncryptedDoc(doc, vc.ID)
+		if err != nil {
+			return err
+		}
+
+		err = o.createEDVDocument(profile, &encryptedDocument)
+		if err != nil {
+			return fmt.Errorf("store credential in vault: %w", err)
+		}
+
+		record.StoredInEDV = true
+	}
+
+	if err := o.registry.Save(record); err != nil {
+		return err
+	}
+
+	o.webhooks.Notify(profile.Name, webhook.CredentialIssued, record)
+
+	if record.StoredInEDV {
+		o.webhooks.Notify(profile.Name, webhook.CredentialStored, record)
+	}
+
+	return nil
+}
+
+// markStoredInEDV notes that the credential is stored in the profile's vault, so that it is deleted from the vault
+// along with the profile. The credentials the profile did not issue are noted apart from the issued ones.
+func (o *Operation) markStoredInEDV(profile, vcID string) error {
+	record, err := o.registry.Get(profile, vcID)
+	if errors.Is(err, ariesstorage.ErrDataNotFound) {
+		if err = o.registry.SaveStored(profile, vcID); err != nil {
+			return fmt.Errorf("failed to save stored credential: %w", err)
+		}
+
+		return nil
+	}
+
 	if err != nil {
-		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+		return fmt.Errorf("failed to get issued credential: %w", err)
+	}
 
-		return
+	if record.StoredInEDV {
+		return nil
 	}
+
+	record.StoredInEDV = true
+
+	return o.registry.Save(record)
 }
 
 func (o *Operation) buildEncryptedDoc(structuredDoc *models.StructuredDocument,
@@ -654,9 +1092,12 @@ func (o *Operation) createIssuerProfile(pr *ProfileRequest) (*vcprofile.IssuerPr
 		DataProfile: &vcprofile.DataProfile{
 			Name: pr.Name, Created: &created, DID: didID,
 			SignatureType: pr.SignatureType, SignatureRepresentation: pr.SignatureRepresentation, Creator: publicKeyID,
+			KeyType: vcutil.ProfileKeyType(pr.DIDKeyType, pr.DID, pr.DIDPrivateKey),
 		},
 		URI: pr.URI, EDVCapability: capability, EDVVaultID: edvVaultID, DisableVCStatus: pr.DisableVCStatus,
-		OverwriteIssuer: pr.OverwriteIssuer, EDVController: didKey,
+		OverwriteIssuer: pr.OverwriteIssuer, EDVController: didKey, StoreIssuedCredentials: pr.StoreIssuedCredentials,
+		CredentialTemplates: pr.CredentialTemplates, RefreshPolicy: pr.RefreshPolicy,
+		IssuancePolicy: pr.IssuancePolicy,
 	}, nil
 }
 
@@ -702,7 +1143,15 @@ func validateProfileRequest(pr *ProfileRequest) error {
 		return fmt.Errorf("invalid uri: %w", err)
 	}
 
-	return nil
+	if err = validateRefreshPolicy(pr.RefreshPolicy, pr.IssuancePolicy); err != nil {
+		return err
+	}
+
+	if err = validateIssuancePolicy(pr.IssuancePolicy); err != nil {
+		return err
+	}
+
+	return validateCredentialTemplates(pr.CredentialTemplates)
 }
 
 func validateRequest(profileName, vcID string) error {
@@ -729,7 +1178,7 @@ func (o *Operation) issueCredentialHandler(rw http.ResponseWriter, req *http.Req
 	// get the issuer profile
 	profileID := mux.Vars(req)[profileIDPathParam]
 
-	profile, err := o.profileStore.GetProfile(profileID)
+	profile, err := o.getActiveProfile(profileID)
 	if err != nil {
 		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
 			profileID, err.Error()))
@@ -747,18 +1196,9 @@ func (o *Operation) issueCredentialHandler(rw http.ResponseWriter, req *http.Req
 		return
 	}
 
-	// validate options
-	if err = validateIssueCredOptions(cred.Opts); err != nil {
-		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
-
-		return
-	}
-
-	// validate the VC (ignore the proof)
-	credential, err := verifiable.ParseCredential(cred.Credential, verifiable.WithDisabledProofCheck(),
-		verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+	credential, err := o.parseCredentialToIssue(profile, &cred)
 	if err != nil {
-		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("failed to validate credential: %s", err.Error()))
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
 
 		return
 	}
@@ -777,23 +1217,62 @@ func (o *Operation) issueCredentialHandler(rw http.ResponseWriter, req *http.Req
 		credential.Context = append(credential.Context, cslstatus.Context)
 	}
 
+	signedVC, err := o.issue(profile, credential, getIssuerSigningOpts(cred.Opts))
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusCreated)
+	commhttp.WriteResponse(rw, signedVC)
+}
+
+// parseCredentialToIssue validates the issue request and parses its credential, which must comply with the issuance
+// policy of the profile.
+func (o *Operation) parseCredentialToIssue(profile *vcprofile.IssuerProfile,
+	cred *IssueCredentialRequest) (*verifiable.Credential, error) {
+	// validate options
+	if err := validateIssueCredOptions(cred.Opts); err != nil {
+		return nil, err
+	}
+
+	// validate the VC (ignore the proof)
+	credential, err := verifiable.ParseCredential(cred.Credential, verifiable.WithDisabledProofCheck(),
+		verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate credential: %w", err)
+	}
+
+	if err = checkIssuancePolicy(profile, credential); err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+// issue signs the credential, which has its status set already, and records it.
+func (o *Operation) issue(profile *vcprofile.IssuerProfile, credential *verifiable.Credential,
+	opts []crypto.SigningOpts) (*verifiable.Credential, error) {
 	// update context
 	vcutil.UpdateSignatureTypeContext(credential, profile)
 
 	// update credential issuer
 	vcutil.UpdateIssuer(credential, profile)
 
+	o.embedRefreshService(profile, credential)
+
 	// sign the credential
-	signedVC, err := o.crypto.SignCredential(profile.DataProfile, credential, getIssuerSigningOpts(cred.Opts)...)
+	signedVC, err := o.crypto.SignCredential(profile.DataProfile, credential, opts...)
 	if err != nil {
-		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to sign credential:"+
-			" %s", err.Error()))
+		return nil, fmt.Errorf("failed to sign credential: %w", err)
+	}
 
-		return
+	if err = o.recordCredential(profile, signedVC); err != nil {
+		return nil, fmt.Errorf("failed to record credential: %w", err)
 	}
 
-	rw.WriteHeader(http.StatusCreated)
-	commhttp.WriteResponse(rw, signedVC)
+	return signedVC, nil
 }
 
 //nolint:funlen
@@ -807,7 +1286,7 @@ func (o *Operation) issueCredentialHandler(rw http.ResponseWriter, req *http.Req
 func (o *Operation) composeAndIssueCredentialHandler(rw http.ResponseWriter, req *http.Request) {
 	id := mux.Vars(req)[profileIDPathParam]
 
-	profile, err := o.profileStore.GetProfile(id)
+	profile, err := o.getActiveProfile(id)
 	if err != nil {
 		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile: %s", err.Error()))
 
@@ -825,10 +1304,9 @@ func (o *Operation) composeAndIssueCredentialHandler(rw http.ResponseWriter, req
 	}
 
 	// create the verifiable credential
-	credential, err := buildCredential(&composeCredReq)
+	credential, opts, err := composeCredential(profile, &composeCredReq)
 	if err != nil {
-		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("failed to build credential:"+
-			" %s", err.Error()))
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
 
 		return
 	}
@@ -847,39 +1325,54 @@ func (o *Operation) composeAndIssueCredentialHandler(rw http.ResponseWriter, req
 		credential.Context = append(credential.Context, cslstatus.Context)
 	}
 
-	// update context
-	vcutil.UpdateSignatureTypeContext(credential, profile)
-
-	// update credential issuer
-	vcutil.UpdateIssuer(credential, profile)
-
-	// prepare signing options from request options
-	opts, err := getComposeSigningOpts(&composeCredReq)
+	signedVC, err := o.issue(profile, credential, opts)
 	if err != nil {
-		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("failed to prepare signing options:"+
-			" %s", err.Error()))
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
 
 		return
 	}
 
-	// sign the credential
-	signedVC, err := o.crypto.SignCredential(profile.DataProfile, credential, opts...)
+	// response
+	rw.WriteHeader(http.StatusCreated)
+	commhttp.WriteResponse(rw, signedVC)
+}
+
+// composeCredential builds the credential of the compose request along with its signing options, the credential must
+// comply with the issuance policy of the profile.
+func composeCredential(profile *vcprofile.IssuerProfile,
+	composeCredReq *ComposeCredentialRequest) (*verifiable.Credential, []crypto.SigningOpts, error) {
+	var (
+		credential *verifiable.Credential
+		err        error
+	)
+
+	if composeCredReq.TemplateID != "" {
+		credential, err = buildCredentialFromTemplate(profile, composeCredReq)
+	} else {
+		credential, err = buildCredential(composeCredReq)
+	}
+
 	if err != nil {
-		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to sign credential:"+
-			" %s", err.Error()))
+		return nil, nil, fmt.Errorf("failed to build credential: %w", err)
+	}
 
-		return
+	if err = checkIssuancePolicy(profile, credential); err != nil {
+		return nil, nil, err
 	}
 
-	// response
-	rw.WriteHeader(http.StatusCreated)
-	commhttp.WriteResponse(rw, signedVC)
+	// prepare signing options from request options
+	opts, err := getComposeSigningOpts(composeCredReq)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to prepare signing options: %w", err)
+	}
+
+	return credential, opts, nil
 }
 
 // nolint: funlen
 func buildCredential(composeCredReq *ComposeCredentialRequest) (*verifiable.Credential, error) {
-	// create the verifiable credential
-	credential := &verifiable.Credential{}
+	// create the verifiable credential, identified so that its status can be updated later
+	credential := &verifiable.Credential{ID: uuid.New().URN()}
 
 	var err error
 
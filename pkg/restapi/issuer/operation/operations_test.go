@@ -47,6 +47,7 @@ import (
 
 	vccrypto "github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
 	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/registry"
 	cslstatus "github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
 	"github.com/trustbloc/edge-service/pkg/internal/mock/edv"
 	"github.com/trustbloc/edge-service/pkg/internal/testutil"
@@ -472,6 +473,8 @@ type mockCommonDID struct {
 	createDIDValue string
 	createDIDKeyID string
 	createDIDErr   error
+	rotateKeyValue string
+	rotateKeyErr   error
 }
 
 func (m *mockCommonDID) CreateDID(keyType, signatureType, didID, privateKey, keyID, purpose string,
@@ -479,6 +482,10 @@ func (m *mockCommonDID) CreateDID(keyType, signatureType, didID, privateKey, key
 	return m.createDIDValue, m.createDIDKeyID, m.createDIDErr
 }
 
+func (m *mockCommonDID) RotateKey(didID, keyType, signatureType, privateKey, keyID string) (string, error) {
+	return m.rotateKeyValue, m.rotateKeyErr
+}
+
 type mockAuthService struct {
 	createDIDKeyFunc func() (string, error)
 	signHeaderFunc   func(req *http.Request, capability []byte,
@@ -883,6 +890,389 @@ func TestDeleteProfileHandler(t *testing.T) {
 	})
 }
 
+func TestDeleteProfileHandler_Cascade(t *testing.T) {
+	kmsSecretsProvider := ariesmemstorage.NewProvider()
+
+	customKMS, err := localkms.New("local-lock://custom/primary/key/",
+		mockkms.NewProviderForKMS(kmsSecretsProvider, &noop.NoLock{}))
+	require.NoError(t, err)
+
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	edvClient := edv.NewMockEDVClient("test", nil, nil, []string{"https://edv.example.com/vault/documents/doc1"}, nil)
+
+	op, err := New(&Config{
+		StoreProvider:      ariesmemstorage.NewProvider(),
+		KMSSecretsProvider: kmsSecretsProvider,
+		EDVClient:          edvClient,
+		KeyManager:         customKMS,
+		VDRI:               &vdrmock.MockVDRegistry{},
+		Crypto:             customCrypto,
+		HostURL:            "https://issuer.example.com",
+	})
+	require.NoError(t, err)
+
+	statusManager := &mockVCStatusManager{}
+	op.vcStatusManager = statusManager
+
+	handler := getHandler(t, op, deleteProfileEndpoint, http.MethodDelete)
+
+	deleteProfile := func(t *testing.T, profileID, query string) (int, string) {
+		t.Helper()
+
+		rr := serveHTTPMux(t, handler, "/profile/"+profileID+query, nil, map[string]string{"id": profileID})
+
+		return rr.Code, rr.Body.String()
+	}
+
+	saveRecord := func(t *testing.T, id string, storedInEDV bool) {
+		t.Helper()
+
+		record := registry.NewRecord("test", &verifiable.Credential{ID: id})
+		record.StoredInEDV = storedInEDV
+
+		require.NoError(t, op.registry.Save(record))
+	}
+
+	t.Run("delete profile - deletes the vault documents, status lists and records", func(t *testing.T) {
+		saveTestProfile(t, op, getTestProfile())
+		saveRecord(t, "http://example.edu/credentials/1", true)
+		saveRecord(t, "http://example.edu/credentials/2", false)
+
+		edvClient.DeletedDocuments = nil
+
+		code, body := deleteProfile(t, "test", "")
+		require.Equal(t, http.StatusOK, code, body)
+
+		require.Equal(t, []string{"doc1"}, edvClient.DeletedDocuments)
+		require.Equal(t, "https://issuer.example.com/test/status", statusManager.deletedStatusListsURL)
+
+		records, err := op.registry.List("test")
+		require.NoError(t, err)
+		require.Empty(t, records)
+
+		_, err = op.profileStore.GetProfile("test")
+		require.Error(t, err)
+	})
+
+	t.Run("delete profile - deletes the credentials stored without being issued", func(t *testing.T) {
+		saveTestProfile(t, op, getTestProfile())
+		require.NoError(t, op.markStoredInEDV("test", "http://example.edu/credentials/3"))
+
+		edvClient.DeletedDocuments = nil
+
+		code, body := deleteProfile(t, "test", "")
+		require.Equal(t, http.StatusOK, code, body)
+
+		require.Equal(t, []string{"doc1"}, edvClient.DeletedDocuments)
+
+		ids, err := op.registry.ListStored("test")
+		require.NoError(t, err)
+		require.Empty(t, ids)
+	})
+
+	t.Run("delete profile - destroys the signing key", func(t *testing.T) {
+		keyID, _, err := customKMS.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		profile := getTestProfile()
+		profile.Creator = "did:test:abc#" + keyID
+		saveTestProfile(t, op, profile)
+
+		code, body := deleteProfile(t, "test", "?destroyKeys=true")
+		require.Equal(t, http.StatusOK, code, body)
+
+		_, err = customKMS.Get(keyID)
+		require.Error(t, err)
+	})
+
+	t.Run("soft delete profile - the profile can't issue credentials anymore", func(t *testing.T) {
+		saveTestProfile(t, op, getTestProfile())
+		saveRecord(t, "http://example.edu/credentials/1", true)
+
+		edvClient.DeletedDocuments = nil
+		statusManager.deletedStatusListsURL = ""
+
+		code, body := deleteProfile(t, "test", "?soft=true")
+		require.Equal(t, http.StatusOK, code, body)
+
+		profile, err := op.profileStore.GetProfile("test")
+		require.NoError(t, err)
+		require.NotNil(t, profile.Deleted)
+
+		require.Empty(t, edvClient.DeletedDocuments)
+		require.Empty(t, statusManager.deletedStatusListsURL)
+
+		rr := serveHTTPMux(t, getHandler(t, op, issueCredentialPath, http.MethodPost), "/test/credentials/issue",
+			[]byte(`{"credential":`+validVC+`}`), map[string]string{profileIDPathParam: "test"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "profile test is deleted")
+
+		// soft deleting again is a no-op, a hard delete still cleans the profile up
+		code, body = deleteProfile(t, "test", "?soft=true")
+		require.Equal(t, http.StatusOK, code, body)
+
+		code, body = deleteProfile(t, "test", "")
+		require.Equal(t, http.StatusOK, code, body)
+		require.Equal(t, []string{"doc1"}, edvClient.DeletedDocuments)
+	})
+
+	t.Run("delete profile - invalid options", func(t *testing.T) {
+		saveTestProfile(t, op, getTestProfile())
+
+		code, body := deleteProfile(t, "test", "?soft=true&destroyKeys=true")
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "keys can't be destroyed on soft delete")
+
+		code, body = deleteProfile(t, "test", "?soft=maybe")
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid soft")
+	})
+
+	t.Run("delete profile - invalid profile", func(t *testing.T) {
+		code, body := deleteProfile(t, "unknown", "")
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid issuer profile")
+	})
+
+	t.Run("delete profile - failed to delete vault documents", func(t *testing.T) {
+		saveTestProfile(t, op, getTestProfile())
+		saveRecord(t, "http://example.edu/credentials/1", true)
+
+		edvClient.DeleteDocumentError = errors.New("delete document error")
+		defer func() { edvClient.DeleteDocumentError = nil }()
+
+		code, body := deleteProfile(t, "test", "")
+		require.Equal(t, http.StatusInternalServerError, code)
+		require.Contains(t, body, "delete document error")
+
+		// nothing else is deleted, the deletion can be retried
+		_, err = op.profileStore.GetProfile("test")
+		require.NoError(t, err)
+	})
+
+	t.Run("delete profile - failed to delete status lists", func(t *testing.T) {
+		saveTestProfile(t, op, getTestProfile())
+
+		statusManager.deleteStatusListsErr = errors.New("status error")
+		defer func() { statusManager.deleteStatusListsErr = nil }()
+
+		code, body := deleteProfile(t, "test", "")
+		require.Equal(t, http.StatusInternalServerError, code)
+		require.Contains(t, body, "failed to delete status lists: status error")
+	})
+
+	t.Run("delete profile - failed to destroy signing key", func(t *testing.T) {
+		profile := getTestProfile()
+		profile.Creator = "did:test:abc"
+		saveTestProfile(t, op, profile)
+
+		code, body := deleteProfile(t, "test", "?destroyKeys=true")
+		require.Equal(t, http.StatusInternalServerError, code)
+		require.Contains(t, body, "failed to destroy signing key: invalid creator did:test:abc")
+
+		kmsStore := op.kmsStore
+		defer func() { op.kmsStore = kmsStore }()
+
+		op.kmsStore = nil
+
+		code, body = deleteProfile(t, "test", "?destroyKeys=true")
+		require.Equal(t, http.StatusInternalServerError, code)
+		require.Contains(t, body, "kms secrets store isn't configured")
+	})
+}
+
+func TestListProfilesHandler(t *testing.T) {
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	op, err := New(&Config{
+		StoreProvider:      ariesmemstorage.NewProvider(),
+		KMSSecretsProvider: ariesmemstorage.NewProvider(),
+		KeyManager:         createKMS(t),
+		VDRI:               &vdrmock.MockVDRegistry{},
+		Crypto:             customCrypto,
+		HostURL:            "localhost:8080",
+	})
+	require.NoError(t, err)
+
+	for _, name := range []string{"c", "a", "b"} {
+		profile := getTestProfile()
+		profile.Name = name
+
+		saveTestProfile(t, op, profile)
+	}
+
+	handler := getHandler(t, op, createProfileEndpoint, http.MethodGet)
+
+	t.Run("list profiles - success", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, createProfileEndpoint+"?offset=1&limit=1", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		resp := &ListProfilesResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, 3, resp.Total)
+		require.Len(t, resp.Profiles, 1)
+		require.Equal(t, "b", resp.Profiles[0].Name)
+	})
+
+	t.Run("list profiles - invalid page", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, createProfileEndpoint+"?limit=0", nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "limit must be between 1 and 1000")
+	})
+
+	t.Run("list profiles - store error", func(t *testing.T) {
+		op, err := New(&Config{
+			StoreProvider: &ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+				Store:    make(map[string]ariesmockstorage.DBEntry),
+				ErrQuery: errors.New("query error"),
+			}},
+			KMSSecretsProvider: ariesmemstorage.NewProvider(),
+			KeyManager:         createKMS(t),
+			VDRI:               &vdrmock.MockVDRegistry{},
+			Crypto:             customCrypto,
+		})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, createProfileEndpoint, http.MethodGet), createProfileEndpoint, nil, nil)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to list issuer profiles")
+	})
+}
+
+func TestUpdateProfileHandler(t *testing.T) {
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	op, err := New(&Config{
+		StoreProvider:      ariesmemstorage.NewProvider(),
+		KMSSecretsProvider: ariesmemstorage.NewProvider(),
+		KeyManager:         createKMS(t),
+		VDRI:               &vdrmock.MockVDRegistry{},
+		Crypto:             customCrypto,
+		HostURL:            "localhost:8080",
+	})
+	require.NoError(t, err)
+
+	update := func(t *testing.T, method, profileID string, req interface{}) (int, *vcprofile.IssuerProfile, string) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, getProfileEndpoint, method), "/profile/"+profileID, reqBytes,
+			map[string]string{"id": profileID})
+
+		profile := &vcprofile.IssuerProfile{}
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), profile))
+		}
+
+		return rr.Code, profile, rr.Body.String()
+	}
+
+	uri := "https://updated.com/credentials"
+	signatureType := "JsonWebSignature2020"
+	jws := verifiable.SignatureJWS
+	enabled := true
+
+	t.Run("patch profile - updates the fields set", func(t *testing.T) {
+		profile := getTestProfile()
+		profile.OverwriteIssuer = true
+		saveTestProfile(t, op, profile)
+
+		code, updated, body := update(t, http.MethodPatch, profile.Name, &UpdateProfileRequest{
+			URI: &uri, SignatureType: &signatureType, SignatureRepresentation: &jws,
+		})
+		require.Equal(t, http.StatusOK, code, body)
+		require.Equal(t, uri, updated.URI)
+		require.Equal(t, signatureType, updated.SignatureType)
+		require.Equal(t, verifiable.SignatureJWS, updated.SignatureRepresentation)
+		require.True(t, updated.OverwriteIssuer)
+		require.Equal(t, profile.DID, updated.DID)
+		require.Equal(t, profile.Creator, updated.Creator)
+
+		saved, err := op.profileStore.GetProfile(profile.Name)
+		require.NoError(t, err)
+		require.Equal(t, updated, saved)
+	})
+
+	t.Run("put profile - resets the fields left out", func(t *testing.T) {
+		profile := getTestProfile()
+		profile.OverwriteIssuer = true
+		profile.SignatureRepresentation = verifiable.SignatureJWS
+		saveTestProfile(t, op, profile)
+
+		code, updated, body := update(t, http.MethodPut, profile.Name, &UpdateProfileRequest{
+			URI: &uri, SignatureType: &signatureType, StoreIssuedCredentials: &enabled,
+		})
+		require.Equal(t, http.StatusOK, code, body)
+		require.Equal(t, uri, updated.URI)
+		require.False(t, updated.OverwriteIssuer)
+		require.True(t, updated.StoreIssuedCredentials)
+		require.Equal(t, verifiable.SignatureProofValue, updated.SignatureRepresentation)
+	})
+
+	t.Run("put profile - missing uri", func(t *testing.T) {
+		saveTestProfile(t, op, getTestProfile())
+
+		code, _, body := update(t, http.MethodPut, "test", &UpdateProfileRequest{SignatureType: &signatureType})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "missing URI information")
+	})
+
+	t.Run("patch profile - invalid signature type", func(t *testing.T) {
+		saveTestProfile(t, op, getTestProfile())
+
+		bbs := "BbsBlsSignature2020"
+
+		code, _, body := update(t, http.MethodPatch, "test", &UpdateProfileRequest{SignatureType: &bbs})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "BbsBlsSignature2020")
+	})
+
+	t.Run("patch profile - vc status can't be disabled once used", func(t *testing.T) {
+		saveTestProfile(t, op, getTestProfile())
+
+		statusManager := op.vcStatusManager
+		defer func() { op.vcStatusManager = statusManager }()
+
+		op.vcStatusManager = &mockVCStatusManager{hasStatusListsValue: true}
+
+		code, _, body := update(t, http.MethodPatch, "test", &UpdateProfileRequest{DisableVCStatus: &enabled})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "vc status can't be disabled")
+
+		op.vcStatusManager = &mockVCStatusManager{hasStatusListsErr: errors.New("get error")}
+
+		code, _, body = update(t, http.MethodPatch, "test", &UpdateProfileRequest{DisableVCStatus: &enabled})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "failed to look up the status lists: get error")
+	})
+
+	t.Run("patch profile - vc status disabled before any status list", func(t *testing.T) {
+		saveTestProfile(t, op, getTestProfile())
+
+		code, profile, body := update(t, http.MethodPatch, "test", &UpdateProfileRequest{DisableVCStatus: &enabled})
+		require.Equal(t, http.StatusOK, code, body)
+		require.True(t, profile.DisableVCStatus)
+	})
+
+	t.Run("patch profile - invalid profile", func(t *testing.T) {
+		code, _, body := update(t, http.MethodPatch, "unknown", &UpdateProfileRequest{})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid issuer profile")
+	})
+
+	t.Run("patch profile - invalid request", func(t *testing.T) {
+		code, _, body := update(t, http.MethodPatch, "test", "invalid")
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, invalidRequestErrMsg)
+	})
+}
+
 func createProfileSuccess(t *testing.T, op *Operation) *vcprofile.IssuerProfile {
 	t.Helper()
 
@@ -2584,6 +2974,215 @@ func TestComposeAndIssueCredential(t *testing.T) {
 	})
 }
 
+func TestIssuedCredentials(t *testing.T) {
+	customKMS := createKMS(t)
+
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	keyID, pubKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ED25519Type)
+	require.NoError(t, err)
+
+	newOperation := func(t *testing.T, storeIssuedCredentials bool) (*Operation, *vcprofile.IssuerProfile) {
+		t.Helper()
+
+		op, err := New(&Config{
+			StoreProvider:      ariesmemstorage.NewProvider(),
+			KMSSecretsProvider: ariesmemstorage.NewProvider(),
+			KeyManager:         customKMS,
+			Crypto:             customCrypto,
+			EDVClient:          edv.NewMockEDVClient("test", nil, nil, []string{"testID"}, nil),
+			VDRI: &vdrmock.MockVDRegistry{
+				ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+					return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, keyID, pubKey)}, nil
+				},
+			},
+			DocumentLoader: testutil.DocumentLoader(t),
+		})
+		require.NoError(t, err)
+
+		op.vcStatusManager = &mockVCStatusManager{createStatusIDValue: &verifiable.TypedID{
+			ID: "https://example.com/status/1#0", Type: cslstatus.RevocationList2020Status,
+		}}
+
+		profile := getTestProfile()
+		profile.Creator = "did:test:abc#" + keyID
+		profile.StoreIssuedCredentials = storeIssuedCredentials
+
+		require.NoError(t, op.profileStore.SaveProfile(profile))
+
+		return op, profile
+	}
+
+	issue := func(t *testing.T, op *Operation, profileID string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(&IssueCredentialRequest{Credential: []byte(validVC)})
+		require.NoError(t, err)
+
+		return serveHTTPMux(t, getHandler(t, op, issueCredentialPath, http.MethodPost),
+			"/"+profileID+"/credentials/issue", reqBytes, map[string]string{profileIDPathParam: profileID})
+	}
+
+	t.Run("issued credentials are recorded", func(t *testing.T) {
+		op, profile := newOperation(t, false)
+
+		rr := issue(t, op, profile.Name)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		rr = serveHTTPMux(t, getHandler(t, op, credentialsBasePath, http.MethodGet),
+			"/"+profile.Name+"/credentials", nil, map[string]string{profileIDPathParam: profile.Name})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		resp := &ListIssuedCredentialsResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, 1, resp.Total)
+
+		records := resp.Credentials
+		require.Len(t, records, 1)
+		require.Equal(t, "http://example.edu/credentials/1872", records[0].ID)
+		require.Equal(t, "did:example:ebfeb1f712ebc6f1c276e12ec21", records[0].Subject)
+		require.Equal(t, []string{"VerifiableCredential"}, records[0].Types)
+		require.Equal(t, "https://example.com/status/1#0", records[0].Status.ID)
+		require.False(t, records[0].Revoked)
+		require.False(t, records[0].StoredInEDV)
+		require.NotNil(t, records[0].IssuanceDate)
+	})
+
+	t.Run("composed credentials are recorded", func(t *testing.T) {
+		op, profile := newOperation(t, false)
+
+		reqBytes, err := json.Marshal(&ComposeCredentialRequest{
+			Subject: "did:example:oleh394sqwnlk223823ln",
+			Types:   []string{"VerifiableCredential", "UniversityDegreeCredential"},
+			CredentialFormatOptions: json.RawMessage(`{"@context": [` +
+				`"https://www.w3.org/2018/credentials/v1", "https://www.w3.org/2018/credentials/examples/v1"]}`),
+		})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, composeAndIssueCredentialPath, http.MethodPost),
+			"/"+profile.Name+"/credentials/composeAndIssueCredential", reqBytes,
+			map[string]string{profileIDPathParam: profile.Name})
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		signedVC := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &signedVC))
+
+		vcID, ok := signedVC["id"].(string)
+		require.True(t, ok)
+		require.True(t, strings.HasPrefix(vcID, "urn:uuid:"))
+
+		record, err := op.registry.Get(profile.Name, vcID)
+		require.NoError(t, err)
+		require.Equal(t, "did:example:oleh394sqwnlk223823ln", record.Subject)
+		require.Equal(t, []string{"VerifiableCredential", "UniversityDegreeCredential"}, record.Types)
+	})
+
+	t.Run("issued credentials are stored in the profile's vault", func(t *testing.T) {
+		op, profile := newOperation(t, true)
+
+		rr := issue(t, op, profile.Name)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		record, err := op.registry.Get(profile.Name, "http://example.edu/credentials/1872")
+		require.NoError(t, err)
+		require.True(t, record.StoredInEDV)
+	})
+
+	t.Run("failed to store issued credential in the profile's vault", func(t *testing.T) {
+		op, profile := newOperation(t, true)
+		op.edvClient = NewMockEDVClient("test")
+
+		rr := issue(t, op, profile.Name)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to record credential: store credential in vault")
+	})
+
+	t.Run("status of issued credentials is updated without the vault", func(t *testing.T) {
+		op, profile := newOperation(t, false)
+
+		rr := issue(t, op, profile.Name)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		// credentials are not looked up in the vault
+		op.edvClient = NewMockEDVClient("test")
+
+		reqBytes, err := json.Marshal(&UpdateCredentialStatusRequest{
+			CredentialID: "http://example.edu/credentials/1872",
+			CredentialStatus: CredentialStatus{
+				Type:   cslstatus.RevocationList2020Status,
+				Status: "true",
+			},
+		})
+		require.NoError(t, err)
+
+		rr = serveHTTPMux(t, getHandler(t, op, updateCredentialStatusEndpoint, http.MethodPost),
+			"/"+profile.Name+"/credentials/status", reqBytes, map[string]string{profileIDPathParam: profile.Name})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		record, err := op.registry.Get(profile.Name, "http://example.edu/credentials/1872")
+		require.NoError(t, err)
+		require.True(t, record.Revoked)
+		require.NotNil(t, record.StatusUpdated)
+	})
+
+	t.Run("list issued credentials - page", func(t *testing.T) {
+		op, profile := newOperation(t, false)
+
+		created := time.Now().UTC()
+
+		for _, id := range []string{"vc3", "vc1", "vc2"} {
+			record := registry.NewRecord(profile.Name, &verifiable.Credential{ID: id})
+			record.Created = created
+
+			require.NoError(t, op.registry.Save(record))
+		}
+
+		rr := serveHTTPMux(t, getHandler(t, op, credentialsBasePath, http.MethodGet),
+			"/"+profile.Name+"/credentials?offset=1&limit=1", nil, map[string]string{profileIDPathParam: profile.Name})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		resp := &ListIssuedCredentialsResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, 3, resp.Total)
+		require.Len(t, resp.Credentials, 1)
+		require.Equal(t, "vc2", resp.Credentials[0].ID)
+	})
+
+	t.Run("list issued credentials - invalid page", func(t *testing.T) {
+		op, profile := newOperation(t, false)
+
+		rr := serveHTTPMux(t, getHandler(t, op, credentialsBasePath, http.MethodGet),
+			"/"+profile.Name+"/credentials?limit=0", nil, map[string]string{profileIDPathParam: profile.Name})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "limit must be between 1 and")
+	})
+
+	t.Run("list issued credentials - invalid profile", func(t *testing.T) {
+		op, _ := newOperation(t, false)
+
+		rr := serveHTTPMux(t, getHandler(t, op, credentialsBasePath, http.MethodGet),
+			"/unknown/credentials", nil, map[string]string{profileIDPathParam: "unknown"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid issuer profile")
+	})
+
+	t.Run("list issued credentials - query error", func(t *testing.T) {
+		op, profile := newOperation(t, false)
+
+		op.registry, err = registry.New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:    map[string]ariesmockstorage.DBEntry{},
+			ErrQuery: errors.New("query error"),
+		}})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, credentialsBasePath, http.MethodGet),
+			"/"+profile.Name+"/credentials", nil, map[string]string{profileIDPathParam: profile.Name})
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to list issued credentials")
+	})
+}
+
 func TestGetComposeSigningOpts(t *testing.T) {
 	t.Run("get signing opts", func(t *testing.T) {
 		tests := []struct {
@@ -2982,18 +3581,41 @@ func (c *TestClient) QueryVault(vaultID, name, value string, opts ...edvclient.R
 	return []string{"dummyID"}, nil
 }
 
+func (c *TestClient) DeleteDocument(vaultID, docID string, opts ...edvclient.ReqOption) error {
+	return errDocumentNotFound
+}
+
 type mockVCStatusManager struct {
 	createStatusIDValue      *verifiable.TypedID
 	createStatusIDErr        error
 	updateVCErr              error
 	getRevocationListVCValue []byte
 	GetRevocationListVCErr   error
+	deleteStatusListsErr     error
+	deletedStatusListsURL    string
+	hasStatusListsValue      bool
+	hasStatusListsErr        error
 }
 
 func (m *mockVCStatusManager) CreateStatusID(profile *vcprofile.DataProfile, url string) (*verifiable.TypedID, error) {
 	return m.createStatusIDValue, m.createStatusIDErr
 }
 
+func (m *mockVCStatusManager) CreateStatusIDs(profile *vcprofile.DataProfile, url string,
+	n int) ([]*verifiable.TypedID, error) {
+	if m.createStatusIDErr != nil {
+		return nil, m.createStatusIDErr
+	}
+
+	statusIDs := make([]*verifiable.TypedID, n)
+
+	for i := range statusIDs {
+		statusIDs[i] = m.createStatusIDValue
+	}
+
+	return statusIDs, nil
+}
+
 func (m *mockVCStatusManager) UpdateVC(v *verifiable.Credential, profile *vcprofile.DataProfile, status bool) error {
 	return m.updateVCErr
 }
@@ -3002,6 +3624,16 @@ func (m *mockVCStatusManager) GetRevocationListVC(id string) ([]byte, error) {
 	return m.getRevocationListVCValue, m.GetRevocationListVCErr
 }
 
+func (m *mockVCStatusManager) DeleteStatusLists(url string) error {
+	m.deletedStatusListsURL = url
+
+	return m.deleteStatusListsErr
+}
+
+func (m *mockVCStatusManager) HasStatusLists(url string) (bool, error) {
+	return m.hasStatusListsValue, m.hasStatusListsErr
+}
+
 type mockCredentialStatusManager struct {
 	CreateErr error
 }
@@ -3015,6 +3647,15 @@ func (m *mockCredentialStatusManager) CreateStatusID(profile *vcprofile.DataProf
 	return nil, nil
 }
 
+func (m *mockCredentialStatusManager) CreateStatusIDs(profile *vcprofile.DataProfile, url string,
+	n int) ([]*verifiable.TypedID, error) {
+	if m.CreateErr != nil {
+		return nil, m.CreateErr
+	}
+
+	return make([]*verifiable.TypedID, n), nil
+}
+
 func (m *mockCredentialStatusManager) UpdateVC(v *verifiable.Credential,
 	profile *vcprofile.DataProfile, status bool) error {
 	return nil
@@ -3024,6 +3665,14 @@ func (m *mockCredentialStatusManager) GetRevocationListVC(id string) ([]byte, er
 	return nil, nil
 }
 
+func (m *mockCredentialStatusManager) DeleteStatusLists(url string) error {
+	return nil
+}
+
+func (m *mockCredentialStatusManager) HasStatusLists(url string) (bool, error) {
+	return false, nil
+}
+
 func createKMS(t *testing.T) *localkms.LocalKMS {
 	t.Helper()
 
@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/internal/common/utils"
+)
+
+const (
+	baseContext        = "https://www.w3.org/2018/credentials/v1"
+	verifiableCredType = "VerifiableCredential"
+)
+
+// checkIssuancePolicy checks the credential complies with the issuance policy of the profile, the error tells which
+// rule it breaks.
+func checkIssuancePolicy(profile *vcprofile.IssuerProfile, credential *verifiable.Credential) error {
+	policy := profile.IssuancePolicy
+	if policy == nil {
+		return nil
+	}
+
+	if err := checkPolicyRules(policy, credential); err != nil {
+		return fmt.Errorf("credential violates issuance policy of profile %s: %w", profile.Name, err)
+	}
+
+	return nil
+}
+
+func checkPolicyRules(policy *vcprofile.IssuancePolicy, credential *verifiable.Credential) error {
+	if len(policy.AllowedTypes) != 0 {
+		for _, t := range credential.Types {
+			if t != verifiableCredType && !utils.Contains(policy.AllowedTypes, t) {
+				return fmt.Errorf("type %s is not allowed", t)
+			}
+		}
+	}
+
+	if len(policy.AllowedContexts) != 0 {
+		for _, c := range credential.Context {
+			if c != baseContext && !utils.Contains(policy.AllowedContexts, c) {
+				return fmt.Errorf("context %s is not allowed", c)
+			}
+		}
+	}
+
+	if err := checkValidityPeriod(policy.MaxValidityPeriod, credential); err != nil {
+		return err
+	}
+
+	if len(policy.RequiredClaims) == 0 && len(policy.ForbiddenClaims) == 0 {
+		return nil
+	}
+
+	subjects, err := utils.CredentialSubjects(credential)
+	if err != nil {
+		return err
+	}
+
+	for _, subject := range subjects {
+		for _, claim := range policy.RequiredClaims {
+			if !hasClaim(subject, claim) {
+				return fmt.Errorf("required claim %s is missing", claim)
+			}
+		}
+
+		for _, claim := range policy.ForbiddenClaims {
+			if hasClaim(subject, claim) {
+				return fmt.Errorf("claim %s is forbidden", claim)
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkValidityPeriod(maxValidityPeriod int64, credential *verifiable.Credential) error {
+	if maxValidityPeriod == 0 {
+		return nil
+	}
+
+	if credential.Expired == nil {
+		return fmt.Errorf("expiration date is required, the validity period is at most %d seconds",
+			maxValidityPeriod)
+	}
+
+	issued := time.Now()
+	if credential.Issued != nil {
+		issued = credential.Issued.Time
+	}
+
+	if credential.Expired.Time.Sub(issued) > time.Duration(maxValidityPeriod)*time.Second {
+		return fmt.Errorf("validity period exceeds %d seconds", maxValidityPeriod)
+	}
+
+	return nil
+}
+
+// hasClaim tells whether the subject has the claim, whose path is dot separated.
+func hasClaim(subject map[string]interface{}, claim string) bool {
+	var value interface{} = subject
+
+	for _, name := range strings.Split(claim, ".") {
+		claims, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+
+		if value, ok = claims[name]; !ok || value == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func validateIssuancePolicy(policy *vcprofile.IssuancePolicy) error {
+	if policy == nil {
+		return nil
+	}
+
+	if policy.MaxValidityPeriod < 0 {
+		return errors.New("invalid maximum validity period of issuance policy")
+	}
+
+	for _, claim := range policy.RequiredClaims {
+		if utils.Contains(policy.ForbiddenClaims, claim) {
+			return fmt.Errorf("claim %s of issuance policy is both required and forbidden", claim)
+		}
+	}
+
+	return nil
+}
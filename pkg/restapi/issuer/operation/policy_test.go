@@ -0,0 +1,189 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+)
+
+const degreeVC = `{
+	"@context": [
+		"https://www.w3.org/2018/credentials/v1",
+		"https://www.w3.org/2018/credentials/examples/v1"
+	],
+	"id": "http://example.edu/credentials/1872",
+	"type": ["VerifiableCredential", "UniversityDegreeCredential"],
+	"credentialSubject": {
+		"id": "did:example:ebfeb1f712ebc6f1c276e12ec21",
+		"degree": {"type": "BachelorDegree", "name": "Bachelor of Science"}
+	},
+	"issuer": "did:example:76e12ec712ebc6f1c221ebfeb1f",
+	"issuanceDate": "2010-01-01T19:23:24Z"
+}`
+
+func TestIssuancePolicy(t *testing.T) {
+	op, keyID := newWalletTestOperation(t, newMockWallet(t))
+
+	profile := getTestProfile()
+	profile.Creator = "did:test:abc#" + keyID
+	profile.DisableVCStatus = true
+	profile.IssuancePolicy = &vcprofile.IssuancePolicy{
+		AllowedTypes:      []string{"UniversityDegreeCredential"},
+		AllowedContexts:   []string{"https://www.w3.org/2018/credentials/examples/v1"},
+		MaxValidityPeriod: 3600,
+		RequiredClaims:    []string{"degree.type"},
+		ForbiddenClaims:   []string{"ssn"},
+	}
+	saveTestProfile(t, op, profile)
+
+	issue := func(t *testing.T, vc string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(&IssueCredentialRequest{Credential: json.RawMessage(vc)})
+		require.NoError(t, err)
+
+		return serveHTTPMux(t, getHandler(t, op, issueCredentialPath, http.MethodPost), "/test/credentials/issue",
+			reqBytes, map[string]string{profileIDPathParam: "test"})
+	}
+
+	compose := func(t *testing.T, req *ComposeCredentialRequest) *httptest.ResponseRecorder {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		return serveHTTPMux(t, getHandler(t, op, composeAndIssueCredentialPath, http.MethodPost),
+			"/test/credentials/composeAndIssueCredential", reqBytes, map[string]string{profileIDPathParam: "test"})
+	}
+
+	issued := time.Now().UTC()
+	expired := issued.Add(time.Hour)
+
+	degreeRequest := func() *ComposeCredentialRequest {
+		return &ComposeCredentialRequest{
+			Subject:        testWalletDID,
+			Types:          []string{"VerifiableCredential", "UniversityDegreeCredential"},
+			IssuanceDate:   &issued,
+			ExpirationDate: &expired,
+			Claims:         json.RawMessage(`{"degree":{"type":"BachelorDegree"}}`),
+			CredentialFormatOptions: json.RawMessage(
+				`{"@context":["https://www.w3.org/2018/credentials/v1","https://www.w3.org/2018/credentials/examples/v1"]}`),
+		}
+	}
+
+	t.Run("compose - success", func(t *testing.T) {
+		rr := compose(t, degreeRequest())
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	})
+
+	t.Run("compose - policy violations", func(t *testing.T) {
+		tooLong := issued.Add(2 * time.Hour)
+
+		tests := []struct {
+			name   string
+			update func(req *ComposeCredentialRequest)
+			err    string
+		}{
+			{
+				name:   "type not allowed",
+				update: func(req *ComposeCredentialRequest) { req.Types = []string{"PermanentResidentCard"} },
+				err:    "type PermanentResidentCard is not allowed",
+			},
+			{
+				name:   "no expiration date",
+				update: func(req *ComposeCredentialRequest) { req.ExpirationDate = nil },
+				err:    "expiration date is required, the validity period is at most 3600 seconds",
+			},
+			{
+				name:   "validity period too long",
+				update: func(req *ComposeCredentialRequest) { req.ExpirationDate = &tooLong },
+				err:    "validity period exceeds 3600 seconds",
+			},
+			{
+				name:   "required claim missing",
+				update: func(req *ComposeCredentialRequest) { req.Claims = json.RawMessage(`{"degree":{}}`) },
+				err:    "required claim degree.type is missing",
+			},
+			{
+				name: "forbidden claim",
+				update: func(req *ComposeCredentialRequest) {
+					req.Claims = json.RawMessage(`{"degree":{"type":"BachelorDegree"},"ssn":"123-45-6789"}`)
+				},
+				err: "claim ssn is forbidden",
+			},
+		}
+
+		for _, tc := range tests {
+			req := degreeRequest()
+			tc.update(req)
+
+			rr := compose(t, req)
+			require.Equal(t, http.StatusBadRequest, rr.Code, tc.name)
+			require.Contains(t, rr.Body.String(), "credential violates issuance policy of profile test: "+tc.err,
+				tc.name)
+		}
+	})
+
+	t.Run("issue - policy violations", func(t *testing.T) {
+		// the credential doesn't expire
+		rr := issue(t, degreeVC)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "expiration date is required")
+
+		profile.IssuancePolicy = &vcprofile.IssuancePolicy{
+			AllowedTypes: []string{"UniversityDegreeCredential"},
+		}
+		saveTestProfile(t, op, profile)
+
+		rr = issue(t, degreeVC)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		profile.IssuancePolicy = &vcprofile.IssuancePolicy{
+			AllowedContexts: []string{"https://w3id.org/citizenship/v1"},
+		}
+		saveTestProfile(t, op, profile)
+
+		rr = issue(t, degreeVC)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(),
+			"context https://www.w3.org/2018/credentials/examples/v1 is not allowed")
+	})
+
+	t.Run("profile - invalid issuance policy", func(t *testing.T) {
+		tests := []struct {
+			policy *vcprofile.IssuancePolicy
+			err    string
+		}{
+			{
+				policy: &vcprofile.IssuancePolicy{MaxValidityPeriod: -1},
+				err:    "invalid maximum validity period of issuance policy",
+			},
+			{
+				policy: &vcprofile.IssuancePolicy{RequiredClaims: []string{"name"}, ForbiddenClaims: []string{"name"}},
+				err:    "claim name of issuance policy is both required and forbidden",
+			},
+		}
+
+		for _, tc := range tests {
+			reqBytes, err := json.Marshal(&UpdateProfileRequest{IssuancePolicy: tc.policy})
+			require.NoError(t, err)
+
+			rr := serveHTTPMux(t, getHandler(t, op, getProfileEndpoint, http.MethodPatch), "/profile/test", reqBytes,
+				map[string]string{"id": "test"})
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.Contains(t, rr.Body.String(), tc.err)
+		}
+	})
+}
@@ -0,0 +1,450 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/edge-service/pkg/claim"
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/registry"
+	cslstatus "github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+	"github.com/trustbloc/edge-service/pkg/restapi/internal/common/vcutil"
+	"github.com/trustbloc/edge-service/pkg/webhook"
+)
+
+const (
+	refreshCredentialPath = credentialsBasePath + "/refresh"
+	refreshChallengesPath = refreshCredentialPath + "/challenges"
+	refreshServiceType    = "ManualRefreshService2018"
+
+	// defaultRefreshWindow is how long before its expiry a credential can be refreshed when the policy doesn't tell.
+	defaultRefreshWindow = 24 * time.Hour
+	// refreshChallengeExpiry is how long the holder has to present the credential with the challenge.
+	refreshChallengeExpiry = 5 * time.Minute
+	// refreshClaimExpiry is how long a refresh holds the credential against other refreshes of it, in case the
+	// replica refreshing it stops before releasing it.
+	refreshClaimExpiry = time.Minute
+)
+
+var errRefreshPending = errors.New("credential is being refreshed")
+
+// embedRefreshService points the expiring credentials of the profile to its refresh service.
+func (o *Operation) embedRefreshService(profile *vcprofile.IssuerProfile, credential *verifiable.Credential) {
+	if profile.RefreshPolicy == nil || credential.Expired == nil || len(credential.RefreshService) != 0 {
+		return
+	}
+
+	credential.RefreshService = []verifiable.TypedID{{
+		ID:   o.hostURL + "/" + profile.Name + "/credentials/refresh",
+		Type: refreshServiceType,
+	}}
+}
+
+// IssueRefreshChallenge swagger:route POST /{id}/credentials/refresh/challenges issuer refreshChallengeReq
+//
+// Issues a single-use challenge for the subject of a credential to sign in the DID authentication proof of its
+// refresh request.
+//
+// Responses:
+//    default: genericError
+//        201: refreshChallengeRes
+func (o *Operation) issueRefreshChallengeHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.getActiveProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	if profile.RefreshPolicy == nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("refresh service is disabled for profile %s", profile.Name))
+
+		return
+	}
+
+	c, err := o.challenges.Issue(profile.Name, o.credentialIssuerID(profile.Name), refreshChallengeExpiry)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to issue challenge: %s", err.Error()))
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusCreated)
+	commhttp.WriteResponse(rw, c)
+}
+
+// RefreshCredential swagger:route POST /{id}/credentials/refresh issuer refreshCredentialReq
+//
+// Refreshes an expiring credential issued by the profile. The subject of the credential presents it with a DID
+// authentication proof over a challenge issued by the profile, the profile issues it again with a new validity
+// period and status.
+//
+// Responses:
+//    default: genericError
+//        201: verifiableCredentialRes
+func (o *Operation) refreshCredentialHandler(rw http.ResponseWriter, req *http.Request) { //nolint: funlen
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.getActiveProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	if profile.RefreshPolicy == nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("refresh service is disabled for profile %s", profile.Name))
+
+		return
+	}
+
+	data := RefreshCredentialRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	credential, record, held, err := o.credentialToRefresh(profile, data.Presentation)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, errRefreshPending) {
+			status = http.StatusConflict
+		}
+
+		commhttp.WriteErrorResponse(rw, status, err.Error())
+
+		return
+	}
+
+	defer o.releaseRefresh(held)
+
+	refreshed, err := refreshedCredential(profile.RefreshPolicy, credential)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	// the profile's issuance policy may have changed since the credential was issued
+	if err = checkIssuancePolicy(profile, refreshed); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	if !profile.DisableVCStatus {
+		refreshed.Status, err = o.vcStatusManager.CreateStatusID(profile.DataProfile,
+			o.hostURL+"/"+profileID+credentialStatus)
+		if err != nil {
+			commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to add credential status:"+
+				" %s", err.Error()))
+
+			return
+		}
+
+		refreshed.Context = append(refreshed.Context, cslstatus.Context)
+	}
+
+	signedVC, err := o.issue(profile, refreshed, nil)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	refreshedAt := time.Now().UTC()
+	record.RefreshedBy = refreshed.ID
+	record.RefreshedAt = &refreshedAt
+
+	if profile.RefreshPolicy.RevokeRefreshed && record.Status != nil {
+		if err = o.revokeRecord(profile, record); err != nil {
+			commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+				fmt.Sprintf("failed to revoke refreshed credential: %s", err.Error()))
+
+			return
+		}
+	} else if err = o.registry.Save(record); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to update refreshed credential record: %s", err.Error()))
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusCreated)
+	commhttp.WriteResponse(rw, signedVC)
+}
+
+// credentialToRefresh verifies the presentation and its credential, which must be issued by the profile to the
+// presentation's signer, neither revoked, suspended nor refreshed already. It returns the claim that holds the
+// credential against other refreshes until the record tells it is refreshed.
+func (o *Operation) credentialToRefresh(profile *vcprofile.IssuerProfile,
+	presentation json.RawMessage) (*verifiable.Credential, *registry.Record, *claim.Claim, error) {
+	keyFetcher := verifiable.NewVDRKeyResolver(o.vdr).PublicKeyFetcher()
+
+	vp, err := verifiable.ParsePresentation(presentation, verifiable.WithPresPublicKeyFetcher(keyFetcher),
+		verifiable.WithPresJSONLDDocumentLoader(o.documentLoader))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to verify presentation: %w", err)
+	}
+
+	holderDID, challenge, err := o.verifyDIDAuthProof(profile, vp)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	vcs, err := vp.MarshalledCredentials()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if len(vcs) != 1 {
+		return nil, nil, nil, errors.New("presentation must hold the credential to refresh only")
+	}
+
+	credential, err := verifiable.ParseCredential(vcs[0], verifiable.WithPublicKeyFetcher(keyFetcher),
+		verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to verify credential: %w", err)
+	}
+
+	if subjectID, errSubject := verifiable.SubjectID(credential.Subject); errSubject != nil || subjectID != holderDID {
+		return nil, nil, nil, errors.New("presentation isn't signed by the credential subject")
+	}
+
+	if err = checkRefreshWindow(profile.RefreshPolicy, credential); err != nil {
+		return nil, nil, nil, err
+	}
+
+	record, held, err := o.holdRefresh(profile, credential.ID, challenge)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return credential, record, held, nil
+}
+
+// holdRefresh holds the credential against other refreshes of it and consumes the challenge, it returns the record
+// of the credential, read once the credential is held so that a refresh that just ended is seen.
+func (o *Operation) holdRefresh(profile *vcprofile.IssuerProfile, id,
+	challenge string) (*registry.Record, *claim.Claim, error) {
+	held, err := o.claims.Claim("refresh_"+profile.Name+"_"+id, refreshClaimExpiry)
+	if errors.Is(err, claim.ErrClaimed) {
+		return nil, nil, fmt.Errorf("%w: %s", errRefreshPending, id)
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	record, err := o.refreshableRecord(profile, id)
+	if err != nil {
+		o.releaseRefresh(held)
+
+		return nil, nil, err
+	}
+
+	if err = o.challenges.Consume(profile.Name, o.credentialIssuerID(profile.Name), challenge); err != nil {
+		o.releaseRefresh(held)
+
+		return nil, nil, fmt.Errorf("invalid presentation proof challenge: %w", err)
+	}
+
+	return record, held, nil
+}
+
+// refreshableRecord returns the record of the credential issued by the profile, which must be neither revoked,
+// suspended nor refreshed already.
+func (o *Operation) refreshableRecord(profile *vcprofile.IssuerProfile, id string) (*registry.Record, error) {
+	record, err := o.registry.Get(profile.Name, id)
+	if err != nil {
+		if errors.Is(err, ariesstorage.ErrDataNotFound) {
+			return nil, fmt.Errorf("credential %s isn't issued by profile %s", id, profile.Name)
+		}
+
+		return nil, fmt.Errorf("failed to get issued credential record: %w", err)
+	}
+
+	switch {
+	case record.Revoked:
+		return nil, fmt.Errorf("credential %s is revoked", id)
+	case record.Suspended:
+		return nil, fmt.Errorf("credential %s is suspended", id)
+	case record.RefreshedBy != "":
+		return nil, fmt.Errorf("credential %s is already refreshed by %s", id, record.RefreshedBy)
+	}
+
+	return record, nil
+}
+
+// releaseRefresh releases the credential held for its refresh, the claim expires anyway if it can't be released.
+func (o *Operation) releaseRefresh(held *claim.Claim) {
+	if err := o.claims.Release(held); err != nil {
+		logger.Warnf("failed to release credential refresh: %s", err)
+	}
+}
+
+// checkRefreshWindow checks the credential expires soon enough to be refreshed, so that a holder can't keep
+// several live copies of it.
+func checkRefreshWindow(policy *vcprofile.RefreshPolicy, credential *verifiable.Credential) error {
+	window := defaultRefreshWindow
+	if policy.RefreshWindow != 0 {
+		window = time.Duration(policy.RefreshWindow) * time.Second
+	}
+
+	if credential.Expired == nil {
+		return fmt.Errorf("credential %s doesn't expire, it can't be refreshed", credential.ID)
+	}
+
+	if refreshable := credential.Expired.Time.Add(-window); time.Now().Before(refreshable) {
+		return fmt.Errorf("credential %s can't be refreshed before %s", credential.ID,
+			refreshable.UTC().Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// verifyDIDAuthProof checks the presentation has a recent authentication proof for the profile, it returns the DID
+// that signed it and the challenge it signed. The challenge is consumed once the credential is found refreshable.
+func (o *Operation) verifyDIDAuthProof(profile *vcprofile.IssuerProfile,
+	vp *verifiable.Presentation) (string, string, error) {
+	if len(vp.Proofs) != 1 {
+		return "", "", errors.New("presentation must have a DID authentication proof")
+	}
+
+	proof := vp.Proofs[0]
+
+	if proof["proofPurpose"] != authentication {
+		return "", "", errors.New("presentation proof purpose must be authentication")
+	}
+
+	if proof["domain"] != o.credentialIssuerID(profile.Name) {
+		return "", "", errors.New("presentation proof domain isn't the issuer profile")
+	}
+
+	challenge, ok := proof["challenge"].(string)
+	if !ok || challenge == "" {
+		return "", "", errors.New("presentation proof must sign a challenge issued by the issuer profile")
+	}
+
+	created, ok := proof["created"].(string)
+	if !ok {
+		return "", "", errors.New("presentation proof has no creation date")
+	}
+
+	createdTime, err := time.Parse(time.RFC3339, created)
+	if err != nil || time.Since(createdTime) > proofMaxAge {
+		return "", "", errors.New("presentation proof isn't created recently")
+	}
+
+	verificationMethod, ok := proof["verificationMethod"].(string)
+	if !ok {
+		return "", "", errors.New("presentation proof has no verification method")
+	}
+
+	return strings.Split(verificationMethod, "#")[0], challenge, nil
+}
+
+// refreshedCredential is a copy of the credential to issue again, with a new ID and validity period.
+func refreshedCredential(policy *vcprofile.RefreshPolicy,
+	credential *verifiable.Credential) (*verifiable.Credential, error) {
+	validity := time.Duration(policy.ValidityPeriod) * time.Second
+
+	if validity == 0 {
+		if credential.Issued == nil || credential.Expired == nil {
+			return nil, errors.New("validity period of the credential is unknown")
+		}
+
+		validity = credential.Expired.Time.Sub(credential.Issued.Time)
+	}
+
+	issued := time.Now().UTC()
+
+	refreshed := *credential
+	refreshed.ID = uuid.New().URN()
+	refreshed.Issued = util.NewTime(issued)
+	refreshed.Expired = util.NewTime(issued.Add(validity))
+	refreshed.Status = nil
+	refreshed.Proofs = nil
+	refreshed.RefreshService = nil
+	refreshed.Context = nil
+
+	// the status and signature contexts are added again as the profile issues the refreshed credential
+	for _, c := range credential.Context {
+		if c != cslstatus.Context && !vcutil.IsSignatureTypeContext(c) {
+			refreshed.Context = append(refreshed.Context, c)
+		}
+	}
+
+	return &refreshed, nil
+}
+
+// revokeRecord revokes the credential issued by the profile.
+func (o *Operation) revokeRecord(profile *vcprofile.IssuerProfile, record *registry.Record) error {
+	err := o.vcStatusManager.UpdateVC(&verifiable.Credential{ID: record.ID, Status: record.Status},
+		profile.DataProfile, true)
+	if err != nil {
+		return err
+	}
+
+	updated := time.Now().UTC()
+	record.Revoked = true
+	record.Suspended = false
+	record.StatusUpdated = &updated
+
+	if err = o.registry.Save(record); err != nil {
+		return err
+	}
+
+	o.webhooks.Notify(profile.Name, webhook.CredentialRevoked, record)
+
+	return nil
+}
+
+// validateRefreshPolicy checks the refresh policy, the credentials it refreshes must comply with the issuance policy.
+func validateRefreshPolicy(policy *vcprofile.RefreshPolicy, issuancePolicy *vcprofile.IssuancePolicy) error {
+	if policy == nil {
+		return nil
+	}
+
+	if policy.ValidityPeriod < 0 {
+		return errors.New("invalid validity period of refresh policy")
+	}
+
+	if policy.RefreshWindow < 0 {
+		return errors.New("invalid refresh window of refresh policy")
+	}
+
+	if issuancePolicy != nil && issuancePolicy.MaxValidityPeriod != 0 &&
+		policy.ValidityPeriod > issuancePolicy.MaxValidityPeriod {
+		return fmt.Errorf("validity period of refresh policy exceeds the maximum validity period of %d seconds "+
+			"of issuance policy", issuancePolicy.MaxValidityPeriod)
+	}
+
+	return nil
+}
@@ -0,0 +1,379 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/stretchr/testify/require"
+
+	challengestore "github.com/trustbloc/edge-service/pkg/challenge"
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+)
+
+func TestRefreshCredential(t *testing.T) {
+	w := newMockWallet(t)
+	op, keyID := newWalletTestOperation(t, w)
+
+	profile := getTestProfile()
+	profile.Creator = "did:test:abc#" + keyID
+	profile.RefreshPolicy = &vcprofile.RefreshPolicy{RevokeRefreshed: true}
+	saveTestProfile(t, op, profile)
+
+	issuer := testHostURL + "/test"
+
+	issueExpiring := func(t *testing.T, validity time.Duration) []byte {
+		t.Helper()
+
+		issued := time.Now().UTC()
+		expired := issued.Add(validity)
+
+		reqBytes, err := json.Marshal(&ComposeCredentialRequest{
+			Subject:        testWalletDID,
+			IssuanceDate:   &issued,
+			ExpirationDate: &expired,
+		})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, composeAndIssueCredentialPath, http.MethodPost),
+			"/test/credentials/composeAndIssueCredential", reqBytes, map[string]string{profileIDPathParam: "test"})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		return rr.Body.Bytes()
+	}
+
+	refresh := func(t *testing.T, profileID string, vp []byte) (int, []byte) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(&RefreshCredentialRequest{Presentation: vp})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, refreshCredentialPath, http.MethodPost),
+			"/"+profileID+"/credentials/refresh", reqBytes, map[string]string{profileIDPathParam: profileID})
+
+		return rr.Code, rr.Body.Bytes()
+	}
+
+	issueChallenge := func(t *testing.T) string {
+		t.Helper()
+
+		rr := serveHTTPMux(t, getHandler(t, op, refreshChallengesPath, http.MethodPost),
+			"/test/credentials/refresh/challenges", nil, map[string]string{profileIDPathParam: "test"})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		c := &challengestore.Challenge{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), c))
+		require.Equal(t, issuer, c.Domain)
+
+		return c.Challenge
+	}
+
+	t.Run("refresh - success", func(t *testing.T) {
+		vcBytes := issueExpiring(t, time.Hour)
+
+		vc := parseTestCredential(t, op, vcBytes)
+		require.Len(t, vc.RefreshService, 1)
+		require.Equal(t, issuer+"/credentials/refresh", vc.RefreshService[0].ID)
+		require.Equal(t, refreshServiceType, vc.RefreshService[0].Type)
+
+		challenge := issueChallenge(t)
+
+		code, body := refresh(t, "test", w.presentation(t, op, vcBytes, issuer, challenge, time.Now()))
+		require.Equal(t, http.StatusCreated, code, string(body))
+
+		refreshed := parseTestCredential(t, op, body)
+		require.NotEqual(t, vc.ID, refreshed.ID)
+		require.Equal(t, vc.Context, refreshed.Context)
+		require.Equal(t, vc.RefreshService, refreshed.RefreshService)
+		require.WithinDuration(t, time.Now().Add(time.Hour), refreshed.Expired.Time, time.Minute)
+
+		subjectID, err := verifiable.SubjectID(refreshed.Subject)
+		require.NoError(t, err)
+		require.Equal(t, testWalletDID, subjectID)
+
+		record, err := op.registry.Get("test", vc.ID)
+		require.NoError(t, err)
+		require.True(t, record.Revoked)
+		require.Equal(t, refreshed.ID, record.RefreshedBy)
+		require.NotNil(t, record.RefreshedAt)
+
+		// the challenge is used up
+		code, body = refresh(t, "test", w.presentation(t, op, issueExpiring(t, time.Hour), issuer, challenge,
+			time.Now()))
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "invalid presentation proof challenge")
+
+		// the revoked credential can't be refreshed again
+		code, body = refresh(t, "test", w.presentation(t, op, vcBytes, issuer, issueChallenge(t), time.Now()))
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "is revoked")
+	})
+
+	t.Run("refresh - refreshed already without revoking", func(t *testing.T) {
+		kept, err := op.profileStore.GetProfile("test")
+		require.NoError(t, err)
+
+		kept.RefreshPolicy = &vcprofile.RefreshPolicy{}
+		require.NoError(t, op.profileStore.SaveProfile(kept))
+
+		defer func() {
+			kept.RefreshPolicy = &vcprofile.RefreshPolicy{RevokeRefreshed: true}
+			require.NoError(t, op.profileStore.SaveProfile(kept))
+		}()
+
+		vcBytes := issueExpiring(t, time.Hour)
+
+		code, body := refresh(t, "test", w.presentation(t, op, vcBytes, issuer, issueChallenge(t), time.Now()))
+		require.Equal(t, http.StatusCreated, code, string(body))
+
+		refreshed := parseTestCredential(t, op, body)
+
+		code, body = refresh(t, "test", w.presentation(t, op, vcBytes, issuer, issueChallenge(t), time.Now()))
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "is already refreshed by "+refreshed.ID)
+	})
+
+	t.Run("refresh - suspended credential", func(t *testing.T) {
+		vcBytes := issueExpiring(t, time.Hour)
+		vc := parseTestCredential(t, op, vcBytes)
+
+		record, err := op.registry.Get("test", vc.ID)
+		require.NoError(t, err)
+
+		record.Suspended = true
+		require.NoError(t, op.registry.Save(record))
+
+		challenge := issueChallenge(t)
+
+		code, body := refresh(t, "test", w.presentation(t, op, vcBytes, issuer, challenge, time.Now()))
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "is suspended")
+
+		// the challenge is left usable once the credential is reinstated
+		record.Suspended = false
+		require.NoError(t, op.registry.Save(record))
+
+		code, body = refresh(t, "test", w.presentation(t, op, vcBytes, issuer, challenge, time.Now()))
+		require.Equal(t, http.StatusCreated, code, string(body))
+	})
+
+	t.Run("refresh - credential being refreshed by another replica", func(t *testing.T) {
+		vcBytes := issueExpiring(t, time.Hour)
+		vc := parseTestCredential(t, op, vcBytes)
+
+		held, err := op.claims.Claim("refresh_test_"+vc.ID, time.Minute)
+		require.NoError(t, err)
+
+		challenge := issueChallenge(t)
+
+		code, body := refresh(t, "test", w.presentation(t, op, vcBytes, issuer, challenge, time.Now()))
+		require.Equal(t, http.StatusConflict, code)
+		require.Contains(t, string(body), "credential is being refreshed")
+
+		require.NoError(t, op.claims.Release(held))
+
+		code, body = refresh(t, "test", w.presentation(t, op, vcBytes, issuer, challenge, time.Now()))
+		require.Equal(t, http.StatusCreated, code, string(body))
+	})
+
+	t.Run("refresh - credential not about to expire", func(t *testing.T) {
+		vcBytes := issueExpiring(t, 48*time.Hour)
+
+		code, body := refresh(t, "test", w.presentation(t, op, vcBytes, issuer, issueChallenge(t), time.Now()))
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "can't be refreshed before")
+	})
+
+	t.Run("refresh - invalid presentation", func(t *testing.T) {
+		vcBytes := issueExpiring(t, time.Hour)
+		challenge := issueChallenge(t)
+
+		tests := []struct {
+			name string
+			vp   []byte
+			err  string
+		}{
+			{
+				name: "wrong domain",
+				vp:   w.presentation(t, op, vcBytes, "https://other.example.com", challenge, time.Now()),
+				err:  "domain isn't the issuer profile",
+			},
+			{
+				name: "no challenge",
+				vp:   w.presentation(t, op, vcBytes, issuer, "", time.Now()),
+				err:  "must sign a challenge issued by the issuer profile",
+			},
+			{
+				name: "unknown challenge",
+				vp:   w.presentation(t, op, vcBytes, issuer, "challenge", time.Now()),
+				err:  "invalid presentation proof challenge: unknown challenge",
+			},
+			{
+				name: "stale proof",
+				vp:   w.presentation(t, op, vcBytes, issuer, challenge, time.Now().Add(-time.Hour)),
+				err:  "isn't created recently",
+			},
+			{
+				name: "signed by another key",
+				vp:   newMockWallet(t).presentation(t, op, vcBytes, issuer, challenge, time.Now()),
+				err:  "failed to verify presentation",
+			},
+			{
+				name: "no proof",
+				vp:   []byte(`{"@context":["https://www.w3.org/2018/credentials/v1"],"type":"VerifiablePresentation"}`),
+				err:  "must have a DID authentication proof",
+			},
+		}
+
+		for _, tc := range tests {
+			code, body := refresh(t, "test", tc.vp)
+			require.Equal(t, http.StatusBadRequest, code, tc.name)
+			require.Contains(t, string(body), tc.err, tc.name)
+		}
+
+		// the rejected presentations leave the challenge usable
+		code, body := refresh(t, "test", w.presentation(t, op, vcBytes, issuer, challenge, time.Now()))
+		require.Equal(t, http.StatusCreated, code, string(body))
+	})
+
+	t.Run("refresh - violates the issuance policy", func(t *testing.T) {
+		vcBytes := issueExpiring(t, time.Hour)
+
+		restricted, err := op.profileStore.GetProfile("test")
+		require.NoError(t, err)
+
+		restricted.IssuancePolicy = &vcprofile.IssuancePolicy{RequiredClaims: []string{"name"}}
+		require.NoError(t, op.profileStore.SaveProfile(restricted))
+
+		defer func() {
+			restricted.IssuancePolicy = nil
+			require.NoError(t, op.profileStore.SaveProfile(restricted))
+		}()
+
+		challenge := issueChallenge(t)
+
+		code, body := refresh(t, "test", w.presentation(t, op, vcBytes, issuer, challenge, time.Now()))
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "credential violates issuance policy of profile test")
+	})
+
+	t.Run("refresh - disabled", func(t *testing.T) {
+		disabled := getTestProfile()
+		disabled.Name = "disabled"
+		saveTestProfile(t, op, disabled)
+
+		code, body := refresh(t, "disabled", nil)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "refresh service is disabled for profile disabled")
+
+		rr := serveHTTPMux(t, getHandler(t, op, refreshChallengesPath, http.MethodPost),
+			"/disabled/credentials/refresh/challenges", nil, map[string]string{profileIDPathParam: "disabled"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "refresh service is disabled for profile disabled")
+	})
+
+	t.Run("refresh - invalid profile", func(t *testing.T) {
+		code, body := refresh(t, "unknown", nil)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "invalid issuer profile")
+	})
+
+	t.Run("update profile - invalid refresh policy", func(t *testing.T) {
+		reqBytes, err := json.Marshal(&UpdateProfileRequest{
+			RefreshPolicy: &vcprofile.RefreshPolicy{ValidityPeriod: -1},
+		})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, getProfileEndpoint, http.MethodPatch), "/profile/test", reqBytes,
+			map[string]string{"id": "test"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid validity period of refresh policy")
+
+		reqBytes, err = json.Marshal(&UpdateProfileRequest{
+			RefreshPolicy: &vcprofile.RefreshPolicy{RefreshWindow: -1},
+		})
+		require.NoError(t, err)
+
+		rr = serveHTTPMux(t, getHandler(t, op, getProfileEndpoint, http.MethodPatch), "/profile/test", reqBytes,
+			map[string]string{"id": "test"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid refresh window of refresh policy")
+
+		reqBytes, err = json.Marshal(&UpdateProfileRequest{
+			RefreshPolicy:  &vcprofile.RefreshPolicy{ValidityPeriod: 7200},
+			IssuancePolicy: &vcprofile.IssuancePolicy{MaxValidityPeriod: 3600},
+		})
+		require.NoError(t, err)
+
+		rr = serveHTTPMux(t, getHandler(t, op, getProfileEndpoint, http.MethodPatch), "/profile/test", reqBytes,
+			map[string]string{"id": "test"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "validity period of refresh policy exceeds the maximum validity period")
+	})
+
+	t.Run("issue - no refresh service for credentials that don't expire", func(t *testing.T) {
+		issued := time.Now().UTC()
+
+		reqBytes, err := json.Marshal(&ComposeCredentialRequest{Subject: testWalletDID, IssuanceDate: &issued})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, composeAndIssueCredentialPath, http.MethodPost),
+			"/test/credentials/composeAndIssueCredential", reqBytes, map[string]string{profileIDPathParam: "test"})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+		require.Empty(t, parseTestCredential(t, op, rr.Body.Bytes()).RefreshService)
+	})
+}
+
+// presentation presents the credential with a DID authentication proof over the challenge for the domain.
+func (w *mockWallet) presentation(t *testing.T, op *Operation, vcBytes []byte, domain, challenge string,
+	created time.Time) []byte {
+	t.Helper()
+
+	vc, err := verifiable.ParseCredential(vcBytes, verifiable.WithDisabledProofCheck(),
+		verifiable.WithJSONLDDocumentLoader(op.documentLoader))
+	require.NoError(t, err)
+
+	vp, err := verifiable.NewPresentation(verifiable.WithCredentials(vc))
+	require.NoError(t, err)
+
+	vp.Holder = testWalletDID
+
+	err = vp.AddLinkedDataProof(&verifiable.LinkedDataProofContext{
+		SignatureType:           "Ed25519Signature2018",
+		Suite:                   ed25519signature2018.New(suite.WithSigner(&walletSigner{privKey: w.privKey})),
+		SignatureRepresentation: verifiable.SignatureJWS,
+		Created:                 &created,
+		VerificationMethod:      testWalletDID + "#key-1",
+		Domain:                  domain,
+		Challenge:               challenge,
+		Purpose:                 authentication,
+	}, jsonld.WithDocumentLoader(op.documentLoader))
+	require.NoError(t, err)
+
+	vpBytes, err := vp.MarshalJSON()
+	require.NoError(t, err)
+
+	return vpBytes
+}
+
+func parseTestCredential(t *testing.T, op *Operation, vcBytes []byte) *verifiable.Credential {
+	t.Helper()
+
+	vc, err := verifiable.ParseCredential(vcBytes,
+		verifiable.WithPublicKeyFetcher(verifiable.NewVDRKeyResolver(op.vdr).PublicKeyFetcher()),
+		verifiable.WithJSONLDDocumentLoader(op.documentLoader))
+	require.NoError(t, err)
+
+	return vc
+}
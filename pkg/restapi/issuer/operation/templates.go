@@ -0,0 +1,252 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/xeipuuv/gojsonschema"
+
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+)
+
+const (
+	templateIDPathParam         = "templateID"
+	credentialTemplatesEndpoint = getProfileEndpoint + "/templates"
+	credentialTemplateEndpoint  = credentialTemplatesEndpoint + "/{" + templateIDPathParam + "}"
+)
+
+// AddCredentialTemplate swagger:route POST /profile/{id}/templates issuer credentialTemplateReq
+//
+// Adds a credential template to the issuer profile, replacing the template with the same ID.
+//
+// Responses:
+//    default: genericError
+//        201: credentialTemplateRes
+func (o *Operation) addCredentialTemplateHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)["id"]
+
+	profile, err := o.getActiveProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	template := &vcprofile.CredentialTemplate{}
+
+	if err = json.NewDecoder(req.Body).Decode(template); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	if err = validateCredentialTemplate(template); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	templates := []*vcprofile.CredentialTemplate{template}
+
+	for _, t := range profile.CredentialTemplates {
+		if t.ID != template.ID {
+			templates = append(templates, t)
+		}
+	}
+
+	profile.CredentialTemplates = templates
+
+	if err = o.profileStore.SaveProfile(profile); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to save issuer profile: %s", err.Error()))
+
+		return
+	}
+
+	o.notifyProfileChanged(profile.Name, profileTemplateAdded)
+
+	rw.WriteHeader(http.StatusCreated)
+	commhttp.WriteResponse(rw, template)
+}
+
+// DeleteCredentialTemplate swagger:route DELETE /profile/{id}/templates/{templateID} issuer deleteCredentialTemplateReq
+//
+// Deletes a credential template of the issuer profile.
+//
+// Responses:
+//    default: genericError
+//        200: emptyRes
+func (o *Operation) deleteCredentialTemplateHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)["id"]
+	templateID := mux.Vars(req)[templateIDPathParam]
+
+	profile, err := o.getActiveProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	if profile.CredentialTemplate(templateID) == nil {
+		commhttp.WriteErrorResponse(rw, http.StatusNotFound,
+			fmt.Sprintf("credential template %s not found", templateID))
+
+		return
+	}
+
+	var templates []*vcprofile.CredentialTemplate
+
+	for _, t := range profile.CredentialTemplates {
+		if t.ID != templateID {
+			templates = append(templates, t)
+		}
+	}
+
+	profile.CredentialTemplates = templates
+
+	if err = o.profileStore.SaveProfile(profile); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to save issuer profile: %s", err.Error()))
+
+		return
+	}
+
+	o.notifyProfileChanged(profile.Name, profileTemplateDeleted)
+}
+
+func validateCredentialTemplates(templates []*vcprofile.CredentialTemplate) error {
+	ids := make(map[string]bool)
+
+	for _, template := range templates {
+		if err := validateCredentialTemplate(template); err != nil {
+			return err
+		}
+
+		if ids[template.ID] {
+			return fmt.Errorf("duplicate credential template %s", template.ID)
+		}
+
+		ids[template.ID] = true
+	}
+
+	return nil
+}
+
+func validateCredentialTemplate(template *vcprofile.CredentialTemplate) error {
+	if template.ID == "" {
+		return errors.New("missing credential template id")
+	}
+
+	if len(template.Types) == 0 {
+		return fmt.Errorf("missing types of credential template %s", template.ID)
+	}
+
+	if template.ValidityPeriod < 0 {
+		return fmt.Errorf("invalid validity period of credential template %s", template.ID)
+	}
+
+	if len(template.ClaimsSchema) != 0 {
+		_, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(template.ClaimsSchema))
+		if err != nil {
+			return fmt.Errorf("invalid claims schema of credential template %s: %w", template.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// buildCredentialFromTemplate builds the credential from the profile's template the request refers to.
+// The template fixes the credential's contexts, types, validity period and terms of use, the request only brings
+// the subject, its claims and the evidence.
+func buildCredentialFromTemplate(profile *vcprofile.IssuerProfile,
+	composeCredReq *ComposeCredentialRequest) (*verifiable.Credential, error) {
+	template := profile.CredentialTemplate(composeCredReq.TemplateID)
+	if template == nil {
+		return nil, fmt.Errorf("credential template %s not found", composeCredReq.TemplateID)
+	}
+
+	if len(composeCredReq.Types) != 0 || len(composeCredReq.TermsOfUse) != 0 ||
+		len(composeCredReq.CredentialFormatOptions) != 0 || composeCredReq.ExpirationDate != nil {
+		return nil, fmt.Errorf("types, contexts, expiration date and terms of use are set by credential template %s",
+			template.ID)
+	}
+
+	if err := validateClaims(template, composeCredReq.Claims); err != nil {
+		return nil, err
+	}
+
+	issued := time.Now().UTC()
+	if composeCredReq.IssuanceDate != nil {
+		issued = *composeCredReq.IssuanceDate
+	}
+
+	req := &ComposeCredentialRequest{
+		Issuer:       composeCredReq.Issuer,
+		Subject:      composeCredReq.Subject,
+		Types:        template.Types,
+		IssuanceDate: &issued,
+		Claims:       composeCredReq.Claims,
+		Evidence:     composeCredReq.Evidence,
+		TermsOfUse:   template.TermsOfUse,
+	}
+
+	if template.ValidityPeriod > 0 {
+		expired := issued.Add(time.Duration(template.ValidityPeriod) * time.Second)
+		req.ExpirationDate = &expired
+	}
+
+	if len(template.Contexts) != 0 {
+		contexts, err := json.Marshal(map[string]interface{}{"@context": template.Contexts})
+		if err != nil {
+			return nil, err
+		}
+
+		req.CredentialFormatOptions = contexts
+	}
+
+	return buildCredential(req)
+}
+
+func validateClaims(template *vcprofile.CredentialTemplate, claims json.RawMessage) error {
+	if len(template.ClaimsSchema) == 0 {
+		return nil
+	}
+
+	if len(claims) == 0 {
+		claims = json.RawMessage("{}")
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(template.ClaimsSchema),
+		gojsonschema.NewBytesLoader(claims))
+	if err != nil {
+		return fmt.Errorf("validate claims: %w", err)
+	}
+
+	if !result.Valid() {
+		var errs []string
+
+		for _, e := range result.Errors() {
+			errs = append(errs, e.String())
+		}
+
+		return fmt.Errorf("claims don't match the schema of credential template %s: %s", template.ID,
+			strings.Join(errs, "; "))
+	}
+
+	return nil
+}
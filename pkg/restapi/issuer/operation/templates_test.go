@@ -0,0 +1,230 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+const degreeClaimsSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string"},
+		"degree": {"type": "object"}
+	},
+	"required": ["name", "degree"]
+}`
+
+func TestCredentialTemplates(t *testing.T) {
+	customKMS := createKMS(t)
+
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	keyID, pubKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ED25519Type)
+	require.NoError(t, err)
+
+	op, err := New(&Config{
+		StoreProvider:      ariesmemstorage.NewProvider(),
+		KMSSecretsProvider: ariesmemstorage.NewProvider(),
+		KeyManager:         customKMS,
+		Crypto:             customCrypto,
+		VDRI: &vdrmock.MockVDRegistry{
+			ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+				return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, keyID, pubKey)}, nil
+			},
+		},
+		DocumentLoader: testutil.DocumentLoader(t),
+	})
+	require.NoError(t, err)
+
+	op.vcStatusManager = &mockVCStatusManager{createStatusIDValue: &verifiable.TypedID{ID: "status"}}
+
+	profile := getTestProfile()
+	profile.Creator = "did:test:abc#" + keyID
+	profile.DisableVCStatus = true
+
+	require.NoError(t, op.profileStore.SaveProfile(profile))
+
+	template := &vcprofile.CredentialTemplate{
+		ID: "degree",
+		Contexts: []string{
+			"https://www.w3.org/2018/credentials/v1", "https://www.w3.org/2018/credentials/examples/v1",
+		},
+		Types:          []string{"VerifiableCredential", "UniversityDegreeCredential"},
+		ValidityPeriod: 3600,
+		TermsOfUse:     json.RawMessage(`[{"id":"http://example.com/policies/credential/4","type":"IssuerPolicy"}]`),
+		ClaimsSchema:   json.RawMessage(degreeClaimsSchema),
+	}
+
+	addTemplate := func(t *testing.T, profileID string, template *vcprofile.CredentialTemplate) int {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(template)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, credentialTemplatesEndpoint, http.MethodPost),
+			"/profile/"+profileID+"/templates", reqBytes, map[string]string{"id": profileID})
+
+		return rr.Code
+	}
+
+	compose := func(t *testing.T, req *ComposeCredentialRequest) (int, map[string]interface{}) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, composeAndIssueCredentialPath, http.MethodPost),
+			"/"+profile.Name+"/credentials/composeAndIssueCredential", reqBytes,
+			map[string]string{profileIDPathParam: profile.Name})
+
+		resp := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+		return rr.Code, resp
+	}
+
+	require.Equal(t, http.StatusCreated, addTemplate(t, profile.Name, template))
+
+	t.Run("compose credential from template - success", func(t *testing.T) {
+		issued := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		code, vc := compose(t, &ComposeCredentialRequest{
+			TemplateID:   "degree",
+			Subject:      "did:example:oleh394sqwnlk223823ln",
+			IssuanceDate: &issued,
+			Claims:       json.RawMessage(`{"name":"John Doe","degree":{"type":"BachelorDegree"}}`),
+		})
+		require.Equal(t, http.StatusCreated, code, vc)
+
+		require.Equal(t, []interface{}{
+			"https://www.w3.org/2018/credentials/v1", "https://www.w3.org/2018/credentials/examples/v1",
+		}, vc["@context"])
+		require.Equal(t, []interface{}{"VerifiableCredential", "UniversityDegreeCredential"}, vc["type"])
+		require.Equal(t, "2021-01-01T00:00:00Z", vc["issuanceDate"])
+		require.Equal(t, "2021-01-01T01:00:00Z", vc["expirationDate"])
+		require.NotEmpty(t, vc["termsOfUse"])
+
+		subject, ok := vc["credentialSubject"].(map[string]interface{})
+		require.True(t, ok)
+		require.Equal(t, "did:example:oleh394sqwnlk223823ln", subject["id"])
+		require.Equal(t, "John Doe", subject["name"])
+	})
+
+	t.Run("compose credential from template - claims don't match the schema", func(t *testing.T) {
+		code, resp := compose(t, &ComposeCredentialRequest{
+			TemplateID: "degree",
+			Subject:    "did:example:oleh394sqwnlk223823ln",
+			Claims:     json.RawMessage(`{"name":1}`),
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, resp["errMessage"], "claims don't match the schema of credential template degree")
+	})
+
+	t.Run("compose credential from template - template fields in the request", func(t *testing.T) {
+		code, resp := compose(t, &ComposeCredentialRequest{
+			TemplateID: "degree",
+			Types:      []string{"VerifiableCredential"},
+			Claims:     json.RawMessage(`{"name":"John Doe","degree":{}}`),
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, resp["errMessage"], "are set by credential template degree")
+	})
+
+	t.Run("compose credential from template - unknown template", func(t *testing.T) {
+		code, resp := compose(t, &ComposeCredentialRequest{TemplateID: "unknown"})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, resp["errMessage"], "credential template unknown not found")
+	})
+
+	t.Run("add credential template - replaces the template with the same ID", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, addTemplate(t, profile.Name, &vcprofile.CredentialTemplate{
+			ID: "other", Types: []string{"VerifiableCredential"},
+		}))
+		require.Equal(t, http.StatusCreated, addTemplate(t, profile.Name, &vcprofile.CredentialTemplate{
+			ID: "other", Types: []string{"VerifiableCredential", "OtherCredential"},
+		}))
+
+		saved, err := op.profileStore.GetProfile(profile.Name)
+		require.NoError(t, err)
+		require.Len(t, saved.CredentialTemplates, 2)
+		require.Equal(t, []string{"VerifiableCredential", "OtherCredential"}, saved.CredentialTemplate("other").Types)
+	})
+
+	t.Run("add credential template - invalid template", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, addTemplate(t, profile.Name, &vcprofile.CredentialTemplate{
+			Types: []string{"VerifiableCredential"},
+		}))
+		require.Equal(t, http.StatusBadRequest, addTemplate(t, profile.Name, &vcprofile.CredentialTemplate{
+			ID: "invalid",
+		}))
+		require.Equal(t, http.StatusBadRequest, addTemplate(t, profile.Name, &vcprofile.CredentialTemplate{
+			ID: "invalid", Types: []string{"VerifiableCredential"}, ValidityPeriod: -1,
+		}))
+		require.Equal(t, http.StatusBadRequest, addTemplate(t, profile.Name, &vcprofile.CredentialTemplate{
+			ID: "invalid", Types: []string{"VerifiableCredential"}, ClaimsSchema: json.RawMessage(`{"type":1}`),
+		}))
+	})
+
+	t.Run("add credential template - invalid profile", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, addTemplate(t, "unknown", template))
+	})
+
+	t.Run("delete credential template", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, addTemplate(t, profile.Name, &vcprofile.CredentialTemplate{
+			ID: "deleted", Types: []string{"VerifiableCredential"},
+		}))
+
+		handler := getHandler(t, op, credentialTemplateEndpoint, http.MethodDelete)
+
+		rr := serveHTTPMux(t, handler, "/profile/"+profile.Name+"/templates/deleted", nil,
+			map[string]string{"id": profile.Name, templateIDPathParam: "deleted"})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		saved, err := op.profileStore.GetProfile(profile.Name)
+		require.NoError(t, err)
+		require.Nil(t, saved.CredentialTemplate("deleted"))
+		require.NotNil(t, saved.CredentialTemplate("degree"))
+
+		rr = serveHTTPMux(t, handler, "/profile/"+profile.Name+"/templates/deleted", nil,
+			map[string]string{"id": profile.Name, templateIDPathParam: "deleted"})
+		require.Equal(t, http.StatusNotFound, rr.Code)
+
+		rr = serveHTTPMux(t, handler, "/profile/unknown/templates/deleted", nil,
+			map[string]string{"id": "unknown", templateIDPathParam: "deleted"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestValidateCredentialTemplates(t *testing.T) {
+	require.NoError(t, validateCredentialTemplates([]*vcprofile.CredentialTemplate{
+		{ID: "a", Types: []string{"VerifiableCredential"}},
+		{ID: "b", Types: []string{"VerifiableCredential"}, ClaimsSchema: json.RawMessage(degreeClaimsSchema)},
+	}))
+
+	err := validateCredentialTemplates([]*vcprofile.CredentialTemplate{
+		{ID: "a", Types: []string{"VerifiableCredential"}},
+		{ID: "a", Types: []string{"VerifiableCredential"}},
+	})
+	require.EqualError(t, err, "duplicate credential template a")
+}
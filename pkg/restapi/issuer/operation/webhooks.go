@@ -0,0 +1,204 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/registry"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+	"github.com/trustbloc/edge-service/pkg/webhook"
+)
+
+const (
+	webhookIDPathParam        = "webhookID"
+	webhooksEndpoint          = getProfileEndpoint + "/webhooks"
+	webhookEndpoint           = webhooksEndpoint + "/{" + webhookIDPathParam + "}"
+	webhookDeliveriesEndpoint = webhooksEndpoint + "/deliveries"
+
+	statusPurposeRevocation = "revocation"
+	statusPurposeSuspension = "suspension"
+)
+
+// actions of the profile.changed events
+const (
+	profileUpdated         = "updated"
+	profileReplaced        = "replaced"
+	profileKeysRotated     = "keysRotated"
+	profileTemplateAdded   = "templateAdded"
+	profileTemplateDeleted = "templateDeleted"
+	profileDisabled        = "disabled"
+	profileDeleted         = "deleted"
+)
+
+// CreateWebhook swagger:route POST /profile/{id}/webhooks issuer webhookReq
+//
+// Subscribes a URL to the events of the issuer profile. The payloads are signed with the HMAC-SHA256 of the secret
+// returned, which isn't returned again.
+//
+// Responses:
+//    default: genericError
+//        201: webhookRes
+func (o *Operation) createWebhookHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)["id"]
+
+	profile, err := o.getActiveProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	data := WebhookRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	sub := &webhook.Subscription{Profile: profile.Name, URL: data.URL, Events: data.Events}
+
+	if err = o.webhooks.Subscribe(sub); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("failed to subscribe webhook: %s",
+			err.Error()))
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusCreated)
+	commhttp.WriteResponse(rw, sub)
+}
+
+// ListWebhooks swagger:route GET /profile/{id}/webhooks issuer listWebhooksReq
+//
+// Lists the webhooks subscribed to the events of the issuer profile.
+//
+// Responses:
+//    default: genericError
+//        200: listWebhooksRes
+func (o *Operation) listWebhooksHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)["id"]
+
+	if _, err := o.profileStore.GetProfile(profileID); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	subs, err := o.webhooks.Subscriptions(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	webhooks := make([]*webhook.Subscription, 0, len(subs))
+
+	for _, sub := range subs {
+		sub.Secret = ""
+		webhooks = append(webhooks, sub)
+	}
+
+	commhttp.WriteResponse(rw, webhooks)
+}
+
+// DeleteWebhook swagger:route DELETE /profile/{id}/webhooks/{webhookID} issuer deleteWebhookReq
+//
+// Unsubscribes a webhook from the events of the issuer profile.
+//
+// Responses:
+//    default: genericError
+//        200: emptyRes
+func (o *Operation) deleteWebhookHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)["id"]
+	webhookID := mux.Vars(req)[webhookIDPathParam]
+
+	if _, err := o.profileStore.GetProfile(profileID); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	if err := o.webhooks.Unsubscribe(profileID, webhookID); err != nil {
+		if errors.Is(err, ariesstorage.ErrDataNotFound) {
+			commhttp.WriteErrorResponse(rw, http.StatusNotFound, fmt.Sprintf("webhook %s not found", webhookID))
+
+			return
+		}
+
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to unsubscribe webhook: %s", err.Error()))
+
+		return
+	}
+}
+
+// ListWebhookDeliveries swagger:route GET /profile/{id}/webhooks/deliveries issuer listWebhookDeliveriesReq
+//
+// Lists the deliveries of the events of the issuer profile to its webhooks, newest first.
+//
+// Responses:
+//    default: genericError
+//        200: listWebhookDeliveriesRes
+func (o *Operation) listWebhookDeliveriesHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)["id"]
+
+	if _, err := o.profileStore.GetProfile(profileID); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	offset, limit, err := commhttp.ParsePage(req)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	deliveries, total, err := o.webhooks.Deliveries(profileID, offset, limit)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, &ListWebhookDeliveriesResponse{Deliveries: deliveries, Total: total})
+}
+
+// notifyProfileChanged notifies the webhooks of the profile that it changed.
+func (o *Operation) notifyProfileChanged(profile, action string) {
+	o.webhooks.Notify(profile, webhook.ProfileChanged, &webhook.ProfileChange{Action: action})
+}
+
+// statusRecord updates the record of the credential with its new status and returns the event notifying it.
+func statusRecord(record *registry.Record, status bool, purpose string) string {
+	suspension := purpose == statusPurposeSuspension
+
+	record.Revoked = status && !suspension
+	record.Suspended = status && suspension
+
+	switch {
+	case record.Revoked:
+		return webhook.CredentialRevoked
+	case record.Suspended:
+		return webhook.CredentialSuspended
+	default:
+		return webhook.CredentialReinstated
+	}
+}
@@ -0,0 +1,251 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/registry"
+	"github.com/trustbloc/edge-service/pkg/webhook"
+)
+
+func TestWebhooks(t *testing.T) {
+	var (
+		mutex  sync.Mutex
+		events []*webhook.Event
+	)
+
+	var secret string
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		payload, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.Equal(t, webhook.Sign(secret, payload), req.Header.Get(webhook.SignatureHeader))
+
+		event := &webhook.Event{}
+		require.NoError(t, json.Unmarshal(payload, event))
+
+		events = append(events, event)
+	}))
+	defer server.Close()
+
+	// takeEvents returns the events received since the last call
+	takeEvents := func() []*webhook.Event {
+		t.Helper()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		taken := events
+		events = nil
+
+		return taken
+	}
+
+	w := newMockWallet(t)
+	op, keyID := newWalletTestOperation(t, w)
+
+	profile := getTestProfile()
+	profile.Creator = "did:test:abc#" + keyID
+	saveTestProfile(t, op, profile)
+
+	subscribe := func(t *testing.T, profileID string, data *WebhookRequest) *httptest.ResponseRecorder {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(data)
+		require.NoError(t, err)
+
+		return serveHTTPMux(t, getHandler(t, op, webhooksEndpoint, http.MethodPost),
+			"/profile/"+profileID+"/webhooks", reqBytes, map[string]string{"id": profileID})
+	}
+
+	updateStatus := func(t *testing.T, vcID, status, purpose string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(&UpdateCredentialStatusRequest{
+			CredentialID: vcID,
+			CredentialStatus: CredentialStatus{
+				Type: "RevocationList2020Status", Status: status, StatusPurpose: purpose,
+			},
+		})
+		require.NoError(t, err)
+
+		return serveHTTPMux(t, getHandler(t, op, updateCredentialStatusEndpoint, http.MethodPost),
+			"/test/credentials/status", reqBytes, map[string]string{profileIDPathParam: "test"})
+	}
+
+	rr := subscribe(t, "test", &WebhookRequest{URL: server.URL})
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	sub := &webhook.Subscription{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), sub))
+	require.NotEmpty(t, sub.Secret)
+
+	secret = sub.Secret
+
+	t.Run("credential events", func(t *testing.T) {
+		issued := time.Now().UTC()
+
+		reqBytes, err := json.Marshal(&ComposeCredentialRequest{Subject: testWalletDID, IssuanceDate: &issued})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, composeAndIssueCredentialPath, http.MethodPost),
+			"/test/credentials/composeAndIssueCredential", reqBytes, map[string]string{profileIDPathParam: "test"})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		vc := parseTestCredential(t, op, rr.Body.Bytes())
+
+		op.webhooks.Wait()
+
+		received := takeEvents()
+		require.Len(t, received, 1)
+		require.Equal(t, webhook.CredentialIssued, received[0].Type)
+		require.Equal(t, "test", received[0].Profile)
+
+		for _, tc := range []struct {
+			status, purpose, event string
+			revoked, suspended     bool
+		}{
+			{status: "true", purpose: "suspension", event: webhook.CredentialSuspended, suspended: true},
+			{status: "false", event: webhook.CredentialReinstated},
+			{status: "true", purpose: "revocation", event: webhook.CredentialRevoked, revoked: true},
+		} {
+			rr = updateStatus(t, vc.ID, tc.status, tc.purpose)
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+			op.webhooks.Wait()
+
+			received = takeEvents()
+			require.Len(t, received, 1)
+			require.Equal(t, tc.event, received[0].Type)
+
+			dataBytes, err := json.Marshal(received[0].Data)
+			require.NoError(t, err)
+
+			record := &registry.Record{}
+			require.NoError(t, json.Unmarshal(dataBytes, record))
+			require.Equal(t, vc.ID, record.ID)
+			require.Equal(t, tc.revoked, record.Revoked)
+			require.Equal(t, tc.suspended, record.Suspended)
+		}
+
+		rr = updateStatus(t, vc.ID, "true", "expiry")
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "status purpose expiry not supported")
+	})
+
+	t.Run("profile changed event", func(t *testing.T) {
+		reqBytes, err := json.Marshal(&UpdateProfileRequest{})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, getProfileEndpoint, http.MethodPatch), "/profile/test", reqBytes,
+			map[string]string{"id": "test"})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		op.webhooks.Wait()
+
+		received := takeEvents()
+		require.Len(t, received, 1)
+		require.Equal(t, webhook.ProfileChanged, received[0].Type)
+		require.Equal(t, map[string]interface{}{"action": profileUpdated}, received[0].Data)
+	})
+
+	t.Run("list webhooks and deliveries", func(t *testing.T) {
+		rr := serveHTTPMux(t, getHandler(t, op, webhooksEndpoint, http.MethodGet), "/profile/test/webhooks", nil,
+			map[string]string{"id": "test"})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var subs []*webhook.Subscription
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &subs))
+		require.Len(t, subs, 1)
+		require.Equal(t, sub.ID, subs[0].ID)
+		require.Empty(t, subs[0].Secret)
+
+		rr = serveHTTPMux(t, getHandler(t, op, webhookDeliveriesEndpoint, http.MethodGet),
+			"/profile/test/webhooks/deliveries?limit=2", nil, map[string]string{"id": "test"})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		resp := &ListWebhookDeliveriesResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, 5, resp.Total)
+		require.Len(t, resp.Deliveries, 2)
+		require.Equal(t, webhook.ProfileChanged, resp.Deliveries[0].EventType)
+		require.True(t, resp.Deliveries[0].Delivered)
+
+		rr = serveHTTPMux(t, getHandler(t, op, webhookDeliveriesEndpoint, http.MethodGet),
+			"/profile/test/webhooks/deliveries?limit=0", nil, map[string]string{"id": "test"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("subscribe - invalid request", func(t *testing.T) {
+		rr := subscribe(t, "test", &WebhookRequest{URL: "not a url"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid webhook url")
+
+		rr = subscribe(t, "test", &WebhookRequest{URL: server.URL, Events: []string{"credential.deleted"}})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "unknown event type credential.deleted")
+
+		rr = subscribe(t, "unknown", &WebhookRequest{URL: server.URL})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid issuer profile")
+	})
+
+	t.Run("delete webhook", func(t *testing.T) {
+		deleteWebhook := func(webhookID string) *httptest.ResponseRecorder {
+			return serveHTTPMux(t, getHandler(t, op, webhookEndpoint, http.MethodDelete),
+				"/profile/test/webhooks/"+webhookID, nil, map[string]string{"id": "test", webhookIDPathParam: webhookID})
+		}
+
+		rr := deleteWebhook(sub.ID)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		rr = deleteWebhook(sub.ID)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+
+		subs, err := op.webhooks.Subscriptions("test")
+		require.NoError(t, err)
+		require.Empty(t, subs)
+	})
+
+	t.Run("profile deletion deletes its webhooks", func(t *testing.T) {
+		rr := subscribe(t, "test", &WebhookRequest{URL: server.URL})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		sub := &webhook.Subscription{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), sub))
+
+		mutex.Lock()
+		secret = sub.Secret
+		mutex.Unlock()
+
+		rr = serveHTTPMux(t, getHandler(t, op, deleteProfileEndpoint, http.MethodDelete), "/profile/test", nil,
+			map[string]string{"id": "test"})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		subs, err := op.webhooks.Subscriptions("test")
+		require.NoError(t, err)
+		require.Empty(t, subs)
+
+		op.webhooks.Wait()
+
+		received := takeEvents()
+		require.Len(t, received, 1)
+		require.Equal(t, map[string]interface{}{"action": profileDeleted}, received[0].Data)
+	})
+}
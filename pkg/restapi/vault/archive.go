@@ -0,0 +1,197 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+
+	ariescrypto "github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	edv "github.com/trustbloc/edv/pkg/client"
+	"github.com/trustbloc/edv/pkg/restapi/models"
+)
+
+// Archive is a portable export of a vault's documents.
+type Archive struct {
+	VaultID   string              `json:"vaultID"`
+	Documents []*ArchivedDocument `json:"documents"`
+}
+
+// ArchivedDocument is a vault document encrypted to the archive's recipient key.
+type ArchivedDocument struct {
+	ID  string          `json:"id"`
+	JWE json.RawMessage `json:"jwe"`
+}
+
+// archivedContent is the plaintext of an archived document.
+type archivedContent struct {
+	Content json.RawMessage    `json:"content"`
+	Indexed []IndexedAttribute `json:"indexed,omitempty"`
+}
+
+// storedDocument is the plaintext of a document stored in the EDV.
+type storedDocument struct {
+	Meta struct {
+		Indexed []IndexedAttribute `json:"indexed"`
+	} `json:"meta"`
+	Content json.RawMessage `json:"content"`
+}
+
+// CreateRecipientKey creates a new encryption key in the vault's KMS keystore.
+// Archives encrypted to this key can be imported into the vault.
+func (c *Client) CreateRecipientKey(vaultID string) (*ariescrypto.PublicKey, error) {
+	info, err := c.getVaultInfo(vaultID)
+	if err != nil {
+		return nil, fmt.Errorf("get vault info: %w", err)
+	}
+
+	_, pubKey, err := newEncryptionKey(c.keyManager(info.DidURL, info.Auth.KMS))
+	if err != nil {
+		return nil, fmt.Errorf("new encryption key: %w", err)
+	}
+
+	return pubKey, nil
+}
+
+// ExportDocs decrypts every document stored in the vault, re-encrypts it to the given recipient key
+// and passes it to fn one by one. An export stops at the first error returned by fn.
+func (c *Client) ExportDocs(vaultID string, recipient *ariescrypto.PublicKey,
+	fn func(*ArchivedDocument) error) error {
+	info, err := c.getVaultInfo(vaultID)
+	if err != nil {
+		return fmt.Errorf("get vault info: %w", err)
+	}
+
+	encrypter, err := jose.NewJWEEncrypt(jose.A256GCM, jose.A256GCMALG, "", "", nil,
+		[]*ariescrypto.PublicKey{recipient}, c.crypto)
+	if err != nil {
+		return fmt.Errorf("new JWE encrypt: %w", err)
+	}
+
+	docIDs, err := c.getDocIDs(vaultID, info)
+	if err != nil {
+		return fmt.Errorf("get doc IDs: %w", err)
+	}
+
+	decrypter := jose.NewJWEDecrypt(nil, c.keyCrypto(info.DidURL, info.Auth.KMS), c.keyManager(info.DidURL, info.Auth.KMS))
+
+	for _, docID := range docIDs {
+		content, err := c.readDocContent(vaultID, docID, info, decrypter)
+		if err != nil {
+			return fmt.Errorf("read doc %s: %w", docID, err)
+		}
+
+		src, err := json.Marshal(content)
+		if err != nil {
+			return fmt.Errorf("marshal: %w", err)
+		}
+
+		jwe, err := encrypter.Encrypt(src)
+		if err != nil {
+			return fmt.Errorf("encrypt: %w", err)
+		}
+
+		eContent, err := jwe.FullSerialize(json.Marshal)
+		if err != nil {
+			return fmt.Errorf("full serialize: %w", err)
+		}
+
+		err = fn(&ArchivedDocument{ID: docID, JWE: json.RawMessage(eContent)})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ImportDocs decrypts the documents of an archive with the vault's keys and saves them into the vault.
+// The archive must be encrypted to a key created by CreateRecipientKey for the same vault.
+func (c *Client) ImportDocs(vaultID string, archive *Archive) ([]*DocumentMetadata, error) {
+	info, err := c.getVaultInfo(vaultID)
+	if err != nil {
+		return nil, fmt.Errorf("get vault info: %w", err)
+	}
+
+	decrypter := jose.NewJWEDecrypt(nil, c.keyCrypto(info.DidURL, info.Auth.KMS), c.keyManager(info.DidURL, info.Auth.KMS))
+
+	result := make([]*DocumentMetadata, 0, len(archive.Documents))
+
+	for _, doc := range archive.Documents {
+		jwe, err := jose.Deserialize(string(doc.JWE))
+		if err != nil {
+			return nil, fmt.Errorf("deserialize doc %s: %w", doc.ID, err)
+		}
+
+		src, err := decrypter.Decrypt(jwe)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt doc %s: %w", doc.ID, err)
+		}
+
+		var content archivedContent
+
+		err = json.Unmarshal(src, &content)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal doc %s: %w", doc.ID, err)
+		}
+
+		docMeta, err := c.SaveDoc(vaultID, doc.ID, content.Content, content.Indexed...)
+		if err != nil {
+			return nil, fmt.Errorf("save doc %s: %w", doc.ID, err)
+		}
+
+		result = append(result, docMeta)
+	}
+
+	return result, nil
+}
+
+func (c *Client) readDocContent(vaultID, docID string, info *vaultInfo,
+	decrypter jose.Decrypter) (*archivedContent, error) {
+	dInfo, err := c.getMetaDocInfo(vaultID, docID)
+	if err != nil {
+		return nil, fmt.Errorf("get meta doc info: %w", err)
+	}
+
+	_, src, err := c.readDocument(info, dInfo.EdvID, decrypter)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc storedDocument
+
+	err = json.Unmarshal(src, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	return &archivedContent{Content: doc.Content, Indexed: doc.Meta.Indexed}, nil
+}
+
+// readDocument reads an encrypted document from the EDV and returns it along with its plaintext.
+func (c *Client) readDocument(info *vaultInfo, edvID string,
+	decrypter jose.Decrypter) (*models.EncryptedDocument, []byte, error) {
+	encDoc, err := c.edvClient.ReadDocument(lastElm(info.Auth.EDV.URI, "/"), edvID, edv.WithRequestHeader(
+		c.edvSign(info.DidURL, info.Auth.EDV)),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read document: %w", err)
+	}
+
+	jwe, err := jose.Deserialize(string(encDoc.JWE))
+	if err != nil {
+		return nil, nil, fmt.Errorf("deserialize: %w", err)
+	}
+
+	src, err := decrypter.Decrypt(jwe)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decrypt: %w", err)
+	}
+
+	return encDoc, src, nil
+}
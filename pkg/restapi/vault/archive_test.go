@@ -0,0 +1,310 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vault_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+	. "github.com/trustbloc/edge-service/pkg/restapi/vault"
+)
+
+func TestClient_CreateRecipientKey(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	t.Run("No vault", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{},
+		}, loader)
+		require.NoError(t, err)
+
+		_, err = client.CreateRecipientKey("vid")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("KMS error", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{
+				Store: map[string]mockstorage.DBEntry{
+					"info_vid": {Value: []byte(`{"docs_tracked":true,"auth":{"edv":{},"kms":{}}}`)},
+				},
+			},
+		}, loader)
+		require.NoError(t, err)
+
+		_, err = client.CreateRecipientKey("vid")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "new encryption key: create: posting Create key failed")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		kmsHandlers := make(chan func(w http.ResponseWriter, r *http.Request), 2)
+		kmsHandlers <- func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Location", "/kms/keystores/c0ekinlioud42c84qs7g/keys/GKszTDQcWrFlMS-BO7-asfNgaFfMZ96t6eeTjI__Y1c")
+
+			w.WriteHeader(http.StatusCreated)
+		}
+
+		kmsHandlers <- func(w http.ResponseWriter, _ *http.Request) {
+			payload, err := json.Marshal(map[string][]byte{"publicKey": []byte(`{"kid":"GKszTDQcWrFlMS-BO7-asfNgaFfMZ96t6eeTjI__Y1c","x":"IM1/HfveJ4rbqAYzBOmVOnpys4h3J0yA3I238AjYzZc=","y":"S+h2S7IbWCZiQjOaNIhSvyqNcRnRKavdiC1BU8F2UU4=","curve":"NIST_P256","type":"EC"}`)}) // nolint: lll
+			require.NoError(t, err)
+
+			w.WriteHeader(http.StatusOK)
+
+			_, err = w.Write(payload)
+			require.NoError(t, err)
+		}
+
+		remoteKMS := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case fn := <-kmsHandlers:
+				fn(w, r)
+			default:
+				t.Error("no handler")
+			}
+		}))
+
+		data := map[string]mockstorage.DBEntry{}
+		store := &mockstorage.MockStoreProvider{Store: &mockstorage.MockStore{Store: data}}
+
+		lKMS := newLocalKms(t, store)
+		client, err := NewClient(remoteKMS.URL, "", lKMS, store, loader)
+		require.NoError(t, err)
+
+		vID, dURL, _ := createVaultID(t, lKMS)
+
+		data["info_"+vID] = mockstorage.DBEntry{
+			Value: []byte(`{"docs_tracked":true,"did_url":"` + dURL + `", "auth":{"edv":{},"kms":{"uri":"/"}}}`),
+		}
+
+		pubKey, err := client.CreateRecipientKey(vID)
+		require.NoError(t, err)
+		require.Equal(t, "GKszTDQcWrFlMS-BO7-asfNgaFfMZ96t6eeTjI__Y1c", pubKey.KID)
+	})
+}
+
+func TestClient_ExportDocs(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	t.Run("No vault", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{},
+		}, loader)
+		require.NoError(t, err)
+
+		err = client.ExportDocs("vid", newRecipientKey(t), nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get vault info: get: data not found")
+	})
+
+	t.Run("Untracked docs", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{
+				Store: map[string]mockstorage.DBEntry{
+					"info_vid": {Value: []byte(`{"auth":{"edv":{},"kms":{}}}`)},
+				},
+			},
+		}, loader)
+		require.NoError(t, err)
+
+		err = client.ExportDocs("vid", newRecipientKey(t), nil)
+		require.True(t, errors.Is(err, ErrDocsNotTracked))
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{
+				Store: map[string]mockstorage.DBEntry{
+					"info_vid": {Value: []byte(`{"docs_tracked":true,"auth":{"edv":{},"kms":{}}}`)},
+				},
+				ErrQuery: errors.New("test"),
+			},
+		}, loader)
+		require.NoError(t, err)
+
+		err = client.ExportDocs("vid", newRecipientKey(t), nil)
+		require.EqualError(t, err, "get doc IDs: store query: test")
+	})
+
+	t.Run("Read document error", func(t *testing.T) {
+		edv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+
+		data := map[string]mockstorage.DBEntry{}
+		store := &mockstorage.MockStoreProvider{Store: &mockstorage.MockStore{Store: data}}
+
+		lKMS := newLocalKms(t, store)
+		client, err := NewClient("", edv.URL, lKMS, store, loader)
+		require.NoError(t, err)
+
+		vID, dURL, _ := createVaultID(t, lKMS)
+
+		data["info_"+vID] = mockstorage.DBEntry{
+			Value: []byte(`{"docs_tracked":true,"did_url":"` + dURL + `", "auth":{"edv":{},"kms":{}}}`),
+		}
+		data["meta_doc_info_"+vID+"_docID"] = mockstorage.DBEntry{
+			Value: []byte(`{"edv_id":"eID", "kid_url":"kURL"}`),
+			Tags:  []storage.Tag{{Name: "vaultDocs", Value: base64.RawURLEncoding.EncodeToString([]byte(vID))}},
+		}
+
+		err = client.ExportDocs(vID, newRecipientKey(t), func(*ArchivedDocument) error {
+			return nil
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "read doc docID: read document")
+	})
+
+	t.Run("Empty vault", func(t *testing.T) {
+		store := mem.NewProvider()
+
+		client, err := NewClient("", "", nil, store, loader)
+		require.NoError(t, err)
+
+		s, err := store.OpenStore("vault")
+		require.NoError(t, err)
+		require.NoError(t, s.Put("info_vid", []byte(`{"docs_tracked":true,"auth":{"edv":{},"kms":{}}}`)))
+
+		var docs []*ArchivedDocument
+
+		err = client.ExportDocs("vid", newRecipientKey(t), func(doc *ArchivedDocument) error {
+			docs = append(docs, doc)
+
+			return nil
+		})
+		require.NoError(t, err)
+		require.Empty(t, docs)
+	})
+}
+
+func TestClient_ImportDocs(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	t.Run("No vault", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{},
+		}, loader)
+		require.NoError(t, err)
+
+		_, err = client.ImportDocs("vid", &Archive{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get vault info: get: data not found")
+	})
+
+	t.Run("Deserialize error", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{
+				Store: map[string]mockstorage.DBEntry{
+					"info_vid": {Value: []byte(`{"docs_tracked":true,"auth":{"edv":{},"kms":{}}}`)},
+				},
+			},
+		}, loader)
+		require.NoError(t, err)
+
+		_, err = client.ImportDocs("vid", &Archive{Documents: []*ArchivedDocument{{ID: "doc1", JWE: []byte(`{}`)}}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "deserialize doc doc1")
+	})
+
+	t.Run("Empty archive", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{
+				Store: map[string]mockstorage.DBEntry{
+					"info_vid": {Value: []byte(`{"docs_tracked":true,"auth":{"edv":{},"kms":{}}}`)},
+				},
+			},
+		}, loader)
+		require.NoError(t, err)
+
+		docs, err := client.ImportDocs("vid", &Archive{})
+		require.NoError(t, err)
+		require.Empty(t, docs)
+	})
+}
+
+func TestClient_TrackDocs(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	t.Run("No vault", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{},
+		}, loader)
+		require.NoError(t, err)
+
+		err = client.TrackDocs("vid", nil)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("Unknown doc", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{
+				Store: map[string]mockstorage.DBEntry{
+					"info_vid": {Value: []byte(`{"auth":{"edv":{},"kms":{}}}`)},
+				},
+			},
+		}, loader)
+		require.NoError(t, err)
+
+		err = client.TrackDocs("vid", []string{"docID"})
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+		require.Contains(t, err.Error(), "get meta doc info docID")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		store := mem.NewProvider()
+
+		client, err := NewClient("", "", nil, store, loader)
+		require.NoError(t, err)
+
+		s, err := store.OpenStore("vault")
+		require.NoError(t, err)
+		// a vault and a document saved before the documents were tracked
+		require.NoError(t, s.Put("info_vid", []byte(`{"auth":{"edv":{},"kms":{}}}`)))
+		require.NoError(t, s.Put("meta_doc_info_vid_docID", []byte(`{"edv_id":"eID","kid_url":"kURL"}`)))
+
+		_, err = client.RotateKey("vid")
+		require.True(t, errors.Is(err, ErrDocsNotTracked))
+
+		require.NoError(t, client.TrackDocs("vid", []string{"docID"}))
+
+		tags, err := s.GetTags("meta_doc_info_vid_docID")
+		require.NoError(t, err)
+		require.Equal(t, []storage.Tag{{Name: "vaultDocs", Value: "dmlk"}}, tags)
+
+		info, err := s.Get("info_vid")
+		require.NoError(t, err)
+		require.Contains(t, string(info), `"docs_tracked":true`)
+	})
+}
+
+func newRecipientKey(t *testing.T) *crypto.PublicKey {
+	t.Helper()
+
+	lKMS := newLocalKms(t, mem.NewProvider())
+
+	_, src, err := lKMS.CreateAndExportPubKeyBytes(kms.NISTP256ECDHKWType)
+	require.NoError(t, err)
+
+	var pubKey *crypto.PublicKey
+
+	require.NoError(t, json.Unmarshal(src, &pubKey))
+
+	return pubKey
+}
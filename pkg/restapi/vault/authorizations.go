@@ -0,0 +1,228 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vault
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+// KeyLister lists the keys of the store that start with the prefix. Stores can only be queried by tag, the records
+// saved before they were tagged are found by listing the keys of the database.
+type KeyLister func(storeName, prefix string) ([]string, error)
+
+// ListAuthorizations returns the vault's authorizations that have not expired yet.
+// Tokens are not included, use GetAuthorization to retrieve them.
+// Authorizations created before they could be listed are left out, as well as from DeleteExpiredAuthorizations,
+// until they are tagged by TagLegacyAuthorizations.
+func (c *Client) ListAuthorizations(vaultID string) ([]*CreatedAuthorization, error) {
+	_, err := c.getVaultInfo(vaultID)
+	if err != nil {
+		return nil, fmt.Errorf("get vault info: %w", err)
+	}
+
+	_, auths, err := c.queryAuthorizations(vaultAuthorizationsTagName + ":" + vaultTagValue(vaultID))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]*CreatedAuthorization, 0, len(auths))
+
+	for _, auth := range auths {
+		if auth.ExpiresAt != nil && auth.ExpiresAt.Before(now) {
+			continue
+		}
+
+		auth.Tokens = nil
+
+		result = append(result, auth)
+	}
+
+	return result, nil
+}
+
+// DeleteExpiredAuthorizations deletes the authorizations of all vaults whose expiry caveat has passed.
+// It returns the number of deleted authorizations.
+func (c *Client) DeleteExpiredAuthorizations() (int, error) {
+	keys, auths, err := c.queryAuthorizations(expiringAuthTagName)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+
+	var deleted int
+
+	for i, auth := range auths {
+		if auth.ExpiresAt == nil || auth.ExpiresAt.After(now) {
+			continue
+		}
+
+		err = c.store.Delete(keys[i])
+		if err != nil {
+			return deleted, fmt.Errorf("store delete: %w", err)
+		}
+
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+func (c *Client) queryAuthorizations(expression string) ([]string, []*CreatedAuthorization, error) {
+	iter, err := c.store.Query(expression)
+	if err != nil {
+		return nil, nil, fmt.Errorf("store query: %w", err)
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("failed to close iterator: %v", errClose)
+		}
+	}()
+
+	var (
+		keys  []string
+		auths []*CreatedAuthorization
+	)
+
+	more, err := iter.Next()
+	if err != nil {
+		return nil, nil, fmt.Errorf("iterator next: %w", err)
+	}
+
+	for more {
+		key, err := iter.Key()
+		if err != nil {
+			return nil, nil, fmt.Errorf("iterator key: %w", err)
+		}
+
+		src, err := iter.Value()
+		if err != nil {
+			return nil, nil, fmt.Errorf("iterator value: %w", err)
+		}
+
+		var auth *CreatedAuthorization
+
+		err = json.Unmarshal(src, &auth)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unmarshal: %w", err)
+		}
+
+		keys = append(keys, key)
+		auths = append(auths, auth)
+
+		more, err = iter.Next()
+		if err != nil {
+			return nil, nil, fmt.Errorf("iterator next: %w", err)
+		}
+	}
+
+	return keys, auths, nil
+}
+
+// TagLegacyAuthorizations tags the authorizations of all vaults created before they were tagged, so that they are
+// listed and deleted once expired. It returns the number of tagged authorizations.
+func (c *Client) TagLegacyAuthorizations(listKeys KeyLister) (int, error) {
+	keys, err := listKeys(storeName, authorizationKeyPrefix)
+	if err != nil {
+		return 0, fmt.Errorf("list authorization keys: %w", err)
+	}
+
+	var tagged int
+
+	for _, key := range keys {
+		// the vault ID is a DID, the authorization ID a UUID: neither has an underscore
+		sep := strings.LastIndex(key, "_")
+		if sep < len(authorizationKeyPrefix) {
+			continue
+		}
+
+		vaultID, id := key[len(authorizationKeyPrefix):sep], key[sep+1:]
+
+		auth, err := c.getAuthorization(vaultID, id)
+		if err != nil {
+			return tagged, fmt.Errorf("get authorization: %w", err)
+		}
+
+		if auth.CreatedAt != nil {
+			continue
+		}
+
+		if err = c.tagLegacyAuthorization(vaultID, auth); err != nil {
+			logger.Warnf("failed to tag authorization %s of vault %s: %v", id, vaultID, err)
+
+			continue
+		}
+
+		tagged++
+	}
+
+	return tagged, nil
+}
+
+// tagLegacyAuthorization saves an authorization created before they were tagged with the tags it would get today.
+// Its creation time is taken from the proof of its KMS capability, expiry caveats count from it.
+func (c *Client) tagLegacyAuthorization(vaultID string, auth *CreatedAuthorization) error {
+	if auth.Tokens == nil {
+		return errors.New("no authorization tokens")
+	}
+
+	capability, err := zcapld.DecompressZCAP(auth.Tokens.KMS)
+	if err != nil {
+		return fmt.Errorf("decompress zcap: %w", err)
+	}
+
+	if len(capability.Proof) == 0 {
+		return errors.New("zcap has no proof")
+	}
+
+	created, ok := capability.Proof[0]["created"].(string)
+	if !ok {
+		return errors.New("zcap proof has no creation time")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, created)
+	if err != nil {
+		return fmt.Errorf("parse zcap proof creation time: %w", err)
+	}
+
+	createdAt = createdAt.UTC()
+
+	auth.CreatedAt = &createdAt
+
+	if auth.Scope != nil {
+		auth.ExpiresAt = expiresAt(createdAt, auth.Scope.Caveats)
+	}
+
+	return c.saveAuthorization(vaultID, auth)
+}
+
+// expiresAt computes when an authorization expires from its expiry caveats, the shortest one wins.
+func expiresAt(createdAt time.Time, caveats []Caveat) *time.Time {
+	var result *time.Time
+
+	for _, caveat := range caveats {
+		if caveat.Type != zcapld.CaveatTypeExpiry {
+			continue
+		}
+
+		expiry := createdAt.Add(time.Duration(caveat.Duration) * time.Second)
+
+		if result == nil || expiry.Before(*result) {
+			result = &expiry
+		}
+	}
+
+	return result
+}
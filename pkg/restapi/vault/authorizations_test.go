@@ -0,0 +1,488 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vault_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util/signature"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	ariesvdr "github.com/hyperledger/aries-framework-go/pkg/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/vdr/fingerprint"
+	vdrkey "github.com/hyperledger/aries-framework-go/pkg/vdr/key"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/igor-pavlenko/httpsignatures-go"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+	. "github.com/trustbloc/edge-service/pkg/restapi/vault"
+)
+
+func TestClient_ListAuthorizations(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	t.Run("No vault", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{},
+		}, loader)
+		require.NoError(t, err)
+
+		_, err = client.ListAuthorizations("vid")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{
+				Store: map[string]mockstorage.DBEntry{
+					"info_vid": {Value: []byte(`{"auth":{"edv":{},"kms":{}}}`)},
+				},
+				ErrQuery: errors.New("test"),
+			},
+		}, loader)
+		require.NoError(t, err)
+
+		_, err = client.ListAuthorizations("vid")
+		require.EqualError(t, err, "store query: test")
+	})
+
+	t.Run("Unmarshal error", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{
+				Store: map[string]mockstorage.DBEntry{
+					"info_vid":            {Value: []byte(`{"auth":{"edv":{},"kms":{}}}`)},
+					"authorization_vid_1": authorizationEntry("vid", `{`, false),
+				},
+			},
+		}, loader)
+		require.NoError(t, err)
+
+		_, err = client.ListAuthorizations("vid")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{
+				Store: map[string]mockstorage.DBEntry{
+					"info_vid": {Value: []byte(`{"auth":{"edv":{},"kms":{}}}`)},
+					"authorization_vid_1": authorizationEntry("vid",
+						`{"id":"1","requestingParty":"rp1","authTokens":{"edv":"edv","kms":"kms"}}`, false),
+					"authorization_vid_2": authorizationEntry("vid",
+						`{"id":"2","requestingParty":"rp2","expiresAt":"`+timeString(time.Hour)+`"}`, true),
+					"authorization_vid_3": authorizationEntry("vid",
+						`{"id":"3","requestingParty":"rp3","expiresAt":"`+timeString(-time.Hour)+`"}`, true),
+					"authorization_other_4": authorizationEntry("other", `{"id":"4"}`, false),
+				},
+			},
+		}, loader)
+		require.NoError(t, err)
+
+		auths, err := client.ListAuthorizations("vid")
+		require.NoError(t, err)
+		require.Len(t, auths, 2)
+
+		for _, auth := range auths {
+			require.Contains(t, []string{"1", "2"}, auth.ID)
+			require.Nil(t, auth.Tokens)
+		}
+	})
+}
+
+func TestClient_DeleteExpiredAuthorizations(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	t.Run("Query error", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{ErrQuery: errors.New("test")},
+		}, loader)
+		require.NoError(t, err)
+
+		_, err = client.DeleteExpiredAuthorizations()
+		require.EqualError(t, err, "store query: test")
+	})
+
+	t.Run("Delete error", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{
+				Store: map[string]mockstorage.DBEntry{
+					"authorization_vid_1": authorizationEntry("vid",
+						`{"id":"1","expiresAt":"`+timeString(-time.Hour)+`"}`, true),
+				},
+				ErrDelete: errors.New("test"),
+			},
+		}, loader)
+		require.NoError(t, err)
+
+		_, err = client.DeleteExpiredAuthorizations()
+		require.EqualError(t, err, "store delete: test")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		data := map[string]mockstorage.DBEntry{
+			"authorization_vid_1": authorizationEntry("vid", `{"id":"1"}`, false),
+			"authorization_vid_2": authorizationEntry("vid",
+				`{"id":"2","expiresAt":"`+timeString(time.Hour)+`"}`, true),
+			"authorization_vid_3": authorizationEntry("vid",
+				`{"id":"3","expiresAt":"`+timeString(-time.Hour)+`"}`, true),
+			"authorization_other_4": authorizationEntry("other",
+				`{"id":"4","expiresAt":"`+timeString(-time.Minute)+`"}`, true),
+		}
+
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{Store: data},
+		}, loader)
+		require.NoError(t, err)
+
+		deleted, err := client.DeleteExpiredAuthorizations()
+		require.NoError(t, err)
+		require.Equal(t, 2, deleted)
+
+		require.Contains(t, data, "authorization_vid_1")
+		require.Contains(t, data, "authorization_vid_2")
+		require.NotContains(t, data, "authorization_vid_3")
+		require.NotContains(t, data, "authorization_other_4")
+	})
+}
+
+func TestClient_TagLegacyAuthorizations(t *testing.T) {
+	legacyAuthorization := func(t *testing.T, created time.Duration) []byte {
+		t.Helper()
+
+		token, err := zcapld.CompressZCAP(&zcapld.Capability{
+			ID:    "urn:uuid:kms-zcap",
+			Proof: []verifiable.Proof{{"created": timeString(created)}},
+		})
+		require.NoError(t, err)
+
+		src, err := json.Marshal(&CreatedAuthorization{
+			ID:              "1",
+			RequestingParty: "rp1",
+			Scope: &AuthorizationsScope{
+				Caveats: []Caveat{{Type: zcapld.CaveatTypeExpiry, Duration: 60}},
+			},
+			Tokens: &Tokens{EDV: "edv", KMS: token},
+		})
+		require.NoError(t, err)
+
+		return src
+	}
+
+	// lists the keys of the mock store as the database would
+	keyLister := func(data map[string]mockstorage.DBEntry) KeyLister {
+		return func(storeName, prefix string) ([]string, error) {
+			require.Equal(t, "vault", storeName)
+
+			var keys []string
+
+			for k := range data {
+				if strings.HasPrefix(k, prefix) {
+					keys = append(keys, k)
+				}
+			}
+
+			return keys, nil
+		}
+	}
+
+	t.Run("Tagged", func(t *testing.T) {
+		data := map[string]mockstorage.DBEntry{
+			"info_vid":            {Value: []byte(`{"auth":{"edv":{},"kms":{}}}`)},
+			"authorization_vid_1": {Value: legacyAuthorization(t, 0)},
+			"authorization_vid_2": authorizationEntry("vid", fmt.Sprintf(
+				`{"id":"2","createdAt":%q,"expiresAt":%q}`, timeString(0), timeString(time.Hour),
+			), true),
+		}
+
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{Store: data},
+		}, testutil.DocumentLoader(t))
+		require.NoError(t, err)
+
+		auths, err := client.ListAuthorizations("vid")
+		require.NoError(t, err)
+		require.Len(t, auths, 1)
+
+		tagged, err := client.TagLegacyAuthorizations(keyLister(data))
+		require.NoError(t, err)
+		require.Equal(t, 1, tagged)
+
+		auth, err := client.GetAuthorization("vid", "1")
+		require.NoError(t, err)
+		require.NotNil(t, auth.CreatedAt)
+		require.Equal(t, auth.CreatedAt.Add(time.Minute), *auth.ExpiresAt)
+
+		auths, err = client.ListAuthorizations("vid")
+		require.NoError(t, err)
+		require.Len(t, auths, 2)
+
+		// tagged only once
+		tagged, err = client.TagLegacyAuthorizations(keyLister(data))
+		require.NoError(t, err)
+		require.Equal(t, 0, tagged)
+	})
+
+	t.Run("Expired authorization is deleted once tagged", func(t *testing.T) {
+		data := map[string]mockstorage.DBEntry{
+			"authorization_vid_1": {Value: legacyAuthorization(t, -time.Hour)},
+		}
+
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{Store: data},
+		}, testutil.DocumentLoader(t))
+		require.NoError(t, err)
+
+		deleted, err := client.DeleteExpiredAuthorizations()
+		require.NoError(t, err)
+		require.Equal(t, 0, deleted)
+
+		_, err = client.TagLegacyAuthorizations(keyLister(data))
+		require.NoError(t, err)
+
+		deleted, err = client.DeleteExpiredAuthorizations()
+		require.NoError(t, err)
+		require.Equal(t, 1, deleted)
+		require.NotContains(t, data, "authorization_vid_1")
+	})
+
+	t.Run("Not a zcap", func(t *testing.T) {
+		data := map[string]mockstorage.DBEntry{
+			"authorization_vid_1": {Value: []byte(`{"id":"1","authTokens":{"edv":"edv","kms":"kms"}}`)},
+		}
+
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{Store: data},
+		}, testutil.DocumentLoader(t))
+		require.NoError(t, err)
+
+		// skipped, the authorization is still returned
+		tagged, err := client.TagLegacyAuthorizations(keyLister(data))
+		require.NoError(t, err)
+		require.Equal(t, 0, tagged)
+		require.Nil(t, data["authorization_vid_1"].Tags)
+
+		auth, err := client.GetAuthorization("vid", "1")
+		require.NoError(t, err)
+		require.Equal(t, "1", auth.ID)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{Store: map[string]mockstorage.DBEntry{}},
+		}, testutil.DocumentLoader(t))
+		require.NoError(t, err)
+
+		_, err = client.TagLegacyAuthorizations(func(string, string) ([]string, error) {
+			return nil, errors.New("test")
+		})
+		require.EqualError(t, err, "list authorization keys: test")
+
+		_, err = client.TagLegacyAuthorizations(func(string, string) ([]string, error) {
+			return []string{"authorization_vid_1"}, nil
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get authorization")
+	})
+}
+
+func authorizationEntry(vaultID, value string, expiring bool) mockstorage.DBEntry {
+	tags := []storage.Tag{{
+		Name:  "vaultAuthorizations",
+		Value: base64.RawURLEncoding.EncodeToString([]byte(vaultID)),
+	}}
+
+	if expiring {
+		tags = append(tags, storage.Tag{Name: "expiringAuthorization"})
+	}
+
+	return mockstorage.DBEntry{Value: []byte(value), Tags: tags}
+}
+
+func timeString(d time.Duration) string {
+	return time.Now().Add(d).UTC().Format(time.RFC3339Nano)
+}
+
+func TestClient_UseAuthorization(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	t.Run("Remote KMS", func(t *testing.T) {
+		data := map[string]mockstorage.DBEntry{}
+		store := &mockstorage.MockStoreProvider{Store: &mockstorage.MockStore{Store: data}}
+
+		lKMS := newLocalKms(t, store)
+		vID, dURL, kid := createVaultID(t, lKMS)
+
+		var rootZCAP *zcapld.Capability
+
+		// the KMS accepts the invocations of its keystore through the capability chains rooted in the keystore's zcap
+		kmsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			zcapld.NewHTTPSigAuthHandler(&zcapld.HTTPSigAuthConfig{
+				CapabilityResolver: zcapld.SimpleCapabilityResolver{rootZCAP.ID: rootZCAP},
+				KeyResolver:        zcapld.NewDIDKeyResolver(nil),
+				VDRResolver:        ariesvdr.New(ariesvdr.WithVDR(vdrkey.New())),
+				VerifierOptions: []zcapld.VerificationOption{
+					zcapld.WithSignatureSuites(ed25519signature2018.New(
+						suite.WithVerifier(ed25519signature2018.NewPublicKeyVerifier()))),
+					zcapld.WithLDDocumentLoaders(loader),
+				},
+				Secrets: &zcapld.AriesDIDKeySecrets{},
+				KMS:     lKMS,
+				Crypto:  newCrypto(t),
+			}, &zcapld.InvocationExpectations{
+				Target:         rootZCAP.ID,
+				RootCapability: rootZCAP.ID,
+				Action:         lastPathElm(r.URL.Path),
+			}, func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})(w, r)
+		}))
+		defer kmsServer.Close()
+
+		rootZCAP = newRootZCAP(t, kmsServer.URL+"/kms/keystores/ks1", dURL, "urn:kms:keystore", "wrap", "unwrap")
+
+		kmsToken, err := zcapld.CompressZCAP(rootZCAP)
+		require.NoError(t, err)
+
+		// the EDV token isn't invoked, the zcap is not signed
+		edvToken, err := zcapld.CompressZCAP(&zcapld.Capability{
+			ID:               "urn:uuid:edv-zcap",
+			Parent:           "urn:uuid:edv-root-zcap",
+			Invoker:          dURL,
+			AllowedAction:    []string{"read", "write"},
+			InvocationTarget: zcapld.InvocationTarget{ID: "vid", Type: "urn:edv:vault"},
+		})
+		require.NoError(t, err)
+
+		info, err := json.Marshal(map[string]interface{}{
+			"did_url": dURL,
+			"kid":     kid,
+			"auth": &Authorization{
+				EDV: &Location{URI: "https://edv.example.com/encrypted-data-vaults/vid", AuthToken: edvToken},
+				KMS: &Location{URI: rootZCAP.ID, AuthToken: kmsToken},
+			},
+		})
+		require.NoError(t, err)
+
+		data["info_"+vID] = mockstorage.DBEntry{Value: info}
+
+		client, err := NewClient(kmsServer.URL, "", lKMS, store, loader)
+		require.NoError(t, err)
+
+		rpKMS := newLocalKms(t, mockstorage.NewMockStoreProvider())
+		_, rpURL, _ := createVaultID(t, rpKMS)
+
+		auth, err := client.CreateAuthorization(vID, rpURL, &AuthorizationsScope{Actions: []string{"read"}})
+		require.NoError(t, err)
+
+		invoke := func(action string) int {
+			req, errReq := http.NewRequest(http.MethodPost, rootZCAP.ID+"/keys/key1/"+action, nil)
+			require.NoError(t, errReq)
+
+			req.Header.Set(zcapld.CapabilityInvocationHTTPHeader,
+				fmt.Sprintf(`zcap capability="%s",action="%s"`, auth.Tokens.KMS, action))
+
+			hs := httpsignatures.NewHTTPSignatures(&zcapld.AriesDIDKeySecrets{})
+			hs.SetSignatureHashAlgorithm(&zcapld.AriesDIDKeySignatureHashAlgorithm{
+				Crypto:   newCrypto(t),
+				KMS:      rpKMS,
+				Resolver: ariesvdr.New(ariesvdr.WithVDR(vdrkey.New())),
+			})
+			require.NoError(t, hs.Sign(rpURL, req))
+
+			resp, errDo := http.DefaultClient.Do(req)
+			require.NoError(t, errDo)
+			require.NoError(t, resp.Body.Close())
+
+			return resp.StatusCode
+		}
+
+		// the requesting party unwraps the keys of the vault's documents with the KMS token, and nothing else
+		require.Equal(t, http.StatusOK, invoke("unwrap"))
+		require.Equal(t, http.StatusUnauthorized, invoke("wrap"))
+	})
+
+	t.Run("Local KMS", func(t *testing.T) {
+		data := map[string]mockstorage.DBEntry{}
+		store := &mockstorage.MockStoreProvider{Store: &mockstorage.MockStore{Store: data}}
+
+		lKMS := newLocalKms(t, store)
+
+		provider, err := NewLocalKMSProvider(lKMS, mockstorage.NewMockStoreProvider(), loader)
+		require.NoError(t, err)
+
+		vID, dURL, kid := createVaultID(t, lKMS)
+
+		kmsURI, kmsToken, err := provider.CreateKeyStore(dURL)
+		require.NoError(t, err)
+
+		info, err := json.Marshal(map[string]interface{}{
+			"did_url": dURL,
+			"kid":     kid,
+			"auth":    &Authorization{KMS: &Location{URI: kmsURI, AuthToken: kmsToken}},
+		})
+		require.NoError(t, err)
+
+		data["info_"+vID] = mockstorage.DBEntry{Value: info}
+
+		client, err := NewClient("", "", lKMS, store, loader, WithKMSProvider(provider))
+		require.NoError(t, err)
+
+		// the local KMS rejects delegated zcaps, no authorization is created that couldn't be used
+		_, err = client.CreateAuthorization(vID, "did:example:rp#key-1", &AuthorizationsScope{Actions: []string{"read"}})
+		require.True(t, errors.Is(err, ErrAuthorizationsNotSupported))
+
+		auths, err := client.ListAuthorizations(vID)
+		require.NoError(t, err)
+		require.Empty(t, auths)
+	})
+}
+
+// newRootZCAP returns the root zcap of the target, signed by the server holding it.
+func newRootZCAP(t *testing.T, target, invoker, targetType string, actions ...string) *zcapld.Capability {
+	t.Helper()
+
+	sig, err := signature.NewCryptoSigner(newCrypto(t), newLocalKms(t, mockstorage.NewMockStoreProvider()), kms.ED25519)
+	require.NoError(t, err)
+
+	_, verificationMethod := fingerprint.CreateDIDKey(sig.PublicKeyBytes())
+
+	capability, err := zcapld.NewCapability(&zcapld.Signer{
+		SignatureSuite:     ed25519signature2018.New(suite.WithSigner(sig)),
+		SuiteType:          ed25519signature2018.SignatureType,
+		VerificationMethod: verificationMethod,
+		ProcessorOpts:      []jsonld.ProcessorOpts{jsonld.WithDocumentLoader(testutil.DocumentLoader(t))},
+	}, zcapld.WithID(target), zcapld.WithInvoker(invoker), zcapld.WithAllowedActions(actions...),
+		zcapld.WithInvocationTarget(target, targetType))
+	require.NoError(t, err)
+
+	return capability
+}
+
+func newCrypto(t *testing.T) *tinkcrypto.Crypto {
+	t.Helper()
+
+	cryptoService, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	return cryptoService
+}
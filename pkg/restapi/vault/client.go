@@ -9,12 +9,14 @@ package vault
 import (
 	"bytes"
 	"crypto/ed25519"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,7 +24,6 @@ import (
 	"github.com/hyperledger/aries-framework-go-ext/component/vdr/sidetree/doc"
 	ariescrypto "github.com/hyperledger/aries-framework-go/pkg/crypto"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
-	webcrypto "github.com/hyperledger/aries-framework-go/pkg/crypto/webkms"
 	ariesdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
@@ -30,13 +31,13 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
-	"github.com/hyperledger/aries-framework-go/pkg/kms/webkms"
 	ariesvdr "github.com/hyperledger/aries-framework-go/pkg/vdr"
 	"github.com/hyperledger/aries-framework-go/pkg/vdr/fingerprint"
 	vdrkey "github.com/hyperledger/aries-framework-go/pkg/vdr/key"
 	"github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/igor-pavlenko/httpsignatures-go"
 	"github.com/piprate/json-gold/ld"
+	"github.com/trustbloc/edge-core/pkg/log"
 	"github.com/trustbloc/edge-core/pkg/zcapld"
 	edv "github.com/trustbloc/edv/pkg/client"
 	"github.com/trustbloc/edv/pkg/edvutils"
@@ -44,24 +45,51 @@ import (
 	"github.com/trustbloc/edv/pkg/restapi/models"
 	"github.com/trustbloc/kms/pkg/restapi/kms/operation"
 
+	"github.com/trustbloc/edge-service/pkg/claim"
 	"github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
 )
 
 const (
 	storeName = "vault"
 
-	authorizationFormat = "authorization_%s_%s"
+	authorizationKeyPrefix = "authorization_"
+
+	authorizationFormat = authorizationKeyPrefix + "%s_%s"
 	metaDocInfoFormat   = "meta_doc_info_%s_%s"
+	docIDFormat         = "doc_id_%s_%s"
 	infoFormat          = "info_%s"
+	keyRotationFormat   = "key_rotation_%s"
+
+	vaultDocsTagName           = "vaultDocs"
+	vaultAuthorizationsTagName = "vaultAuthorizations"
+	expiringAuthTagName        = "expiringAuthorization"
 )
 
+var logger = log.New("vault")
+
+// ErrDocsNotTracked is returned when the documents of a vault created before they were tracked are needed,
+// until they are registered by TrackDocs.
+var ErrDocsNotTracked = errors.New("vault has documents that are not tracked, register them with TrackDocs")
+
+// ErrAuthorizationsNotSupported is returned when an authorization is created for a vault whose keys are in the local
+// KMS, which rejects the delegated zcaps the authorizations' KMS tokens are.
+var ErrAuthorizationsNotSupported = errors.New("authorizations are not supported by vaults with keys in the local KMS")
+
 // Vault defines vault client interface.
 type Vault interface {
 	CreateVault() (*CreatedVault, error)
-	SaveDoc(vaultID, id string, content []byte) (*DocumentMetadata, error)
+	SaveDoc(vaultID, id string, content []byte, indexed ...IndexedAttribute) (*DocumentMetadata, error)
 	GetDocMetadata(vaultID, docID string) (*DocumentMetadata, error)
+	QueryDocs(vaultID, name, value string) ([]*DocumentMetadata, error)
 	CreateAuthorization(vaultID, requestingParty string, scope *AuthorizationsScope) (*CreatedAuthorization, error)
 	GetAuthorization(vaultID, id string) (*CreatedAuthorization, error)
+	ListAuthorizations(vaultID string) ([]*CreatedAuthorization, error)
+	CreateRecipientKey(vaultID string) (*ariescrypto.PublicKey, error)
+	RotateKey(vaultID string) (*KeyRotation, error)
+	GetKeyRotation(vaultID string) (*KeyRotation, error)
+	ExportDocs(vaultID string, recipient *ariescrypto.PublicKey, fn func(*ArchivedDocument) error) error
+	ImportDocs(vaultID string, archive *Archive) ([]*DocumentMetadata, error)
+	TrackDocs(vaultID string, docIDs []string) error
 }
 
 // KeyManager KMS alias.
@@ -83,7 +111,9 @@ type CreatedAuthorization struct {
 	ID              string               `json:"id"`
 	Scope           *AuthorizationsScope `json:"scope"`
 	RequestingParty string               `json:"requestingParty"`
-	Tokens          *Tokens              `json:"authTokens"`
+	Tokens          *Tokens              `json:"authTokens,omitempty"`
+	CreatedAt       *time.Time           `json:"createdAt,omitempty"`
+	ExpiresAt       *time.Time           `json:"expiresAt,omitempty"`
 }
 
 // Tokens zcap tokens.
@@ -125,6 +155,13 @@ type DocumentMetadata struct {
 	EncKeyURI string `json:"encKeyURI"`
 }
 
+// IndexedAttribute is a document attribute the document can be found by.
+type IndexedAttribute struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Unique bool   `json:"unique,omitempty"`
+}
+
 // Client vault`s client.
 type Client struct {
 	remoteKMSURL    string
@@ -140,6 +177,9 @@ type Client struct {
 	store           storage.Store
 	registry        vdr.Registry
 	documentLoader  ld.DocumentLoader
+	kmsProvider     KMSProvider
+	vaultLocks      sync.Map
+	claims          *claim.Store
 }
 
 // Opt represents Client`s option.
@@ -198,6 +238,11 @@ func NewClient(kmsURL, edvURL string, kmsClient kms.KeyManager, db storage.Provi
 		return nil, fmt.Errorf("open store: %w", err)
 	}
 
+	claims, err := claim.New(db)
+	if err != nil {
+		return nil, err
+	}
+
 	client := &Client{
 		remoteKMSURL: kmsURL,
 		edvHost:      u.Host,
@@ -205,6 +250,7 @@ func NewClient(kmsURL, edvURL string, kmsClient kms.KeyManager, db storage.Provi
 		kms:          kmsClient,
 		crypto:       cryptoService,
 		store:        store,
+		claims:       claims,
 		httpClient: &http.Client{
 			Timeout: time.Minute,
 		},
@@ -219,6 +265,10 @@ func NewClient(kmsURL, edvURL string, kmsClient kms.KeyManager, db storage.Provi
 		fn(client)
 	}
 
+	if client.kmsProvider == nil {
+		client.kmsProvider = &remoteKMSProvider{client: client}
+	}
+
 	client.edvClient = edv.New(edvURL, edv.WithHTTPClient(client.httpClient))
 
 	return client, nil
@@ -231,7 +281,7 @@ func (c *Client) CreateVault() (*CreatedVault, error) {
 		return nil, fmt.Errorf("create DID key: %w", err)
 	}
 
-	kmsURI, kmsZCAP, err := webkms.CreateKeyStore(c.httpClient, c.remoteKMSURL, didURL, "")
+	kmsURI, kmsZCAP, err := c.kmsProvider.CreateKeyStore(didURL)
 	if err != nil {
 		return nil, fmt.Errorf("create key store: %w", err)
 	}
@@ -251,7 +301,19 @@ func (c *Client) CreateVault() (*CreatedVault, error) {
 		EDV: edvLoc,
 	}
 
-	err = c.saveVaultInfo(didKey, &vaultInfo{Auth: auth, KID: kid, DidURL: didURL})
+	// the MAC key is created along with the vault, so saving documents never has to update the vault's info
+	_, macKeyURL, err := c.keyManager(didURL, auth.KMS).Create(kms.HMACSHA256Tag256Type)
+	if err != nil {
+		return nil, fmt.Errorf("create MAC key: %w", err)
+	}
+
+	err = c.saveVaultInfo(didKey, &vaultInfo{
+		Auth:        auth,
+		KID:         kid,
+		DidURL:      didURL,
+		MACKeyURL:   fmt.Sprintf("%s", macKeyURL),
+		DocsTracked: true,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("save vault info: %w", err)
 	}
@@ -271,11 +333,18 @@ func (c *Client) CreateAuthorization(vaultID, requestingParty string,
 		return nil, fmt.Errorf("get vault info: %w", err)
 	}
 
+	if info.Auth.KMS != nil && strings.HasPrefix(info.Auth.KMS.URI, LocalKeyStoreURIPrefix) {
+		return nil, ErrAuthorizationsNotSupported
+	}
+
 	kh, err := c.kms.Get(info.KID)
 	if err != nil {
 		return nil, fmt.Errorf("kms get: %w", err)
 	}
 
+	// expiry caveats count from the capability's proof creation time, which is not earlier than this
+	createdAt := time.Now().UTC()
+
 	kmsCapability, err := zcapld.DecompressZCAP(info.Auth.KMS.AuthToken)
 	if err != nil {
 		return nil, fmt.Errorf("kms uncompressZCAP: %w", err)
@@ -332,6 +401,8 @@ func (c *Client) CreateAuthorization(vaultID, requestingParty string,
 			KMS: kmsCompressedCapability,
 			EDV: edvCompressedCapability,
 		},
+		CreatedAt: &createdAt,
+		ExpiresAt: expiresAt(createdAt, scope.Caveats),
 	}
 
 	err = c.saveAuthorization(vaultID, res)
@@ -366,7 +437,13 @@ func (c *Client) saveAuthorization(vID string, a *CreatedAuthorization) error {
 		return fmt.Errorf("marshal: %w", err)
 	}
 
-	return c.store.Put(fmt.Sprintf(authorizationFormat, vID, a.ID), src)
+	tags := []storage.Tag{{Name: vaultAuthorizationsTagName, Value: vaultTagValue(vID)}}
+
+	if a.ExpiresAt != nil {
+		tags = append(tags, storage.Tag{Name: expiringAuthTagName})
+	}
+
+	return c.store.Put(fmt.Sprintf(authorizationFormat, vID, a.ID), src, tags...)
 }
 
 func (c *Client) getAuthorization(vID, id string) (*CreatedAuthorization, error) {
@@ -414,15 +491,20 @@ func (c *Client) GetDocMetadata(vaultID, docID string) (*DocumentMetadata, error
 }
 
 // SaveDoc saves a document by encrypting it and storing it in the vault.
-func (c *Client) SaveDoc(vaultID, id string, content []byte) (*DocumentMetadata, error) { // nolint:funlen,gocyclo
-	info, err := c.getVaultInfo(vaultID)
+// Indexed attributes are stored blinded with the vault's MAC key, so the document can be found by QueryDocs.
+func (c *Client) SaveDoc(vaultID, id string, content []byte, // nolint:funlen,gocyclo
+	indexed ...IndexedAttribute) (*DocumentMetadata, error) {
+	docID, err := edvutils.GenerateEDVCompatibleID()
 	if err != nil {
-		return nil, fmt.Errorf("get vault info: %w", err)
+		return nil, fmt.Errorf("failed to generate an EDV document ID: %w", err)
 	}
 
-	docID, err := edvutils.GenerateEDVCompatibleID()
+	defer c.lockVault(vaultID)()
+
+	// read under the lock, so the info is not one that a concurrent update of the vault replaced
+	info, err := c.getVaultInfo(vaultID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate an EDV document ID: %w", err)
+		return nil, fmt.Errorf("get vault info: %w", err)
 	}
 
 	docContents := make(map[string]interface{})
@@ -433,10 +515,11 @@ func (c *Client) SaveDoc(vaultID, id string, content []byte) (*DocumentMetadata,
 	}
 
 	kidURL, encContent, err := encryptContent(
-		c.webKMS(info.DidURL, info.Auth.KMS),
-		c.webCrypto(info.DidURL, info.Auth.KMS),
+		c.keyManager(info.DidURL, info.Auth.KMS),
+		c.keyCrypto(info.DidURL, info.Auth.KMS),
 		&models.StructuredDocument{
 			ID:      docID,
+			Meta:    docMeta(indexed),
 			Content: docContents,
 		},
 	)
@@ -456,11 +539,17 @@ func (c *Client) SaveDoc(vaultID, id string, content []byte) (*DocumentMetadata,
 		}
 	}
 
+	indexedCollections, err := c.indexedAttributeCollections(vaultID, id, dInfo.EdvID, info, indexed)
+	if err != nil {
+		return nil, fmt.Errorf("indexed attributes: %w", err)
+	}
+
 	edvVaultID := lastElm(info.Auth.EDV.URI, "/")
 
 	_, err = c.edvClient.CreateDocument(edvVaultID, &models.EncryptedDocument{
-		ID:  dInfo.EdvID,
-		JWE: []byte(encContent),
+		ID:                          dInfo.EdvID,
+		IndexedAttributeCollections: indexedCollections,
+		JWE:                         []byte(encContent),
 	}, edv.WithRequestHeader(c.edvSign(info.DidURL, info.Auth.EDV)))
 	if err == nil {
 		return &DocumentMetadata{
@@ -475,13 +564,24 @@ func (c *Client) SaveDoc(vaultID, id string, content []byte) (*DocumentMetadata,
 	}
 
 	err = c.edvClient.UpdateDocument(edvVaultID, dInfo.EdvID, &models.EncryptedDocument{
-		ID:  dInfo.EdvID,
-		JWE: []byte(encContent),
+		ID:                          dInfo.EdvID,
+		IndexedAttributeCollections: indexedCollections,
+		JWE:                         []byte(encContent),
 	}, edv.WithRequestHeader(c.edvSign(info.DidURL, info.Auth.EDV)))
 	if err != nil {
 		return nil, fmt.Errorf("update document: %w", err)
 	}
 
+	// every save encrypts the document to a new key
+	if dInfo.KidURL != c.buildKMSURL(kidURL) {
+		dInfo.KidURL = c.buildKMSURL(kidURL)
+
+		err = c.saveMetaDocInfo(vaultID, id, dInfo)
+		if err != nil {
+			return nil, fmt.Errorf("save meta doc info: %w", err)
+		}
+	}
+
 	return &DocumentMetadata{
 		ID:        id,
 		URI:       buildEDVDocURI(c.edvScheme, c.edvHost, edvVaultID, dInfo.EdvID),
@@ -490,9 +590,13 @@ func (c *Client) SaveDoc(vaultID, id string, content []byte) (*DocumentMetadata,
 }
 
 type vaultInfo struct {
-	KID    string         `json:"kid"`
-	DidURL string         `json:"did_url"`
-	Auth   *Authorization `json:"auth"`
+	KID       string         `json:"kid"`
+	DidURL    string         `json:"did_url"`
+	Auth      *Authorization `json:"auth"`
+	MACKeyURL string         `json:"mac_key_url,omitempty"`
+	// DocsTracked tells whether every document of the vault can be found by getDocIDs. It is false for the vaults
+	// created before the documents were tagged, until their documents are registered by TrackDocs.
+	DocsTracked bool `json:"docs_tracked,omitempty"`
 }
 
 func (c *Client) saveVaultInfo(id string, info *vaultInfo) error {
@@ -517,17 +621,118 @@ func (c *Client) createMetaDocInfo(vid, id, kid string) (*metaDocInfo, error) {
 
 	info := &metaDocInfo{EdvID: edvID, KidURL: c.buildKMSURL(kid)}
 
+	err = c.saveMetaDocInfo(vid, id, info)
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+func (c *Client) saveMetaDocInfo(vid, id string, info *metaDocInfo) error {
 	src, err := json.Marshal(info)
 	if err != nil {
-		return nil, fmt.Errorf("marshal: %w", err)
+		return fmt.Errorf("marshal: %w", err)
 	}
 
-	err = c.store.Put(fmt.Sprintf(metaDocInfoFormat, vid, id), src)
+	err = c.store.Put(fmt.Sprintf(metaDocInfoFormat, vid, id), src, storage.Tag{
+		Name:  vaultDocsTagName,
+		Value: vaultTagValue(vid),
+	})
 	if err != nil {
-		return nil, fmt.Errorf("store put: %w", err)
+		return fmt.Errorf("store put: %w", err)
 	}
 
-	return info, nil
+	return nil
+}
+
+// lockVault serializes document writes of the vault, so a key rotation does not overwrite a concurrent update.
+func (c *Client) lockVault(vaultID string) func() {
+	mu, _ := c.vaultLocks.LoadOrStore(vaultID, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+
+	return mu.(*sync.Mutex).Unlock
+}
+
+// getDocIDs returns IDs of all documents saved in the vault.
+func (c *Client) getDocIDs(vid string, info *vaultInfo) ([]string, error) {
+	if !info.DocsTracked {
+		return nil, ErrDocsNotTracked
+	}
+
+	iter, err := c.store.Query(vaultDocsTagName + ":" + vaultTagValue(vid))
+	if err != nil {
+		return nil, fmt.Errorf("store query: %w", err)
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("failed to close iterator: %v", errClose)
+		}
+	}()
+
+	prefix := fmt.Sprintf(metaDocInfoFormat, vid, "")
+
+	var ids []string
+
+	more, err := iter.Next()
+	if err != nil {
+		return nil, fmt.Errorf("iterator next: %w", err)
+	}
+
+	for more {
+		key, err := iter.Key()
+		if err != nil {
+			return nil, fmt.Errorf("iterator key: %w", err)
+		}
+
+		ids = append(ids, strings.TrimPrefix(key, prefix))
+
+		more, err = iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterator next: %w", err)
+		}
+	}
+
+	return ids, nil
+}
+
+// TrackDocs registers the documents saved in a vault before its documents were tracked, so they are exported and
+// re-encrypted by a key rotation. The IDs must be those of every such document, the ones saved since are already
+// tracked. The documents of the vault are tracked once they are registered.
+func (c *Client) TrackDocs(vaultID string, docIDs []string) error {
+	defer c.lockVault(vaultID)()
+
+	info, err := c.getVaultInfo(vaultID)
+	if err != nil {
+		return fmt.Errorf("get vault info: %w", err)
+	}
+
+	for _, docID := range docIDs {
+		dInfo, errGet := c.getMetaDocInfo(vaultID, docID)
+		if errGet != nil {
+			return fmt.Errorf("get meta doc info %s: %w", docID, errGet)
+		}
+
+		// saving the meta doc info tags it
+		if errSave := c.saveMetaDocInfo(vaultID, docID, dInfo); errSave != nil {
+			return fmt.Errorf("save meta doc info %s: %w", docID, errSave)
+		}
+	}
+
+	info.DocsTracked = true
+
+	err = c.saveVaultInfo(vaultID, info)
+	if err != nil {
+		return fmt.Errorf("save vault info: %w", err)
+	}
+
+	return nil
+}
+
+// vaultTagValue encodes vault ID to be used as a tag value (DIDs contain colons which are not allowed).
+func vaultTagValue(vid string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(vid))
 }
 
 func (c *Client) getMetaDocInfo(vid, id string) (*metaDocInfo, error) {
@@ -562,12 +767,8 @@ func (c *Client) getVaultInfo(id string) (*vaultInfo, error) {
 	return info, nil
 }
 
-func (c *Client) webKMS(controller string, auth *Location) *webkms.RemoteKMS {
-	return webkms.New(
-		c.buildKMSURL(auth.URI),
-		c.httpClient,
-		webkms.WithHeaders(c.kmsSign(controller, auth)),
-	)
+func (c *Client) keyManager(controller string, auth *Location) KeyManager {
+	return c.kmsProvider.KeyManager(controller, auth)
 }
 
 func (c *Client) buildKMSURL(uri string) string {
@@ -578,12 +779,8 @@ func (c *Client) buildKMSURL(uri string) string {
 	return uri
 }
 
-func (c *Client) webCrypto(controller string, auth *Location) *webcrypto.RemoteCrypto {
-	return webcrypto.New(
-		c.buildKMSURL(auth.URI),
-		c.httpClient,
-		webkms.WithHeaders(c.kmsSign(controller, auth)),
-	)
+func (c *Client) keyCrypto(controller string, auth *Location) ariescrypto.Crypto {
+	return c.kmsProvider.Crypto(controller, auth)
 }
 
 func (c *Client) createDIDKey(method string) (string, string, string, error) {
@@ -759,45 +956,63 @@ func encryptContent(wKMS KeyManager, wCrypto ariescrypto.Crypto, content interfa
 		return "", "", fmt.Errorf("marshal: %w", err)
 	}
 
-	_, kidURL, err := wKMS.Create(kms.NISTP256ECDHKW)
+	kidURLStr, ecPubKey, err := newEncryptionKey(wKMS)
 	if err != nil {
-		return "", "", fmt.Errorf("create: %w", err)
+		return "", "", err
 	}
 
-	kidURLStr, ok := kidURL.(string)
-	if !ok {
-		return "", "", fmt.Errorf("kidURL is not a string")
+	eContent, err := encryptJWE(wCrypto, ecPubKey, src)
+	if err != nil {
+		return "", "", err
 	}
 
-	pubKeyBytes, err := wKMS.ExportPubKeyBytes(lastElm(kidURLStr, "/"))
+	return kidURLStr, eContent, nil
+}
+
+func encryptJWE(wCrypto ariescrypto.Crypto, pubKey *ariescrypto.PublicKey, src []byte) (string, error) {
+	encrypter, err := jose.NewJWEEncrypt(jose.A256GCM, jose.A256GCMALG, "", "", nil,
+		[]*ariescrypto.PublicKey{pubKey}, wCrypto)
 	if err != nil {
-		return "", "", fmt.Errorf("export pubKey bytes: %w", err)
+		return "", fmt.Errorf("new JWE encrypt: %w", err)
 	}
 
-	var ecPubKey *ariescrypto.PublicKey
+	jwe, err := encrypter.Encrypt(src)
+	if err != nil {
+		return "", fmt.Errorf("encrypt: %w", err)
+	}
 
-	err = json.Unmarshal(pubKeyBytes, &ecPubKey)
+	eContent, err := jwe.FullSerialize(json.Marshal)
 	if err != nil {
-		return "", "", fmt.Errorf("unmarshal: %w", err)
+		return "", fmt.Errorf("full serialize: %w", err)
 	}
 
-	encrypter, err := jose.NewJWEEncrypt(jose.A256GCM, jose.A256GCMALG, "", "", nil,
-		[]*ariescrypto.PublicKey{ecPubKey}, wCrypto)
+	return eContent, nil
+}
+
+func newEncryptionKey(wKMS KeyManager) (string, *ariescrypto.PublicKey, error) {
+	_, kidURL, err := wKMS.Create(kms.NISTP256ECDHKW)
 	if err != nil {
-		return "", "", fmt.Errorf("new JWE encrypt: %w", err)
+		return "", nil, fmt.Errorf("create: %w", err)
 	}
 
-	jwe, err := encrypter.Encrypt(src)
+	kidURLStr, ok := kidURL.(string)
+	if !ok {
+		return "", nil, fmt.Errorf("kidURL is not a string")
+	}
+
+	pubKeyBytes, err := wKMS.ExportPubKeyBytes(lastElm(kidURLStr, "/"))
 	if err != nil {
-		return "", "", fmt.Errorf("encrypt: %w", err)
+		return "", nil, fmt.Errorf("export pubKey bytes: %w", err)
 	}
 
-	eContent, err := jwe.FullSerialize(json.Marshal)
+	var ecPubKey *ariescrypto.PublicKey
+
+	err = json.Unmarshal(pubKeyBytes, &ecPubKey)
 	if err != nil {
-		return "", "", fmt.Errorf("full serialize: %w", err)
+		return "", nil, fmt.Errorf("unmarshal: %w", err)
 	}
 
-	return kidURLStr, eContent, nil
+	return kidURLStr, ecPubKey, nil
 }
 
 type signer struct {
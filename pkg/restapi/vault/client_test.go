@@ -12,7 +12,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
@@ -179,7 +181,6 @@ func TestClient_CreateVault(t *testing.T) {
 				Store: &mockstorage.MockStore{ErrPut: errors.New("test")},
 			},
 			loader,
-			WithRegistry(&vdr.MockVDRegistry{CreateValue: newDIDDoc()}),
 		)
 		require.NoError(t, err)
 
@@ -188,6 +189,43 @@ func TestClient_CreateVault(t *testing.T) {
 		require.EqualError(t, err, "save vault info: test")
 	})
 
+	t.Run("Create MAC key error", func(t *testing.T) {
+		remoteKMS := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/keys") {
+				w.WriteHeader(http.StatusInternalServerError)
+
+				return
+			}
+
+			w.Header().Set("Location", "/kms/keystores/c0b9em5ioud57602s7og")
+			w.Header().Set("X-ROOTCAPABILITY", "H4sIAAAAAAAA_5SSS3OjOBSF_8vt5ZAY8AOs1fiBE-LYhEDHga4ul4wULF4ikrAhqfz3KcdxL2bVWfFRdW4d3XPuO_yb8ErRVgGCvVK1RL3esc_INRdpT9KkEUx1vYMJGjACCHp5KXs57aTigspeou_GtBwy3pChNdJNafH0JK0OPKcCEBBGUE479DZa5a951ZGsiukofn1e3ziHLAof2mP5822SvwWGdT-5C5tIrnzZiR_fHQANcFHwIyWTRDFeAfoFiaBY0SXtQAPa1lyoM0uWVqDBgQr2cvo_ClyDBk31BQkv60bR1WT2R3VmWiWiqxVoQOiFmppgRZ350wzXeMcKpj7tsLx8vJqe3CTFxSf-PueT4NMzQyxSqgC9gzv_63jDrqaAoBEVykuJLnr40KAWnL8A-vX-tfypM1M3jSvduOobodFHAwMZ5rU1tAf20DTMf3QT6TpokB0lIKDd3X53kzCP3S1i5zH0A1e6pWuuZ-4oLhcyMX9Kt1x3-NlnXiFZlEW6Wxjj62ujHOZr7Ja7m2c7nT4MNvOGL5WzXSuRkg2RwTLbhtvcC3dzj-I9JtvNON0_tYNVvFkEzzOGsXf3ZlnjdExuX9vofvCoT3zQoOJVclr3celOR0VQzLb2yH1alF5nK8uyjiz37JSNkix4HQ-UT62t8l8qZ8mUzGpxu7ufBk5ylbrjjdVv4sWSvFQ0cpxFNDpOxQS-MntoRM3lySf50-OcFjT9rAk0UOfQHWIOh8Y4YGmFVSOoqRv25UrYudMVVXtO_nf8h9u970dtsG-btj9VEZkZ94_TDV-bmd1ZflyZ093DNMOxPaA_vjsAH78__gsAAP__CIjUdMsDAAA=") // nolint: lll
+
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		edv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Location", "localhost:7777/encrypted-data-vaults/DWPPbEVn1afJY4We3kpQmq")
+			w.WriteHeader(http.StatusCreated)
+
+			_, err := w.Write([]byte(`{"@context":"https://w3id.org/security/v2","id":"urn:uuid:293817e5-3a47-4685-9bd3-51eba3d5e928","invoker":"did:key:z6MkqknydjnZe6ZqXNGEvjYTPxwmUzAkzS17LAJTuYsMQsyr#z6MkqknydjnZe6ZqXNGEvjYTPxwmUzAkzS17LAJTuYsMQsyr","parentCapability":"urn:uuid:3e7f55ea-2e2c-41bd-a167-3cb71db9ca14","allowedAction":["read","write"],"invocationTarget":{"ID":"DWPPbEVn1afJY4We3kpQmq","Type":"urn:edv:vault"},"proof":[{"capabilityChain":["urn:uuid:3e7f55ea-2e2c-41bd-a167-3cb71db9ca14"],"created":"2021-01-31T13:41:13.863452194+02:00","jws":"eyJhbGciOiJFZERTQSIsImI2NCI6ZmFsc2UsImNyaXQiOlsiYjY0Il19..NfznOmAi16H7fXJ1lI3-JzzHlOMopAhdGnBaF_FYK_F5BHbJMpH0u1aZ_JMgrG2XHUFMLNCBxG91DA-tJn2gDQ","nonce":"ZjtzLnBIpSNLteskV4bgTI8LOwrqrETpDI31qPglCNT_V-78ZmChHhqksMEu59WhkA_hofadF8saneziAhCDRA","proofPurpose":"capabilityDelegation","type":"Ed25519Signature2018","verificationMethod":"did:key:z6Mkpi5ZtFzsZv5UQhLzejwaNM5YX38cHBuMopUkayU13zyn#z6Mkpi5ZtFzsZv5UQhLzejwaNM5YX38cHBuMopUkayU13zyn"}]}`)) // nolint: lll
+			require.NoError(t, err)
+		}))
+
+		store := mem.NewProvider()
+		client, err := NewClient(
+			remoteKMS.URL,
+			edv.URL,
+			newLocalKms(t, store),
+			store,
+			loader,
+		)
+		require.NoError(t, err)
+
+		_, err = client.CreateVault()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create MAC key")
+	})
+
 	t.Run("Create vault", func(t *testing.T) {
 		remoteKMS := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Location", "/kms/keystores/c0b9em5ioud57602s7og")
@@ -211,7 +249,6 @@ func TestClient_CreateVault(t *testing.T) {
 			newLocalKms(t, store),
 			store,
 			loader,
-			WithRegistry(&vdr.MockVDRegistry{CreateValue: newDIDDoc()}),
 		)
 		require.NoError(t, err)
 
@@ -724,6 +761,14 @@ func TestClient_CreateAuthorization(t *testing.T) {
 		require.NoError(t, err)
 		require.NotEmpty(t, created.Tokens.EDV)
 		require.NotEmpty(t, created.Tokens.KMS)
+		require.NotNil(t, created.CreatedAt)
+		require.Equal(t, created.CreatedAt.Add(100*time.Second), *created.ExpiresAt)
+
+		auths, err := client.ListAuthorizations(vID)
+		require.NoError(t, err)
+		require.Len(t, auths, 1)
+		require.Equal(t, created.ID, auths[0].ID)
+		require.Nil(t, auths[0].Tokens)
 	})
 }
 
@@ -0,0 +1,247 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vault
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+
+	ariescrypto "github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	edv "github.com/trustbloc/edv/pkg/client"
+	"github.com/trustbloc/edv/pkg/restapi/models"
+)
+
+const (
+	indexedMetaKey = "indexed"
+	hmacKeyType    = "Sha256HmacKey2019"
+
+	macKeyFormat        = "mac_key_%s_%s"
+	vaultMACKeysTagName = "vaultMACKeys"
+)
+
+// QueryDocs returns metadata of the documents that have the given indexed attribute.
+func (c *Client) QueryDocs(vaultID, name, value string) ([]*DocumentMetadata, error) {
+	info, err := c.getVaultInfo(vaultID)
+	if err != nil {
+		return nil, fmt.Errorf("get vault info: %w", err)
+	}
+
+	macKeyURLs, err := c.getMACKeyURLs(vaultID, info)
+	if err != nil {
+		return nil, fmt.Errorf("get MAC keys: %w", err)
+	}
+
+	edvVaultID := lastElm(info.Auth.EDV.URI, "/")
+	wCrypto := c.keyCrypto(info.DidURL, info.Auth.KMS)
+
+	// no MAC key means nothing was ever indexed in the vault, the result stays empty
+	result := []*DocumentMetadata{}
+	found := make(map[string]struct{})
+
+	for _, keyURL := range macKeyURLs {
+		macKeyURL := c.buildKMSURL(keyURL)
+
+		nameMAC, err := computeMAC(wCrypto, name, macKeyURL)
+		if err != nil {
+			return nil, fmt.Errorf("compute MAC: %w", err)
+		}
+
+		valueMAC, err := computeMAC(wCrypto, value, macKeyURL)
+		if err != nil {
+			return nil, fmt.Errorf("compute MAC: %w", err)
+		}
+
+		docURLs, err := c.edvClient.QueryVault(edvVaultID, nameMAC, valueMAC,
+			edv.WithRequestHeader(c.edvSign(info.DidURL, info.Auth.EDV)),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("query vault: %w", err)
+		}
+
+		for _, docURL := range docURLs {
+			edvID := lastElm(docURL, "/")
+
+			if _, ok := found[edvID]; ok {
+				continue
+			}
+
+			found[edvID] = struct{}{}
+
+			docID, err := c.getDocID(vaultID, edvID)
+			if err != nil {
+				return nil, fmt.Errorf("get doc ID: %w", err)
+			}
+
+			dInfo, err := c.getMetaDocInfo(vaultID, docID)
+			if err != nil {
+				return nil, fmt.Errorf("get meta doc info: %w", err)
+			}
+
+			result = append(result, &DocumentMetadata{
+				ID:        docID,
+				URI:       buildEDVDocURI(c.edvScheme, c.edvHost, edvVaultID, edvID),
+				EncKeyURI: dInfo.KidURL,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// indexedAttributeCollections blinds the indexed attributes with the vault's MAC key.
+// The MAC key is created with the vault. Vaults created before that get one the first time a document with indexed
+// attributes is saved, replicas racing to create it record a key each and queries try all of them.
+func (c *Client) indexedAttributeCollections(vaultID, docID, edvID string, info *vaultInfo,
+	indexed []IndexedAttribute) ([]models.IndexedAttributeCollection, error) {
+	if len(indexed) == 0 {
+		return nil, nil
+	}
+
+	macKeyURLs, err := c.getMACKeyURLs(vaultID, info)
+	if err != nil {
+		return nil, fmt.Errorf("get MAC keys: %w", err)
+	}
+
+	if len(macKeyURLs) == 0 {
+		keyURL, errCreate := c.createMACKey(vaultID, info)
+		if errCreate != nil {
+			return nil, fmt.Errorf("create MAC key: %w", errCreate)
+		}
+
+		macKeyURLs = append(macKeyURLs, keyURL)
+	}
+
+	// query results are EDV documents, keep the way back to the vault's document ID
+	err = c.store.Put(fmt.Sprintf(docIDFormat, vaultID, edvID), []byte(docID))
+	if err != nil {
+		return nil, fmt.Errorf("store put: %w", err)
+	}
+
+	wCrypto := c.keyCrypto(info.DidURL, info.Auth.KMS)
+	macKeyURL := c.buildKMSURL(macKeyURLs[0])
+
+	attributes := make([]models.IndexedAttribute, len(indexed))
+
+	for i, attr := range indexed {
+		nameMAC, err := computeMAC(wCrypto, attr.Name, macKeyURL)
+		if err != nil {
+			return nil, fmt.Errorf("compute MAC: %w", err)
+		}
+
+		valueMAC, err := computeMAC(wCrypto, attr.Value, macKeyURL)
+		if err != nil {
+			return nil, fmt.Errorf("compute MAC: %w", err)
+		}
+
+		attributes[i] = models.IndexedAttribute{
+			Name:   nameMAC,
+			Value:  valueMAC,
+			Unique: attr.Unique,
+		}
+	}
+
+	return []models.IndexedAttributeCollection{{
+		HMAC:              models.IDTypePair{ID: macKeyURLs[0], Type: hmacKeyType},
+		IndexedAttributes: attributes,
+	}}, nil
+}
+
+// createMACKey creates a MAC key in the vault's keystore for a vault that was created without one. The key is kept
+// in its own record, so keys created concurrently by several replicas do not overwrite each other.
+func (c *Client) createMACKey(vaultID string, info *vaultInfo) (string, error) {
+	keyID, keyURL, err := c.keyManager(info.DidURL, info.Auth.KMS).Create(kms.HMACSHA256Tag256Type)
+	if err != nil {
+		return "", err
+	}
+
+	macKeyURL := fmt.Sprintf("%s", keyURL)
+
+	err = c.store.Put(fmt.Sprintf(macKeyFormat, vaultID, keyID), []byte(macKeyURL), storage.Tag{
+		Name:  vaultMACKeysTagName,
+		Value: vaultTagValue(vaultID),
+	})
+	if err != nil {
+		return "", fmt.Errorf("store put: %w", err)
+	}
+
+	return macKeyURL, nil
+}
+
+// getMACKeyURLs returns URLs of the vault's MAC keys, the one created with the vault first.
+func (c *Client) getMACKeyURLs(vaultID string, info *vaultInfo) ([]string, error) {
+	var keyURLs []string
+
+	if info.MACKeyURL != "" {
+		keyURLs = append(keyURLs, info.MACKeyURL)
+	}
+
+	iter, err := c.store.Query(vaultMACKeysTagName + ":" + vaultTagValue(vaultID))
+	if err != nil {
+		return nil, fmt.Errorf("store query: %w", err)
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("failed to close iterator: %v", errClose)
+		}
+	}()
+
+	var created []string
+
+	for {
+		ok, errNext := iter.Next()
+		if errNext != nil {
+			return nil, fmt.Errorf("iterator next: %w", errNext)
+		}
+
+		if !ok {
+			break
+		}
+
+		val, errValue := iter.Value()
+		if errValue != nil {
+			return nil, fmt.Errorf("iterator value: %w", errValue)
+		}
+
+		created = append(created, string(val))
+	}
+
+	// every replica picks the same key to index with
+	sort.Strings(created)
+
+	return append(keyURLs, created...), nil
+}
+
+func (c *Client) getDocID(vaultID, edvID string) (string, error) {
+	src, err := c.store.Get(fmt.Sprintf(docIDFormat, vaultID, edvID))
+	if err != nil {
+		return "", fmt.Errorf("store get: %w", err)
+	}
+
+	return string(src), nil
+}
+
+func computeMAC(c ariescrypto.Crypto, data, keyURL string) (string, error) {
+	mac, err := c.ComputeMAC([]byte(data), keyURL)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.URLEncoding.EncodeToString(mac), nil
+}
+
+// docMeta keeps the plaintext indexed attributes in the encrypted document, so they survive an export.
+func docMeta(indexed []IndexedAttribute) map[string]interface{} {
+	if len(indexed) == 0 {
+		return nil
+	}
+
+	return map[string]interface{}{indexedMetaKey: indexed}
+}
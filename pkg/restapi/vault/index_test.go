@@ -0,0 +1,312 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vault_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/edv/pkg/restapi/models"
+
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+	. "github.com/trustbloc/edge-service/pkg/restapi/vault"
+)
+
+const macKeyURL = "/kms/keystores/c0ekinlioud42c84qs7g/keys/mac"
+
+func TestClient_SaveDocIndexed(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	t.Run("Create MAC key (error)", func(t *testing.T) {
+		remoteKMS := newIndexKMS(t, false)
+
+		data := map[string]mockstorage.DBEntry{}
+		store := &mockstorage.MockStoreProvider{Store: &mockstorage.MockStore{Store: data}}
+
+		lKMS := newLocalKms(t, store)
+		client, err := NewClient(remoteKMS.URL, "", lKMS, store, loader)
+		require.NoError(t, err)
+
+		vID, dURL, _ := createVaultID(t, lKMS)
+
+		data["info_"+vID] = mockstorage.DBEntry{
+			Value: []byte(`{"did_url":"` + dURL + `", "auth":{"edv":{},"kms":{"uri":"/"}}}`),
+		}
+
+		_, err = client.SaveDoc(vID, "docID", []byte(`{}`), IndexedAttribute{Name: "type", Value: "VC"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "indexed attributes: create MAC key")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		remoteKMS := newIndexKMS(t, true)
+
+		var saved *models.EncryptedDocument
+
+		edv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&saved))
+
+			w.Header().Set("Location", "localhost:7777/encrypted-data-vaults/DWPPbEVn1afJY4We3kpQmq")
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		data := map[string]mockstorage.DBEntry{}
+		store := &mockstorage.MockStoreProvider{Store: &mockstorage.MockStore{Store: data}}
+
+		lKMS := newLocalKms(t, store)
+		client, err := NewClient(remoteKMS.URL, edv.URL, lKMS, store, loader)
+		require.NoError(t, err)
+
+		vID, dURL, _ := createVaultID(t, lKMS)
+
+		data["info_"+vID] = mockstorage.DBEntry{
+			Value: []byte(`{"did_url":"` + dURL + `", "auth":{"edv":{},"kms":{"uri":"/"}}}`),
+		}
+
+		docMeta, err := client.SaveDoc(vID, "docID", []byte(`{}`), IndexedAttribute{Name: "type", Value: "VC"})
+		require.NoError(t, err)
+		require.Equal(t, "docID", docMeta.ID)
+
+		require.Len(t, saved.IndexedAttributeCollections, 1)
+		require.Equal(t, macKeyURL, saved.IndexedAttributeCollections[0].HMAC.ID)
+		require.Len(t, saved.IndexedAttributeCollections[0].IndexedAttributes, 1)
+		// attributes are blinded
+		require.NotEqual(t, "type", saved.IndexedAttributeCollections[0].IndexedAttributes[0].Name)
+		require.NotEqual(t, "VC", saved.IndexedAttributeCollections[0].IndexedAttributes[0].Value)
+
+		// the vault's info is not updated, the key gets its own record
+		require.NotContains(t, string(data["info_"+vID].Value), macKeyURL)
+		require.Equal(t, macKeyURL, string(data["mac_key_"+vID+"_mac"].Value))
+		require.Equal(t, "docID", string(data["doc_id_"+vID+"_"+saved.ID].Value))
+
+		// later saves index with the recorded key
+		_, err = client.SaveDoc(vID, "docID2", []byte(`{}`), IndexedAttribute{Name: "type", Value: "VC"})
+		require.NoError(t, err)
+		require.Equal(t, macKeyURL, saved.IndexedAttributeCollections[0].HMAC.ID)
+	})
+}
+
+func TestClient_QueryDocs(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	t.Run("No vault", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{},
+		}, loader)
+		require.NoError(t, err)
+
+		_, err = client.QueryDocs("vid", "name", "value")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("Nothing indexed", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{
+				Store: map[string]mockstorage.DBEntry{
+					"info_vid": {Value: []byte(`{"auth":{"edv":{},"kms":{}}}`)},
+				},
+			},
+		}, loader)
+		require.NoError(t, err)
+
+		docs, err := client.QueryDocs("vid", "name", "value")
+		require.NoError(t, err)
+		require.Empty(t, docs)
+	})
+
+	t.Run("Compute MAC (error)", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{
+				Store: map[string]mockstorage.DBEntry{
+					"info_vid": {Value: []byte(`{"mac_key_url":"` + macKeyURL + `","auth":{"edv":{},"kms":{}}}`)},
+				},
+			},
+		}, loader)
+		require.NoError(t, err)
+
+		_, err = client.QueryDocs("vid", "name", "value")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "compute MAC")
+	})
+
+	t.Run("Query vault (error)", func(t *testing.T) {
+		remoteKMS := newIndexKMS(t, true)
+
+		edv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+
+		client, vID := newIndexedClient(t, remoteKMS.URL, edv.URL, nil)
+
+		_, err := client.QueryDocs(vID, "name", "value")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "query vault")
+	})
+
+	t.Run("Unknown document", func(t *testing.T) {
+		remoteKMS := newIndexKMS(t, true)
+
+		edv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, json.NewEncoder(w).Encode([]string{"localhost:7777/encrypted-data-vaults/vid/documents/eID"}))
+		}))
+
+		client, vID := newIndexedClient(t, remoteKMS.URL, edv.URL, nil)
+
+		_, err := client.QueryDocs(vID, "name", "value")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get doc ID")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		remoteKMS := newIndexKMS(t, true)
+
+		edv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var query models.Query
+
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&query))
+			require.NotEqual(t, "name", query.Name)
+			require.NotEqual(t, "value", query.Value)
+
+			require.NoError(t, json.NewEncoder(w).Encode([]string{"localhost:7777/encrypted-data-vaults/vid/documents/eID"}))
+		}))
+
+		client, vID := newIndexedClient(t, remoteKMS.URL, edv.URL, func(vID string) map[string]mockstorage.DBEntry {
+			return map[string]mockstorage.DBEntry{
+				"doc_id_" + vID + "_eID":          {Value: []byte(`docID`)},
+				"meta_doc_info_" + vID + "_docID": {Value: []byte(`{"edv_id":"eID", "kid_url":"kURL"}`)},
+			}
+		})
+
+		docs, err := client.QueryDocs(vID, "name", "value")
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+		require.Equal(t, "docID", docs[0].ID)
+		require.Equal(t, "kURL", docs[0].EncKeyURI)
+		require.True(t, strings.HasSuffix(docs[0].URI, "/documents/eID"))
+	})
+
+	t.Run("MAC keys created by several replicas", func(t *testing.T) {
+		remoteKMS := newIndexKMS(t, true)
+
+		const otherMACKeyURL = "/kms/keystores/c0ekinlioud42c84qs7g/keys/mac2"
+
+		edv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var query models.Query
+
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&query))
+
+			// the mock KMS derives MACs from the key's URL
+			mac, err := base64.URLEncoding.DecodeString(query.Name)
+			require.NoError(t, err)
+
+			docURL := "localhost:7777/encrypted-data-vaults/vid/documents/eID"
+			if strings.Contains(string(mac), "/mac2/") {
+				docURL = "localhost:7777/encrypted-data-vaults/vid/documents/eID2"
+			}
+
+			require.NoError(t, json.NewEncoder(w).Encode([]string{docURL}))
+		}))
+
+		client, vID := newIndexedClient(t, remoteKMS.URL, edv.URL, func(vID string) map[string]mockstorage.DBEntry {
+			return map[string]mockstorage.DBEntry{
+				"mac_key_" + vID + "_mac2": {Value: []byte(otherMACKeyURL), Tags: []storage.Tag{{
+					Name:  "vaultMACKeys",
+					Value: base64.RawURLEncoding.EncodeToString([]byte(vID)),
+				}}},
+				"doc_id_" + vID + "_eID":           {Value: []byte(`docID`)},
+				"doc_id_" + vID + "_eID2":          {Value: []byte(`docID2`)},
+				"meta_doc_info_" + vID + "_docID":  {Value: []byte(`{"edv_id":"eID", "kid_url":"kURL"}`)},
+				"meta_doc_info_" + vID + "_docID2": {Value: []byte(`{"edv_id":"eID2", "kid_url":"kURL"}`)},
+			}
+		})
+
+		docs, err := client.QueryDocs(vID, "name", "value")
+		require.NoError(t, err)
+		require.Len(t, docs, 2)
+		require.Equal(t, "docID", docs[0].ID)
+		require.Equal(t, "docID2", docs[1].ID)
+	})
+}
+
+func newIndexedClient(t *testing.T, kmsURL, edvURL string,
+	records func(vID string) map[string]mockstorage.DBEntry) (*Client, string) {
+	t.Helper()
+
+	data := map[string]mockstorage.DBEntry{}
+	store := &mockstorage.MockStoreProvider{Store: &mockstorage.MockStore{Store: data}}
+
+	lKMS := newLocalKms(t, store)
+	client, err := NewClient(kmsURL, edvURL, lKMS, store, testutil.DocumentLoader(t))
+	require.NoError(t, err)
+
+	vID, dURL, _ := createVaultID(t, lKMS)
+
+	data["info_"+vID] = mockstorage.DBEntry{
+		Value: []byte(`{"did_url":"` + dURL + `","mac_key_url":"` + macKeyURL + `","auth":{"edv":{},"kms":{"uri":"/"}}}`),
+	}
+
+	if records != nil {
+		for k, v := range records(vID) {
+			data[k] = v
+		}
+	}
+
+	return client, vID
+}
+
+// newIndexKMS returns a remote KMS that serves encryption keys and, if macKeys is set, MAC keys.
+func newIndexKMS(t *testing.T, macKeys bool) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			KeyType string `json:"keyType"`
+		}
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/keys"):
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+			if req.KeyType == string(kms.HMACSHA256Tag256Type) {
+				if !macKeys {
+					w.WriteHeader(http.StatusInternalServerError)
+
+					return
+				}
+
+				w.Header().Set("Location", macKeyURL)
+			} else {
+				w.Header().Set("Location", "/kms/keystores/c0ekinlioud42c84qs7g/keys/GKszTDQcWrFlMS-BO7-asfNgaFfMZ96t6eeTjI__Y1c")
+			}
+
+			w.WriteHeader(http.StatusCreated)
+		case strings.HasSuffix(r.URL.Path, "/export"):
+			payload, err := json.Marshal(map[string][]byte{"publicKey": []byte(`{"kid":"GKszTDQcWrFlMS-BO7-asfNgaFfMZ96t6eeTjI__Y1c","x":"IM1/HfveJ4rbqAYzBOmVOnpys4h3J0yA3I238AjYzZc=","y":"S+h2S7IbWCZiQjOaNIhSvyqNcRnRKavdiC1BU8F2UU4=","curve":"NIST_P256","type":"EC"}`)}) // nolint: lll
+			require.NoError(t, err)
+
+			_, err = w.Write(payload)
+			require.NoError(t, err)
+		case strings.HasSuffix(r.URL.Path, "/computemac"):
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]string{
+				"mac": base64.URLEncoding.EncodeToString([]byte(r.URL.Path + "mac")),
+			}))
+		default:
+			_, err := w.Write([]byte(kmsResponse))
+			require.NoError(t, err)
+		}
+	}))
+}
@@ -0,0 +1,58 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vault
+
+import (
+	ariescrypto "github.com/hyperledger/aries-framework-go/pkg/crypto"
+	webcrypto "github.com/hyperledger/aries-framework-go/pkg/crypto/webkms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/webkms"
+)
+
+// KMSProvider provides the keystores holding the vaults' keys.
+// Every keystore is controlled by the vault's DID and accessed with the zcap returned on its creation,
+// the zcap is the root of the authorizations' KMS capability chains.
+type KMSProvider interface {
+	// CreateKeyStore creates a keystore for the controller. It returns the keystore's URI and the compressed zcap
+	// granting the controller access to it.
+	CreateKeyStore(controller string) (string, string, error)
+	// KeyManager returns the key manager of the keystore the location points to.
+	KeyManager(controller string, auth *Location) KeyManager
+	// Crypto returns the crypto operating on the keys of the keystore the location points to.
+	Crypto(controller string, auth *Location) ariescrypto.Crypto
+}
+
+// WithKMSProvider allows providing the KMS the vaults' keystores are created in. Defaults to the remote KMS.
+func WithKMSProvider(provider KMSProvider) Opt {
+	return func(vault *Client) {
+		vault.kmsProvider = provider
+	}
+}
+
+// remoteKMSProvider keeps the vaults' keystores in a remote KMS, requests are signed with the vault's DID keys.
+type remoteKMSProvider struct {
+	client *Client
+}
+
+func (p *remoteKMSProvider) CreateKeyStore(controller string) (string, string, error) {
+	return webkms.CreateKeyStore(p.client.httpClient, p.client.remoteKMSURL, controller, "")
+}
+
+func (p *remoteKMSProvider) KeyManager(controller string, auth *Location) KeyManager {
+	return webkms.New(
+		p.client.buildKMSURL(auth.URI),
+		p.client.httpClient,
+		webkms.WithHeaders(p.client.kmsSign(controller, auth)),
+	)
+}
+
+func (p *remoteKMSProvider) Crypto(controller string, auth *Location) ariescrypto.Crypto {
+	return webcrypto.New(
+		p.client.buildKMSURL(auth.URI),
+		p.client.httpClient,
+		webkms.WithHeaders(p.client.kmsSign(controller, auth)),
+	)
+}
@@ -0,0 +1,397 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vault
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	ariescrypto "github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/vdr/fingerprint"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/piprate/json-gold/ld"
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+const (
+	localKMSStoreName = "vault_kms"
+
+	// LocalKeyStoreURIPrefix prefixes URIs of keystores created by the local KMS provider.
+	LocalKeyStoreURIPrefix = "local-kms://keystores/"
+
+	localKeyStoreFormat = "keystore_%s"
+	localKeyFormat      = "key_%s_%s"
+
+	keyStoreTargetType = "urn:kms:keystore"
+
+	ed25519VerificationKey2018 = "Ed25519VerificationKey2018"
+)
+
+// ErrKeyNotFound is returned when a key does not belong to the keystore.
+var ErrKeyNotFound = errors.New("key not found in the keystore")
+
+// nolint: gochecknoglobals
+var keyStoreActions = []string{
+	"createKey", "exportKey", "importKey", "sign", "verify", "wrap", "unwrap", "computeMAC", "verifyMAC",
+	"encrypt", "decrypt", "updateEDVCapability", "easy", "easyOpen", "sealOpen", "signMulti", "verifyMulti",
+	"deriveProof", "verifyProof",
+}
+
+// LocalKMSProvider keeps the vaults' keystores in a local KMS, so vault-server can run without a remote KMS.
+// Keystores are logical, all keys live in the given key manager and each keystore only exposes the keys
+// created in it. Like the remote KMS, every keystore comes with a root zcap for its controller and can only be
+// used with it, the zcap's proof is verified. Unlike the remote KMS, delegated zcaps are rejected: the keystores
+// are only used by vault-server on behalf of the vaults' controllers, so the vaults can't create authorizations.
+type LocalKMSProvider struct {
+	kms            kms.KeyManager
+	crypto         ariescrypto.Crypto
+	store          storage.Store
+	documentLoader ld.DocumentLoader
+	verified       sync.Map
+}
+
+// localKeyStore is the record of a keystore of the local KMS.
+type localKeyStore struct {
+	Controller         string `json:"controller"`
+	VerificationMethod string `json:"verificationMethod"`
+	PublicKey          []byte `json:"publicKey"`
+}
+
+// NewLocalKMSProvider creates a new local KMS provider on top of the key manager.
+func NewLocalKMSProvider(km kms.KeyManager, db storage.Provider,
+	loader ld.DocumentLoader) (*LocalKMSProvider, error) {
+	cryptoService, err := tinkcrypto.New()
+	if err != nil {
+		return nil, fmt.Errorf("tinkcrypto new: %w", err)
+	}
+
+	store, err := db.OpenStore(localKMSStoreName)
+	if err != nil {
+		return nil, fmt.Errorf("open store: %w", err)
+	}
+
+	return &LocalKMSProvider{
+		kms:            km,
+		crypto:         cryptoService,
+		store:          store,
+		documentLoader: loader,
+	}, nil
+}
+
+// CreateKeyStore creates a keystore for the controller. It returns the keystore's URI and the compressed zcap
+// granting the controller access to it.
+func (p *LocalKMSProvider) CreateKeyStore(controller string) (string, string, error) {
+	keyStoreID := uuid.New().String()
+	keyStoreURI := LocalKeyStoreURIPrefix + keyStoreID
+
+	// as the remote KMS does, the root capability is signed with a key dedicated to it
+	kid, pubKey, err := p.kms.CreateAndExportPubKeyBytes(kms.ED25519Type)
+	if err != nil {
+		return "", "", fmt.Errorf("create signing key: %w", err)
+	}
+
+	kh, err := p.kms.Get(kid)
+	if err != nil {
+		return "", "", fmt.Errorf("kms get: %w", err)
+	}
+
+	_, verificationMethod := fingerprint.CreateDIDKey(pubKey)
+
+	capability, err := zcapld.NewCapability(&zcapld.Signer{
+		SignatureSuite:     ed25519signature2018.New(suite.WithSigner(newSigner(p.crypto, kh))),
+		SuiteType:          ed25519signature2018.SignatureType,
+		VerificationMethod: verificationMethod,
+		ProcessorOpts:      []jsonld.ProcessorOpts{jsonld.WithDocumentLoader(p.documentLoader)},
+	}, zcapld.WithID(keyStoreURI), zcapld.WithInvoker(controller),
+		zcapld.WithAllowedActions(keyStoreActions...),
+		zcapld.WithInvocationTarget(keyStoreURI, keyStoreTargetType))
+	if err != nil {
+		return "", "", fmt.Errorf("new capability: %w", err)
+	}
+
+	compressed, err := zcapld.CompressZCAP(capability)
+	if err != nil {
+		return "", "", fmt.Errorf("compress zcap: %w", err)
+	}
+
+	src, err := json.Marshal(&localKeyStore{
+		Controller:         controller,
+		VerificationMethod: verificationMethod,
+		PublicKey:          pubKey,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("marshal keystore: %w", err)
+	}
+
+	err = p.store.Put(fmt.Sprintf(localKeyStoreFormat, keyStoreID), src)
+	if err != nil {
+		return "", "", fmt.Errorf("store put: %w", err)
+	}
+
+	return keyStoreURI, compressed, nil
+}
+
+// KeyManager returns the key manager of the keystore the location points to.
+func (p *LocalKMSProvider) KeyManager(controller string, auth *Location) KeyManager {
+	keyStoreID, err := p.openKeyStore(controller, auth)
+
+	return &localKeyManager{provider: p, keyStoreID: keyStoreID, err: err}
+}
+
+// Crypto returns the crypto operating on the keys of the keystore the location points to.
+// Keys can be referred to by their URLs as well as by their handles.
+func (p *LocalKMSProvider) Crypto(controller string, auth *Location) ariescrypto.Crypto {
+	keyStoreID, err := p.openKeyStore(controller, auth)
+
+	return &localCrypto{
+		Crypto: p.crypto,
+		km:     &localKeyManager{provider: p, keyStoreID: keyStoreID, err: err},
+	}
+}
+
+// openKeyStore checks that the location's zcap grants the controller access to the keystore.
+func (p *LocalKMSProvider) openKeyStore(controller string, auth *Location) (string, error) {
+	if !strings.HasPrefix(auth.URI, LocalKeyStoreURIPrefix) {
+		return "", fmt.Errorf("not a local keystore: %s", auth.URI)
+	}
+
+	keyStoreID := strings.TrimPrefix(auth.URI, LocalKeyStoreURIPrefix)
+
+	// the same zcap is presented by every operation on the vault, its proof is verified once
+	verifiedKey := strings.Join([]string{auth.URI, controller, auth.AuthToken}, " ")
+
+	if _, ok := p.verified.Load(verifiedKey); ok {
+		return keyStoreID, nil
+	}
+
+	src, err := p.store.Get(fmt.Sprintf(localKeyStoreFormat, keyStoreID))
+	if err != nil {
+		return "", fmt.Errorf("get keystore: %w", err)
+	}
+
+	var keyStore localKeyStore
+
+	err = json.Unmarshal(src, &keyStore)
+	if err != nil {
+		return "", fmt.Errorf("unmarshal keystore: %w", err)
+	}
+
+	capability, err := zcapld.DecompressZCAP(auth.AuthToken)
+	if err != nil {
+		return "", fmt.Errorf("decompress zcap: %w", err)
+	}
+
+	if capability.Parent != "" {
+		return "", errors.New("delegated zcaps are not supported by the local KMS")
+	}
+
+	if capability.ID != auth.URI || capability.InvocationTarget.ID != auth.URI ||
+		capability.Invoker != controller || keyStore.Controller != controller {
+		return "", errors.New("zcap does not grant access to the keystore")
+	}
+
+	err = p.verifyZCAP(capability, &keyStore)
+	if err != nil {
+		return "", fmt.Errorf("verify zcap: %w", err)
+	}
+
+	p.verified.Store(verifiedKey, struct{}{})
+
+	return keyStoreID, nil
+}
+
+// verifyZCAP checks that the zcap is signed with the key the keystore's root zcap was created with.
+func (p *LocalKMSProvider) verifyZCAP(capability *zcapld.Capability, keyStore *localKeyStore) error {
+	if len(capability.Proof) != 1 || capability.Proof[0]["verificationMethod"] != keyStore.VerificationMethod {
+		return errors.New("zcap is not signed by the keystore")
+	}
+
+	v, err := verifier.New(zcapld.SimpleKeyResolver{
+		keyStore.VerificationMethod: {Type: ed25519VerificationKey2018, Value: keyStore.PublicKey},
+	}, ed25519signature2018.New(suite.WithVerifier(ed25519signature2018.NewPublicKeyVerifier())))
+	if err != nil {
+		return fmt.Errorf("new verifier: %w", err)
+	}
+
+	src, err := json.Marshal(capability)
+	if err != nil {
+		return fmt.Errorf("marshal zcap: %w", err)
+	}
+
+	return v.Verify(src, jsonld.WithDocumentLoader(p.documentLoader))
+}
+
+// localKeyManager exposes the keys of a single keystore of the local KMS.
+// Created keys are identified by their URLs, as the remote KMS does.
+type localKeyManager struct {
+	provider   *LocalKMSProvider
+	keyStoreID string
+	err        error
+}
+
+func (k *localKeyManager) Create(kt kms.KeyType) (string, interface{}, error) {
+	if k.err != nil {
+		return "", nil, k.err
+	}
+
+	keyID, _, err := k.provider.kms.Create(kt)
+	if err != nil {
+		return "", nil, err
+	}
+
+	keyURL, err := k.addKey(keyID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return keyID, keyURL, nil
+}
+
+func (k *localKeyManager) Get(keyID string) (interface{}, error) {
+	if err := k.checkKey(keyID); err != nil {
+		return nil, err
+	}
+
+	return k.provider.kms.Get(keyID)
+}
+
+func (k *localKeyManager) Rotate(kt kms.KeyType, keyID string) (string, interface{}, error) {
+	if err := k.checkKey(keyID); err != nil {
+		return "", nil, err
+	}
+
+	newKeyID, kh, err := k.provider.kms.Rotate(kt, keyID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if _, err = k.addKey(newKeyID); err != nil {
+		return "", nil, err
+	}
+
+	return newKeyID, kh, nil
+}
+
+func (k *localKeyManager) ExportPubKeyBytes(keyID string) ([]byte, error) {
+	if err := k.checkKey(keyID); err != nil {
+		return nil, err
+	}
+
+	return k.provider.kms.ExportPubKeyBytes(keyID)
+}
+
+func (k *localKeyManager) CreateAndExportPubKeyBytes(kt kms.KeyType) (string, []byte, error) {
+	if k.err != nil {
+		return "", nil, k.err
+	}
+
+	keyID, pubKey, err := k.provider.kms.CreateAndExportPubKeyBytes(kt)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if _, err = k.addKey(keyID); err != nil {
+		return "", nil, err
+	}
+
+	return keyID, pubKey, nil
+}
+
+func (k *localKeyManager) PubKeyBytesToHandle(pubKey []byte, kt kms.KeyType) (interface{}, error) {
+	if k.err != nil {
+		return nil, k.err
+	}
+
+	return k.provider.kms.PubKeyBytesToHandle(pubKey, kt)
+}
+
+func (k *localKeyManager) ImportPrivateKey(privKey interface{}, kt kms.KeyType,
+	opts ...kms.PrivateKeyOpts) (string, interface{}, error) {
+	if k.err != nil {
+		return "", nil, k.err
+	}
+
+	keyID, kh, err := k.provider.kms.ImportPrivateKey(privKey, kt, opts...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if _, err = k.addKey(keyID); err != nil {
+		return "", nil, err
+	}
+
+	return keyID, kh, nil
+}
+
+func (k *localKeyManager) addKey(keyID string) (string, error) {
+	err := k.provider.store.Put(fmt.Sprintf(localKeyFormat, k.keyStoreID, keyID), []byte(keyID))
+	if err != nil {
+		return "", fmt.Errorf("store put: %w", err)
+	}
+
+	return LocalKeyStoreURIPrefix + k.keyStoreID + "/keys/" + keyID, nil
+}
+
+func (k *localKeyManager) checkKey(keyID string) error {
+	if k.err != nil {
+		return k.err
+	}
+
+	_, err := k.provider.store.Get(fmt.Sprintf(localKeyFormat, k.keyStoreID, keyID))
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+
+	if err != nil {
+		return fmt.Errorf("store get: %w", err)
+	}
+
+	return nil
+}
+
+// localCrypto resolves key URLs to the keystore's key handles, MAC keys are referred to by URLs.
+type localCrypto struct {
+	ariescrypto.Crypto
+	km *localKeyManager
+}
+
+func (c *localCrypto) ComputeMAC(data []byte, kh interface{}) ([]byte, error) {
+	kh, err := c.keyHandle(kh)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Crypto.ComputeMAC(data, kh)
+}
+
+func (c *localCrypto) VerifyMAC(mac, data []byte, kh interface{}) error {
+	kh, err := c.keyHandle(kh)
+	if err != nil {
+		return err
+	}
+
+	return c.Crypto.VerifyMAC(mac, data, kh)
+}
+
+func (c *localCrypto) keyHandle(kh interface{}) (interface{}, error) {
+	keyURL, ok := kh.(string)
+	if !ok {
+		return kh, nil
+	}
+
+	return c.km.Get(lastElm(keyURL, "/"))
+}
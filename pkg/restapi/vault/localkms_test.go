@@ -0,0 +1,339 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vault_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util/signature"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/vdr/fingerprint"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	"github.com/trustbloc/edv/pkg/restapi/models"
+
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+	. "github.com/trustbloc/edge-service/pkg/restapi/vault"
+)
+
+func TestNewLocalKMSProvider(t *testing.T) {
+	t.Run("Open store (error)", func(t *testing.T) {
+		_, err := NewLocalKMSProvider(nil, &mockstorage.MockStoreProvider{
+			ErrOpenStoreHandle: errors.New("test"),
+		}, testutil.DocumentLoader(t))
+		require.EqualError(t, err, "open store: test")
+	})
+}
+
+func TestLocalKMSProvider_CreateKeyStore(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		provider := newLocalKMSProvider(t)
+
+		uri, token, err := provider.CreateKeyStore("did:example:controller#key-1")
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(uri, LocalKeyStoreURIPrefix))
+
+		capability, err := zcapld.DecompressZCAP(token)
+		require.NoError(t, err)
+		require.Equal(t, uri, capability.ID)
+		require.Equal(t, uri, capability.InvocationTarget.ID)
+		require.Equal(t, "urn:kms:keystore", capability.InvocationTarget.Type)
+		require.Equal(t, "did:example:controller#key-1", capability.Invoker)
+		require.Contains(t, capability.AllowedAction, "unwrap")
+		require.Len(t, capability.Proof, 1)
+	})
+
+	t.Run("Store put (error)", func(t *testing.T) {
+		store := &mockstorage.MockStoreProvider{Store: &mockstorage.MockStore{
+			Store:  map[string]mockstorage.DBEntry{},
+			ErrPut: errors.New("test"),
+		}}
+
+		provider, err := NewLocalKMSProvider(newLocalKms(t, mockstorage.NewMockStoreProvider()), store,
+			testutil.DocumentLoader(t))
+		require.NoError(t, err)
+
+		_, _, err = provider.CreateKeyStore("did:example:controller#key-1")
+		require.EqualError(t, err, "store put: test")
+	})
+}
+
+func TestLocalKMSProvider_KeyManager(t *testing.T) {
+	const controller = "did:example:controller#key-1"
+
+	provider := newLocalKMSProvider(t)
+
+	uri, token, err := provider.CreateKeyStore(controller)
+	require.NoError(t, err)
+
+	t.Run("Keys are identified by URLs", func(t *testing.T) {
+		km := provider.KeyManager(controller, &Location{URI: uri, AuthToken: token})
+
+		keyID, keyURL, err := km.Create(kms.NISTP256ECDHKW)
+		require.NoError(t, err)
+		require.Equal(t, uri+"/keys/"+keyID, keyURL)
+
+		_, err = km.ExportPubKeyBytes(keyID)
+		require.NoError(t, err)
+
+		_, err = km.Get(keyID)
+		require.NoError(t, err)
+	})
+
+	t.Run("Keys of other keystores are not exposed", func(t *testing.T) {
+		keyID, _, err := provider.KeyManager(controller, &Location{URI: uri, AuthToken: token}).
+			CreateAndExportPubKeyBytes(kms.ED25519Type)
+		require.NoError(t, err)
+
+		otherURI, otherToken, err := provider.CreateKeyStore(controller)
+		require.NoError(t, err)
+
+		_, err = provider.KeyManager(controller, &Location{URI: otherURI, AuthToken: otherToken}).Get(keyID)
+		require.True(t, errors.Is(err, ErrKeyNotFound))
+	})
+
+	t.Run("Not a local keystore", func(t *testing.T) {
+		_, _, err := provider.KeyManager(controller, &Location{URI: "https://kms/keystores/ks", AuthToken: token}).
+			Create(kms.NISTP256ECDHKW)
+		require.EqualError(t, err, "not a local keystore: https://kms/keystores/ks")
+	})
+
+	t.Run("Unknown keystore", func(t *testing.T) {
+		_, _, err := provider.KeyManager(controller, &Location{URI: LocalKeyStoreURIPrefix + "ks", AuthToken: token}).
+			Create(kms.NISTP256ECDHKW)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get keystore")
+	})
+
+	t.Run("Zcap of another controller", func(t *testing.T) {
+		_, _, err := provider.KeyManager("did:example:other#key-1", &Location{URI: uri, AuthToken: token}).
+			Create(kms.NISTP256ECDHKW)
+		require.EqualError(t, err, "zcap does not grant access to the keystore")
+	})
+
+	t.Run("Zcap of another keystore", func(t *testing.T) {
+		_, otherToken, err := provider.CreateKeyStore(controller)
+		require.NoError(t, err)
+
+		_, _, err = provider.KeyManager(controller, &Location{URI: uri, AuthToken: otherToken}).
+			Create(kms.NISTP256ECDHKW)
+		require.EqualError(t, err, "zcap does not grant access to the keystore")
+	})
+
+	t.Run("Zcap not signed by the keystore", func(t *testing.T) {
+		signer, err := signature.NewSigner(kms.ED25519Type)
+		require.NoError(t, err)
+
+		_, verificationMethod := fingerprint.CreateDIDKey(signer.PublicKeyBytes())
+
+		capability, err := zcapld.NewCapability(&zcapld.Signer{
+			SignatureSuite:     ed25519signature2018.New(suite.WithSigner(signer)),
+			SuiteType:          ed25519signature2018.SignatureType,
+			VerificationMethod: verificationMethod,
+			ProcessorOpts:      []jsonld.ProcessorOpts{jsonld.WithDocumentLoader(testutil.DocumentLoader(t))},
+		}, zcapld.WithID(uri), zcapld.WithInvoker(controller), zcapld.WithInvocationTarget(uri, "urn:kms:keystore"))
+		require.NoError(t, err)
+
+		forged, err := zcapld.CompressZCAP(capability)
+		require.NoError(t, err)
+
+		_, _, err = provider.KeyManager(controller, &Location{URI: uri, AuthToken: forged}).
+			Create(kms.NISTP256ECDHKW)
+		require.EqualError(t, err, "verify zcap: zcap is not signed by the keystore")
+	})
+
+	t.Run("Zcap with a tampered proof", func(t *testing.T) {
+		capability, err := zcapld.DecompressZCAP(token)
+		require.NoError(t, err)
+
+		capability.AllowedAction = append(capability.AllowedAction, "tampered")
+
+		tampered, err := zcapld.CompressZCAP(capability)
+		require.NoError(t, err)
+
+		_, _, err = provider.KeyManager(controller, &Location{URI: uri, AuthToken: tampered}).
+			Create(kms.NISTP256ECDHKW)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "verify zcap")
+	})
+
+	t.Run("Delegated zcap", func(t *testing.T) {
+		delegated, err := zcapld.CompressZCAP(&zcapld.Capability{
+			ID:               uri,
+			Parent:           uri,
+			Invoker:          controller,
+			InvocationTarget: zcapld.InvocationTarget{ID: uri, Type: "urn:kms:keystore"},
+		})
+		require.NoError(t, err)
+
+		_, _, err = provider.KeyManager(controller, &Location{URI: uri, AuthToken: delegated}).
+			Create(kms.NISTP256ECDHKW)
+		require.EqualError(t, err, "delegated zcaps are not supported by the local KMS")
+	})
+}
+
+func TestLocalKMSProvider_Crypto(t *testing.T) {
+	const controller = "did:example:controller#key-1"
+
+	provider := newLocalKMSProvider(t)
+
+	uri, token, err := provider.CreateKeyStore(controller)
+	require.NoError(t, err)
+
+	auth := &Location{URI: uri, AuthToken: token}
+
+	_, keyURL, err := provider.KeyManager(controller, auth).Create(kms.HMACSHA256Tag256Type)
+	require.NoError(t, err)
+
+	mac, err := provider.Crypto(controller, auth).ComputeMAC([]byte("data"), keyURL)
+	require.NoError(t, err)
+
+	require.NoError(t, provider.Crypto(controller, auth).VerifyMAC(mac, []byte("data"), keyURL))
+
+	otherURI, otherToken, err := provider.CreateKeyStore(controller)
+	require.NoError(t, err)
+
+	_, err = provider.Crypto(controller, &Location{URI: otherURI, AuthToken: otherToken}).
+		ComputeMAC([]byte("data"), keyURL)
+	require.True(t, errors.Is(err, ErrKeyNotFound))
+}
+
+func TestClient_LocalKMS(t *testing.T) {
+	edv := newMemEDV(t)
+
+	data := map[string]mockstorage.DBEntry{}
+	store := &mockstorage.MockStoreProvider{Store: &mockstorage.MockStore{Store: data}}
+
+	lKMS := newLocalKms(t, store)
+
+	provider, err := NewLocalKMSProvider(lKMS, mockstorage.NewMockStoreProvider(), testutil.DocumentLoader(t))
+	require.NoError(t, err)
+
+	client, err := NewClient("", edv.URL, lKMS, store, testutil.DocumentLoader(t), WithKMSProvider(provider))
+	require.NoError(t, err)
+
+	vID, dURL, kid := createVaultID(t, lKMS)
+
+	kmsURI, kmsToken, err := provider.CreateKeyStore(dURL)
+	require.NoError(t, err)
+
+	// the mock EDV does not check capabilities, the zcap is not signed
+	edvToken, err := zcapld.CompressZCAP(&zcapld.Capability{
+		ID:               "urn:uuid:edv-zcap",
+		Parent:           "urn:uuid:edv-root-zcap",
+		Invoker:          dURL,
+		AllowedAction:    []string{"read", "write"},
+		InvocationTarget: zcapld.InvocationTarget{ID: "vid", Type: "urn:edv:vault"},
+	})
+	require.NoError(t, err)
+
+	info, err := json.Marshal(map[string]interface{}{
+		"did_url":      dURL,
+		"kid":          kid,
+		"docs_tracked": true,
+		"auth": &Authorization{
+			EDV: &Location{URI: edv.URL + "/vid", AuthToken: edvToken},
+			KMS: &Location{URI: kmsURI, AuthToken: kmsToken},
+		},
+	})
+	require.NoError(t, err)
+
+	data["info_"+vID] = mockstorage.DBEntry{Value: info}
+
+	docMeta, err := client.SaveDoc(vID, "docID", []byte(`{"name":"value"}`), IndexedAttribute{Name: "type", Value: "VC"})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(docMeta.EncKeyURI, kmsURI+"/keys/"))
+
+	docs, err := client.QueryDocs(vID, "type", "VC")
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	require.Equal(t, "docID", docs[0].ID)
+
+	rotation, err := client.RotateKey(vID)
+	require.NoError(t, err)
+
+	rotation = waitForKeyRotation(t, client, vID)
+	require.Equal(t, KeyRotationCompleted, rotation.Status, rotation.Error)
+	require.Equal(t, 1, rotation.Done)
+
+	rotated, err := client.GetDocMetadata(vID, "docID")
+	require.NoError(t, err)
+	require.Equal(t, rotation.EncKeyURI, rotated.EncKeyURI)
+}
+
+func newLocalKMSProvider(t *testing.T) *LocalKMSProvider {
+	t.Helper()
+
+	store := mockstorage.NewMockStoreProvider()
+
+	provider, err := NewLocalKMSProvider(newLocalKms(t, store), store, testutil.DocumentLoader(t))
+	require.NoError(t, err)
+
+	return provider
+}
+
+// newMemEDV returns an EDV that keeps documents in memory.
+func newMemEDV(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var (
+		mu   sync.Mutex
+		docs = map[string][]byte{}
+	)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet:
+			_, err := w.Write(docs[lastPathElm(r.URL.Path)])
+			require.NoError(t, err)
+		case strings.HasSuffix(r.URL.Path, "/query"):
+			var ids []string
+
+			for id := range docs {
+				ids = append(ids, "localhost:7777/encrypted-data-vaults/vid/documents/"+id)
+			}
+
+			require.NoError(t, json.NewEncoder(w).Encode(ids))
+		default:
+			var doc models.EncryptedDocument
+
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&doc))
+
+			src, err := json.Marshal(doc)
+			require.NoError(t, err)
+
+			_, update := docs[doc.ID]
+			docs[doc.ID] = src
+
+			if update {
+				w.WriteHeader(http.StatusOK)
+
+				return
+			}
+
+			w.Header().Set("Location", "localhost:7777/encrypted-data-vaults/vid/documents/"+doc.ID)
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+}
+
+func lastPathElm(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
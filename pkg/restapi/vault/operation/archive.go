@@ -0,0 +1,83 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/trustbloc/edge-service/pkg/restapi/vault"
+)
+
+// archiveWriter streams a vault.Archive to the response document by document.
+type archiveWriter struct {
+	rw      http.ResponseWriter
+	vaultID string
+	started bool
+}
+
+// Write writes a document to the archive. The first call writes the response header.
+func (w *archiveWriter) Write(doc *vault.ArchivedDocument) error {
+	src, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	sep := ","
+
+	if !w.started {
+		if err = w.start(); err != nil {
+			return err
+		}
+
+		sep = ""
+	}
+
+	if _, err = w.rw.Write(append([]byte(sep), src...)); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	if f, ok := w.rw.(http.Flusher); ok {
+		f.Flush()
+	}
+
+	return nil
+}
+
+// Close completes the archive.
+func (w *archiveWriter) Close() {
+	if !w.started {
+		if err := w.start(); err != nil {
+			logger.Errorf("unable to send a response: %v", err)
+
+			return
+		}
+	}
+
+	if _, err := w.rw.Write([]byte("]}")); err != nil {
+		logger.Errorf("unable to send a response: %v", err)
+	}
+}
+
+func (w *archiveWriter) start() error {
+	vaultID, err := json.Marshal(w.vaultID)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	w.started = true
+
+	w.rw.Header().Set("Content-Type", "application/json")
+	w.rw.WriteHeader(http.StatusOK)
+
+	if _, err = w.rw.Write([]byte(fmt.Sprintf(`{"vaultID":%s,"documents":[`, vaultID))); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return nil
+}
@@ -9,6 +9,8 @@ package operation
 import (
 	"encoding/json"
 
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
 	"github.com/trustbloc/edge-service/pkg/restapi/vault"
 )
@@ -50,6 +52,8 @@ type SaveDocRequestBody struct {
 	ID      string          `json:"id"`
 	Content json.RawMessage `json:"content"`
 	Tags    []string        `json:"tags"`
+	// Indexed attributes are blinded with the vault's MAC key and can be used to query documents.
+	Indexed []vault.IndexedAttribute `json:"indexed,omitempty"`
 }
 
 // saveDocResp model
@@ -60,6 +64,52 @@ type saveDocResp struct {
 	Body *vault.DocumentMetadata
 }
 
+// queryDocsReq model
+//
+// swagger:parameters queryDocsReq
+type queryDocsReq struct {
+	// in: path
+	VaultID string `json:"vaultID"`
+	// in: body
+	// required: true
+	Request QueryDocsBody
+}
+
+// QueryDocsBody describes body for the QueryDocs request.
+type QueryDocsBody struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// queryDocsResp model
+//
+// swagger:response queryDocsResp
+type queryDocsResp struct {
+	// in: body
+	Body []*vault.DocumentMetadata
+}
+
+// trackDocsReq model
+//
+// swagger:parameters trackDocsReq
+type trackDocsReq struct {
+	// in: path
+	VaultID string `json:"vaultID"`
+	// in: body
+	// required: true
+	Request TrackDocsBody
+}
+
+// TrackDocsBody describes body for the TrackDocs request.
+type TrackDocsBody struct {
+	DocIDs []string `json:"docIDs"`
+}
+
+// trackDocsResp model
+//
+// swagger:response trackDocsResp
+type trackDocsResp struct{} // nolint: unused,deadcode
+
 // getDocMetadataReq model
 //
 // swagger:parameters getDocMetadataReq
@@ -103,6 +153,22 @@ type createAuthorizationResp struct {
 	Body *vault.CreatedAuthorization
 }
 
+// listAuthorizationsReq model
+//
+// swagger:parameters listAuthorizationsReq
+type listAuthorizationsReq struct { // nolint: unused,deadcode
+	// in: path
+	VaultID string `json:"vaultID"`
+}
+
+// listAuthorizationsResp model
+//
+// swagger:response listAuthorizationsResp
+type listAuthorizationsResp struct {
+	// in: body
+	Body []*vault.CreatedAuthorization
+}
+
 // getAuthorizationReq model
 //
 // swagger:parameters getAuthorizationReq
@@ -148,3 +214,86 @@ type deleteVaultReq struct { // nolint: unused,deadcode
 //
 // swagger:response deleteVaultResp
 type deleteVaultResp struct{} // nolint: unused,deadcode
+
+// createRecipientKeyReq model
+//
+// swagger:parameters createRecipientKeyReq
+type createRecipientKeyReq struct { // nolint: unused,deadcode
+	// in: path
+	VaultID string `json:"vaultID"`
+}
+
+// createRecipientKeyResp model
+//
+// swagger:response createRecipientKeyResp
+type createRecipientKeyResp struct {
+	// in: body
+	Body *crypto.PublicKey
+}
+
+// rotateKeyReq model
+//
+// swagger:parameters rotateKeyReq
+type rotateKeyReq struct { // nolint: unused,deadcode
+	// in: path
+	VaultID string `json:"vaultID"`
+}
+
+// getKeyRotationReq model
+//
+// swagger:parameters getKeyRotationReq
+type getKeyRotationReq struct { // nolint: unused,deadcode
+	// in: path
+	VaultID string `json:"vaultID"`
+}
+
+// keyRotationResp model
+//
+// swagger:response keyRotationResp
+type keyRotationResp struct {
+	// in: body
+	Body *vault.KeyRotation
+}
+
+// exportDocsReq model
+//
+// swagger:parameters exportDocsReq
+type exportDocsReq struct {
+	// in: path
+	VaultID string `json:"vaultID"`
+	// in: body
+	// required: true
+	Request ExportDocsBody
+}
+
+// ExportDocsBody describes body for the ExportDocs request.
+type ExportDocsBody struct {
+	RecipientKey *crypto.PublicKey `json:"recipientKey"`
+}
+
+// exportDocsResp model
+//
+// swagger:response exportDocsResp
+type exportDocsResp struct { // nolint: unused,deadcode
+	// in: body
+	Body *vault.Archive
+}
+
+// importDocsReq model
+//
+// swagger:parameters importDocsReq
+type importDocsReq struct {
+	// in: path
+	VaultID string `json:"vaultID"`
+	// in: body
+	// required: true
+	Request vault.Archive
+}
+
+// importDocsResp model
+//
+// swagger:response importDocsResp
+type importDocsResp struct {
+	// in: body
+	Body []*vault.DocumentMetadata
+}
@@ -30,10 +30,17 @@ const (
 	CreateVaultPath         = operationID
 	DeleteVaultPath         = operationID + "/{vaultID}"
 	SaveDocPath             = operationID + "/{vaultID}/docs"
+	QueryDocsPath           = operationID + "/{vaultID}/docs/query"
+	TrackDocsPath           = operationID + "/{vaultID}/docs/track"
 	GetDocMetadataPath      = operationID + "/{vaultID}/docs/{docID}/metadata"
 	CreateAuthorizationPath = operationID + "/{vaultID}/authorizations"
+	ListAuthorizationsPath  = operationID + "/{vaultID}/authorizations"
 	GetAuthorizationPath    = operationID + "/{vaultID}/authorizations/{authID}"
 	DeleteAuthorizationPath = operationID + "/{vaultID}/authorizations/{authID}"
+	CreateRecipientKeyPath  = operationID + "/{vaultID}/recipient-keys"
+	KeyRotationPath         = operationID + "/{vaultID}/key-rotation"
+	ExportDocsPath          = operationID + "/{vaultID}/export"
+	ImportDocsPath          = operationID + "/{vaultID}/import"
 )
 
 var logger = log.New("vault-operation")
@@ -58,10 +65,18 @@ func (o *Operation) GetRESTHandlers() []support.Handler {
 		support.NewHTTPHandler(CreateVaultPath, http.MethodPost, o.CreateVault),
 		support.NewHTTPHandler(DeleteVaultPath, http.MethodDelete, o.DeleteVault),
 		support.NewHTTPHandler(SaveDocPath, http.MethodPost, o.SaveDoc),
+		support.NewHTTPHandler(QueryDocsPath, http.MethodPost, o.QueryDocs),
+		support.NewHTTPHandler(TrackDocsPath, http.MethodPost, o.TrackDocs),
 		support.NewHTTPHandler(GetDocMetadataPath, http.MethodGet, o.GetDocMetadata),
 		support.NewHTTPHandler(CreateAuthorizationPath, http.MethodPost, o.CreateAuthorization),
+		support.NewHTTPHandler(ListAuthorizationsPath, http.MethodGet, o.ListAuthorizations),
 		support.NewHTTPHandler(GetAuthorizationPath, http.MethodGet, o.GetAuthorization),
 		support.NewHTTPHandler(DeleteAuthorizationPath, http.MethodDelete, o.DeleteAuthorization),
+		support.NewHTTPHandler(CreateRecipientKeyPath, http.MethodPost, o.CreateRecipientKey),
+		support.NewHTTPHandler(KeyRotationPath, http.MethodPost, o.RotateKey),
+		support.NewHTTPHandler(KeyRotationPath, http.MethodGet, o.GetKeyRotation),
+		support.NewHTTPHandler(ExportDocsPath, http.MethodPost, o.ExportDocs),
+		support.NewHTTPHandler(ImportDocsPath, http.MethodPost, o.ImportDocs),
 	}
 }
 
@@ -130,7 +145,7 @@ func (o *Operation) SaveDoc(rw http.ResponseWriter, req *http.Request) {
 		}
 	}
 
-	result, err := o.vault.SaveDoc(vaultID, docID, docContent)
+	result, err := o.vault.SaveDoc(vaultID, docID, docContent, doc.Request.Indexed...)
 	if err != nil {
 		o.writeErrorResponse(rw, err, http.StatusInternalServerError)
 
@@ -143,6 +158,78 @@ func (o *Operation) SaveDoc(rw http.ResponseWriter, req *http.Request) {
 	o.WriteResponse(rw, resp.Body, http.StatusCreated)
 }
 
+// QueryDocs swagger:route POST /vaults/{vaultID}/docs/query vault queryDocsReq
+//
+// Returns metadata of the documents that have the given indexed attribute.
+//
+// Responses:
+//    default: genericError
+//        200: queryDocsResp
+func (o *Operation) QueryDocs(rw http.ResponseWriter, req *http.Request) {
+	var query queryDocsReq
+
+	if err := json.NewDecoder(req.Body).Decode(&query.Request); err != nil {
+		o.writeErrorResponse(rw, err, http.StatusBadRequest)
+
+		return
+	}
+
+	if query.Request.Name == "" {
+		o.writeErrorResponse(rw, errors.New("name is required"), http.StatusBadRequest)
+
+		return
+	}
+
+	result, err := o.vault.QueryDocs(mux.Vars(req)["vaultID"], query.Request.Name, query.Request.Value)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, storage.ErrDataNotFound) {
+			status = http.StatusNotFound
+		}
+
+		o.writeErrorResponse(rw, err, status)
+
+		return
+	}
+
+	var resp queryDocsResp
+	resp.Body = result
+
+	o.WriteResponse(rw, resp.Body, http.StatusOK)
+}
+
+// TrackDocs swagger:route POST /vaults/{vaultID}/docs/track vault trackDocsReq
+//
+// Registers the documents saved in the vault before its documents were tracked, so they can be exported and
+// re-encrypted by a key rotation.
+//
+// Responses:
+//    default: genericError
+//        200: trackDocsResp
+func (o *Operation) TrackDocs(rw http.ResponseWriter, req *http.Request) {
+	var request trackDocsReq
+
+	if err := json.NewDecoder(req.Body).Decode(&request.Request); err != nil {
+		o.writeErrorResponse(rw, err, http.StatusBadRequest)
+
+		return
+	}
+
+	err := o.vault.TrackDocs(mux.Vars(req)["vaultID"], request.Request.DocIDs)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, storage.ErrDataNotFound) {
+			status = http.StatusNotFound
+		}
+
+		o.writeErrorResponse(rw, err, status)
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
+
 // GetDocMetadata swagger:route GET /vaults/{vaultID}/docs/{docID}/metadata vault getDocMetadataReq
 //
 // Returns the document`s metadata by given docID.
@@ -198,7 +285,12 @@ func (o *Operation) CreateAuthorization(rw http.ResponseWriter, req *http.Reques
 
 	result, err := o.vault.CreateAuthorization(vaultID, requestingParty, &scope)
 	if err != nil {
-		o.writeErrorResponse(rw, err, http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if errors.Is(err, vault.ErrAuthorizationsNotSupported) {
+			status = http.StatusNotImplemented
+		}
+
+		o.writeErrorResponse(rw, err, status)
 
 		return
 	}
@@ -209,6 +301,32 @@ func (o *Operation) CreateAuthorization(rw http.ResponseWriter, req *http.Reques
 	o.WriteResponse(rw, resp.Body, http.StatusCreated)
 }
 
+// ListAuthorizations swagger:route GET /vaults/{vaultID}/authorizations vault listAuthorizationsReq
+//
+// Lists the vault's authorizations that have not expired yet. Tokens are not included.
+//
+// Responses:
+//    default: genericError
+//        200: listAuthorizationsResp
+func (o *Operation) ListAuthorizations(rw http.ResponseWriter, req *http.Request) {
+	result, err := o.vault.ListAuthorizations(mux.Vars(req)["vaultID"])
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, storage.ErrDataNotFound) {
+			status = http.StatusNotFound
+		}
+
+		o.writeErrorResponse(rw, err, status)
+
+		return
+	}
+
+	var resp listAuthorizationsResp
+	resp.Body = result
+
+	o.WriteResponse(rw, resp.Body, http.StatusOK)
+}
+
 // GetAuthorization swagger:route GET /vaults/{vaultID}/authorizations/{authID} vault getAuthorizationReq
 //
 // Fetches an authorization.
@@ -251,6 +369,173 @@ func (o *Operation) DeleteAuthorization(rw http.ResponseWriter, _ *http.Request)
 	rw.WriteHeader(http.StatusOK)
 }
 
+// CreateRecipientKey swagger:route POST /vaults/{vaultID}/recipient-keys vault createRecipientKeyReq
+//
+// Creates a new encryption key in the vault's keystore. Archives encrypted to this key can be imported into the vault.
+//
+// Responses:
+//    default: genericError
+//        201: createRecipientKeyResp
+func (o *Operation) CreateRecipientKey(rw http.ResponseWriter, req *http.Request) {
+	result, err := o.vault.CreateRecipientKey(mux.Vars(req)["vaultID"])
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, storage.ErrDataNotFound) {
+			status = http.StatusNotFound
+		}
+
+		o.writeErrorResponse(rw, err, status)
+
+		return
+	}
+
+	var resp createRecipientKeyResp
+	resp.Body = result
+
+	o.WriteResponse(rw, resp.Body, http.StatusCreated)
+}
+
+// RotateKey swagger:route POST /vaults/{vaultID}/key-rotation vault rotateKeyReq
+//
+// Creates a new encryption key and re-encrypts the vault's documents to it in the background.
+//
+// Responses:
+//    default: genericError
+//        202: keyRotationResp
+func (o *Operation) RotateKey(rw http.ResponseWriter, req *http.Request) {
+	result, err := o.vault.RotateKey(mux.Vars(req)["vaultID"])
+	if err != nil {
+		status := http.StatusInternalServerError
+
+		switch {
+		case errors.Is(err, storage.ErrDataNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, vault.ErrKeyRotationInProgress), errors.Is(err, vault.ErrDocsNotTracked):
+			status = http.StatusConflict
+		}
+
+		o.writeErrorResponse(rw, err, status)
+
+		return
+	}
+
+	var resp keyRotationResp
+	resp.Body = result
+
+	o.WriteResponse(rw, resp.Body, http.StatusAccepted)
+}
+
+// GetKeyRotation swagger:route GET /vaults/{vaultID}/key-rotation vault getKeyRotationReq
+//
+// Returns the progress of the vault's latest key rotation.
+//
+// Responses:
+//    default: genericError
+//        200: keyRotationResp
+func (o *Operation) GetKeyRotation(rw http.ResponseWriter, req *http.Request) {
+	result, err := o.vault.GetKeyRotation(mux.Vars(req)["vaultID"])
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, storage.ErrDataNotFound) {
+			status = http.StatusNotFound
+		}
+
+		o.writeErrorResponse(rw, err, status)
+
+		return
+	}
+
+	var resp keyRotationResp
+	resp.Body = result
+
+	o.WriteResponse(rw, resp.Body, http.StatusOK)
+}
+
+// ExportDocs swagger:route POST /vaults/{vaultID}/export vault exportDocsReq
+//
+// Exports all documents of the vault re-encrypted to the given recipient key.
+//
+// Responses:
+//    default: genericError
+//        200: exportDocsResp
+func (o *Operation) ExportDocs(rw http.ResponseWriter, req *http.Request) {
+	var request exportDocsReq
+
+	if err := json.NewDecoder(req.Body).Decode(&request.Request); err != nil {
+		o.writeErrorResponse(rw, err, http.StatusBadRequest)
+
+		return
+	}
+
+	if request.Request.RecipientKey == nil {
+		o.writeErrorResponse(rw, errors.New("recipient key is required"), http.StatusBadRequest)
+
+		return
+	}
+
+	vaultID := mux.Vars(req)["vaultID"]
+	w := &archiveWriter{rw: rw, vaultID: vaultID}
+
+	err := o.vault.ExportDocs(vaultID, request.Request.RecipientKey, w.Write)
+	if err != nil && !w.started {
+		status := http.StatusInternalServerError
+
+		switch {
+		case errors.Is(err, storage.ErrDataNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, vault.ErrDocsNotTracked):
+			status = http.StatusConflict
+		}
+
+		o.writeErrorResponse(rw, err, status)
+
+		return
+	}
+
+	if err != nil {
+		// the response is already being streamed, the archive is left incomplete (invalid JSON)
+		logger.Errorf("export docs: %v", err)
+
+		return
+	}
+
+	w.Close()
+}
+
+// ImportDocs swagger:route POST /vaults/{vaultID}/import vault importDocsReq
+//
+// Imports documents from an archive encrypted to one of the vault's recipient keys.
+//
+// Responses:
+//    default: genericError
+//        201: importDocsResp
+func (o *Operation) ImportDocs(rw http.ResponseWriter, req *http.Request) {
+	var request importDocsReq
+
+	if err := json.NewDecoder(req.Body).Decode(&request.Request); err != nil {
+		o.writeErrorResponse(rw, err, http.StatusBadRequest)
+
+		return
+	}
+
+	result, err := o.vault.ImportDocs(mux.Vars(req)["vaultID"], &request.Request)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, storage.ErrDataNotFound) {
+			status = http.StatusNotFound
+		}
+
+		o.writeErrorResponse(rw, err, status)
+
+		return
+	}
+
+	var resp importDocsResp
+	resp.Body = result
+
+	o.WriteResponse(rw, resp.Body, http.StatusCreated)
+}
+
 func (o *Operation) writeErrorResponse(rw http.ResponseWriter, err error, status int) {
 	logger.Errorf("%v", err)
 
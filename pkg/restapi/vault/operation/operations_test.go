@@ -10,6 +10,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -20,6 +21,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
 	"github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/edv/pkg/restapi/messages"
@@ -79,7 +81,8 @@ func TestSaveDoc(t *testing.T) {
 		const path = "/vaults/vaultID1/docs"
 
 		v := newVaultMock()
-		v.saveDocFn = func(vaultID, id string, content interface{}) (*vault.DocumentMetadata, error) {
+		v.saveDocFn = func(vaultID, id string, content interface{},
+			indexed ...vault.IndexedAttribute) (*vault.DocumentMetadata, error) {
 			return nil, errors.New("test")
 		}
 
@@ -144,6 +147,149 @@ func TestSaveDoc(t *testing.T) {
 		require.NotEmpty(t, resp.ID)
 		require.NotEmpty(t, resp.URI)
 	})
+	t.Run("Success (indexed)", func(t *testing.T) {
+		const path = "/vaults/vaultID1/docs"
+
+		v := newVaultMock()
+
+		var received []vault.IndexedAttribute
+
+		v.saveDocFn = func(vaultID, id string, content interface{},
+			indexed ...vault.IndexedAttribute) (*vault.DocumentMetadata, error) {
+			received = indexed
+
+			return &vault.DocumentMetadata{ID: id}, nil
+		}
+
+		h := handlerLookup(t, New(v), SaveDocPath, http.MethodPost)
+		_, code := sendRequestToHandler(t, h, strings.NewReader(
+			`{"content":{},"indexed":[{"name":"type","value":"VerifiableCredential","unique":false}]}`,
+		), path)
+
+		require.Equal(t, http.StatusCreated, code)
+		require.Equal(t, []vault.IndexedAttribute{{Name: "type", Value: "VerifiableCredential"}}, received)
+	})
+}
+
+func TestQueryDocs(t *testing.T) {
+	const path = "/vaults/vaultID1/docs/query"
+
+	t.Run("JSON error", func(t *testing.T) {
+		h := handlerLookup(t, New(newVaultMock()), QueryDocsPath, http.MethodPost)
+		res, code := sendRequestToHandler(t, h, strings.NewReader(`{`), path)
+
+		require.Equal(t, http.StatusBadRequest, code)
+
+		var errResp *model.ErrorResponse
+
+		require.NoError(t, json.NewDecoder(res).Decode(&errResp))
+		require.Contains(t, errResp.Message, "unexpected EOF")
+	})
+
+	t.Run("No name", func(t *testing.T) {
+		h := handlerLookup(t, New(newVaultMock()), QueryDocsPath, http.MethodPost)
+		res, code := sendRequestToHandler(t, h, strings.NewReader(`{"value":"v"}`), path)
+
+		require.Equal(t, http.StatusBadRequest, code)
+
+		var errResp *model.ErrorResponse
+
+		require.NoError(t, json.NewDecoder(res).Decode(&errResp))
+		require.Equal(t, "name is required", errResp.Message)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		v := newVaultMock()
+		v.queryDocsFn = func(string, string, string) ([]*vault.DocumentMetadata, error) {
+			return nil, fmt.Errorf("get vault info: %w", storage.ErrDataNotFound)
+		}
+
+		h := handlerLookup(t, New(v), QueryDocsPath, http.MethodPost)
+		_, code := sendRequestToHandler(t, h, strings.NewReader(`{"name":"n","value":"v"}`), path)
+
+		require.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		v := newVaultMock()
+		v.queryDocsFn = func(string, string, string) ([]*vault.DocumentMetadata, error) {
+			return nil, errors.New("test error")
+		}
+
+		h := handlerLookup(t, New(v), QueryDocsPath, http.MethodPost)
+		res, code := sendRequestToHandler(t, h, strings.NewReader(`{"name":"n","value":"v"}`), path)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+
+		var errResp *model.ErrorResponse
+
+		require.NoError(t, json.NewDecoder(res).Decode(&errResp))
+		require.Contains(t, errResp.Message, "test error")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		h := handlerLookup(t, New(newVaultMock()), QueryDocsPath, http.MethodPost)
+		res, code := sendRequestToHandler(t, h, strings.NewReader(`{"name":"n","value":"v"}`), path)
+
+		require.Equal(t, http.StatusOK, code)
+
+		var resp []*vault.DocumentMetadata
+
+		require.NoError(t, json.NewDecoder(res).Decode(&resp))
+		require.Len(t, resp, 1)
+	})
+}
+
+func TestTrackDocs(t *testing.T) {
+	const path = "/vaults/vaultID1/docs/track"
+
+	t.Run("JSON error", func(t *testing.T) {
+		h := handlerLookup(t, New(newVaultMock()), TrackDocsPath, http.MethodPost)
+		_, code := sendRequestToHandler(t, h, strings.NewReader(`{`), path)
+
+		require.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		v := newVaultMock()
+		v.trackDocsFn = func(string, []string) error {
+			return fmt.Errorf("get meta doc info doc1: %w", storage.ErrDataNotFound)
+		}
+
+		h := handlerLookup(t, New(v), TrackDocsPath, http.MethodPost)
+		_, code := sendRequestToHandler(t, h, strings.NewReader(`{"docIDs":["doc1"]}`), path)
+
+		require.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		v := newVaultMock()
+		v.trackDocsFn = func(string, []string) error {
+			return errors.New("test error")
+		}
+
+		h := handlerLookup(t, New(v), TrackDocsPath, http.MethodPost)
+		_, code := sendRequestToHandler(t, h, strings.NewReader(`{"docIDs":["doc1"]}`), path)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		var tracked []string
+
+		v := newVaultMock()
+		v.trackDocsFn = func(_ string, docIDs []string) error {
+			tracked = docIDs
+
+			return nil
+		}
+
+		h := handlerLookup(t, New(v), TrackDocsPath, http.MethodPost)
+		_, code := sendRequestToHandler(t, h, strings.NewReader(`{"docIDs":["doc1","doc2"]}`), path)
+
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, []string{"doc1", "doc2"}, tracked)
+	})
 }
 
 func TestGetDocMetadata(t *testing.T) {
@@ -302,6 +448,19 @@ func TestCreateAuthorization(t *testing.T) {
 		require.Contains(t, errResp.Message, "test error")
 	})
 
+	t.Run("Not supported", func(t *testing.T) {
+		v := newVaultMock()
+		v.createAuthorizationFn = func(vID, rp string,
+			scope *vault.AuthorizationsScope) (*vault.CreatedAuthorization, error) {
+			return nil, vault.ErrAuthorizationsNotSupported
+		}
+
+		h := handlerLookup(t, New(v), CreateAuthorizationPath, http.MethodPost)
+		_, code := sendRequestToHandler(t, h, strings.NewReader(`{}`), path)
+
+		require.Equal(t, http.StatusNotImplemented, code)
+	})
+
 	t.Run("Success", func(t *testing.T) {
 		operation := New(newVaultMock())
 
@@ -318,6 +477,47 @@ func TestCreateAuthorization(t *testing.T) {
 	})
 }
 
+func TestListAuthorizations(t *testing.T) {
+	const path = "/vaults/vaultID1/authorizations"
+
+	t.Run("Not found", func(t *testing.T) {
+		v := newVaultMock()
+		v.listAuthorizationsFn = func(string) ([]*vault.CreatedAuthorization, error) {
+			return nil, fmt.Errorf("get vault info: %w", storage.ErrDataNotFound)
+		}
+
+		h := handlerLookup(t, New(v), ListAuthorizationsPath, http.MethodGet)
+		_, code := sendRequestToHandler(t, h, nil, path)
+
+		require.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		v := newVaultMock()
+		v.listAuthorizationsFn = func(string) ([]*vault.CreatedAuthorization, error) {
+			return nil, errors.New("test error")
+		}
+
+		h := handlerLookup(t, New(v), ListAuthorizationsPath, http.MethodGet)
+		_, code := sendRequestToHandler(t, h, nil, path)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		h := handlerLookup(t, New(newVaultMock()), ListAuthorizationsPath, http.MethodGet)
+		res, code := sendRequestToHandler(t, h, nil, path)
+
+		require.Equal(t, http.StatusOK, code)
+
+		var resp []*vault.CreatedAuthorization
+
+		require.NoError(t, json.NewDecoder(res).Decode(&resp))
+		require.Len(t, resp, 1)
+		require.Equal(t, "did:key:rp", resp[0].RequestingParty)
+	})
+}
+
 func TestGetAuthorization(t *testing.T) {
 	const path = "/vaults/vaultID1/authorizations/authID1"
 
@@ -363,6 +563,284 @@ func TestDeleteAuthorization(t *testing.T) {
 	require.Equal(t, http.StatusOK, code)
 }
 
+func TestCreateRecipientKey(t *testing.T) {
+	const path = "/vaults/vaultID1/recipient-keys"
+
+	t.Run("Not found", func(t *testing.T) {
+		v := newVaultMock()
+		v.createRecipientKeyFn = func(string) (*crypto.PublicKey, error) {
+			return nil, storage.ErrDataNotFound
+		}
+
+		h := handlerLookup(t, New(v), CreateRecipientKeyPath, http.MethodPost)
+		res, code := sendRequestToHandler(t, h, nil, path)
+
+		require.Equal(t, http.StatusNotFound, code)
+
+		var errResp *model.ErrorResponse
+
+		require.NoError(t, json.NewDecoder(res).Decode(&errResp))
+		require.NotEmpty(t, errResp.Message)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		h := handlerLookup(t, New(newVaultMock()), CreateRecipientKeyPath, http.MethodPost)
+		res, code := sendRequestToHandler(t, h, nil, path)
+
+		require.Equal(t, http.StatusCreated, code)
+
+		var resp *crypto.PublicKey
+
+		require.NoError(t, json.NewDecoder(res).Decode(&resp))
+		require.Equal(t, "kid", resp.KID)
+	})
+}
+
+func TestRotateKey(t *testing.T) {
+	const path = "/vaults/vaultID1/key-rotation"
+
+	t.Run("Not found", func(t *testing.T) {
+		v := newVaultMock()
+		v.rotateKeyFn = func(string) (*vault.KeyRotation, error) {
+			return nil, fmt.Errorf("get vault info: %w", storage.ErrDataNotFound)
+		}
+
+		h := handlerLookup(t, New(v), KeyRotationPath, http.MethodPost)
+		_, code := sendRequestToHandler(t, h, nil, path)
+
+		require.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("In progress", func(t *testing.T) {
+		v := newVaultMock()
+		v.rotateKeyFn = func(string) (*vault.KeyRotation, error) {
+			return nil, vault.ErrKeyRotationInProgress
+		}
+
+		h := handlerLookup(t, New(v), KeyRotationPath, http.MethodPost)
+		res, code := sendRequestToHandler(t, h, nil, path)
+
+		require.Equal(t, http.StatusConflict, code)
+
+		var errResp *model.ErrorResponse
+
+		require.NoError(t, json.NewDecoder(res).Decode(&errResp))
+		require.Equal(t, vault.ErrKeyRotationInProgress.Error(), errResp.Message)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		v := newVaultMock()
+		v.rotateKeyFn = func(string) (*vault.KeyRotation, error) {
+			return nil, errors.New("test error")
+		}
+
+		h := handlerLookup(t, New(v), KeyRotationPath, http.MethodPost)
+		_, code := sendRequestToHandler(t, h, nil, path)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		h := handlerLookup(t, New(newVaultMock()), KeyRotationPath, http.MethodPost)
+		res, code := sendRequestToHandler(t, h, nil, path)
+
+		require.Equal(t, http.StatusAccepted, code)
+
+		var resp *vault.KeyRotation
+
+		require.NoError(t, json.NewDecoder(res).Decode(&resp))
+		require.Equal(t, vault.KeyRotationInProgress, resp.Status)
+	})
+}
+
+func TestGetKeyRotation(t *testing.T) {
+	const path = "/vaults/vaultID1/key-rotation"
+
+	t.Run("Not found", func(t *testing.T) {
+		v := newVaultMock()
+		v.getKeyRotationFn = func(string) (*vault.KeyRotation, error) {
+			return nil, fmt.Errorf("store get: %w", storage.ErrDataNotFound)
+		}
+
+		h := handlerLookup(t, New(v), KeyRotationPath, http.MethodGet)
+		_, code := sendRequestToHandler(t, h, nil, path)
+
+		require.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		v := newVaultMock()
+		v.getKeyRotationFn = func(string) (*vault.KeyRotation, error) {
+			return nil, errors.New("test error")
+		}
+
+		h := handlerLookup(t, New(v), KeyRotationPath, http.MethodGet)
+		_, code := sendRequestToHandler(t, h, nil, path)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		h := handlerLookup(t, New(newVaultMock()), KeyRotationPath, http.MethodGet)
+		res, code := sendRequestToHandler(t, h, nil, path)
+
+		require.Equal(t, http.StatusOK, code)
+
+		var resp *vault.KeyRotation
+
+		require.NoError(t, json.NewDecoder(res).Decode(&resp))
+		require.Equal(t, vault.KeyRotationCompleted, resp.Status)
+		require.Equal(t, 2, resp.Done)
+	})
+}
+
+func TestExportDocs(t *testing.T) {
+	const path = "/vaults/vaultID1/export"
+
+	t.Run("JSON error", func(t *testing.T) {
+		h := handlerLookup(t, New(newVaultMock()), ExportDocsPath, http.MethodPost)
+		res, code := sendRequestToHandler(t, h, strings.NewReader(`{`), path)
+
+		require.Equal(t, http.StatusBadRequest, code)
+
+		var errResp *model.ErrorResponse
+
+		require.NoError(t, json.NewDecoder(res).Decode(&errResp))
+		require.Contains(t, errResp.Message, "unexpected EOF")
+	})
+
+	t.Run("No recipient key", func(t *testing.T) {
+		h := handlerLookup(t, New(newVaultMock()), ExportDocsPath, http.MethodPost)
+		res, code := sendRequestToHandler(t, h, strings.NewReader(`{}`), path)
+
+		require.Equal(t, http.StatusBadRequest, code)
+
+		var errResp *model.ErrorResponse
+
+		require.NoError(t, json.NewDecoder(res).Decode(&errResp))
+		require.Equal(t, "recipient key is required", errResp.Message)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		v := newVaultMock()
+		v.exportDocsFn = func(string, *crypto.PublicKey, func(*vault.ArchivedDocument) error) error {
+			return storage.ErrDataNotFound
+		}
+
+		h := handlerLookup(t, New(v), ExportDocsPath, http.MethodPost)
+		_, code := sendRequestToHandler(t, h, strings.NewReader(`{"recipientKey":{}}`), path)
+
+		require.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("Untracked docs", func(t *testing.T) {
+		v := newVaultMock()
+		v.exportDocsFn = func(string, *crypto.PublicKey, func(*vault.ArchivedDocument) error) error {
+			return fmt.Errorf("get doc IDs: %w", vault.ErrDocsNotTracked)
+		}
+
+		h := handlerLookup(t, New(v), ExportDocsPath, http.MethodPost)
+		_, code := sendRequestToHandler(t, h, strings.NewReader(`{"recipientKey":{}}`), path)
+
+		require.Equal(t, http.StatusConflict, code)
+	})
+
+	t.Run("Error while streaming", func(t *testing.T) {
+		v := newVaultMock()
+		v.exportDocsFn = func(_ string, _ *crypto.PublicKey, fn func(*vault.ArchivedDocument) error) error {
+			require.NoError(t, fn(&vault.ArchivedDocument{ID: "doc1"}))
+
+			return errors.New("test")
+		}
+
+		h := handlerLookup(t, New(v), ExportDocsPath, http.MethodPost)
+		res, code := sendRequestToHandler(t, h, strings.NewReader(`{"recipientKey":{}}`), path)
+
+		require.Equal(t, http.StatusOK, code)
+
+		var archive *vault.Archive
+
+		require.Error(t, json.NewDecoder(res).Decode(&archive))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		h := handlerLookup(t, New(newVaultMock()), ExportDocsPath, http.MethodPost)
+		res, code := sendRequestToHandler(t, h, strings.NewReader(`{"recipientKey":{}}`), path)
+
+		require.Equal(t, http.StatusOK, code)
+
+		var archive *vault.Archive
+
+		require.NoError(t, json.NewDecoder(res).Decode(&archive))
+		require.Equal(t, "vaultID1", archive.VaultID)
+		require.Len(t, archive.Documents, 2)
+	})
+
+	t.Run("Success (empty vault)", func(t *testing.T) {
+		v := newVaultMock()
+		v.exportDocsFn = func(string, *crypto.PublicKey, func(*vault.ArchivedDocument) error) error {
+			return nil
+		}
+
+		h := handlerLookup(t, New(v), ExportDocsPath, http.MethodPost)
+		res, code := sendRequestToHandler(t, h, strings.NewReader(`{"recipientKey":{}}`), path)
+
+		require.Equal(t, http.StatusOK, code)
+
+		var archive *vault.Archive
+
+		require.NoError(t, json.NewDecoder(res).Decode(&archive))
+		require.Empty(t, archive.Documents)
+	})
+}
+
+func TestImportDocs(t *testing.T) {
+	const path = "/vaults/vaultID1/import"
+
+	t.Run("JSON error", func(t *testing.T) {
+		h := handlerLookup(t, New(newVaultMock()), ImportDocsPath, http.MethodPost)
+		res, code := sendRequestToHandler(t, h, strings.NewReader(`{`), path)
+
+		require.Equal(t, http.StatusBadRequest, code)
+
+		var errResp *model.ErrorResponse
+
+		require.NoError(t, json.NewDecoder(res).Decode(&errResp))
+		require.Contains(t, errResp.Message, "unexpected EOF")
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		v := newVaultMock()
+		v.importDocsFn = func(string, *vault.Archive) ([]*vault.DocumentMetadata, error) {
+			return nil, errors.New("test error")
+		}
+
+		h := handlerLookup(t, New(v), ImportDocsPath, http.MethodPost)
+		res, code := sendRequestToHandler(t, h, strings.NewReader(`{}`), path)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+
+		var errResp *model.ErrorResponse
+
+		require.NoError(t, json.NewDecoder(res).Decode(&errResp))
+		require.Contains(t, errResp.Message, "test error")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		h := handlerLookup(t, New(newVaultMock()), ImportDocsPath, http.MethodPost)
+		res, code := sendRequestToHandler(t, h,
+			strings.NewReader(`{"vaultID":"vaultID0","documents":[{"id":"doc1","jwe":{}}]}`), path)
+
+		require.Equal(t, http.StatusCreated, code)
+
+		var resp []*vault.DocumentMetadata
+
+		require.NoError(t, json.NewDecoder(res).Decode(&resp))
+		require.Len(t, resp, 1)
+		require.Equal(t, "doc1", resp[0].ID)
+	})
+}
+
 // sendRequestToHandler reads response from given http handle func.
 func sendRequestToHandler(t *testing.T, h support.Handler, reqBody io.Reader, path string) (*bytes.Buffer, int) {
 	t.Helper()
@@ -416,12 +894,19 @@ func newVaultMock() *vaultMock {
 				},
 			}, nil
 		},
-		saveDocFn: func(vaultID, id string, content interface{}) (*vault.DocumentMetadata, error) {
+		saveDocFn: func(vaultID, id string, content interface{},
+			indexed ...vault.IndexedAttribute) (*vault.DocumentMetadata, error) {
 			return &vault.DocumentMetadata{
 				ID:  "M3aS9xwj8ybCwHkEiCJJR1",
 				URI: "localhost:7777/encrypted-data-vaults/HwtZ1bUn4SzXoQRoX9br6m/documents/M3aS9xwj8ybCwHkEiCJJR1",
 			}, nil
 		},
+		queryDocsFn: func(vaultID, name, value string) ([]*vault.DocumentMetadata, error) {
+			return []*vault.DocumentMetadata{{
+				ID:  "M3aS9xwj8ybCwHkEiCJJR1",
+				URI: "localhost:7777/encrypted-data-vaults/HwtZ1bUn4SzXoQRoX9br6m/documents/M3aS9xwj8ybCwHkEiCJJR1",
+			}}, nil
+		},
 		getDocMetadataFn: func(vaultID, id string) (*vault.DocumentMetadata, error) {
 			return &vault.DocumentMetadata{
 				ID:  "M3aS9xwj8ybCwHkEiCJJR1",
@@ -431,26 +916,74 @@ func newVaultMock() *vaultMock {
 		createAuthorizationFn: func(vID, rp string, scope *vault.AuthorizationsScope) (*vault.CreatedAuthorization, error) {
 			return &vault.CreatedAuthorization{ID: uuid.New().String()}, nil
 		},
+		listAuthorizationsFn: func(vaultID string) ([]*vault.CreatedAuthorization, error) {
+			return []*vault.CreatedAuthorization{{ID: uuid.New().String(), RequestingParty: "did:key:rp"}}, nil
+		},
 		getAuthorizationFn: func(vaultID, id string) (*vault.CreatedAuthorization, error) {
 			return &vault.CreatedAuthorization{ID: uuid.New().String()}, nil
 		},
+		createRecipientKeyFn: func(vaultID string) (*crypto.PublicKey, error) {
+			return &crypto.PublicKey{KID: "kid", Curve: "NIST_P256", Type: "EC"}, nil
+		},
+		rotateKeyFn: func(vaultID string) (*vault.KeyRotation, error) {
+			return &vault.KeyRotation{Status: vault.KeyRotationInProgress, Total: 2}, nil
+		},
+		getKeyRotationFn: func(vaultID string) (*vault.KeyRotation, error) {
+			return &vault.KeyRotation{Status: vault.KeyRotationCompleted, Total: 2, Done: 2}, nil
+		},
+		exportDocsFn: func(vaultID string, _ *crypto.PublicKey, fn func(*vault.ArchivedDocument) error) error {
+			for _, id := range []string{"doc1", "doc2"} {
+				if err := fn(&vault.ArchivedDocument{ID: id, JWE: []byte(`{}`)}); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		importDocsFn: func(vaultID string, archive *vault.Archive) ([]*vault.DocumentMetadata, error) {
+			result := make([]*vault.DocumentMetadata, len(archive.Documents))
+
+			for i, doc := range archive.Documents {
+				result[i] = &vault.DocumentMetadata{ID: doc.ID}
+			}
+
+			return result, nil
+		},
 	}
 }
 
 type vaultMock struct {
 	createVaultFn         func() (*vault.CreatedVault, error)
-	saveDocFn             func(vaultID, id string, content interface{}) (*vault.DocumentMetadata, error)
 	getDocMetadataFn      func(vaultID, docID string) (*vault.DocumentMetadata, error)
 	createAuthorizationFn func(vID, rp string, scope *vault.AuthorizationsScope) (*vault.CreatedAuthorization, error)
 	getAuthorizationFn    func(vaultID, id string) (*vault.CreatedAuthorization, error)
+	createRecipientKeyFn  func(vaultID string) (*crypto.PublicKey, error)
+	exportDocsFn          func(vaultID string, key *crypto.PublicKey, fn func(*vault.ArchivedDocument) error) error
+	importDocsFn          func(vaultID string, archive *vault.Archive) ([]*vault.DocumentMetadata, error)
+	queryDocsFn           func(vaultID, name, value string) ([]*vault.DocumentMetadata, error)
+	rotateKeyFn           func(vaultID string) (*vault.KeyRotation, error)
+	getKeyRotationFn      func(vaultID string) (*vault.KeyRotation, error)
+	listAuthorizationsFn  func(vaultID string) ([]*vault.CreatedAuthorization, error)
+	trackDocsFn           func(vaultID string, docIDs []string) error
+	saveDocFn             func(vaultID, id string, content interface{},
+		indexed ...vault.IndexedAttribute) (*vault.DocumentMetadata, error)
 }
 
 func (v *vaultMock) CreateVault() (*vault.CreatedVault, error) {
 	return v.createVaultFn()
 }
 
-func (v *vaultMock) SaveDoc(vaultID, id string, content []byte) (*vault.DocumentMetadata, error) {
-	return v.saveDocFn(vaultID, id, content)
+func (v *vaultMock) SaveDoc(vaultID, id string, content []byte,
+	indexed ...vault.IndexedAttribute) (*vault.DocumentMetadata, error) {
+	return v.saveDocFn(vaultID, id, content, indexed...)
+}
+
+func (v *vaultMock) QueryDocs(vaultID, name, value string) ([]*vault.DocumentMetadata, error) {
+	return v.queryDocsFn(vaultID, name, value)
+}
+
+func (v *vaultMock) TrackDocs(vaultID string, docIDs []string) error {
+	return v.trackDocsFn(vaultID, docIDs)
 }
 
 func (v *vaultMock) GetDocMetadata(vaultID, docID string) (*vault.DocumentMetadata, error) {
@@ -462,6 +995,30 @@ func (v *vaultMock) CreateAuthorization(vID, rp string,
 	return v.createAuthorizationFn(vID, rp, scope)
 }
 
+func (v *vaultMock) ListAuthorizations(vaultID string) ([]*vault.CreatedAuthorization, error) {
+	return v.listAuthorizationsFn(vaultID)
+}
+
 func (v *vaultMock) GetAuthorization(vaultID, id string) (*vault.CreatedAuthorization, error) {
 	return v.getAuthorizationFn(vaultID, id)
 }
+
+func (v *vaultMock) CreateRecipientKey(vaultID string) (*crypto.PublicKey, error) {
+	return v.createRecipientKeyFn(vaultID)
+}
+
+func (v *vaultMock) RotateKey(vaultID string) (*vault.KeyRotation, error) {
+	return v.rotateKeyFn(vaultID)
+}
+
+func (v *vaultMock) GetKeyRotation(vaultID string) (*vault.KeyRotation, error) {
+	return v.getKeyRotationFn(vaultID)
+}
+
+func (v *vaultMock) ExportDocs(vaultID string, key *crypto.PublicKey, fn func(*vault.ArchivedDocument) error) error {
+	return v.exportDocsFn(vaultID, key, fn)
+}
+
+func (v *vaultMock) ImportDocs(vaultID string, archive *vault.Archive) ([]*vault.DocumentMetadata, error) {
+	return v.importDocsFn(vaultID, archive)
+}
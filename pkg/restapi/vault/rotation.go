@@ -0,0 +1,216 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vault
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	ariescrypto "github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	edv "github.com/trustbloc/edv/pkg/client"
+	"github.com/trustbloc/edv/pkg/restapi/models"
+
+	"github.com/trustbloc/edge-service/pkg/claim"
+)
+
+// Key rotation statuses.
+const (
+	KeyRotationInProgress = "in-progress"
+	KeyRotationCompleted  = "completed"
+	KeyRotationFailed     = "failed"
+)
+
+// keyRotationClaimExpiry bounds how long the replica rotating the key of a vault re-encrypts a document, its claim of
+// the rotation is renewed after each one. The claim of a stopped replica expires, and the rotation can be started over.
+const keyRotationClaimExpiry = 10 * time.Minute
+
+// ErrKeyRotationInProgress is returned when a key rotation of the vault is already running.
+var ErrKeyRotationInProgress = errors.New("key rotation is already in progress")
+
+// KeyRotation describes the progress of a vault's key rotation.
+type KeyRotation struct {
+	Status    string     `json:"status"`
+	EncKeyURI string     `json:"encKeyURI"`
+	Total     int        `json:"total"`
+	Done      int        `json:"done"`
+	Error     string     `json:"error,omitempty"`
+	Started   time.Time  `json:"started"`
+	Finished  *time.Time `json:"finished,omitempty"`
+}
+
+// RotateKey creates a new encryption key in the vault's keystore and re-encrypts every document of the vault
+// to it in the background. The progress can be followed with GetKeyRotation.
+// Authorizations stay valid, they grant access to the whole keystore rather than to a particular key.
+func (c *Client) RotateKey(vaultID string) (*KeyRotation, error) {
+	info, err := c.getVaultInfo(vaultID)
+	if err != nil {
+		return nil, fmt.Errorf("get vault info: %w", err)
+	}
+
+	held, err := c.claims.Claim("keyRotation_"+vaultID, keyRotationClaimExpiry)
+	if errors.Is(err, claim.ErrClaimed) {
+		return nil, ErrKeyRotationInProgress
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("claim key rotation: %w", err)
+	}
+
+	rotation, pubKey, docIDs, err := c.startKeyRotation(vaultID, info)
+	if err != nil {
+		c.releaseKeyRotation(vaultID, held)
+
+		return nil, err
+	}
+
+	result := *rotation
+
+	go func() {
+		defer c.releaseKeyRotation(vaultID, held)
+
+		c.reEncryptDocs(vaultID, info, rotation, pubKey, docIDs, held)
+	}()
+
+	return &result, nil
+}
+
+// GetKeyRotation returns the progress of the vault's latest key rotation.
+func (c *Client) GetKeyRotation(vaultID string) (*KeyRotation, error) {
+	src, err := c.store.Get(fmt.Sprintf(keyRotationFormat, vaultID))
+	if err != nil {
+		return nil, fmt.Errorf("store get: %w", err)
+	}
+
+	var rotation *KeyRotation
+
+	err = json.Unmarshal(src, &rotation)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	return rotation, nil
+}
+
+func (c *Client) startKeyRotation(vaultID string,
+	info *vaultInfo) (*KeyRotation, *ariescrypto.PublicKey, []string, error) {
+	docIDs, err := c.getDocIDs(vaultID, info)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("get doc IDs: %w", err)
+	}
+
+	kidURL, pubKey, err := newEncryptionKey(c.keyManager(info.DidURL, info.Auth.KMS))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("new encryption key: %w", err)
+	}
+
+	rotation := &KeyRotation{
+		Status:    KeyRotationInProgress,
+		EncKeyURI: c.buildKMSURL(kidURL),
+		Total:     len(docIDs),
+		Started:   time.Now().UTC(),
+	}
+
+	err = c.saveKeyRotation(vaultID, rotation)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("save key rotation: %w", err)
+	}
+
+	return rotation, pubKey, docIDs, nil
+}
+
+func (c *Client) reEncryptDocs(vaultID string, info *vaultInfo, rotation *KeyRotation,
+	pubKey *ariescrypto.PublicKey, docIDs []string, held *claim.Claim) {
+	decrypter := jose.NewJWEDecrypt(nil, c.keyCrypto(info.DidURL, info.Auth.KMS), c.keyManager(info.DidURL, info.Auth.KMS))
+
+	for _, docID := range docIDs {
+		err := c.reEncryptDoc(vaultID, docID, info, rotation.EncKeyURI, pubKey, decrypter)
+		if err != nil {
+			rotation.Status = KeyRotationFailed
+			rotation.Error = fmt.Sprintf("re-encrypt doc %s: %v", docID, err)
+
+			break
+		}
+
+		rotation.Done++
+
+		if err = c.saveKeyRotation(vaultID, rotation); err != nil {
+			logger.Errorf("key rotation of vault %s: save progress: %v", vaultID, err)
+		}
+
+		if err = c.claims.Renew(held, keyRotationClaimExpiry); err != nil {
+			logger.Errorf("key rotation of vault %s: renew claim: %v", vaultID, err)
+		}
+	}
+
+	if rotation.Status == KeyRotationInProgress {
+		rotation.Status = KeyRotationCompleted
+	}
+
+	finished := time.Now().UTC()
+	rotation.Finished = &finished
+
+	if err := c.saveKeyRotation(vaultID, rotation); err != nil {
+		logger.Errorf("key rotation of vault %s: save result: %v", vaultID, err)
+	}
+}
+
+func (c *Client) reEncryptDoc(vaultID, docID string, info *vaultInfo, kidURL string,
+	pubKey *ariescrypto.PublicKey, decrypter jose.Decrypter) error {
+	defer c.lockVault(vaultID)()
+
+	dInfo, err := c.getMetaDocInfo(vaultID, docID)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("get meta doc info: %w", err)
+	}
+
+	encDoc, src, err := c.readDocument(info, dInfo.EdvID, decrypter)
+	if err != nil {
+		return err
+	}
+
+	encContent, err := encryptJWE(c.keyCrypto(info.DidURL, info.Auth.KMS), pubKey, src)
+	if err != nil {
+		return err
+	}
+
+	err = c.edvClient.UpdateDocument(lastElm(info.Auth.EDV.URI, "/"), dInfo.EdvID, &models.EncryptedDocument{
+		ID:                          dInfo.EdvID,
+		Sequence:                    encDoc.Sequence + 1,
+		IndexedAttributeCollections: encDoc.IndexedAttributeCollections,
+		JWE:                         []byte(encContent),
+	}, edv.WithRequestHeader(c.edvSign(info.DidURL, info.Auth.EDV)))
+	if err != nil {
+		return fmt.Errorf("update document: %w", err)
+	}
+
+	dInfo.KidURL = kidURL
+
+	return c.saveMetaDocInfo(vaultID, docID, dInfo)
+}
+
+func (c *Client) releaseKeyRotation(vaultID string, held *claim.Claim) {
+	if err := c.claims.Release(held); err != nil {
+		logger.Errorf("key rotation of vault %s: release claim: %v", vaultID, err)
+	}
+}
+
+func (c *Client) saveKeyRotation(vaultID string, rotation *KeyRotation) error {
+	src, err := json.Marshal(rotation)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	return c.store.Put(fmt.Sprintf(keyRotationFormat, vaultID), src)
+}
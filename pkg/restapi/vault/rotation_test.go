@@ -0,0 +1,190 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vault_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+	. "github.com/trustbloc/edge-service/pkg/restapi/vault"
+)
+
+func TestClient_RotateKey(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	t.Run("No vault", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{},
+		}, loader)
+		require.NoError(t, err)
+
+		_, err = client.RotateKey("vid")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("KMS error", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{
+				Store: map[string]mockstorage.DBEntry{
+					"info_vid": {Value: []byte(`{"docs_tracked":true,"auth":{"edv":{},"kms":{}}}`)},
+				},
+			},
+		}, loader)
+		require.NoError(t, err)
+
+		_, err = client.RotateKey("vid")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "new encryption key: create")
+
+		// a failed start does not block the next rotation
+		_, err = client.RotateKey("vid")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "new encryption key: create")
+	})
+
+	t.Run("Empty vault", func(t *testing.T) {
+		client, vID, _ := newRotationClient(t, newIndexKMS(t, false).URL, "", false)
+
+		rotation, err := client.RotateKey(vID)
+		require.NoError(t, err)
+		require.Equal(t, KeyRotationInProgress, rotation.Status)
+		require.NotEmpty(t, rotation.EncKeyURI)
+
+		rotation = waitForKeyRotation(t, client, vID)
+		require.Equal(t, KeyRotationCompleted, rotation.Status)
+		require.Equal(t, 0, rotation.Total)
+		require.NotNil(t, rotation.Finished)
+	})
+
+	t.Run("Read document error", func(t *testing.T) {
+		edv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+
+		client, vID, _ := newRotationClient(t, newIndexKMS(t, false).URL, edv.URL, true)
+
+		rotation, err := client.RotateKey(vID)
+		require.NoError(t, err)
+		require.Equal(t, 1, rotation.Total)
+
+		rotation = waitForKeyRotation(t, client, vID)
+		require.Equal(t, KeyRotationFailed, rotation.Status)
+		require.Equal(t, 0, rotation.Done)
+		require.Contains(t, rotation.Error, "re-encrypt doc docID: read document")
+	})
+
+	t.Run("In progress", func(t *testing.T) {
+		release := make(chan struct{})
+
+		edv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+
+		client, vID, store := newRotationClient(t, newIndexKMS(t, false).URL, edv.URL, true)
+
+		_, err := client.RotateKey(vID)
+		require.NoError(t, err)
+
+		_, err = client.RotateKey(vID)
+		require.True(t, errors.Is(err, ErrKeyRotationInProgress))
+
+		// another replica sharing the storage
+		replica, err := NewClient(newIndexKMS(t, false).URL, edv.URL, nil, store, loader)
+		require.NoError(t, err)
+
+		_, err = replica.RotateKey(vID)
+		require.True(t, errors.Is(err, ErrKeyRotationInProgress))
+
+		close(release)
+
+		require.Equal(t, KeyRotationFailed, waitForKeyRotation(t, client, vID).Status)
+	})
+}
+
+func TestClient_GetKeyRotation(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	t.Run("Not found", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{},
+		}, loader)
+		require.NoError(t, err)
+
+		_, err = client.GetKeyRotation("vid")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("Unmarshal error", func(t *testing.T) {
+		client, err := NewClient("", "", nil, &mockstorage.MockStoreProvider{
+			Store: &mockstorage.MockStore{
+				Store: map[string]mockstorage.DBEntry{
+					"key_rotation_vid": {Value: []byte(`{`)},
+				},
+			},
+		}, loader)
+		require.NoError(t, err)
+
+		_, err = client.GetKeyRotation("vid")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal")
+	})
+}
+
+func newRotationClient(t *testing.T, kmsURL, edvURL string, withDoc bool) (*Client, string, storage.Provider) {
+	t.Helper()
+
+	data := map[string]mockstorage.DBEntry{}
+	store := &mockstorage.MockStoreProvider{Store: &mockstorage.MockStore{Store: data}}
+
+	lKMS := newLocalKms(t, store)
+	client, err := NewClient(kmsURL, edvURL, lKMS, store, testutil.DocumentLoader(t))
+	require.NoError(t, err)
+
+	vID, dURL, _ := createVaultID(t, lKMS)
+
+	data["info_"+vID] = mockstorage.DBEntry{
+		Value: []byte(`{"docs_tracked":true,"did_url":"` + dURL + `", "auth":{"edv":{},"kms":{"uri":"/"}}}`),
+	}
+
+	if withDoc {
+		data["meta_doc_info_"+vID+"_docID"] = mockstorage.DBEntry{
+			Value: []byte(`{"edv_id":"eID", "kid_url":"kURL"}`),
+			Tags:  []storage.Tag{{Name: "vaultDocs", Value: base64.RawURLEncoding.EncodeToString([]byte(vID))}},
+		}
+	}
+
+	return client, vID, store
+}
+
+func waitForKeyRotation(t *testing.T, client *Client, vaultID string) *KeyRotation {
+	t.Helper()
+
+	var rotation *KeyRotation
+
+	require.Eventually(t, func() bool {
+		var err error
+
+		rotation, err = client.GetKeyRotation(vaultID)
+		require.NoError(t, err)
+
+		return rotation.Status != KeyRotationInProgress
+	}, time.Second*5, time.Millisecond*10)
+
+	return rotation
+}
@@ -51,5 +51,5 @@ func TestController_GetOperations(t *testing.T) {
 
 	ops := controller.GetOperations()
 
-	require.Equal(t, 6, len(ops))
+	require.Equal(t, 14, len(ops))
 }
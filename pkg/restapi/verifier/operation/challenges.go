@@ -0,0 +1,92 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+)
+
+const (
+	challengesEndpoint = "/" + "{" + profileIDPathParam + "}" + verifierBasePath + "/challenges"
+
+	defaultChallengeExpiry = 5 * time.Minute
+	maxChallengeExpiry     = time.Hour
+)
+
+// IssueChallenge swagger:route POST /{id}/verifier/challenges verifier challengeReq
+//
+// Issues a single-use challenge for the holder to sign in the proof of its presentation, bound to the verifier
+// profile and domain. The presentation verification consumes it when the profile requires challenges.
+//
+// Responses:
+//    default: genericError
+//        201: challengeRes
+func (o *Operation) issueChallengeHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid verifier profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	data := ChallengeRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	expiry := defaultChallengeExpiry
+	if data.ExpiresIn != 0 {
+		expiry = time.Duration(data.ExpiresIn) * time.Second
+	}
+
+	if expiry < 0 || expiry > maxChallengeExpiry {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("invalid challenge expiry, it is at most %d seconds", int64(maxChallengeExpiry.Seconds())))
+
+		return
+	}
+
+	c, err := o.challenges.Issue(profile.ID, data.Domain, expiry)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to issue challenge: %s", err.Error()))
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusCreated)
+	commhttp.WriteResponse(rw, c)
+}
+
+// consumeChallenge consumes the challenge of the verification options, when the profile requires issued challenges.
+// The proof was checked to sign the same challenge and domain.
+func (o *Operation) consumeChallenge(profile *verifier.ProfileData, opts *VerifyPresentationOptions) error {
+	if !profile.ChallengeRequired {
+		return nil
+	}
+
+	if opts == nil || opts.Challenge == "" {
+		return errors.New("challenge issued by the verifier is required")
+	}
+
+	return o.challenges.Consume(profile.ID, opts.Domain, opts.Challenge)
+}
@@ -0,0 +1,140 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	challengestore "github.com/trustbloc/edge-service/pkg/challenge"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+func TestChallenges(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	didID := "did:test:EiBNfNRaz1Ll8BjVsbNv-fWc7K_KIoPuW8GFCh1_Tz_Iuw=="
+	didDoc := createDIDDoc(didID, pubKey)
+	verificationMethod := didDoc.VerificationMethod[0].ID
+
+	op, err := New(&Config{
+		VDRI:           &vdrmock.MockVDRegistry{ResolveValue: didDoc},
+		StoreProvider:  ariesmemstorage.NewProvider(),
+		DocumentLoader: testutil.DocumentLoader(t),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+		ID:                 "test",
+		Name:               "test verifier",
+		PresentationChecks: []string{proofCheck},
+		ChallengeRequired:  true,
+	}))
+
+	issueChallenge := func(t *testing.T, profileID string, data *ChallengeRequest) *httptest.ResponseRecorder {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(data)
+		require.NoError(t, err)
+
+		return serveHTTPMux(t, getHandler(t, op, challengesEndpoint, http.MethodPost),
+			"/"+profileID+"/verifier/challenges", reqBytes, map[string]string{profileIDPathParam: profileID})
+	}
+
+	verify := func(t *testing.T, vp []byte, opts *VerifyPresentationOptions) *httptest.ResponseRecorder {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(&VerifyPresentationRequest{Presentation: vp, Opts: opts})
+		require.NoError(t, err)
+
+		return serveHTTPMux(t, getHandler(t, op, presentationsVerificationEndpoint, http.MethodPost),
+			"/test/verifier/presentations/verify", reqBytes, map[string]string{profileIDPathParam: "test"})
+	}
+
+	t.Run("challenge is used once", func(t *testing.T) {
+		rr := issueChallenge(t, "test", &ChallengeRequest{Domain: "example.com"})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		c := &challengestore.Challenge{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), c))
+		require.NotEmpty(t, c.Challenge)
+		require.Equal(t, "example.com", c.Domain)
+
+		vp := getSignedVP(t, privKey, prCardVC, didID, verificationMethod, didID, verificationMethod,
+			"example.com", c.Challenge)
+		opts := &VerifyPresentationOptions{Domain: "example.com", Challenge: c.Challenge}
+
+		rr = verify(t, vp, opts)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		// the presentation is replayed
+		rr = verify(t, vp, opts)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "challenge already used")
+
+		// the presentation is replayed without asking for the proof check
+		rr = verify(t, vp, &VerifyPresentationOptions{Domain: "example.com", Challenge: c.Challenge,
+			Checks: []string{statusCheck}})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "challenge already used")
+	})
+
+	t.Run("challenge not issued", func(t *testing.T) {
+		vp := getSignedVP(t, privKey, prCardVC, didID, verificationMethod, didID, verificationMethod,
+			"example.com", "made-up")
+
+		rr := verify(t, vp, &VerifyPresentationOptions{Domain: "example.com", Challenge: "made-up"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "unknown challenge")
+
+		vp = getSignedVP(t, privKey, prCardVC, didID, verificationMethod, didID, verificationMethod, "", "")
+
+		rr = verify(t, vp, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "challenge issued by the verifier is required")
+	})
+
+	t.Run("challenge issued for another domain", func(t *testing.T) {
+		rr := issueChallenge(t, "test", &ChallengeRequest{Domain: "example.com"})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		c := &challengestore.Challenge{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), c))
+
+		vp := getSignedVP(t, privKey, prCardVC, didID, verificationMethod, didID, verificationMethod,
+			"other.com", c.Challenge)
+
+		rr = verify(t, vp, &VerifyPresentationOptions{Domain: "other.com", Challenge: c.Challenge})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "unknown challenge")
+	})
+
+	t.Run("issue challenge - invalid request", func(t *testing.T) {
+		rr := issueChallenge(t, "test", &ChallengeRequest{ExpiresIn: 7200})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid challenge expiry, it is at most 3600 seconds")
+
+		rr = issueChallenge(t, "unknown", &ChallengeRequest{})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid verifier profile")
+
+		rr = serveHTTPMux(t, getHandler(t, op, challengesEndpoint, http.MethodPost), "/test/verifier/challenges",
+			[]byte("{"), map[string]string{profileIDPathParam: "test"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), invalidRequestErrMsg)
+	})
+}
@@ -0,0 +1,197 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/presexch"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/internal/common/utils"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+)
+
+const (
+	definitionIDPathParam = "definitionID"
+
+	presentationDefinitionEndpoint = "/" + "{" + profileIDPathParam + "}" + verifierBasePath +
+		"/presentation-definitions/" + "{" + definitionIDPathParam + "}"
+
+	// presentation verification check against the profile's presentation definitions
+	presentationDefinitionCheck = "presentationDefinition"
+
+	submissionProperty = "presentation_submission"
+)
+
+// PresentationDefinition swagger:route GET /{id}/verifier/presentation-definitions/{definitionID} verifier defReq
+//
+// Retrieves a presentation definition of the verifier profile, to request a presentation from a holder.
+//
+// Responses:
+//    default: genericError
+//        200: presentationDefinitionRes
+func (o *Operation) getPresentationDefinitionHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+	definitionID := mux.Vars(req)[definitionIDPathParam]
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid verifier profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	definition := findPresentationDefinition(profile, definitionID)
+	if definition == nil {
+		commhttp.WriteErrorResponse(rw, http.StatusNotFound,
+			fmt.Sprintf("presentation definition %s not found", definitionID))
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, definition)
+}
+
+// matchPresentationDefinition checks the presentation submission of the presentation against the profile's
+// presentation definition, and returns the credentials matched per input descriptor.
+func (o *Operation) matchPresentationDefinition(profile *verifier.ProfileData, vpBytes []byte,
+	opts *VerifyPresentationOptions) (map[string]*verifiable.Credential, error) {
+	vp, err := o.parseAndVerifyVP(vpBytes, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := vp.CustomFields[submissionProperty]; !ok && opts != nil && opts.PresentationSubmission != nil {
+		if vp.CustomFields == nil {
+			vp.CustomFields = verifiable.CustomFields{}
+		}
+
+		// the submission passed along the presentation is matched as if the presentation held it
+		vp.CustomFields[submissionProperty] = opts.PresentationSubmission
+		vp.Context = append(vp.Context, presexch.PresentationSubmissionJSONLDContextIRI)
+		vp.Type = append(vp.Type, presexch.PresentationSubmissionJSONLDType)
+	}
+
+	definitionID := submissionDefinitionID(vp)
+
+	if opts != nil && opts.PresentationDefinitionID != "" {
+		if definitionID != "" && definitionID != opts.PresentationDefinitionID {
+			return nil, fmt.Errorf("presentation submission is for presentation definition %s, expected %s",
+				definitionID, opts.PresentationDefinitionID)
+		}
+
+		definitionID = opts.PresentationDefinitionID
+	}
+
+	if definitionID == "" {
+		if len(profile.PresentationDefinitions) != 1 {
+			return nil, errors.New("presentation definition not specified")
+		}
+
+		definitionID = profile.PresentationDefinitions[0].ID
+	}
+
+	definition := findPresentationDefinition(profile, definitionID)
+	if definition == nil {
+		return nil, fmt.Errorf("presentation definition %s not found", definitionID)
+	}
+
+	credentialOpts := []verifiable.CredentialOpt{
+		verifiable.WithDisabledProofCheck(),
+		verifiable.WithJSONLDDocumentLoader(o.documentLoader),
+	}
+
+	matched, err := definition.Match(vp, presexch.WithCredentialOptions(credentialOpts...))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, descriptor := range definition.InputDescriptors {
+		if err = checkConstraints(descriptor, matched[descriptor.ID], credentialOpts); err != nil {
+			return nil, err
+		}
+	}
+
+	return matched, nil
+}
+
+// checkConstraints checks the credential satisfies the field constraints of the input descriptor, the definition
+// already matched its schemas.
+func checkConstraints(descriptor *presexch.InputDescriptor, credential *verifiable.Credential,
+	opts []verifiable.CredentialOpt) error {
+	if descriptor.Constraints == nil || len(descriptor.Constraints.Fields) == 0 {
+		return nil
+	}
+
+	ok, err := utils.SatisfiesDescriptor(descriptor, credential, opts...)
+	if err != nil {
+		return fmt.Errorf("check constraints of input descriptor %s: %w", descriptor.ID, err)
+	}
+
+	if !ok {
+		return fmt.Errorf("credential submitted for input descriptor %s does not satisfy its constraints",
+			descriptor.ID)
+	}
+
+	return nil
+}
+
+// submissionDefinitionID returns the definition_id of the presentation submission, if any.
+func submissionDefinitionID(vp *verifiable.Presentation) string {
+	submission, ok := vp.CustomFields[submissionProperty].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	definitionID, _ := submission["definition_id"].(string) // nolint: errcheck
+
+	return definitionID
+}
+
+func findPresentationDefinition(profile *verifier.ProfileData, id string) *presexch.PresentationDefinition {
+	for _, definition := range profile.PresentationDefinitions {
+		if definition.ID == id {
+			return definition
+		}
+	}
+
+	return nil
+}
+
+func validatePresentationDefinitions(pr *verifier.ProfileData) error {
+	ids := make(map[string]bool)
+
+	for _, definition := range pr.PresentationDefinitions {
+		if definition == nil || definition.ID == "" {
+			return errors.New("missing presentation definition id")
+		}
+
+		if ids[definition.ID] {
+			return fmt.Errorf("duplicate presentation definition id - %s", definition.ID)
+		}
+
+		ids[definition.ID] = true
+
+		if err := definition.ValidateSchema(); err != nil {
+			return fmt.Errorf("invalid presentation definition %s: %w", definition.ID, err)
+		}
+	}
+
+	for _, val := range pr.PresentationChecks {
+		if val == presentationDefinitionCheck && len(pr.PresentationDefinitions) == 0 {
+			return errors.New("presentationDefinition check requires presentation definitions")
+		}
+	}
+
+	return nil
+}
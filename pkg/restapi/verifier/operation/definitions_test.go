@@ -0,0 +1,223 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/presexch"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+const (
+	degreeDefinition = `{
+		"id": "degree",
+		"input_descriptors": [{
+			"id": "degree_input",
+			"schema": [{"uri": "https://www.w3.org/2018/credentials/examples/v1#UniversityDegreeCredential"}],
+			"constraints": {
+				"fields": [{
+					"path": ["$.credentialSubject.degree.type"],
+					"filter": {"type": "string", "const": "BachelorDegree"}
+				}]
+			}
+		}]
+	}`
+
+	// nolint: gosec
+	degreeSubmissionVP = `{
+		"@context": [
+			"https://www.w3.org/2018/credentials/v1",
+			"https://identity.foundation/presentation-exchange/submission/v1"
+		],
+		"type": ["VerifiablePresentation", "PresentationSubmission"],
+		"presentation_submission": {
+			"id": "a30e3b91-fb77-4d22-95fa-871689c322e2",
+			"definition_id": "%s",
+			"descriptor_map": [{"id": "degree_input", "format": "ldp_vc", "path": "$.verifiableCredential[0]"}]
+		},
+		"verifiableCredential": [{
+			"@context": [
+				"https://www.w3.org/2018/credentials/v1",
+				"https://www.w3.org/2018/credentials/examples/v1"
+			],
+			"id": "http://example.edu/credentials/1872",
+			"type": ["VerifiableCredential", "UniversityDegreeCredential"],
+			"credentialSubject": {
+				"id": "did:example:ebfeb1f712ebc6f1c276e12ec21",
+				"degree": {"type": "%s", "name": "Bachelor of Science"}
+			},
+			"issuer": "did:example:76e12ec712ebc6f1c221ebfeb1f",
+			"issuanceDate": "2010-01-01T19:23:24Z"
+		}]
+	}`
+)
+
+func TestPresentationDefinitions(t *testing.T) {
+	op, err := New(&Config{
+		VDRI:           &vdrmock.MockVDRegistry{},
+		StoreProvider:  ariesmemstorage.NewProvider(),
+		DocumentLoader: testutil.DocumentLoader(t),
+	})
+	require.NoError(t, err)
+
+	definition := &presexch.PresentationDefinition{}
+	require.NoError(t, json.Unmarshal([]byte(degreeDefinition), definition))
+
+	profile := &verifier.ProfileData{
+		ID:                      "test",
+		Name:                    "test verifier",
+		PresentationChecks:      []string{presentationDefinitionCheck},
+		PresentationDefinitions: []*presexch.PresentationDefinition{definition},
+	}
+
+	profileBytes, err := json.Marshal(profile)
+	require.NoError(t, err)
+
+	rr := serveHTTP(t, getHandler(t, op, profileEndpoint, http.MethodPost).Handle(), http.MethodPost,
+		profileEndpoint, profileBytes)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	verify := func(t *testing.T, vp string, opts *VerifyPresentationOptions) *httptest.ResponseRecorder {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(&VerifyPresentationRequest{Presentation: []byte(vp), Opts: opts})
+		require.NoError(t, err)
+
+		return serveHTTPMux(t, getHandler(t, op, presentationsVerificationEndpoint, http.MethodPost),
+			"/test/verifier/presentations/verify", reqBytes, map[string]string{profileIDPathParam: "test"})
+	}
+
+	t.Run("get presentation definition", func(t *testing.T) {
+		handler := getHandler(t, op, presentationDefinitionEndpoint, http.MethodGet)
+
+		rr := serveHTTPMux(t, handler, "/test/verifier/presentation-definitions/degree", nil,
+			map[string]string{profileIDPathParam: "test", definitionIDPathParam: "degree"})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		received := &presexch.PresentationDefinition{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), received))
+		require.Equal(t, definition, received)
+
+		rr = serveHTTPMux(t, handler, "/test/verifier/presentation-definitions/unknown", nil,
+			map[string]string{profileIDPathParam: "test", definitionIDPathParam: "unknown"})
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Contains(t, rr.Body.String(), "presentation definition unknown not found")
+
+		rr = serveHTTPMux(t, handler, "/unknown/verifier/presentation-definitions/degree", nil,
+			map[string]string{profileIDPathParam: "unknown", definitionIDPathParam: "degree"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid verifier profile")
+	})
+
+	t.Run("presentation definition check - success", func(t *testing.T) {
+		for _, definitionID := range []string{"degree", ""} {
+			rr := verify(t, fmt.Sprintf(degreeSubmissionVP, definitionID, "BachelorDegree"), nil)
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+			resp := &VerifyPresentationSuccessResponse{}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+			require.Equal(t, []string{presentationDefinitionCheck}, resp.Checks)
+			require.Len(t, resp.MatchedCredentials, 1)
+			require.Equal(t, "http://example.edu/credentials/1872", resp.MatchedCredentials["degree_input"].ID)
+		}
+	})
+
+	t.Run("presentation definition check - failure", func(t *testing.T) {
+		tests := []struct {
+			name string
+			vp   string
+			opts *VerifyPresentationOptions
+			err  string
+		}{
+			{
+				name: "field filter not satisfied",
+				vp:   fmt.Sprintf(degreeSubmissionVP, "degree", "MasterDegree"),
+				err:  "credential submitted for input descriptor degree_input does not satisfy its constraints",
+			},
+			{
+				name: "unknown definition",
+				vp:   fmt.Sprintf(degreeSubmissionVP, "employment", "BachelorDegree"),
+				err:  "presentation definition employment not found",
+			},
+			{
+				name: "definition mismatch",
+				vp:   fmt.Sprintf(degreeSubmissionVP, "degree", "BachelorDegree"),
+				opts: &VerifyPresentationOptions{PresentationDefinitionID: "employment"},
+				err:  "presentation submission is for presentation definition degree, expected employment",
+			},
+			{
+				name: "no submission",
+				vp:   `{"@context": ["https://www.w3.org/2018/credentials/v1"], "type": "VerifiablePresentation"}`,
+				err:  "input verifiable presentation must have json-ld context",
+			},
+			{
+				name: "invalid presentation",
+				vp:   `{}`,
+				err:  "verifiable presentation",
+			},
+		}
+
+		for _, tc := range tests {
+			rr := verify(t, tc.vp, tc.opts)
+			require.Equal(t, http.StatusBadRequest, rr.Code, tc.name)
+
+			resp := &VerifyPresentationFailureResponse{}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp), tc.name)
+			require.Len(t, resp.Checks, 1, tc.name)
+			require.Equal(t, presentationDefinitionCheck, resp.Checks[0].Check, tc.name)
+			require.Contains(t, resp.Checks[0].Error, tc.err, tc.name)
+		}
+	})
+
+	t.Run("profile - invalid presentation definitions", func(t *testing.T) {
+		invalid := &presexch.PresentationDefinition{ID: "empty"}
+
+		tests := []struct {
+			update *UpdateProfileRequest
+			err    string
+		}{
+			{
+				update: &UpdateProfileRequest{
+					PresentationDefinitions: []*presexch.PresentationDefinition{definition, definition},
+				},
+				err: "duplicate presentation definition id - degree",
+			},
+			{
+				update: &UpdateProfileRequest{PresentationDefinitions: []*presexch.PresentationDefinition{{}}},
+				err:    "missing presentation definition id",
+			},
+			{
+				update: &UpdateProfileRequest{PresentationDefinitions: []*presexch.PresentationDefinition{invalid}},
+				err:    "invalid presentation definition empty",
+			},
+			{
+				update: &UpdateProfileRequest{PresentationDefinitions: []*presexch.PresentationDefinition{}},
+				err:    "presentationDefinition check requires presentation definitions",
+			},
+		}
+
+		for _, tc := range tests {
+			reqBytes, err := json.Marshal(tc.update)
+			require.NoError(t, err)
+
+			rr := serveHTTPMux(t, getHandler(t, op, getProfileEndpoint, http.MethodPatch), "/verifier/profile/test",
+				reqBytes, map[string]string{profileIDPathParam: "test"})
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.Contains(t, rr.Body.String(), tc.err)
+		}
+	})
+}
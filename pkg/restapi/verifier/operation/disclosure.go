@@ -0,0 +1,163 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/internal/common/utils"
+)
+
+const (
+	// credential verification check of the claims disclosed by the credential
+	disclosureCheck = "disclosure"
+
+	bbsBlsSignatureProof2020 = "BbsBlsSignatureProof2020"
+)
+
+// disclosedClaims returns the subject claims the credential reveals, nil unless it is derived for selective
+// disclosure with a BbsBlsSignatureProof2020 proof.
+func disclosedClaims(vc *verifiable.Credential) ([]string, error) {
+	for _, proof := range vc.Proofs {
+		if proof["type"] == bbsBlsSignatureProof2020 {
+			return subjectClaims(vc)
+		}
+	}
+
+	return nil, nil
+}
+
+// checkDisclosure checks the claims the credential discloses comply with the disclosure policy of the profile. When
+// the policy binds the nonce, the challenge is consumed if the profile requires challenges issued by the verifier.
+func (o *Operation) checkDisclosure(profile *verifier.ProfileData, vc *verifiable.Credential,
+	opts *CredentialsVerificationOptions) error {
+	policy := profile.DisclosurePolicy
+	if policy == nil {
+		return nil
+	}
+
+	claims, err := subjectClaims(vc)
+	if err != nil {
+		return err
+	}
+
+	for _, attr := range policy.RequiredAttributes {
+		if !disclosed(claims, attr) {
+			return fmt.Errorf("required attribute %s is not disclosed", attr)
+		}
+	}
+
+	for _, attr := range policy.ForbiddenAttributes {
+		if disclosed(claims, attr) {
+			return fmt.Errorf("forbidden attribute %s is disclosed", attr)
+		}
+	}
+
+	if !policy.NonceBound {
+		return nil
+	}
+
+	if opts == nil || opts.Challenge == "" {
+		return errors.New("challenge is required to bind the nonce of the derived proof")
+	}
+
+	bound := false
+
+	for _, proof := range vc.Proofs {
+		if proof["type"] == bbsBlsSignatureProof2020 && nonceBound(proof, opts.Challenge) {
+			bound = true
+
+			break
+		}
+	}
+
+	if !bound {
+		return errors.New("credential has no " + bbsBlsSignatureProof2020 + " proof bound to the challenge")
+	}
+
+	if !profile.ChallengeRequired {
+		return nil
+	}
+
+	return o.challenges.Consume(profile.ID, opts.Domain, opts.Challenge)
+}
+
+// nonceBound tells whether the proof is derived with the challenge as nonce, the nonce of the proof is base64
+// encoded.
+func nonceBound(proof verifiable.Proof, challenge string) bool {
+	nonce, _ := proof["nonce"].(string) // nolint: errcheck
+
+	return nonce == base64.StdEncoding.EncodeToString([]byte(challenge))
+}
+
+// subjectClaims returns the dot separated paths of the claims of the credential's subjects, sorted.
+func subjectClaims(vc *verifiable.Credential) ([]string, error) {
+	subjects, err := utils.CredentialSubjects(vc)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]bool)
+
+	for _, subject := range subjects {
+		collectClaims("", subject, paths)
+	}
+
+	claims := make([]string, 0, len(paths))
+
+	for path := range paths {
+		claims = append(claims, path)
+	}
+
+	sort.Strings(claims)
+
+	return claims, nil
+}
+
+func collectClaims(path string, value interface{}, paths map[string]bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, claim := range v {
+			// JSON-LD keywords are no claims
+			if strings.HasPrefix(name, "@") {
+				continue
+			}
+
+			if path != "" {
+				name = path + "." + name
+			}
+
+			collectClaims(name, claim, paths)
+		}
+	case []interface{}:
+		for _, claim := range v {
+			collectClaims(path, claim, paths)
+		}
+	default:
+		if path != "" {
+			paths[path] = true
+		}
+	}
+}
+
+// disclosed tells whether the claims disclose the attribute, or one of its claims.
+func disclosed(claims []string, attr string) bool {
+	for _, claim := range claims {
+		if claim == attr || strings.HasPrefix(claim, attr+".") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,240 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/bbs12381g2pub"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/bbsblssignature2020"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/vdr/fingerprint"
+	"github.com/hyperledger/aries-framework-go/pkg/vdr/key"
+	"github.com/stretchr/testify/require"
+
+	vccrypto "github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+const (
+	residentCardVC = `{
+		"@context": [
+			"https://www.w3.org/2018/credentials/v1",
+			"https://w3id.org/citizenship/v1",
+			"https://w3id.org/security/bbs/v1"
+		],
+		"id": "https://issuer.oidp.uscis.gov/credentials/83627465",
+		"type": ["VerifiableCredential", "PermanentResidentCard"],
+		"issuer": "did:example:489398593",
+		"identifier": "83627465",
+		"issuanceDate": "2019-12-03T12:19:52Z",
+		"credentialSubject": {
+			"id": "did:example:b34ca6cd37bbf23",
+			"type": ["PermanentResident", "Person"],
+			"givenName": "JOHN",
+			"familyName": "SMITH",
+			"gender": "Male",
+			"birthDate": "1958-07-17"
+		}
+	}`
+
+	residentCardFrame = `{
+		"@context": [
+			"https://www.w3.org/2018/credentials/v1",
+			"https://w3id.org/citizenship/v1",
+			"https://w3id.org/security/bbs/v1"
+		],
+		"type": ["VerifiableCredential", "PermanentResidentCard"],
+		"@explicit": true,
+		"identifier": {},
+		"issuer": {},
+		"issuanceDate": {},
+		"credentialSubject": {
+			"@explicit": true,
+			"type": ["PermanentResident", "Person"],
+			"givenName": {},
+			"familyName": {}
+		}
+	}`
+)
+
+func TestDisclosure(t *testing.T) {
+	pubKey, privKey, err := bbs12381g2pub.GenerateKeyPair(sha256.New, nil)
+	require.NoError(t, err)
+
+	pubKeyBytes, err := pubKey.Marshal()
+	require.NoError(t, err)
+
+	privKeyBytes, err := privKey.Marshal()
+	require.NoError(t, err)
+
+	methodID := fingerprint.KeyFingerprint(0xeb, pubKeyBytes)
+	didKey := "did:key:" + methodID
+
+	loader := testutil.DocumentLoader(t)
+
+	op, err := New(&Config{
+		VDRI: &vdrmock.MockVDRegistry{
+			ResolveFunc: func(didID string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+				if didID != didKey {
+					return nil, fmt.Errorf("DID not found")
+				}
+
+				return key.New().Read(didKey)
+			},
+		},
+		StoreProvider:  ariesmemstorage.NewProvider(),
+		DocumentLoader: loader,
+	})
+	require.NoError(t, err)
+
+	vc, err := verifiable.ParseCredential([]byte(residentCardVC), verifiable.WithJSONLDDocumentLoader(loader))
+	require.NoError(t, err)
+
+	vc.Issuer.ID = didKey
+
+	err = vc.AddLinkedDataProof(&verifiable.LinkedDataProofContext{
+		SignatureType:           "BbsBlsSignature2020",
+		SignatureRepresentation: verifiable.SignatureProofValue,
+		Suite:                   bbsblssignature2020.New(suite.WithSigner(&bbsSigner{privKeyBytes: privKeyBytes})),
+		VerificationMethod:      didKey + "#" + methodID,
+		Purpose:                 vccrypto.AssertionMethod,
+	}, jsonld.WithDocumentLoader(loader))
+	require.NoError(t, err)
+
+	frame := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal([]byte(residentCardFrame), &frame))
+
+	derive := func(t *testing.T, nonce string) []byte {
+		t.Helper()
+
+		derived, err := vc.GenerateBBSSelectiveDisclosure(frame, []byte(nonce),
+			verifiable.WithPublicKeyFetcher(verifiable.NewVDRKeyResolver(op.vdr).PublicKeyFetcher()),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+
+		vcBytes, err := derived.MarshalJSON()
+		require.NoError(t, err)
+
+		return vcBytes
+	}
+
+	verify := func(t *testing.T, policy *verifier.DisclosurePolicy, vcBytes []byte,
+		opts *CredentialsVerificationOptions) (int, []byte) {
+		t.Helper()
+
+		require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+			ID:                "test",
+			Name:              "test verifier",
+			CredentialChecks:  []string{proofCheck, disclosureCheck},
+			DisclosurePolicy:  policy,
+			ChallengeRequired: true,
+		}))
+
+		reqBytes, err := json.Marshal(&CredentialsVerificationRequest{Credential: vcBytes, Opts: opts})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, credentialsVerificationEndpoint, http.MethodPost),
+			"/test/verifier/credentials/verify", reqBytes, map[string]string{profileIDPathParam: "test"})
+
+		return rr.Code, rr.Body.Bytes()
+	}
+
+	t.Run("disclosed claims", func(t *testing.T) {
+		code, body := verify(t, &verifier.DisclosurePolicy{
+			RequiredAttributes:  []string{"givenName", "familyName"},
+			ForbiddenAttributes: []string{"birthDate", "gender"},
+		}, derive(t, "nonce"), nil)
+		require.Equal(t, http.StatusOK, code, string(body))
+
+		resp := &CredentialsVerificationSuccessResponse{}
+		require.NoError(t, json.Unmarshal(body, resp))
+		require.Equal(t, []string{"familyName", "givenName", "id", "type"}, resp.DisclosedClaims)
+	})
+
+	t.Run("disclosure policy violated", func(t *testing.T) {
+		vcBytes := derive(t, "nonce")
+
+		code, body := verify(t, &verifier.DisclosurePolicy{RequiredAttributes: []string{"birthDate"}}, vcBytes, nil)
+		require.Equal(t, http.StatusBadRequest, code)
+
+		resp := &CredentialsVerificationFailResponse{}
+		require.NoError(t, json.Unmarshal(body, resp))
+		require.Len(t, resp.Checks, 1)
+		require.Equal(t, disclosureCheck, resp.Checks[0].Check)
+		require.Equal(t, "required attribute birthDate is not disclosed", resp.Checks[0].Error)
+		require.Contains(t, resp.Checks[0].DisclosedClaims, "givenName")
+
+		code, body = verify(t, &verifier.DisclosurePolicy{ForbiddenAttributes: []string{"givenName"}}, vcBytes, nil)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "forbidden attribute givenName is disclosed")
+	})
+
+	t.Run("nonce bound to the challenge", func(t *testing.T) {
+		c, err := op.challenges.Issue("test", "", defaultChallengeExpiry)
+		require.NoError(t, err)
+
+		policy := &verifier.DisclosurePolicy{NonceBound: true}
+		vcBytes := derive(t, c.Challenge)
+		opts := &CredentialsVerificationOptions{Challenge: c.Challenge}
+
+		code, body := verify(t, policy, vcBytes, opts)
+		require.Equal(t, http.StatusOK, code, string(body))
+
+		// the credential is replayed
+		code, body = verify(t, policy, vcBytes, opts)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "challenge already used")
+
+		code, body = verify(t, policy, vcBytes, nil)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "challenge is required to bind the nonce of the derived proof")
+
+		code, body = verify(t, policy, derive(t, "nonce"), opts)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "invalid nonce in the proof")
+		require.Contains(t, string(body), "credential has no BbsBlsSignatureProof2020 proof bound to the challenge")
+	})
+
+	t.Run("credential not derived", func(t *testing.T) {
+		claims, err := disclosedClaims(vc)
+		require.NoError(t, err)
+		require.Nil(t, claims)
+
+		claims, err = subjectClaims(vc)
+		require.NoError(t, err)
+		require.Equal(t, []string{"birthDate", "familyName", "gender", "givenName", "id", "type"}, claims)
+	})
+}
+
+type bbsSigner struct {
+	privKeyBytes []byte
+}
+
+func (s *bbsSigner) Sign(data []byte) ([]byte, error) {
+	var msgs [][]byte
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) != "" {
+			msgs = append(msgs, []byte(line))
+		}
+	}
+
+	return bbs12381g2pub.New().Sign(msgs, s.privKeyBytes)
+}
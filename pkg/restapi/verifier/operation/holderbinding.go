@@ -0,0 +1,166 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/internal/common/utils"
+)
+
+// presentation verification check of the credentials being issued to the holder
+const holderBindingCheck = "holderBinding"
+
+// checkHolderBinding checks the credentials of the presentation were issued to its signer: one of their subjects is
+// a DID with a verified proof of the presentation, unless the profile makes an exception for the credential. The
+// challenge and domain of the proofs are left to the proof check. The outcome for each credential is added to the
+// credential results.
+func (o *Operation) checkHolderBinding(profile *verifier.ProfileData, vpBytes []byte,
+	credentials *credentialResults) error {
+	vp, vcs, err := o.presentedCredentials(vpBytes)
+	if err != nil {
+		return err
+	}
+
+	holders, err := o.presentationSigners(vpBytes, vp)
+	if err != nil {
+		for i := range vcs {
+			credentials.add(i, holderBindingCheck, err, nil)
+		}
+
+		return err
+	}
+
+	var bindingErr error
+
+	for i, vc := range vcs {
+		errBinding := checkCredentialBinding(profile, vc, holders)
+		credentials.add(i, holderBindingCheck, errBinding, nil)
+
+		if errBinding != nil && bindingErr == nil {
+			bindingErr = errBinding
+		}
+	}
+
+	return bindingErr
+}
+
+// presentationSigners returns the DIDs controlling the verification methods of the verified proofs of the
+// presentation, the holder alone when the presentation names it. The holder must then have a verified proof.
+func (o *Operation) presentationSigners(vpBytes []byte, vp *verifiable.Presentation) ([]string, error) {
+	if len(vp.Proofs) == 0 {
+		return nil, errors.New("presentation has no proof binding it to its holder")
+	}
+
+	var signers []string
+
+	for _, result := range o.checkPresentationProofs(vpBytes, vp.Proofs, nil) {
+		if result.Verified && !utils.Contains(signers, result.Controller) {
+			signers = append(signers, result.Controller)
+		}
+	}
+
+	if len(signers) == 0 {
+		return nil, errors.New("presentation has no verified proof binding it to its holder")
+	}
+
+	if vp.Holder != "" {
+		if !utils.Contains(signers, vp.Holder) {
+			return nil, fmt.Errorf("presentation has no verified proof of its holder %s", vp.Holder)
+		}
+
+		return []string{vp.Holder}, nil
+	}
+
+	return signers, nil
+}
+
+// checkCredentialBinding checks one of the holders may present the credential.
+func checkCredentialBinding(profile *verifier.ProfileData, vc *verifiable.Credential, holders []string) error {
+	binding := profile.HolderBinding
+	if binding == nil {
+		binding = &verifier.HolderBinding{}
+	}
+
+	for _, holder := range holders {
+		bound, err := boundToHolder(binding, vc, holder)
+		if err != nil {
+			return err
+		}
+
+		if bound {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("credential %s is not bound to the holder %s", vc.ID, strings.Join(holders, ", "))
+}
+
+// boundToHolder tells whether the holder may present the credential.
+func boundToHolder(binding *verifier.HolderBinding, vc *verifiable.Credential, holder string) (bool, error) {
+	for _, t := range vc.Types {
+		if utils.Contains(binding.BearerTypes, t) {
+			return true, nil
+		}
+	}
+
+	subjects, err := utils.CredentialSubjects(vc)
+	if err != nil {
+		return false, err
+	}
+
+	subjectless := true
+
+	for _, subject := range subjects {
+		if id, ok := subject["id"].(string); ok && id != "" {
+			if id == holder {
+				return true, nil
+			}
+
+			subjectless = false
+		}
+
+		for _, claim := range binding.RelationshipClaims {
+			if claimID(subject, claim) == holder {
+				return true, nil
+			}
+		}
+	}
+
+	return subjectless && binding.AllowSubjectless, nil
+}
+
+// claimID returns the ID the claim of the subject refers to, either the claim itself or its id. The claim path is
+// dot separated.
+func claimID(subject map[string]interface{}, claim string) string {
+	var value interface{} = subject
+
+	for _, name := range strings.Split(claim, ".") {
+		claims, ok := value.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+
+		value = claims[name]
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		id, _ := v["id"].(string) // nolint: errcheck
+
+		return id
+	}
+
+	return ""
+}
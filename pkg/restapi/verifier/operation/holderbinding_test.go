@@ -0,0 +1,168 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+const subjectVC = `{
+	"@context": [
+		"https://www.w3.org/2018/credentials/v1",
+		"https://www.w3.org/2018/credentials/examples/v1"
+	],
+	"id": "http://example.edu/credentials/1872",
+	"type": ["VerifiableCredential", "UniversityDegreeCredential"],
+	"credentialSubject": %s,
+	"issuer": "did:example:76e12ec712ebc6f1c221ebfeb1f",
+	"issuanceDate": "2010-01-01T19:23:24Z"
+}`
+
+func TestHolderBinding(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	didID := "did:test:EiBNfNRaz1Ll8BjVsbNv-fWc7K_KIoPuW8GFCh1_Tz_Iuw=="
+	didDoc := createDIDDoc(didID, pubKey)
+	verificationMethod := didDoc.VerificationMethod[0].ID
+
+	loader := testutil.DocumentLoader(t)
+
+	op, err := New(&Config{
+		VDRI:           &vdrmock.MockVDRegistry{ResolveValue: didDoc},
+		StoreProvider:  ariesmemstorage.NewProvider(),
+		DocumentLoader: loader,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+		ID:                 "test",
+		Name:               "test verifier",
+		PresentationChecks: []string{proofCheck, holderBindingCheck},
+	}))
+
+	verify := func(t *testing.T, subject string) *VerifyPresentationFailureResponse {
+		t.Helper()
+
+		vp := getSignedVP(t, privKey, fmt.Sprintf(subjectVC, subject), didID, verificationMethod, didID,
+			verificationMethod, "", "")
+
+		reqBytes, err := json.Marshal(&VerifyPresentationRequest{Presentation: vp})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, presentationsVerificationEndpoint, http.MethodPost),
+			"/test/verifier/presentations/verify", reqBytes, map[string]string{profileIDPathParam: "test"})
+		if rr.Code == http.StatusOK {
+			return nil
+		}
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+
+		resp := &VerifyPresentationFailureResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+
+		return resp
+	}
+
+	t.Run("credential issued to the holder", func(t *testing.T) {
+		require.Nil(t, verify(t, fmt.Sprintf(`{"id": "%s", "degree": {"type": "BachelorDegree"}}`, didID)))
+	})
+
+	t.Run("credential issued to someone else", func(t *testing.T) {
+		resp := verify(t, `{"id": "did:example:ebfeb1f712ebc6f1c276e12ec21", "degree": {"type": "BachelorDegree"}}`)
+		require.NotNil(t, resp)
+		require.Len(t, resp.Checks, 1)
+		require.Equal(t, holderBindingCheck, resp.Checks[0].Check)
+		require.Equal(t, "credential http://example.edu/credentials/1872 is not bound to the holder "+didID,
+			resp.Checks[0].Error)
+	})
+
+	t.Run("unsigned presentation", func(t *testing.T) {
+		vp, err := verifiable.NewPresentation()
+		require.NoError(t, err)
+
+		vpBytes, err := vp.MarshalJSON()
+		require.NoError(t, err)
+
+		err = op.checkHolderBinding(&verifier.ProfileData{}, vpBytes, newCredentialResults())
+		require.EqualError(t, err, "presentation has no proof binding it to its holder")
+	})
+
+	t.Run("proof claiming the holder's verification method", func(t *testing.T) {
+		_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		vp := getSignedVP(t, otherKey, fmt.Sprintf(subjectVC, fmt.Sprintf(`{"id": "%s"}`, didID)), didID,
+			verificationMethod, didID, verificationMethod, "", "")
+
+		err = op.checkHolderBinding(&verifier.ProfileData{}, vp, newCredentialResults())
+		require.EqualError(t, err, "presentation has no verified proof binding it to its holder")
+	})
+
+	t.Run("holder without a verified proof", func(t *testing.T) {
+		vp := getSignedVP(t, privKey, fmt.Sprintf(subjectVC, fmt.Sprintf(`{"id": "%s"}`, didID)),
+			"did:example:ebfeb1f712ebc6f1c276e12ec21", verificationMethod, didID, verificationMethod, "", "")
+
+		err := op.checkHolderBinding(&verifier.ProfileData{}, vp, newCredentialResults())
+		require.EqualError(t, err, "presentation has no verified proof of its holder "+
+			"did:example:ebfeb1f712ebc6f1c276e12ec21")
+	})
+
+	t.Run("exceptions", func(t *testing.T) {
+		parse := func(subject string) *verifiable.Credential {
+			vc, err := verifiable.ParseCredential([]byte(fmt.Sprintf(subjectVC, subject)),
+				verifiable.WithDisabledProofCheck(), verifiable.WithJSONLDDocumentLoader(loader))
+			require.NoError(t, err)
+
+			return vc
+		}
+
+		holder := "did:example:parent"
+		child := parse(`{"id": "did:example:child", "parent": {"id": "did:example:parent"}, "guardian": "did:example:x"}`)
+		bearer := parse(`{"degree": {"type": "BachelorDegree"}}`)
+
+		tests := []struct {
+			name    string
+			binding *verifier.HolderBinding
+			vc      *verifiable.Credential
+			bound   bool
+		}{
+			{name: "no exception", binding: &verifier.HolderBinding{}, vc: child},
+			{name: "bearer type", binding: &verifier.HolderBinding{
+				BearerTypes: []string{"UniversityDegreeCredential"},
+			}, vc: child, bound: true},
+			{name: "relationship", binding: &verifier.HolderBinding{
+				RelationshipClaims: []string{"parent"},
+			}, vc: child, bound: true},
+			{name: "other relationship", binding: &verifier.HolderBinding{
+				RelationshipClaims: []string{"guardian", "parent.name"},
+			}, vc: child},
+			{name: "subjectless not allowed", binding: &verifier.HolderBinding{}, vc: bearer},
+			{name: "subjectless", binding: &verifier.HolderBinding{AllowSubjectless: true}, vc: bearer, bound: true},
+			{name: "subjectless doesn't apply", binding: &verifier.HolderBinding{AllowSubjectless: true}, vc: child},
+		}
+
+		for _, tc := range tests {
+			bound, err := boundToHolder(tc.binding, tc.vc, holder)
+			require.NoError(t, err, tc.name)
+			require.Equal(t, tc.bound, bound, tc.name)
+		}
+	})
+}
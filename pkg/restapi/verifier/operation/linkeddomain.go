@@ -0,0 +1,131 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	"github.com/trustbloc/edge-service/pkg/doc/didconfig"
+)
+
+// credential and presentation verification check of the web origins the issuer DIDs link to
+const linkedDomainCheck = "linkedDomain"
+
+// checkLinkedDomain checks the issuer of the credential controls the origins its DID links to with LinkedDomains
+// services: each origin must publish a DID configuration with a valid domain linkage credential of the DID.
+func (o *Operation) checkLinkedDomain(vc *verifiable.Credential) error {
+	issuer := vc.Issuer.ID
+
+	docResolution, err := o.vdr.Resolve(issuer)
+	if err != nil {
+		return fmt.Errorf("failed to resolve issuer DID %s: %w", issuer, err)
+	}
+
+	origins := didconfig.LinkedDomains(docResolution.DIDDocument)
+	if len(origins) == 0 {
+		return fmt.Errorf("issuer DID %s has no %s service", issuer, didconfig.LinkedDomainsServiceType)
+	}
+
+	for _, origin := range origins {
+		if err = o.checkDIDConfiguration(issuer, origin); err != nil {
+			return fmt.Errorf("domain %s isn't linked to %s : %w", origin, issuer, err)
+		}
+	}
+
+	return nil
+}
+
+// checkPresentationLinkedDomains checks the linked domains of the issuers of the presentation's credentials, the
+// outcome for each credential is added to the credential results.
+func (o *Operation) checkPresentationLinkedDomains(vpBytes []byte, credentials *credentialResults) error {
+	_, vcs, err := o.presentedCredentials(vpBytes)
+	if err != nil {
+		return err
+	}
+
+	var domainErr error
+
+	for i, vc := range vcs {
+		errDomain := o.checkLinkedDomain(vc)
+		credentials.add(i, linkedDomainCheck, errDomain, nil)
+
+		if errDomain != nil && domainErr == nil {
+			domainErr = errDomain
+		}
+	}
+
+	return domainErr
+}
+
+// checkDIDConfiguration fetches the DID configuration of the origin, one of its domain linkage credentials must link
+// the DID to the origin.
+func (o *Operation) checkDIDConfiguration(didID, origin string) error {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(origin, "/")+didconfig.WellKnownPath, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := o.sendHTTPRequest(req, http.StatusOK, "")
+	if err != nil {
+		return fmt.Errorf("failed to fetch did configuration: %w", err)
+	}
+
+	config := &didconfig.Configuration{}
+
+	if err = json.Unmarshal(resp, config); err != nil {
+		return fmt.Errorf("invalid did configuration: %w", err)
+	}
+
+	if len(config.LinkedDIDs) == 0 {
+		return errors.New("did configuration has no domain linkage credential")
+	}
+
+	errs := make([]string, 0, len(config.LinkedDIDs))
+
+	for _, linkedDID := range config.LinkedDIDs {
+		if err = o.checkDomainLinkageCredential(linkedDID, didID, origin); err == nil {
+			return nil
+		}
+
+		errs = append(errs, err.Error())
+	}
+
+	return fmt.Errorf("no valid domain linkage credential: %s", strings.Join(errs, "; "))
+}
+
+// checkDomainLinkageCredential verifies the domain linkage credential of the DID configuration, in the JSON-LD or
+// the JWT format.
+func (o *Operation) checkDomainLinkageCredential(linkedDID json.RawMessage, didID, origin string) error {
+	vcBytes := []byte(linkedDID)
+	isJWT := false
+
+	var jwt string
+
+	if json.Unmarshal(linkedDID, &jwt) == nil {
+		vcBytes = []byte(jwt)
+		isJWT = true
+	}
+
+	vc, err := verifiable.ParseCredential(vcBytes,
+		verifiable.WithPublicKeyFetcher(verifiable.NewVDRKeyResolver(o.vdr).PublicKeyFetcher()),
+		verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+	if err != nil {
+		return err
+	}
+
+	if !isJWT && len(vc.Proofs) == 0 {
+		return errors.New("credential has no proof")
+	}
+
+	return didconfig.ValidateCredential(vc, didID, origin)
+}
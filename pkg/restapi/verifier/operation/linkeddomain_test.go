@@ -0,0 +1,207 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/doc/didconfig"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+const domainLinkageVC = `{
+	"@context": [
+		"https://www.w3.org/2018/credentials/v1",
+		"https://identity.foundation/.well-known/did-configuration/v1"
+	],
+	"type": ["VerifiableCredential", "DomainLinkageCredential"],
+	"issuer": "%s",
+	"issuanceDate": "2020-01-01T00:00:00Z",
+	"expirationDate": "%s",
+	"credentialSubject": {"id": "%s", "origin": "%s"}
+}`
+
+func TestLinkedDomain(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	const origin = "https://issuer.example.com"
+
+	didID := "did:test:EiBNfNRaz1Ll8BjVsbNv-fWc7K_KIoPuW8GFCh1_Tz_Iuw=="
+	didDoc := createDIDDoc(didID, pubKey)
+	verificationMethod := didDoc.VerificationMethod[0].ID
+
+	didDoc.Service = append(didDoc.Service, did.Service{
+		ID:              didID + "#linked-domain",
+		Type:            didconfig.LinkedDomainsServiceType,
+		ServiceEndpoint: origin,
+	})
+
+	vdr := &vdrmock.MockVDRegistry{ResolveValue: didDoc}
+
+	op, err := New(&Config{
+		VDRI:           vdr,
+		StoreProvider:  ariesmemstorage.NewProvider(),
+		DocumentLoader: testutil.DocumentLoader(t),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+		ID:                 "test",
+		Name:               "test verifier",
+		CredentialChecks:   []string{linkedDomainCheck},
+		PresentationChecks: []string{linkedDomainCheck},
+	}))
+
+	linkage := func(t *testing.T, subjectOrigin string, expires time.Time) json.RawMessage {
+		t.Helper()
+
+		return getSignedVC(t, privKey, fmt.Sprintf(domainLinkageVC, didID, expires.Format(time.RFC3339), didID,
+			subjectOrigin), didID, verificationMethod, "", "")
+	}
+
+	// the origin of the issuer is a local stand-in
+	serve := func(t *testing.T, linkedDIDs ...json.RawMessage) {
+		t.Helper()
+
+		configBytes, err := json.Marshal(&didconfig.Configuration{Context: didconfig.Context, LinkedDIDs: linkedDIDs})
+		require.NoError(t, err)
+
+		op.httpClient = &handlerClient{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.String() != origin+didconfig.WellKnownPath {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			w.Write(configBytes) // nolint: errcheck,gosec
+		})}
+	}
+
+	vc := getSignedVC(t, privKey, fmt.Sprintf(subjectVC, `{"id": "did:example:ebfeb1f712ebc6f1c276e12ec21"}`),
+		didID, verificationMethod, "", "")
+
+	verify := func(t *testing.T) (int, string) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(&CredentialsVerificationRequest{Credential: vc})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, credentialsVerificationEndpoint, http.MethodPost),
+			"/test/verifier/credentials/verify", reqBytes, map[string]string{profileIDPathParam: "test"})
+
+		return rr.Code, rr.Body.String()
+	}
+
+	t.Run("linked domain", func(t *testing.T) {
+		serve(t, linkage(t, origin, time.Now().Add(time.Hour)))
+
+		code, body := verify(t)
+		require.Equal(t, http.StatusOK, code, body)
+
+		vp := getSignedVP(t, privKey, fmt.Sprintf(subjectVC, `{"id": "did:example:ebfeb1f712ebc6f1c276e12ec21"}`),
+			didID, verificationMethod, didID, verificationMethod, "", "")
+
+		reqBytes, err := json.Marshal(&VerifyPresentationRequest{Presentation: vp})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, presentationsVerificationEndpoint, http.MethodPost),
+			"/test/verifier/presentations/verify", reqBytes, map[string]string{profileIDPathParam: "test"})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	})
+
+	t.Run("one of the linkage credentials is valid", func(t *testing.T) {
+		serve(t, linkage(t, "https://other.example.com", time.Now().Add(time.Hour)),
+			linkage(t, origin, time.Now().Add(time.Hour)))
+
+		code, body := verify(t)
+		require.Equal(t, http.StatusOK, code, body)
+	})
+
+	t.Run("domain not linked", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			linkedDIDs []json.RawMessage
+			err        string
+		}{
+			{
+				name: "no linkage credential",
+				err:  "did configuration has no domain linkage credential",
+			},
+			{
+				name:       "other origin",
+				linkedDIDs: []json.RawMessage{linkage(t, "https://other.example.com", time.Now().Add(time.Hour))},
+				err:        "credential origin https://other.example.com isn't " + origin,
+			},
+			{
+				name:       "expired",
+				linkedDIDs: []json.RawMessage{linkage(t, origin, time.Now().Add(-time.Hour))},
+				err:        "credential is expired",
+			},
+			{
+				name: "no proof",
+				linkedDIDs: []json.RawMessage{json.RawMessage(fmt.Sprintf(domainLinkageVC, didID,
+					time.Now().Add(time.Hour).Format(time.RFC3339), didID, origin))},
+				err: "credential has no proof",
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				serve(t, tc.linkedDIDs...)
+
+				code, body := verify(t)
+				require.Equal(t, http.StatusBadRequest, code)
+				require.Contains(t, body, "domain "+origin+" isn't linked to "+didID)
+				require.Contains(t, body, tc.err)
+			})
+		}
+	})
+
+	t.Run("did configuration not found", func(t *testing.T) {
+		op.httpClient = &handlerClient{handler: http.NotFoundHandler()}
+
+		code, body := verify(t)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "failed to fetch did configuration")
+	})
+
+	t.Run("issuer without linked domains", func(t *testing.T) {
+		vdr.ResolveValue = createDIDDoc(didID, pubKey)
+		defer func() { vdr.ResolveValue = didDoc }()
+
+		code, body := verify(t)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "issuer DID "+didID+" has no LinkedDomains service")
+	})
+}
+
+// handlerClient serves the requests with its handler, in place of the origins.
+type handlerClient struct {
+	handler http.Handler
+}
+
+func (c *handlerClient) Do(req *http.Request) (*http.Response, error) {
+	rr := httptest.NewRecorder()
+
+	c.handler.ServeHTTP(rr, req)
+
+	return rr.Result(), nil
+}
@@ -6,7 +6,87 @@ SPDX-License-Identifier: Apache-2.0
 
 package operation
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/presexch"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+)
+
+// UpdateProfileRequest updates the mutable fields of a verifier profile. PUT replaces them all, the fields left out
+// are reset, whereas PATCH only updates the fields set. An empty list of checks or definitions clears them.
+type UpdateProfileRequest struct {
+	Name                    *string                            `json:"name,omitempty"`
+	CredentialChecks        []string                           `json:"credentialChecks"`
+	PresentationChecks      []string                           `json:"presentationChecks"`
+	PresentationDefinitions []*presexch.PresentationDefinition `json:"presentationDefinitions"`
+	ChallengeRequired       *bool                              `json:"challengeRequired,omitempty"`
+	HolderBinding           *verifier.HolderBinding            `json:"holderBinding,omitempty"`
+	ProofPolicy             *verifier.ProofPolicy              `json:"proofPolicy,omitempty"`
+	DisclosurePolicy        *verifier.DisclosurePolicy         `json:"disclosurePolicy,omitempty"`
+	CachedStatusLists       *bool                              `json:"cachedStatusLists,omitempty"`
+}
+
+// apply returns a copy of the profile updated by the request.
+func (r *UpdateProfileRequest) apply(profile *verifier.ProfileData, replace bool) *verifier.ProfileData {
+	updated := *profile
+
+	switch {
+	case r.Name != nil:
+		updated.Name = *r.Name
+	case replace:
+		updated.Name = ""
+	}
+
+	if r.CredentialChecks != nil || replace {
+		updated.CredentialChecks = r.CredentialChecks
+	}
+
+	if r.PresentationChecks != nil || replace {
+		updated.PresentationChecks = r.PresentationChecks
+	}
+
+	if r.PresentationDefinitions != nil || replace {
+		updated.PresentationDefinitions = r.PresentationDefinitions
+	}
+
+	switch {
+	case r.ChallengeRequired != nil:
+		updated.ChallengeRequired = *r.ChallengeRequired
+	case replace:
+		updated.ChallengeRequired = false
+	}
+
+	if r.HolderBinding != nil || replace {
+		updated.HolderBinding = r.HolderBinding
+	}
+
+	if r.ProofPolicy != nil || replace {
+		updated.ProofPolicy = r.ProofPolicy
+	}
+
+	if r.DisclosurePolicy != nil || replace {
+		updated.DisclosurePolicy = r.DisclosurePolicy
+	}
+
+	switch {
+	case r.CachedStatusLists != nil:
+		updated.CachedStatusLists = *r.CachedStatusLists
+	case replace:
+		updated.CachedStatusLists = false
+	}
+
+	return &updated
+}
+
+// ListProfilesResponse is a page of verifier profiles.
+type ListProfilesResponse struct {
+	Profiles []*verifier.ProfileData `json:"profiles"`
+	Total    int                     `json:"total"`
+}
 
 // CredentialsVerificationRequest request for verifying credential.
 type CredentialsVerificationRequest struct {
@@ -19,23 +99,34 @@ type CredentialsVerificationOptions struct {
 	Domain    string   `json:"domain,omitempty"`
 	Challenge string   `json:"challenge,omitempty"`
 	Checks    []string `json:"checks,omitempty"`
+	// Detailed asks for the verification report instead of the check results.
+	Detailed bool `json:"detailed,omitempty"`
 }
 
 // CredentialsVerificationSuccessResponse resp when credential verification is success.
 type CredentialsVerificationSuccessResponse struct {
 	Checks []string `json:"checks,omitempty"`
+	// Proofs are the results of the proof check per proof.
+	Proofs []*ProofResult `json:"proofs,omitempty"`
+	// DisclosedClaims are the subject claims a credential derived for selective disclosure reveals.
+	DisclosedClaims []string `json:"disclosedClaims,omitempty"`
+	// Warnings tell the checks passed with caveats, e.g. the status list was fetched from cache.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // CredentialsVerificationFailResponse resp when credential verification is failed.
 type CredentialsVerificationFailResponse struct {
-	Checks []CredentialsVerificationCheckResult `json:"checks,omitempty"`
+	Checks   []CredentialsVerificationCheckResult `json:"checks,omitempty"`
+	Warnings []string                             `json:"warnings,omitempty"`
 }
 
 // CredentialsVerificationCheckResult resp containing failure check details.
 type CredentialsVerificationCheckResult struct {
-	Check              string `json:"check,omitempty"`
-	Error              string `json:"error,omitempty"`
-	VerificationMethod string `json:"verificationMethod,omitempty"`
+	Check              string         `json:"check,omitempty"`
+	Error              string         `json:"error,omitempty"`
+	VerificationMethod string         `json:"verificationMethod,omitempty"`
+	Proofs             []*ProofResult `json:"proofs,omitempty"`
+	DisclosedClaims    []string       `json:"disclosedClaims,omitempty"`
 }
 
 // VerifyPresentationRequest request for verifying presentation.
@@ -49,23 +140,61 @@ type VerifyPresentationOptions struct {
 	Domain    string   `json:"domain,omitempty"`
 	Challenge string   `json:"challenge,omitempty"`
 	Checks    []string `json:"checks,omitempty"`
+	// PresentationDefinitionID selects the profile's presentation definition the presentation is checked against,
+	// by default the definition_id of its presentation_submission or else the profile's only definition.
+	PresentationDefinitionID string `json:"presentationDefinitionID,omitempty"`
+	// Detailed asks for the verification report instead of the check results.
+	Detailed bool `json:"detailed,omitempty"`
+	// PresentationSubmission is the submission of a presentation that doesn't embed it, as OpenID4VP passes it
+	// alongside the presentation.
+	PresentationSubmission map[string]interface{} `json:"presentationSubmission,omitempty"`
 }
 
 // VerifyPresentationSuccessResponse resp when presentation verification is success.
 type VerifyPresentationSuccessResponse struct {
 	Checks []string `json:"checks,omitempty"`
+	// Proofs are the results of the proof check per presentation proof.
+	Proofs []*ProofResult `json:"proofs,omitempty"`
+	// MatchedCredentials are the credentials of the presentation matched per input descriptor ID, set by the
+	// presentationDefinition check.
+	MatchedCredentials map[string]*verifiable.Credential `json:"matchedCredentials,omitempty"`
+	// Warnings tell the checks passed with caveats, e.g. the status list of a credential was fetched from cache.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // VerifyPresentationFailureResponse resp when presentation verification is failed.
 type VerifyPresentationFailureResponse struct {
-	Checks []VerifyPresentationCheckResult `json:"checks,omitempty"`
+	Checks   []VerifyPresentationCheckResult `json:"checks,omitempty"`
+	Warnings []string                        `json:"warnings,omitempty"`
 }
 
 // VerifyPresentationCheckResult resp containing failure check details.
 type VerifyPresentationCheckResult struct {
-	Check              string `json:"check,omitempty"`
-	Error              string `json:"error,omitempty"`
+	Check              string         `json:"check,omitempty"`
+	Error              string         `json:"error,omitempty"`
+	VerificationMethod string         `json:"verificationMethod,omitempty"`
+	Proofs             []*ProofResult `json:"proofs,omitempty"`
+	DisclosedClaims    []string       `json:"disclosedClaims,omitempty"`
+}
+
+// ProofResult is the verification result of a proof.
+type ProofResult struct {
 	VerificationMethod string `json:"verificationMethod,omitempty"`
+	// Type is the signature suite of the proof.
+	Type         string `json:"type,omitempty"`
+	ProofPurpose string `json:"proofPurpose,omitempty"`
+	// Controller is the DID of the verification method, the signer.
+	Controller string `json:"controller,omitempty"`
+	Verified   bool   `json:"verified"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ChallengeRequest requests a challenge for the holder to sign in the proof of its presentation.
+type ChallengeRequest struct {
+	// Domain the challenge is bound to, the presentation is verified with the same domain.
+	Domain string `json:"domain,omitempty"`
+	// ExpiresIn is the validity of the challenge in seconds, 5 minutes by default and an hour at most.
+	ExpiresIn int64 `json:"expiresIn,omitempty"`
 }
 
 // VerifyCredentialResponse describes verify credential response
@@ -73,3 +202,105 @@ type VerifyCredentialResponse struct {
 	Verified bool   `json:"verified"`
 	Message  string `json:"message"`
 }
+
+// VerificationReport is the detailed result of a credential or presentation verification, with the outcome of each
+// check and the warnings apart from the errors.
+type VerificationReport struct {
+	Verified bool `json:"verified"`
+	// Checks are the outcomes of the presentation checks, the credential checks are reported per credential.
+	Checks []*CheckReport `json:"checks,omitempty"`
+	// Credentials are the verified credential, or the credentials of the presentation.
+	Credentials []*CredentialReport `json:"credentials,omitempty"`
+	Warnings    []string            `json:"warnings,omitempty"`
+	// MatchedCredentials are the credentials of the presentation matched per input descriptor ID.
+	MatchedCredentials map[string]*verifiable.Credential `json:"matchedCredentials,omitempty"`
+}
+
+// CredentialReport is the verification result of a credential.
+type CredentialReport struct {
+	ID              string         `json:"id,omitempty"`
+	Issuer          string         `json:"issuer,omitempty"`
+	Types           []string       `json:"types,omitempty"`
+	Verified        bool           `json:"verified"`
+	Checks          []*CheckReport `json:"checks,omitempty"`
+	Warnings        []string       `json:"warnings,omitempty"`
+	DisclosedClaims []string       `json:"disclosedClaims,omitempty"`
+}
+
+// CheckReport is the outcome of a verification check.
+type CheckReport struct {
+	Check    string         `json:"check"`
+	Verified bool           `json:"verified"`
+	Error    string         `json:"error,omitempty"`
+	Proofs   []*ProofResult `json:"proofs,omitempty"`
+}
+
+// CreatePresentationRequest creates a request for a presentation.
+type CreatePresentationRequest struct {
+	// Format is openid4vp, by default, for an OpenID4VP authorization request or vpr for a Verifiable Presentation
+	// Request.
+	Format string `json:"format,omitempty"`
+	// PresentationDefinitionID selects the profile's presentation definition the presentation is requested for, by
+	// default the profile's only definition. OpenID4VP requests require one.
+	PresentationDefinitionID string `json:"presentationDefinitionID,omitempty"`
+	// Query is the query of a Verifiable Presentation Request, a DIDAuthentication query by default.
+	Query []map[string]interface{} `json:"query,omitempty"`
+	// ExpiresIn is how many seconds the wallet has to respond, 10 minutes by default.
+	ExpiresIn int64 `json:"expiresIn,omitempty"`
+	// CallbackURL is posted the status of the request once the wallet responds.
+	CallbackURL string `json:"callbackURL,omitempty"`
+}
+
+// PresentationRequestResponse is the created presentation request, to pass to the wallet.
+type PresentationRequestResponse struct {
+	ID string `json:"id"`
+	// AuthorizationRequest is the OpenID4VP authorization request, RequestURI passes it by value.
+	AuthorizationRequest *AuthorizationRequest `json:"authorizationRequest,omitempty"`
+	RequestURI           string                `json:"requestURI,omitempty"`
+	// VPR is the Verifiable Presentation Request.
+	VPR       *VerifiablePresentationRequest `json:"vpr,omitempty"`
+	ExpiresAt time.Time                      `json:"expiresAt"`
+}
+
+// AuthorizationRequest is an OpenID4VP authorization request, the wallet posts its response to the response URI.
+type AuthorizationRequest struct {
+	ResponseType           string                           `json:"response_type"`
+	ResponseMode           string                           `json:"response_mode"`
+	ClientID               string                           `json:"client_id"`
+	ClientIDScheme         string                           `json:"client_id_scheme"`
+	ResponseURI            string                           `json:"response_uri"`
+	Nonce                  string                           `json:"nonce"`
+	State                  string                           `json:"state"`
+	PresentationDefinition *presexch.PresentationDefinition `json:"presentation_definition"`
+}
+
+// VerifiablePresentationRequest is a Verifiable Presentation Request, the wallet posts its presentation to the
+// service it interacts with.
+type VerifiablePresentationRequest struct {
+	Query     []map[string]interface{} `json:"query"`
+	Challenge string                   `json:"challenge"`
+	Domain    string                   `json:"domain,omitempty"`
+	Interact  *VPRInteract             `json:"interact"`
+}
+
+// VPRInteract lists the services a wallet interacts with to respond to a Verifiable Presentation Request.
+type VPRInteract struct {
+	Service []*VPRService `json:"service"`
+}
+
+// VPRService is a service a wallet interacts with.
+type VPRService struct {
+	Type            string `json:"type"`
+	ServiceEndpoint string `json:"serviceEndpoint"`
+}
+
+// PresentationRequestStatus is the status of a presentation request: pending until the wallet responds with a
+// presentation verified, then verified, or expired.
+type PresentationRequestStatus struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	// Presentation is the verified presentation the wallet responded with.
+	Presentation json.RawMessage     `json:"presentation,omitempty"`
+	Report       *VerificationReport `json:"report,omitempty"`
+	ExpiresAt    time.Time           `json:"expiresAt"`
+}
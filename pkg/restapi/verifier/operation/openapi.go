@@ -7,6 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package operation
 
 import (
+	"github.com/hyperledger/aries-framework-go/pkg/doc/presexch"
+
+	challengestore "github.com/trustbloc/edge-service/pkg/challenge"
 	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
 )
@@ -38,6 +41,43 @@ type getProfileReq struct { // nolint: unused,deadcode
 	ID string `json:"id"`
 }
 
+// listProfilesReq model
+//
+// swagger:parameters listProfilesReq
+type listProfilesReq struct { // nolint: unused,deadcode
+	// index of the first profile listed
+	//
+	// in: query
+	Offset int `json:"offset"`
+
+	// maximum number of profiles listed, 100 by default
+	//
+	// in: query
+	Limit int `json:"limit"`
+}
+
+// listProfilesRes model
+//
+// swagger:response listProfilesRes
+type listProfilesRes struct { // nolint: unused,deadcode
+	// in: body
+	ListProfilesResponse
+}
+
+// updateProfileReq model
+//
+// swagger:parameters updateProfileReq
+type updateProfileReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params UpdateProfileRequest
+}
+
 // deleteProfileReq model
 //
 // swagger:parameters deleteProfileReq
@@ -76,7 +116,8 @@ type verifyCredentialSuccessResp struct { // nolint: unused,deadcode
 // swagger:response verifyCredentialFailureResp
 type verifyCredentialFailureResp struct { // nolint: unused,deadcode
 	// in: body
-	Checks []*CredentialsVerificationCheckResult `json:"checks,omitempty"`
+	Checks   []*CredentialsVerificationCheckResult `json:"checks,omitempty"`
+	Warnings []string                              `json:"warnings,omitempty"`
 }
 
 // verifyPresentationReq model
@@ -106,7 +147,55 @@ type verifyPresentationSuccessResp struct { // nolint: unused,deadcode
 // swagger:response verifyPresentationFailureResp
 type verifyPresentationFailureResp struct { // nolint: unused,deadcode
 	// in: body
-	Checks []*VerifyPresentationCheckResult `json:"checks,omitempty"`
+	Checks   []*VerifyPresentationCheckResult `json:"checks,omitempty"`
+	Warnings []string                         `json:"warnings,omitempty"`
+}
+
+// defReq model
+//
+// swagger:parameters defReq
+type defReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// presentation definition
+	//
+	// in: path
+	// required: true
+	DefinitionID string `json:"definitionID"`
+}
+
+// presentationDefinitionRes model
+//
+// swagger:response presentationDefinitionRes
+type presentationDefinitionRes struct { // nolint: unused,deadcode
+	// in: body
+	presexch.PresentationDefinition
+}
+
+// challengeReq model
+//
+// swagger:parameters challengeReq
+type challengeReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params ChallengeRequest
+}
+
+// challengeRes model
+//
+// swagger:response challengeRes
+type challengeRes struct { // nolint: unused,deadcode
+	// in: body
+	challengestore.Challenge
 }
 
 // emptyRes model
@@ -114,3 +203,70 @@ type verifyPresentationFailureResp struct { // nolint: unused,deadcode
 // swagger:response emptyRes
 type emptyRes struct { // nolint: unused,deadcode
 }
+
+// presReqReq model
+//
+// swagger:parameters presReqReq
+type presReqReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params CreatePresentationRequest
+}
+
+// presReqRes model
+//
+// swagger:response presReqRes
+type presReqRes struct { // nolint: unused,deadcode
+	// in: body
+	PresentationRequestResponse
+}
+
+// presReqStatusReq model
+//
+// swagger:parameters presReqStatusReq
+type presReqStatusReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// presentation request
+	//
+	// in: path
+	// required: true
+	RequestID string `json:"requestID"`
+}
+
+// presReqStatusRes model
+//
+// swagger:response presReqStatusRes
+type presReqStatusRes struct { // nolint: unused,deadcode
+	// in: body
+	PresentationRequestStatus
+}
+
+// vpRespReq model
+//
+// swagger:parameters vpRespReq
+type vpRespReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// presentation request
+	//
+	// in: path
+	// required: true
+	RequestID string `json:"requestID"`
+
+	// in: body
+	Params VerifyPresentationRequest
+}
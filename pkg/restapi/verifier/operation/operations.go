@@ -14,6 +14,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	jsonldcontextrest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/jsonld/context"
@@ -23,7 +24,9 @@ import (
 	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/piprate/json-gold/ld"
 	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/edge-core/pkg/utils/retry"
 
+	challengestore "github.com/trustbloc/edge-service/pkg/challenge"
 	"github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
 	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
 	"github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
@@ -31,6 +34,7 @@ import (
 	"github.com/trustbloc/edge-service/pkg/internal/common/support"
 	"github.com/trustbloc/edge-service/pkg/internal/common/utils"
 	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+	"github.com/trustbloc/edge-service/pkg/webhook"
 )
 
 const (
@@ -59,6 +63,9 @@ const (
 	verificationMethod = "verificationMethod"
 
 	cslRequestTokenName = "csl"
+
+	// callbackTimeout bounds each attempt to post the status of a presentation request to its callback URL.
+	callbackTimeout = 10 * time.Second
 )
 
 var logger = log.New("edge-service-verifier-restapi")
@@ -86,6 +93,24 @@ func New(config *Config) (*Operation, error) {
 		return nil, fmt.Errorf("create jsonld context operation: %w", err)
 	}
 
+	challenges, err := challengestore.New(config.StoreProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	requestStore, err := config.StoreProvider.OpenStore(presentationRequestStoreName)
+	if err != nil {
+		return nil, err
+	}
+
+	callbacks, err := webhook.New(config.StoreProvider, &http.Client{
+		Transport: &http.Transport{TLSClientConfig: config.TLSConfig},
+		Timeout:   callbackTimeout,
+	}, config.RetryParameters)
+	if err != nil {
+		return nil, err
+	}
+
 	svc := &Operation{
 		profileStore:            p,
 		vdr:                     config.VDRI,
@@ -93,6 +118,11 @@ func New(config *Config) (*Operation, error) {
 		requestTokens:           config.RequestTokens,
 		documentLoader:          config.DocumentLoader,
 		addJSONLDContextHandler: contextOp.Add,
+		challenges:              challenges,
+		requestStore:            requestStore,
+		callbacks:               callbacks,
+		hostURL:                 config.HostURL,
+		statusLists:             newStatusListCache(),
 	}
 
 	return svc, nil
@@ -105,6 +135,9 @@ type Config struct {
 	TLSConfig      *tls.Config
 	RequestTokens  map[string]string
 	DocumentLoader ld.DocumentLoader
+	HostURL        string
+	// RetryParameters tell how the posts to the callback URLs of the presentation requests are retried.
+	RetryParameters *retry.Params
 }
 
 // Operation defines handlers for Edge service
@@ -115,6 +148,11 @@ type Operation struct {
 	requestTokens           map[string]string
 	documentLoader          ld.DocumentLoader
 	addJSONLDContextHandler http.HandlerFunc
+	challenges              *challengestore.Store
+	requestStore            ariesstorage.Store
+	callbacks               *webhook.Notifier
+	hostURL                 string
+	statusLists             *statusListCache
 }
 
 // GetRESTHandlers get all controller API handler available for this service
@@ -124,10 +162,20 @@ func (o *Operation) GetRESTHandlers() []Handler {
 		support.NewHTTPHandler(profileEndpoint, http.MethodPost, o.createProfileHandler),
 		support.NewHTTPHandler(getProfileEndpoint, http.MethodGet, o.getProfileHandler),
 		support.NewHTTPHandler(deleteProfileEndpoint, http.MethodDelete, o.deleteProfileHandler),
+		support.NewHTTPHandler(profileEndpoint, http.MethodGet, o.listProfilesHandler),
+		support.NewHTTPHandler(getProfileEndpoint, http.MethodPut, o.replaceProfileHandler),
+		support.NewHTTPHandler(getProfileEndpoint, http.MethodPatch, o.updateProfileHandler),
 
 		// verification
 		support.NewHTTPHandler(credentialsVerificationEndpoint, http.MethodPost, o.verifyCredentialHandler),
 		support.NewHTTPHandler(presentationsVerificationEndpoint, http.MethodPost, o.verifyPresentationHandler),
+		support.NewHTTPHandler(presentationDefinitionEndpoint, http.MethodGet, o.getPresentationDefinitionHandler),
+		support.NewHTTPHandler(challengesEndpoint, http.MethodPost, o.issueChallengeHandler),
+
+		// presentation requests
+		support.NewHTTPHandler(presentationRequestsEndpoint, http.MethodPost, o.createPresentationRequestHandler),
+		support.NewHTTPHandler(presentationRequestEndpoint, http.MethodGet, o.getPresentationRequestHandler),
+		support.NewHTTPHandler(presentationResponseEndpoint, http.MethodPost, o.presentationResponseHandler),
 
 		// JSON-LD context API
 		support.NewHTTPHandler(jsonldcontextrest.AddContextPath, http.MethodPost, o.addJSONLDContextHandler),
@@ -219,6 +267,91 @@ func (o *Operation) deleteProfileHandler(rw http.ResponseWriter, req *http.Reque
 	}
 }
 
+// ListVerifierProfiles swagger:route GET /verifier/profile verifier listProfilesReq
+//
+// Lists verifier profiles, sorted by ID.
+//
+// Responses:
+//    default: genericError
+//        200: listProfilesRes
+func (o *Operation) listProfilesHandler(rw http.ResponseWriter, req *http.Request) {
+	offset, limit, err := commhttp.ParsePage(req)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	profiles, total, err := o.profileStore.ListProfiles(offset, limit)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to list verifier profiles: %s", err.Error()))
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, &ListProfilesResponse{Profiles: profiles, Total: total})
+}
+
+// ReplaceVerifierProfile swagger:route PUT /verifier/profile/{id} verifier updateProfileReq
+//
+// Replaces the mutable fields of the verifier profile, the fields left out are reset.
+//
+// Responses:
+//    default: genericError
+//        200: profileData
+func (o *Operation) replaceProfileHandler(rw http.ResponseWriter, req *http.Request) {
+	o.updateProfile(rw, req, true)
+}
+
+// UpdateVerifierProfile swagger:route PATCH /verifier/profile/{id} verifier updateProfileReq
+//
+// Updates the given mutable fields of the verifier profile.
+//
+// Responses:
+//    default: genericError
+//        200: profileData
+func (o *Operation) updateProfileHandler(rw http.ResponseWriter, req *http.Request) {
+	o.updateProfile(rw, req, false)
+}
+
+func (o *Operation) updateProfile(rw http.ResponseWriter, req *http.Request, replace bool) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid verifier profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	data := UpdateProfileRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	updated := data.apply(profile, replace)
+
+	if err = validateProfileRequest(updated); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	if err = o.profileStore.SaveProfile(updated); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to save verifier profile: %s", err.Error()))
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, updated)
+}
+
 //nolint:funlen,gocyclo
 // VerifyCredential swagger:route POST /{id}/verifier/credentials/verify verifier verifyCredentialReq
 //
@@ -250,42 +383,74 @@ func (o *Operation) verifyCredentialHandler(rw http.ResponseWriter, req *http.Re
 		return
 	}
 
-	vc, err := o.parseAndVerifyVC(verificationReq.Credential)
+	checks := getCredentialChecks(profile, verificationReq.Opts)
+
+	var vc *verifiable.Credential
+
+	// the proof check verifies the proofs one by one, as the proof policy of the profile tells
+	if utils.Contains(checks, proofCheck) {
+		vc, err = verifiable.ParseCredential(verificationReq.Credential, verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+	} else {
+		vc, err = o.parseAndVerifyVC(verificationReq.Credential)
+	}
+
 	if err != nil {
 		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
 
 		return
 	}
 
-	checks := getCredentialChecks(profile, verificationReq.Opts)
+	claims, err := disclosedClaims(vc)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
 
-	var result []CredentialsVerificationCheckResult
+		return
+	}
+
+	var (
+		result   []CredentialsVerificationCheckResult
+		proofs   []*ProofResult
+		warnings []string
+	)
 
 	for _, val := range checks {
 		switch val {
 		case proofCheck:
-			err := o.validateCredentialProof(verificationReq.Credential, verificationReq.Opts, false)
+			proofs, err = o.validateCredentialProof(verificationReq.Credential, verificationReq.Opts, false,
+				profile.ProofPolicy)
 			if err != nil {
 				result = append(result, CredentialsVerificationCheckResult{
-					Check: val,
-					Error: err.Error(),
+					Check:  val,
+					Error:  err.Error(),
+					Proofs: proofs,
+				})
+			}
+		case disclosureCheck:
+			if err := o.checkDisclosure(profile, vc, verificationReq.Opts); err != nil {
+				result = append(result, CredentialsVerificationCheckResult{
+					Check:           val,
+					Error:           err.Error(),
+					DisclosedClaims: claims,
 				})
 			}
 		case statusCheck:
-			failureMessage := ""
-
-			ver, err := o.checkVCStatus(vc.Status, vc.Issuer.ID)
+			warning, err := o.checkCredentialStatus(profile, vc)
+			if warning != "" {
+				warnings = append(warnings, warning)
+			}
 
 			if err != nil {
-				failureMessage = fmt.Sprintf("failed to fetch the status : %s", err.Error())
-			} else if !ver.Verified {
-				failureMessage = ver.Message
+				result = append(result, CredentialsVerificationCheckResult{
+					Check: val,
+					Error: err.Error(),
+				})
 			}
-
-			if failureMessage != "" {
+		case linkedDomainCheck:
+			if err := o.checkLinkedDomain(vc); err != nil {
 				result = append(result, CredentialsVerificationCheckResult{
 					Check: val,
-					Error: failureMessage,
+					Error: err.Error(),
 				})
 			}
 		default:
@@ -296,15 +461,35 @@ func (o *Operation) verifyCredentialHandler(rw http.ResponseWriter, req *http.Re
 		}
 	}
 
+	if verificationReq.Opts != nil && verificationReq.Opts.Detailed {
+		failed := make(map[string]string)
+
+		for _, r := range result {
+			failed[r.Check] = r.Error
+		}
+
+		report := newCredentialReport(vc, checkReports(checks, failed, proofs))
+		report.DisclosedClaims = claims
+		report.Warnings = append(report.Warnings, warnings...)
+
+		writeReport(rw, &VerificationReport{Verified: len(result) == 0, Credentials: []*CredentialReport{report}})
+
+		return
+	}
+
 	if len(result) == 0 {
 		rw.WriteHeader(http.StatusOK)
 		commhttp.WriteResponse(rw, &CredentialsVerificationSuccessResponse{
-			Checks: checks,
+			Checks:          checks,
+			Proofs:          proofs,
+			DisclosedClaims: claims,
+			Warnings:        warnings,
 		})
 	} else {
 		rw.WriteHeader(http.StatusBadRequest)
 		commhttp.WriteResponse(rw, &CredentialsVerificationFailResponse{
-			Checks: result,
+			Checks:   result,
+			Warnings: warnings,
 		})
 	}
 }
@@ -317,7 +502,7 @@ func (o *Operation) verifyCredentialHandler(rw http.ResponseWriter, req *http.Re
 //    default: genericError
 //        200: verifyPresentationSuccessResp
 //        400: verifyPresentationFailureResp
-func (o *Operation) verifyPresentationHandler(rw http.ResponseWriter, req *http.Request) { //nolint: funlen
+func (o *Operation) verifyPresentationHandler(rw http.ResponseWriter, req *http.Request) {
 	// get the profile
 	profileID := mux.Vars(req)[profileIDPathParam]
 
@@ -339,28 +524,125 @@ func (o *Operation) verifyPresentationHandler(rw http.ResponseWriter, req *http.
 		return
 	}
 
-	checks := getPresentationChecks(profile, verificationReq.Opts)
+	verification := o.verifyPresentation(profile, verificationReq.Presentation, verificationReq.Opts)
+
+	if verificationReq.Opts != nil && verificationReq.Opts.Detailed {
+		writeReport(rw, o.presentationReport(verificationReq.Presentation, verification))
+
+		return
+	}
+
+	if len(verification.result) == 0 {
+		rw.WriteHeader(http.StatusOK)
+		commhttp.WriteResponse(rw, &VerifyPresentationSuccessResponse{
+			Checks:             verification.checks,
+			Proofs:             verification.proofs,
+			MatchedCredentials: verification.matched,
+			Warnings:           verification.credentials.allWarnings(),
+		})
+	} else {
+		rw.WriteHeader(http.StatusBadRequest)
+		commhttp.WriteResponse(rw, &VerifyPresentationFailureResponse{
+			Checks:   verification.result,
+			Warnings: verification.credentials.allWarnings(),
+		})
+	}
+}
+
+// presentationVerification is the outcome of the checks of a presentation.
+type presentationVerification struct {
+	// checks are the checks run, result the failed ones.
+	checks []string
+	result []VerifyPresentationCheckResult
+	proofs []*ProofResult
+	// proofVerified tells the proof check ran and verified the presentation.
+	proofVerified bool
+	// matched are the credentials matched by the presentation definition.
+	matched map[string]*verifiable.Credential
+	// credentials are the outcomes of the checks for each credential of the presentation.
+	credentials *credentialResults
+}
+
+// verifyPresentation runs the checks of the presentation, the options or else the profile tell which.
+func (o *Operation) verifyPresentation(profile *verifier.ProfileData, vpBytes []byte,
+	opts *VerifyPresentationOptions) *presentationVerification {
+	// the proof binds the challenge, so it is always checked when the profile requires issued challenges
+	verification := o.checkPresentation(profile, vpBytes, opts, profile.ChallengeRequired)
+
+	// the challenge is consumed whichever checks run, once the proof binding it is verified
+	if verification.proofVerified {
+		if err := o.consumeChallenge(profile, opts); err != nil {
+			verification.result = append(verification.result, VerifyPresentationCheckResult{
+				Check:  proofCheck,
+				Error:  err.Error(),
+				Proofs: verification.proofs,
+			})
+		}
+	}
+
+	return verification
+}
+
+// checkPresentation runs the checks of the presentation, along with the proof check if it is required.
+// nolint: funlen
+func (o *Operation) checkPresentation(profile *verifier.ProfileData, vpBytes []byte,
+	opts *VerifyPresentationOptions, proofRequired bool) *presentationVerification {
+	checks := getPresentationChecks(profile, opts)
+
+	if proofRequired && !utils.Contains(checks, proofCheck) {
+		checks = append([]string{proofCheck}, checks...)
+	}
+
+	var (
+		result   []VerifyPresentationCheckResult
+		matched  map[string]*verifiable.Credential
+		proofs   []*ProofResult
+		proofErr error
+		err      error
+	)
 
-	var result []VerifyPresentationCheckResult
+	credentials := newCredentialResults()
 
 	for _, val := range checks {
 		switch val {
 		case proofCheck:
-			err := o.validatePresentationProof(verificationReq.Presentation, verificationReq.Opts)
-			if err != nil {
+			proofs, proofErr = o.validatePresentationProof(vpBytes, opts, profile.ProofPolicy, credentials)
+			if proofErr != nil {
+				result = append(result, VerifyPresentationCheckResult{
+					Check:  val,
+					Error:  proofErr.Error(),
+					Proofs: proofs,
+				})
+			}
+		case statusCheck:
+			if err := o.checkPresentationStatus(profile, vpBytes, credentials); err != nil {
 				result = append(result, VerifyPresentationCheckResult{
 					Check: val,
 					Error: err.Error(),
 				})
 			}
-		case statusCheck:
-			_, err := o.parseAndVerifyVP(verificationReq.Presentation, false, false, true)
+		case presentationDefinitionCheck:
+			matched, err = o.matchPresentationDefinition(profile, vpBytes, opts)
 			if err != nil {
 				result = append(result, VerifyPresentationCheckResult{
 					Check: val,
 					Error: err.Error(),
 				})
 			}
+		case holderBindingCheck:
+			if err := o.checkHolderBinding(profile, vpBytes, credentials); err != nil {
+				result = append(result, VerifyPresentationCheckResult{
+					Check: val,
+					Error: err.Error(),
+				})
+			}
+		case linkedDomainCheck:
+			if err := o.checkPresentationLinkedDomains(vpBytes, credentials); err != nil {
+				result = append(result, VerifyPresentationCheckResult{
+					Check: val,
+					Error: err.Error(),
+				})
+			}
 		default:
 			result = append(result, VerifyPresentationCheckResult{
 				Check: val,
@@ -369,125 +651,108 @@ func (o *Operation) verifyPresentationHandler(rw http.ResponseWriter, req *http.
 		}
 	}
 
-	if len(result) == 0 {
-		rw.WriteHeader(http.StatusOK)
-		commhttp.WriteResponse(rw, &VerifyPresentationSuccessResponse{
-			Checks: checks,
-		})
-	} else {
-		rw.WriteHeader(http.StatusBadRequest)
-		commhttp.WriteResponse(rw, &VerifyPresentationFailureResponse{
-			Checks: result,
-		})
+	return &presentationVerification{
+		checks:        checks,
+		result:        result,
+		proofs:        proofs,
+		proofVerified: proofErr == nil && utils.Contains(checks, proofCheck),
+		matched:       matched,
+		credentials:   credentials,
 	}
 }
 
-func (o *Operation) validateCredentialProof(vcByte []byte, opts *CredentialsVerificationOptions, vcInVPValidation bool) error { // nolint: lll,gocyclo
-	vc, err := o.parseAndVerifyVCStrictMode(vcByte)
+// validateCredentialProof checks the proofs of the credential as the policy tells, one of them must be the issuer's.
+func (o *Operation) validateCredentialProof(vcByte []byte, opts *CredentialsVerificationOptions,
+	vcInVPValidation bool, policy *verifier.ProofPolicy) ([]*ProofResult, error) {
+	// the proofs are verified one by one, along with the strict validation of the credential
+	vc, err := verifiable.ParseCredential(vcByte, verifiable.WithDisabledProofCheck(),
+		verifiable.WithJSONLDDocumentLoader(o.documentLoader))
 	if err != nil {
-		return fmt.Errorf("verifiable credential proof validation error : %w", err)
+		return nil, fmt.Errorf("verifiable credential proof validation error : %w", err)
 	}
 
 	if len(vc.Proofs) == 0 {
-		return errors.New("verifiable credential doesn't contains proof")
-	}
+		if _, err = o.parseAndVerifyVCStrictMode(vcByte); err != nil {
+			return nil, fmt.Errorf("verifiable credential proof validation error : %w", err)
+		}
 
-	// validate proof challenge and domain
-	if opts == nil {
-		opts = &CredentialsVerificationOptions{}
+		return nil, errors.New("verifiable credential doesn't contains proof")
 	}
 
-	// TODO https://github.com/trustbloc/edge-service/issues/412 figure out the process when vc has more than one proof
-	proof := vc.Proofs[0]
+	var proofOpts *proofOptions
 
+	// the challenge and domain of the credentials in a presentation are not checked
 	if !vcInVPValidation {
-		// validate challenge
-		if validateErr := validateProofData(proof, challenge, opts.Challenge); validateErr != nil {
-			return validateErr
-		}
+		proofOpts = &proofOptions{}
 
-		// validate domain
-		if validateErr := validateProofData(proof, domain, opts.Domain); validateErr != nil {
-			return validateErr
+		if opts != nil {
+			proofOpts = &proofOptions{challenge: opts.Challenge, domain: opts.Domain}
 		}
 	}
 
-	// get the verification method
-	verificationMethod, err := getVerificationMethodFromProof(proof)
-	if err != nil {
-		return err
-	}
+	results := o.checkProofs(vc.Proofs, withProof(vcByte, func(vcBytes []byte) error {
+		_, err := o.parseAndVerifyVCStrictMode(vcBytes)
 
-	// get the did doc from verification method
-	didDoc, err := getDIDDocFromProof(verificationMethod, o.vdr)
-	if err != nil {
 		return err
-	}
-
-	// validate if issuer matches the controller of verification method
-	if vc.Issuer.ID != didDoc.ID {
-		return fmt.Errorf("controller of verification method doesn't match the issuer")
-	}
-
-	// validate proof purpose
-	if err := validateProofPurpose(proof, verificationMethod, didDoc); err != nil {
-		return fmt.Errorf("verifiable credential proof purpose validation error : %w", err)
-	}
+	}), proofOpts, "credential")
 
-	return nil
+	return results, checkProofPolicy(policy, results, vc.Issuer.ID, "issuer")
 }
 
-func (o *Operation) validatePresentationProof(vpByte []byte, opts *VerifyPresentationOptions) error { // nolint: gocyclo
-	vp, err := o.parseAndVerifyVP(vpByte, true, true, false)
+// validatePresentationProof checks the proofs of the presentation and of its credentials as the policy tells, one of
+// the presentation proofs must be the holder's if it is set. The required signers of the policy sign the credentials.
+// The outcome for each credential is added to the credential results.
+func (o *Operation) validatePresentationProof(vpByte []byte, opts *VerifyPresentationOptions,
+	policy *verifier.ProofPolicy, credentials *credentialResults) ([]*ProofResult, error) {
+	vp, err := o.parseAndVerifyVP(vpByte, false)
 	if err != nil {
-		return fmt.Errorf("verifiable presentation proof validation error : %w", err)
+		return nil, fmt.Errorf("verifiable presentation proof validation error : %w", err)
 	}
 
-	// validate proof challenge and domain
-	if opts == nil {
-		opts = &VerifyPresentationOptions{}
-	}
+	var credentialErr error
+
+	// verify if the credentials in vp are valid
+	for i, cred := range vp.Credentials() {
+		vcBytes, errMarshal := json.Marshal(cred)
+		if errMarshal != nil {
+			return nil, errMarshal
+		}
 
-	var proof verifiable.Proof
+		vcProofs, errProof := o.validateCredentialProof(vcBytes, nil, true, policy)
+		credentials.add(i, proofCheck, errProof, vcProofs)
 
-	// TODO https://github.com/trustbloc/edge-service/issues/412 figure out the process when vc has more than one proof
-	if len(vp.Proofs) != 0 {
-		proof = vp.Proofs[0]
+		if errProof != nil && credentialErr == nil {
+			credentialErr = fmt.Errorf("verifiable presentation proof validation error : %w", errProof)
+		}
 	}
 
-	// validate challenge
-	if validateErr := validateProofData(proof, challenge, opts.Challenge); validateErr != nil {
-		return validateErr
+	if credentialErr != nil {
+		return nil, credentialErr
 	}
 
-	// validate domain
-	if validateErr := validateProofData(proof, domain, opts.Domain); validateErr != nil {
-		return validateErr
+	if len(vp.Proofs) == 0 {
+		return nil, errors.New("verifiable presentation doesn't contain proof")
 	}
 
-	// get the verification method
-	verificationMethod, err := getVerificationMethodFromProof(proof)
-	if err != nil {
-		return err
+	// validate proof challenge and domain
+	if opts == nil {
+		opts = &VerifyPresentationOptions{}
 	}
 
-	// get the did doc from verification method
-	didDoc, err := getDIDDocFromProof(verificationMethod, o.vdr)
-	if err != nil {
-		return err
-	}
+	results := o.checkPresentationProofs(vpByte, vp.Proofs,
+		&proofOptions{challenge: opts.Challenge, domain: opts.Domain})
 
-	// validate if holder matches the controller of verification method
-	if vp.Holder != "" && vp.Holder != didDoc.ID {
-		return fmt.Errorf("controller of verification method doesn't match the holder")
-	}
+	return results, checkProofPolicy(presentationProofPolicy(policy), results, vp.Holder, "holder")
+}
 
-	// validate proof purpose
-	if err := validateProofPurpose(proof, verificationMethod, didDoc); err != nil {
-		return fmt.Errorf("verifiable presentation proof purpose validation error : %w", err)
-	}
+// checkPresentationProofs verifies the proofs of the presentation one by one.
+func (o *Operation) checkPresentationProofs(vpBytes []byte, proofs []verifiable.Proof,
+	opts *proofOptions) []*ProofResult {
+	return o.checkProofs(proofs, withProof(vpBytes, func(singleProofVP []byte) error {
+		_, err := o.parseAndVerifyVP(singleProofVP, true)
 
-	return nil
+		return err
+	}), opts, "presentation")
 }
 
 func (o *Operation) validateVCStatus(vcStatus *verifiable.TypedID) error {
@@ -510,54 +775,90 @@ func (o *Operation) validateVCStatus(vcStatus *verifiable.TypedID) error {
 	return nil
 }
 
+// checkPresentationStatus checks the credentials of the presentation are not revoked, the outcome for each credential
+// is added to the credential results.
+func (o *Operation) checkPresentationStatus(profile *verifier.ProfileData, vpBytes []byte,
+	credentials *credentialResults) error {
+	_, vcs, err := o.presentedCredentials(vpBytes)
+	if err != nil {
+		return err
+	}
+
+	var statusErr error
+
+	for i, vc := range vcs {
+		warning, errStatus := o.checkCredentialStatus(profile, vc)
+		credentials.add(i, statusCheck, errStatus, nil)
+		credentials.warn(i, warning)
+
+		if errStatus != nil && statusErr == nil {
+			statusErr = errStatus
+		}
+	}
+
+	return statusErr
+}
+
+// checkCredentialStatus checks the credential is not revoked. It returns a warning when the status list was fetched
+// from cache, which the profile must allow.
+func (o *Operation) checkCredentialStatus(profile *verifier.ProfileData, vc *verifiable.Credential) (string, error) {
+	ver, warning, err := o.checkVCStatus(vc.Status, vc.Issuer.ID, profile.CachedStatusLists)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch the status : %w", err)
+	}
+
+	if !ver.Verified {
+		return warning, errors.New(ver.Message)
+	}
+
+	return warning, nil
+}
+
 //nolint: gocyclo
-func (o *Operation) checkVCStatus(vcStatus *verifiable.TypedID, issuer string) (*VerifyCredentialResponse, error) {
+func (o *Operation) checkVCStatus(vcStatus *verifiable.TypedID, issuer string,
+	cachedStatusList bool) (*VerifyCredentialResponse, string, error) {
 	vcResp := &VerifyCredentialResponse{
 		Verified: false, Message: "Revoked",
 	}
 
 	// validate vc status
 	if err := o.validateVCStatus(vcStatus); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	revocationListIndex, err := strconv.Atoi(vcStatus.CustomFields[csl.RevocationListIndex].(string))
 	if err != nil {
-		return nil, err
-	}
-
-	req, err := http.NewRequest(http.MethodGet, vcStatus.CustomFields[csl.RevocationListCredential].(string), nil)
-	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	resp, err := o.sendHTTPRequest(req, http.StatusOK, o.requestTokens[cslRequestTokenName])
+	resp, warning, err := o.fetchStatusList(vcStatus.CustomFields[csl.RevocationListCredential].(string),
+		cachedStatusList)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	revocationListVC, err := o.parseAndVerifyVC(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse and verify status vc: %w", err)
+		return nil, "", fmt.Errorf("failed to parse and verify status vc: %w", err)
 	}
 
 	if revocationListVC.Issuer.ID != issuer {
-		return nil, fmt.Errorf("issuer of the credential do not match vc revocation list issuer")
+		return nil, "", fmt.Errorf("issuer of the credential do not match vc revocation list issuer")
 	}
 
 	credSubject, ok := revocationListVC.Subject.([]verifiable.Subject)
 	if !ok {
-		return nil, fmt.Errorf("")
+		return nil, "", fmt.Errorf("")
 	}
 
 	bitString, err := utils.DecodeBits(credSubject[0].CustomFields["encodedList"].(string))
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode bits: %w", err)
+		return nil, "", fmt.Errorf("failed to decode bits: %w", err)
 	}
 
 	bitSet, err := bitString.Get(revocationListIndex)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	if !bitSet {
@@ -565,7 +866,7 @@ func (o *Operation) checkVCStatus(vcStatus *verifiable.TypedID, issuer string) (
 		vcResp.Message = successMsg
 	}
 
-	return vcResp, nil
+	return vcResp, warning, nil
 }
 
 func (o *Operation) parseAndVerifyVCStrictMode(vcBytes []byte) (*verifiable.Credential, error) {
@@ -584,73 +885,46 @@ func (o *Operation) parseAndVerifyVCStrictMode(vcBytes []byte) (*verifiable.Cred
 	return vc, nil
 }
 
-//nolint: funlen,gocyclo
-func (o *Operation) parseAndVerifyVP(vpBytes []byte, validateVPPoof, validateCredentialProof,
-	validateCredentialStatus bool) (*verifiable.Presentation, error) {
-	var vp *verifiable.Presentation
-
-	var err error
-
+func (o *Operation) parseAndVerifyVP(vpBytes []byte, validateVPPoof bool) (*verifiable.Presentation, error) {
 	if validateVPPoof {
-		vp, err = verifiable.ParsePresentation(
+		return verifiable.ParsePresentation(
 			vpBytes,
 			verifiable.WithPresPublicKeyFetcher(
 				verifiable.NewVDRKeyResolver(o.vdr).PublicKeyFetcher(),
 			),
 			verifiable.WithPresJSONLDDocumentLoader(o.documentLoader),
 		)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		vp, err = verifiable.ParsePresentation(vpBytes, verifiable.WithPresDisabledProofCheck(),
-			verifiable.WithPresJSONLDDocumentLoader(o.documentLoader))
-		if err != nil {
-			return nil, err
-		}
 	}
 
-	// vp is verified
+	return verifiable.ParsePresentation(vpBytes, verifiable.WithPresDisabledProofCheck(),
+		verifiable.WithPresJSONLDDocumentLoader(o.documentLoader))
+}
+
+// presentedCredentials returns the presentation and its credentials, their proofs aren't verified.
+func (o *Operation) presentedCredentials(vpBytes []byte) (*verifiable.Presentation, []*verifiable.Credential, error) {
+	vp, err := o.parseAndVerifyVP(vpBytes, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vcs := make([]*verifiable.Credential, 0, len(vp.Credentials()))
 
-	// verify if the credentials in vp are valid
 	for _, cred := range vp.Credentials() {
-		vcBytes, err := json.Marshal(cred)
-		if err != nil {
-			return nil, err
+		vcBytes, errMarshal := json.Marshal(cred)
+		if errMarshal != nil {
+			return nil, nil, errMarshal
 		}
 
-		if validateCredentialProof {
-			// verify if the credential in vp is valid
-			err = o.validateCredentialProof(vcBytes, nil, true)
-			if err != nil {
-				return nil, err
-			}
+		vc, errParse := verifiable.ParseCredential(vcBytes, verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+		if errParse != nil {
+			return nil, nil, errParse
 		}
 
-		if validateCredentialStatus {
-			failureMessage := ""
-
-			vc, err := verifiable.ParseCredential(vcBytes, verifiable.WithDisabledProofCheck(),
-				verifiable.WithJSONLDDocumentLoader(o.documentLoader))
-			if err != nil {
-				return nil, err
-			}
-
-			ver, err := o.checkVCStatus(vc.Status, vc.Issuer.ID)
-
-			if err != nil {
-				failureMessage = fmt.Sprintf("failed to fetch the status : %s", err.Error())
-			} else if !ver.Verified {
-				failureMessage = ver.Message
-			}
-
-			if failureMessage != "" {
-				return nil, fmt.Errorf(failureMessage)
-			}
-		}
+		vcs = append(vcs, vc)
 	}
 
-	return vp, nil
+	return vp, vcs, nil
 }
 
 func (o *Operation) parseAndVerifyVC(vcBytes []byte) (*verifiable.Credential, error) {
@@ -782,25 +1056,29 @@ func validateProfileRequest(pr *verifier.ProfileData) error {
 		return errors.New("missing profile id")
 	case pr.Name == "":
 		return errors.New("missing profile name")
-	case len(pr.CredentialChecks) != 0:
-		for _, val := range pr.CredentialChecks {
-			switch val {
-			case proofCheck, statusCheck:
-			default:
-				return fmt.Errorf("invalid credential check option - %s", val)
-			}
+	}
+
+	for _, val := range pr.CredentialChecks {
+		switch val {
+		case proofCheck, statusCheck, disclosureCheck, linkedDomainCheck:
+		default:
+			return fmt.Errorf("invalid credential check option - %s", val)
 		}
-	case len(pr.PresentationChecks) != 0:
-		for _, val := range pr.PresentationChecks {
-			switch val {
-			case proofCheck:
-			default:
-				return fmt.Errorf("invalid presentation check option - %s", val)
-			}
+	}
+
+	for _, val := range pr.PresentationChecks {
+		switch val {
+		case proofCheck, presentationDefinitionCheck, holderBindingCheck, linkedDomainCheck:
+		default:
+			return fmt.Errorf("invalid presentation check option - %s", val)
 		}
 	}
 
-	return nil
+	if err := validateProofPolicy(pr.ProofPolicy); err != nil {
+		return err
+	}
+
+	return validatePresentationDefinitions(pr)
 }
 
 type storeProvider struct {
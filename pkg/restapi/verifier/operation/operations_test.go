@@ -337,6 +337,132 @@ func TestDeleteProfileHandler(t *testing.T) {
 	})
 }
 
+func TestListProfilesHandler(t *testing.T) {
+	op, err := New(&Config{
+		StoreProvider: ariesmemstorage.NewProvider(),
+		VDRI:          &vdrmock.MockVDRegistry{},
+	})
+	require.NoError(t, err)
+
+	for _, id := range []string{"c", "a", "b"} {
+		require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{ID: id, Name: id}))
+	}
+
+	handler := getHandler(t, op, profileEndpoint, http.MethodGet)
+
+	t.Run("list profiles - success", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, profileEndpoint+"?offset=2", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		resp := &ListProfilesResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, 3, resp.Total)
+		require.Len(t, resp.Profiles, 1)
+		require.Equal(t, "c", resp.Profiles[0].ID)
+	})
+
+	t.Run("list profiles - invalid page", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, profileEndpoint+"?limit=1001", nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "limit must be between 1 and 1000")
+	})
+
+	t.Run("list profiles - store error", func(t *testing.T) {
+		op, err := New(&Config{
+			StoreProvider: &ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+				Store:    make(map[string]ariesmockstorage.DBEntry),
+				ErrQuery: errors.New("query error"),
+			}},
+			VDRI: &vdrmock.MockVDRegistry{},
+		})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, profileEndpoint, http.MethodGet), profileEndpoint, nil, nil)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to list verifier profiles")
+	})
+}
+
+func TestUpdateProfileHandler(t *testing.T) {
+	op, err := New(&Config{
+		StoreProvider: ariesmemstorage.NewProvider(),
+		VDRI:          &vdrmock.MockVDRegistry{},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+		ID: testProfileID, Name: "test", CredentialChecks: []string{proofCheck, statusCheck},
+		PresentationChecks: []string{proofCheck},
+	}))
+
+	update := func(t *testing.T, method, profileID string, req interface{}) (int, *verifier.ProfileData, string) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, getProfileEndpoint, method), "/verifier/profile/"+profileID,
+			reqBytes, map[string]string{profileIDPathParam: profileID})
+
+		profile := &verifier.ProfileData{}
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), profile))
+		}
+
+		return rr.Code, profile, rr.Body.String()
+	}
+
+	name := "updated"
+
+	t.Run("patch profile - updates the fields set", func(t *testing.T) {
+		code, updated, body := update(t, http.MethodPatch, testProfileID, &UpdateProfileRequest{
+			CredentialChecks: []string{proofCheck},
+		})
+		require.Equal(t, http.StatusOK, code, body)
+		require.Equal(t, "test", updated.Name)
+		require.Equal(t, []string{proofCheck}, updated.CredentialChecks)
+		require.Equal(t, []string{proofCheck}, updated.PresentationChecks)
+
+		saved, err := op.profileStore.GetProfile(testProfileID)
+		require.NoError(t, err)
+		require.Equal(t, updated, saved)
+	})
+
+	t.Run("put profile - resets the fields left out", func(t *testing.T) {
+		code, updated, body := update(t, http.MethodPut, testProfileID, &UpdateProfileRequest{Name: &name})
+		require.Equal(t, http.StatusOK, code, body)
+		require.Equal(t, name, updated.Name)
+		require.Empty(t, updated.CredentialChecks)
+		require.Empty(t, updated.PresentationChecks)
+	})
+
+	t.Run("put profile - missing name", func(t *testing.T) {
+		code, _, body := update(t, http.MethodPut, testProfileID, &UpdateProfileRequest{})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "missing profile name")
+	})
+
+	t.Run("patch profile - invalid check", func(t *testing.T) {
+		code, _, body := update(t, http.MethodPatch, testProfileID, &UpdateProfileRequest{
+			CredentialChecks: []string{"invalid"},
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid credential check option - invalid")
+	})
+
+	t.Run("patch profile - invalid profile", func(t *testing.T) {
+		code, _, body := update(t, http.MethodPatch, "unknown", &UpdateProfileRequest{})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid verifier profile")
+	})
+
+	t.Run("patch profile - invalid request", func(t *testing.T) {
+		code, _, body := update(t, http.MethodPatch, testProfileID, "invalid")
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, invalidRequestErrMsg)
+	})
+}
+
 func TestVerifyCredential(t *testing.T) {
 	loader := testutil.DocumentLoader(t)
 
@@ -432,6 +558,75 @@ func TestVerifyCredential(t *testing.T) {
 		require.Equal(t, 2, len(verificationResp.Checks))
 	})
 
+	t.Run("credential verification - status list from cache", func(t *testing.T) {
+		pubKey, privKey, errGenerateKey := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, errGenerateKey)
+
+		didDoc := createDIDDoc(didID, pubKey)
+		verificationMethod := didDoc.VerificationMethod[0].ID
+		vc.Issuer.ID = didDoc.ID
+
+		ops, errNew := New(&Config{
+			VDRI:           &vdrmock.MockVDRegistry{ResolveValue: didDoc},
+			StoreProvider:  ariesmemstorage.NewProvider(),
+			DocumentLoader: loader,
+		})
+		require.NoError(t, errNew)
+
+		encodeBits, errNew := utils.NewBitString(2).EncodeBits()
+		require.NoError(t, errNew)
+
+		ops.statusLists.put("http://example.com/status/100",
+			[]byte(fmt.Sprintf(revocationListVC, didDoc.ID, encodeBits)))
+		ops.httpClient = &mockHTTPClient{doErr: errors.New("connection refused")}
+
+		vc.Status = &verifiable.TypedID{
+			ID:   "http://example.com/status/100#1",
+			Type: cslstatus.RevocationList2020Status,
+			CustomFields: map[string]interface{}{
+				cslstatus.RevocationListIndex:      "1",
+				cslstatus.RevocationListCredential: "http://example.com/status/100",
+			},
+		}
+
+		vcBytes, errMarshal := vc.MarshalJSON()
+		require.NoError(t, errMarshal)
+
+		handler := getHandler(t, ops, credentialsVerificationEndpoint, http.MethodPost)
+
+		vReqBytes, errMarshal := json.Marshal(&CredentialsVerificationRequest{
+			Credential: getSignedVC(t, privKey, string(vcBytes), didID, verificationMethod, domain, challenge),
+			Opts: &CredentialsVerificationOptions{
+				Checks:    []string{proofCheck, statusCheck},
+				Challenge: challenge,
+				Domain:    domain,
+			},
+		})
+		require.NoError(t, errMarshal)
+
+		err = ops.profileStore.SaveProfile(vReq)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, handler, endpoint, vReqBytes, urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "connection refused")
+
+		cached := *vReq
+		cached.CachedStatusLists = true
+
+		err = ops.profileStore.SaveProfile(&cached)
+		require.NoError(t, err)
+
+		rr = serveHTTPMux(t, handler, endpoint, vReqBytes, urlVars)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		verificationResp := &CredentialsVerificationSuccessResponse{}
+		err = json.Unmarshal(rr.Body.Bytes(), &verificationResp)
+		require.NoError(t, err)
+		require.Len(t, verificationResp.Warnings, 1)
+		require.Contains(t, verificationResp.Warnings[0], "status list http://example.com/status/100 fetched from cache")
+	})
+
 	t.Run("credential verification - vc issuer not equal vc list status", func(t *testing.T) {
 		pubKey, privKey, errGenerateKey := ed25519.GenerateKey(rand.Reader)
 		require.NoError(t, errGenerateKey)
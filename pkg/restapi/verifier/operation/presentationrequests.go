@@ -0,0 +1,483 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/presexch"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+)
+
+const (
+	presentationRequestStoreName = "presentationrequest"
+
+	requestIDPathParam = "requestID"
+
+	presentationRequestsEndpoint = "/" + "{" + profileIDPathParam + "}" + verifierBasePath + "/presentation-requests"
+	presentationRequestEndpoint  = presentationRequestsEndpoint + "/" + "{" + requestIDPathParam + "}"
+	presentationResponseEndpoint = presentationRequestEndpoint + "/response"
+
+	// presentation request formats
+	openID4VPFormat = "openid4vp"
+	vprFormat       = "vpr"
+
+	openID4VPURIScheme = "openid4vp://"
+	vpTokenType        = "vp_token"
+	directPostMode     = "direct_post"
+	redirectURIScheme  = "redirect_uri"
+	vprServiceType     = "UnmediatedHttpPresentationService2021"
+	didAuthQueryType   = "DIDAuthentication"
+
+	// presentation request statuses
+	requestPending  = "pending"
+	requestVerified = "verified"
+	requestExpired  = "expired"
+
+	// defaultRequestExpiry is how long the wallet has to respond when the request doesn't tell.
+	defaultRequestExpiry = 10 * time.Minute
+)
+
+var errPresentationRequestNotFound = errors.New("unknown presentation request")
+
+// presentationRequest is the state of a presentation request, from its creation to the wallet's response.
+type presentationRequest struct {
+	ID           string              `json:"id"`
+	ProfileID    string              `json:"profileID"`
+	Nonce        string              `json:"nonce"`
+	Domain       string              `json:"domain,omitempty"`
+	DefinitionID string              `json:"definitionID,omitempty"`
+	CallbackURL  string              `json:"callbackURL,omitempty"`
+	ExpiresAt    time.Time           `json:"expiresAt"`
+	Status       string              `json:"status"`
+	Presentation json.RawMessage     `json:"presentation,omitempty"`
+	Report       *VerificationReport `json:"report,omitempty"`
+}
+
+// CreatePresentationRequest swagger:route POST /{id}/verifier/presentation-requests verifier presReqReq
+//
+// Creates a request for a presentation: an OpenID4VP authorization request with the presentation definition, or a
+// Verifiable Presentation Request. The wallet posts its response to the response URI of the request, the relying
+// party polls the status of the request or receives it at its callback URL.
+//
+// Responses:
+//    default: genericError
+//        201: presReqRes
+func (o *Operation) createPresentationRequestHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid verifier profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	data := CreatePresentationRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	definition, err := validatePresentationRequest(profile, &data)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	resp, err := o.createPresentationRequest(profile, &data, definition)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to create presentation request: %s", err.Error()))
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusCreated)
+	commhttp.WriteResponse(rw, resp)
+}
+
+// GetPresentationRequest swagger:route GET /{id}/verifier/presentation-requests/{requestID} verifier presReqStatusReq
+//
+// Returns the status of the presentation request, with the verification report once the wallet responded.
+//
+// Responses:
+//    default: genericError
+//        200: presReqStatusRes
+func (o *Operation) getPresentationRequestHandler(rw http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+
+	request, err := o.getPresentationRequest(vars[profileIDPathParam], vars[requestIDPathParam])
+	if errors.Is(err, errPresentationRequestNotFound) {
+		commhttp.WriteErrorResponse(rw, http.StatusNotFound, err.Error())
+
+		return
+	}
+
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to get presentation request: %s", err.Error()))
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, request.status())
+}
+
+// PresentationResponse swagger:route POST /{id}/verifier/presentation-requests/{requestID}/response verifier vpRespReq
+//
+// Receives the wallet's response to the presentation request: an OpenID4VP direct_post of the vp_token and
+// presentation_submission form parameters, or the JSON verifiablePresentation of a Verifiable Presentation Request.
+// The presentation is verified with the profile's checks and its proof must sign the nonce of the request. The
+// request is answered by the first presentation verified, the wallet may respond again until the request expires.
+//
+// Responses:
+//    default: genericError
+//        200: emptyRes
+func (o *Operation) presentationResponseHandler(rw http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+
+	vpBytes, submission, state, err := readPresentationResponse(req)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	if state != "" && state != vars[requestIDPathParam] {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, "state doesn't match the presentation request")
+
+		return
+	}
+
+	request, err := o.answerPresentationRequest(vars[profileIDPathParam], vars[requestIDPathParam], vpBytes,
+		submission)
+	if errors.Is(err, errPresentationRequestNotFound) {
+		commhttp.WriteErrorResponse(rw, http.StatusNotFound, err.Error())
+
+		return
+	}
+
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	if request.CallbackURL != "" {
+		o.postCallback(request)
+	}
+
+	commhttp.WriteResponse(rw, struct{}{})
+}
+
+func (o *Operation) createPresentationRequest(profile *verifier.ProfileData, data *CreatePresentationRequest,
+	definition *presexch.PresentationDefinition) (*PresentationRequestResponse, error) {
+	expiry := defaultRequestExpiry
+	if data.ExpiresIn != 0 {
+		expiry = time.Duration(data.ExpiresIn) * time.Second
+	}
+
+	request := &presentationRequest{
+		ID:          uuid.New().String(),
+		ProfileID:   profile.ID,
+		CallbackURL: data.CallbackURL,
+		ExpiresAt:   time.Now().Add(expiry),
+		Status:      requestPending,
+	}
+
+	if definition != nil {
+		request.DefinitionID = definition.ID
+	}
+
+	responseURI := o.hostURL + "/" + profile.ID + verifierBasePath + "/presentation-requests/" + request.ID +
+		"/response"
+
+	// the wallet signs the verifier's client ID as domain, or its host with a VPR
+	request.Domain = responseURI
+
+	if data.Format == vprFormat {
+		request.Domain = ""
+
+		if u, err := url.Parse(o.hostURL); err == nil {
+			request.Domain = u.Host
+		}
+	}
+
+	// the nonce is a challenge of the profile, used once when the profile requires challenges
+	c, err := o.challenges.Issue(profile.ID, request.Domain, expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Nonce = c.Challenge
+
+	if err = o.savePresentationRequest(request); err != nil {
+		return nil, err
+	}
+
+	resp := &PresentationRequestResponse{ID: request.ID, ExpiresAt: request.ExpiresAt}
+
+	if data.Format == vprFormat {
+		query := data.Query
+		if len(query) == 0 {
+			query = []map[string]interface{}{{"type": didAuthQueryType}}
+		}
+
+		resp.VPR = &VerifiablePresentationRequest{
+			Query:     query,
+			Challenge: request.Nonce,
+			Domain:    request.Domain,
+			Interact: &VPRInteract{Service: []*VPRService{{
+				Type:            vprServiceType,
+				ServiceEndpoint: responseURI,
+			}}},
+		}
+
+		return resp, nil
+	}
+
+	resp.AuthorizationRequest = &AuthorizationRequest{
+		ResponseType:           vpTokenType,
+		ResponseMode:           directPostMode,
+		ClientID:               responseURI,
+		ClientIDScheme:         redirectURIScheme,
+		ResponseURI:            responseURI,
+		Nonce:                  request.Nonce,
+		State:                  request.ID,
+		PresentationDefinition: definition,
+	}
+
+	resp.RequestURI, err = authorizationRequestURI(resp.AuthorizationRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// answerPresentationRequest verifies the presentation the wallet responded with, the request is answered by the
+// first presentation verified. The wallet may respond again until the request expires when the presentation isn't
+// verified.
+func (o *Operation) answerPresentationRequest(profileID, requestID string, vpBytes []byte,
+	submission map[string]interface{}) (*presentationRequest, error) {
+	request, err := o.getPresentationRequest(profileID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Status != requestPending {
+		return nil, fmt.Errorf("presentation request is %s", request.Status)
+	}
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid verifier profile - id=%s: err=%w", profileID, err)
+	}
+
+	opts := &VerifyPresentationOptions{
+		Challenge:                request.Nonce,
+		Domain:                   request.Domain,
+		PresentationDefinitionID: request.DefinitionID,
+		PresentationSubmission:   submission,
+	}
+
+	// the proof binds the presentation to the nonce of the request
+	verification := o.checkPresentation(profile, vpBytes, opts, true)
+	if len(verification.result) != 0 {
+		return nil, fmt.Errorf("presentation not verified : %s check : %s", verification.result[0].Check,
+			verification.result[0].Error)
+	}
+
+	// the nonce is claimed in the shared storage, so one presentation alone answers the request across replicas
+	if err = o.challenges.Consume(profile.ID, request.Domain, request.Nonce); err != nil {
+		return nil, fmt.Errorf("presentation request can't be answered : %w", err)
+	}
+
+	request.Presentation = vpBytes
+	request.Report = o.presentationReport(vpBytes, verification)
+	// the matched credentials are in the presentation
+	request.Report.MatchedCredentials = nil
+	request.Status = requestVerified
+
+	if err = o.savePresentationRequest(request); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// postCallback posts the status of the answered request to its callback URL in the background.
+func (o *Operation) postCallback(request *presentationRequest) {
+	statusBytes, err := json.Marshal(request.status())
+	if err != nil {
+		logger.Errorf("failed to marshal status of presentation request %s: %s", request.ID, err)
+
+		return
+	}
+
+	o.callbacks.Callback(request.CallbackURL, statusBytes)
+}
+
+// readPresentationResponse reads the presentation, its submission and the state from the wallet's response.
+func readPresentationResponse(req *http.Request) ([]byte, map[string]interface{}, string, error) {
+	if !strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		data := VerifyPresentationRequest{}
+
+		if err := json.NewDecoder(req.Body).Decode(&data); err != nil {
+			return nil, nil, "", err
+		}
+
+		if len(data.Presentation) == 0 {
+			return nil, nil, "", errors.New("missing verifiablePresentation")
+		}
+
+		return data.Presentation, nil, "", nil
+	}
+
+	if err := req.ParseForm(); err != nil {
+		return nil, nil, "", err
+	}
+
+	vpToken := req.PostForm.Get(vpTokenType)
+	if vpToken == "" {
+		return nil, nil, "", errors.New("missing vp_token")
+	}
+
+	var submission map[string]interface{}
+
+	if s := req.PostForm.Get("presentation_submission"); s != "" {
+		if err := json.Unmarshal([]byte(s), &submission); err != nil {
+			return nil, nil, "", fmt.Errorf("invalid presentation_submission: %w", err)
+		}
+	}
+
+	return []byte(vpToken), submission, req.PostForm.Get("state"), nil
+}
+
+// authorizationRequestURI passes the authorization request by value in an openid4vp URI.
+func authorizationRequestURI(request *AuthorizationRequest) (string, error) {
+	definitionBytes, err := json.Marshal(request.PresentationDefinition)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", request.ResponseType)
+	params.Set("response_mode", request.ResponseMode)
+	params.Set("client_id", request.ClientID)
+	params.Set("client_id_scheme", request.ClientIDScheme)
+	params.Set("response_uri", request.ResponseURI)
+	params.Set("nonce", request.Nonce)
+	params.Set("state", request.State)
+	params.Set("presentation_definition", string(definitionBytes))
+
+	return openID4VPURIScheme + "?" + params.Encode(), nil
+}
+
+// validatePresentationRequest validates the request and returns the presentation definition it is for, if any.
+func validatePresentationRequest(profile *verifier.ProfileData,
+	data *CreatePresentationRequest) (*presexch.PresentationDefinition, error) {
+	switch data.Format {
+	case "":
+		data.Format = openID4VPFormat
+	case openID4VPFormat, vprFormat:
+	default:
+		return nil, fmt.Errorf("invalid presentation request format - %s", data.Format)
+	}
+
+	if data.ExpiresIn < 0 || time.Duration(data.ExpiresIn)*time.Second > maxChallengeExpiry {
+		return nil, fmt.Errorf("invalid presentation request expiry, it is at most %d seconds",
+			int64(maxChallengeExpiry.Seconds()))
+	}
+
+	if data.CallbackURL != "" {
+		u, err := url.ParseRequestURI(data.CallbackURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil, fmt.Errorf("invalid callback url - %s", data.CallbackURL)
+		}
+	}
+
+	switch {
+	case data.PresentationDefinitionID != "":
+		definition := findPresentationDefinition(profile, data.PresentationDefinitionID)
+		if definition == nil {
+			return nil, fmt.Errorf("presentation definition %s not found", data.PresentationDefinitionID)
+		}
+
+		return definition, nil
+	case len(profile.PresentationDefinitions) == 1:
+		return profile.PresentationDefinitions[0], nil
+	case data.Format == vprFormat:
+		return nil, nil
+	}
+
+	return nil, errors.New("presentation definition not specified")
+}
+
+// status returns the status of the request to the relying party.
+func (r *presentationRequest) status() *PresentationRequestStatus {
+	return &PresentationRequestStatus{
+		ID:           r.ID,
+		Status:       r.Status,
+		Presentation: r.Presentation,
+		Report:       r.Report,
+		ExpiresAt:    r.ExpiresAt,
+	}
+}
+
+func (o *Operation) savePresentationRequest(request *presentationRequest) error {
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	return o.requestStore.Put(request.ID, requestBytes)
+}
+
+// getPresentationRequest gets the request of the profile, an expired request is pending no more.
+func (o *Operation) getPresentationRequest(profileID, requestID string) (*presentationRequest, error) {
+	requestBytes, err := o.requestStore.Get(requestID)
+	if err != nil {
+		if errors.Is(err, ariesstorage.ErrDataNotFound) {
+			return nil, errPresentationRequestNotFound
+		}
+
+		return nil, err
+	}
+
+	request := &presentationRequest{}
+
+	if err = json.Unmarshal(requestBytes, request); err != nil {
+		return nil, err
+	}
+
+	if request.ProfileID != profileID {
+		return nil, errPresentationRequestNotFound
+	}
+
+	if request.Status == requestPending && time.Now().After(request.ExpiresAt) {
+		request.Status = requestExpired
+	}
+
+	return request, nil
+}
@@ -0,0 +1,436 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/presexch"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+const degreeSubmission = `{
+	"id": "a30e3b91-fb77-4d22-95fa-871689c322e2",
+	"definition_id": "degree",
+	"descriptor_map": [{"id": "degree_input", "format": "ldp_vc", "path": "$.verifiableCredential[0]"}]
+}`
+
+func TestPresentationRequests(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	didID := "did:test:EiBNfNRaz1Ll8BjVsbNv-fWc7K_KIoPuW8GFCh1_Tz_Iuw=="
+	didDoc := createDIDDoc(didID, pubKey)
+	verificationMethod := didDoc.VerificationMethod[0].ID
+
+	op, err := New(&Config{
+		VDRI:           &vdrmock.MockVDRegistry{ResolveValue: didDoc},
+		StoreProvider:  ariesmemstorage.NewProvider(),
+		DocumentLoader: testutil.DocumentLoader(t),
+		HostURL:        "https://verifier.example.com",
+	})
+	require.NoError(t, err)
+
+	definition := &presexch.PresentationDefinition{}
+	require.NoError(t, json.Unmarshal([]byte(degreeDefinition), definition))
+
+	require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+		ID:                      "test",
+		Name:                    "test verifier",
+		PresentationChecks:      []string{proofCheck, presentationDefinitionCheck},
+		PresentationDefinitions: []*presexch.PresentationDefinition{definition},
+		ChallengeRequired:       true,
+	}))
+
+	require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+		ID:                 "auth",
+		Name:               "DID auth verifier",
+		PresentationChecks: []string{proofCheck},
+		ChallengeRequired:  true,
+	}))
+
+	degreeVC := fmt.Sprintf(subjectVC, `{
+		"id": "did:example:ebfeb1f712ebc6f1c276e12ec21",
+		"degree": {"type": "BachelorDegree", "name": "Bachelor of Science"}
+	}`)
+
+	create := func(t *testing.T, profileID string, data *CreatePresentationRequest) *httptest.ResponseRecorder {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(data)
+		require.NoError(t, err)
+
+		return serveHTTPMux(t, getHandler(t, op, presentationRequestsEndpoint, http.MethodPost),
+			"/"+profileID+"/verifier/presentation-requests", reqBytes,
+			map[string]string{profileIDPathParam: profileID})
+	}
+
+	getStatus := func(t *testing.T, profileID, requestID string) *PresentationRequestStatus {
+		t.Helper()
+
+		rr := serveHTTPMux(t, getHandler(t, op, presentationRequestEndpoint, http.MethodGet),
+			"/"+profileID+"/verifier/presentation-requests/"+requestID, nil,
+			map[string]string{profileIDPathParam: profileID, requestIDPathParam: requestID})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		status := &PresentationRequestStatus{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), status))
+
+		return status
+	}
+
+	respond := func(t *testing.T, profileID, requestID, contentType string, body []byte) *httptest.ResponseRecorder {
+		t.Helper()
+
+		r, err := http.NewRequest(http.MethodPost, "/"+profileID+"/verifier/presentation-requests/"+requestID+
+			"/response", strings.NewReader(string(body)))
+		require.NoError(t, err)
+
+		r.Header.Set("Content-Type", contentType)
+
+		rr := httptest.NewRecorder()
+
+		getHandler(t, op, presentationResponseEndpoint, http.MethodPost).Handle().ServeHTTP(rr,
+			mux.SetURLVars(r, map[string]string{profileIDPathParam: profileID, requestIDPathParam: requestID}))
+
+		return rr
+	}
+
+	postForm := func(t *testing.T, requestID string, form url.Values) *httptest.ResponseRecorder {
+		t.Helper()
+
+		return respond(t, "test", requestID, "application/x-www-form-urlencoded", []byte(form.Encode()))
+	}
+
+	t.Run("openid4vp", func(t *testing.T) {
+		rr := create(t, "test", &CreatePresentationRequest{})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		resp := &PresentationRequestResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.NotEmpty(t, resp.ID)
+		require.Nil(t, resp.VPR)
+
+		authReq := resp.AuthorizationRequest
+		require.NotNil(t, authReq)
+		require.Equal(t, "vp_token", authReq.ResponseType)
+		require.Equal(t, "direct_post", authReq.ResponseMode)
+		require.Equal(t, "https://verifier.example.com/test/verifier/presentation-requests/"+resp.ID+"/response",
+			authReq.ResponseURI)
+		require.Equal(t, authReq.ResponseURI, authReq.ClientID)
+		require.Equal(t, resp.ID, authReq.State)
+		require.NotEmpty(t, authReq.Nonce)
+		require.Equal(t, "degree", authReq.PresentationDefinition.ID)
+
+		requestURI, err := url.Parse(resp.RequestURI)
+		require.NoError(t, err)
+		require.Equal(t, "openid4vp", requestURI.Scheme)
+		require.Equal(t, authReq.Nonce, requestURI.Query().Get("nonce"))
+
+		require.Equal(t, requestPending, getStatus(t, "test", resp.ID).Status)
+
+		vp := getSignedVP(t, privKey, degreeVC, didID, verificationMethod, didID, verificationMethod,
+			authReq.ResponseURI, authReq.Nonce)
+
+		form := url.Values{}
+		form.Set("vp_token", string(vp))
+		form.Set("presentation_submission", degreeSubmission)
+		form.Set("state", resp.ID)
+
+		rr = postForm(t, resp.ID, form)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		status := getStatus(t, "test", resp.ID)
+		require.Equal(t, requestVerified, status.Status)
+		require.NotEmpty(t, status.Presentation)
+		require.True(t, status.Report.Verified)
+		require.Len(t, status.Report.Checks, 2)
+		require.Len(t, status.Report.Credentials, 1)
+
+		// a request is answered once
+		rr = postForm(t, resp.ID, form)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "presentation request is verified")
+	})
+
+	t.Run("openid4vp - presentation not verified", func(t *testing.T) {
+		rr := create(t, "test", &CreatePresentationRequest{PresentationDefinitionID: "degree"})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		resp := &PresentationRequestResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+
+		// the presentation isn't bound to the nonce
+		vp := getSignedVP(t, privKey, degreeVC, didID, verificationMethod, didID, verificationMethod,
+			resp.AuthorizationRequest.ResponseURI, "nonce")
+
+		form := url.Values{}
+		form.Set("vp_token", string(vp))
+		form.Set("presentation_submission", degreeSubmission)
+
+		rr = postForm(t, resp.ID, form)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "presentation not verified : proof check")
+
+		status := getStatus(t, "test", resp.ID)
+		require.Equal(t, requestPending, status.Status)
+		require.Nil(t, status.Report)
+
+		// the wallet responds again until the request expires
+		vp = getSignedVP(t, privKey, degreeVC, didID, verificationMethod, didID, verificationMethod,
+			resp.AuthorizationRequest.ResponseURI, resp.AuthorizationRequest.Nonce)
+		form.Set("vp_token", string(vp))
+
+		rr = postForm(t, resp.ID, form)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, requestVerified, getStatus(t, "test", resp.ID).Status)
+	})
+
+	t.Run("openid4vp - nonce claimed by another replica", func(t *testing.T) {
+		rr := create(t, "test", &CreatePresentationRequest{PresentationDefinitionID: "degree"})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		resp := &PresentationRequestResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+
+		authReq := resp.AuthorizationRequest
+		require.NoError(t, op.challenges.Consume("test", authReq.ResponseURI, authReq.Nonce))
+
+		vp := getSignedVP(t, privKey, degreeVC, didID, verificationMethod, didID, verificationMethod,
+			authReq.ResponseURI, authReq.Nonce)
+
+		form := url.Values{}
+		form.Set("vp_token", string(vp))
+		form.Set("presentation_submission", degreeSubmission)
+
+		rr = postForm(t, resp.ID, form)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "presentation request can't be answered : challenge already used")
+		require.Equal(t, requestPending, getStatus(t, "test", resp.ID).Status)
+	})
+
+	t.Run("vpr", func(t *testing.T) {
+		rr := create(t, "auth", &CreatePresentationRequest{Format: "vpr"})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		resp := &PresentationRequestResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Nil(t, resp.AuthorizationRequest)
+		require.Empty(t, resp.RequestURI)
+
+		vpr := resp.VPR
+		require.NotNil(t, vpr)
+		require.Equal(t, "verifier.example.com", vpr.Domain)
+		require.NotEmpty(t, vpr.Challenge)
+		require.Equal(t, []map[string]interface{}{{"type": "DIDAuthentication"}}, vpr.Query)
+		require.Len(t, vpr.Interact.Service, 1)
+		require.Equal(t, "UnmediatedHttpPresentationService2021", vpr.Interact.Service[0].Type)
+		require.Equal(t, "https://verifier.example.com/auth/verifier/presentation-requests/"+resp.ID+"/response",
+			vpr.Interact.Service[0].ServiceEndpoint)
+
+		vp := getSignedVP(t, privKey, degreeVC, didID, verificationMethod, didID, verificationMethod,
+			vpr.Domain, vpr.Challenge)
+
+		reqBytes, err := json.Marshal(&VerifyPresentationRequest{Presentation: vp})
+		require.NoError(t, err)
+
+		rr = respond(t, "auth", resp.ID, "application/json", reqBytes)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, requestVerified, getStatus(t, "auth", resp.ID).Status)
+	})
+
+	t.Run("callback", func(t *testing.T) {
+		received := make(chan *PresentationRequestStatus, 1)
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			status := &PresentationRequestStatus{}
+			require.NoError(t, json.Unmarshal(body, status))
+
+			received <- status
+		}))
+		defer srv.Close()
+
+		rr := create(t, "auth", &CreatePresentationRequest{Format: "vpr", CallbackURL: srv.URL, ExpiresIn: 60})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		resp := &PresentationRequestResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.WithinDuration(t, time.Now().Add(time.Minute), resp.ExpiresAt, 5*time.Second)
+
+		vp := getSignedVP(t, privKey, degreeVC, didID, verificationMethod, didID, verificationMethod,
+			resp.VPR.Domain, resp.VPR.Challenge)
+
+		reqBytes, err := json.Marshal(&VerifyPresentationRequest{Presentation: vp})
+		require.NoError(t, err)
+
+		rr = respond(t, "auth", resp.ID, "application/json", reqBytes)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		op.callbacks.Wait()
+
+		status := <-received
+		require.Equal(t, resp.ID, status.ID)
+		require.Equal(t, requestVerified, status.Status)
+		require.True(t, status.Report.Verified)
+	})
+
+	t.Run("expired request", func(t *testing.T) {
+		rr := create(t, "auth", &CreatePresentationRequest{Format: "vpr"})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		resp := &PresentationRequestResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+
+		request, err := op.getPresentationRequest("auth", resp.ID)
+		require.NoError(t, err)
+
+		request.ExpiresAt = time.Now().Add(-time.Second)
+		require.NoError(t, op.savePresentationRequest(request))
+
+		require.Equal(t, requestExpired, getStatus(t, "auth", resp.ID).Status)
+
+		reqBytes, err := json.Marshal(&VerifyPresentationRequest{Presentation: []byte(`{}`)})
+		require.NoError(t, err)
+
+		rr = respond(t, "auth", resp.ID, "application/json", reqBytes)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "presentation request is expired")
+	})
+
+	t.Run("unknown request", func(t *testing.T) {
+		rr := serveHTTPMux(t, getHandler(t, op, presentationRequestEndpoint, http.MethodGet),
+			"/test/verifier/presentation-requests/unknown", nil,
+			map[string]string{profileIDPathParam: "test", requestIDPathParam: "unknown"})
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Contains(t, rr.Body.String(), "unknown presentation request")
+
+		rr = create(t, "auth", &CreatePresentationRequest{Format: "vpr"})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		resp := &PresentationRequestResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+
+		// the request is of another profile
+		form := url.Values{}
+		form.Set("vp_token", "{}")
+
+		rr = postForm(t, resp.ID, form)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Contains(t, rr.Body.String(), "unknown presentation request")
+	})
+
+	t.Run("invalid response", func(t *testing.T) {
+		rr := create(t, "test", &CreatePresentationRequest{})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		resp := &PresentationRequestResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+
+		rr = postForm(t, resp.ID, url.Values{})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "missing vp_token")
+
+		form := url.Values{}
+		form.Set("vp_token", "{}")
+		form.Set("presentation_submission", "{")
+
+		rr = postForm(t, resp.ID, form)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid presentation_submission")
+
+		form.Set("presentation_submission", degreeSubmission)
+		form.Set("state", "other")
+
+		rr = postForm(t, resp.ID, form)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "state doesn't match the presentation request")
+
+		rr = respond(t, "test", resp.ID, "application/json", []byte(`{}`))
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "missing verifiablePresentation")
+
+		require.Equal(t, requestPending, getStatus(t, "test", resp.ID).Status)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			profileID string
+			data      *CreatePresentationRequest
+			err       string
+		}{
+			{
+				name:      "unknown profile",
+				profileID: "unknown",
+				data:      &CreatePresentationRequest{},
+				err:       "invalid verifier profile - id=unknown",
+			},
+			{
+				name:      "invalid format",
+				profileID: "test",
+				data:      &CreatePresentationRequest{Format: "other"},
+				err:       "invalid presentation request format - other",
+			},
+			{
+				name:      "invalid expiry",
+				profileID: "test",
+				data:      &CreatePresentationRequest{ExpiresIn: int64(maxChallengeExpiry.Seconds()) + 1},
+				err:       "invalid presentation request expiry",
+			},
+			{
+				name:      "invalid callback url",
+				profileID: "test",
+				data:      &CreatePresentationRequest{CallbackURL: "ftp://example.com"},
+				err:       "invalid callback url - ftp://example.com",
+			},
+			{
+				name:      "unknown presentation definition",
+				profileID: "test",
+				data:      &CreatePresentationRequest{PresentationDefinitionID: "employment"},
+				err:       "presentation definition employment not found",
+			},
+			{
+				name:      "presentation definition not specified",
+				profileID: "auth",
+				data:      &CreatePresentationRequest{},
+				err:       "presentation definition not specified",
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				rr := create(t, tc.profileID, tc.data)
+				require.Equal(t, http.StatusBadRequest, rr.Code)
+				require.Contains(t, rr.Body.String(), tc.err)
+			})
+		}
+
+		rr := serveHTTPMux(t, getHandler(t, op, presentationRequestsEndpoint, http.MethodPost),
+			"/test/verifier/presentation-requests", []byte("{"), map[string]string{profileIDPathParam: "test"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), invalidRequestErrMsg)
+	})
+}
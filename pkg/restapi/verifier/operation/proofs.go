@@ -0,0 +1,199 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+)
+
+// proof policy rules
+const (
+	allProofsRule       = "all"
+	anyProofRule        = "any"
+	requiredSignersRule = "requiredSigners"
+)
+
+// proofOptions are the challenge and domain the proofs must sign.
+type proofOptions struct {
+	challenge string
+	domain    string
+}
+
+// checkProofs checks each proof of the document on its own, verify checks the signature of the document bearing
+// the proof alone. The challenge and domain of the proofs are checked unless opts is nil.
+func (o *Operation) checkProofs(proofs []verifiable.Proof, verify func(verifiable.Proof) error, opts *proofOptions,
+	kind string) []*ProofResult {
+	results := make([]*ProofResult, len(proofs))
+
+	for i, proof := range proofs {
+		result := &ProofResult{}
+		result.Type, _ = proof["type"].(string)                           // nolint: errcheck
+		result.ProofPurpose, _ = proof[proofPurpose].(string)             // nolint: errcheck
+		result.VerificationMethod, _ = proof[verificationMethod].(string) // nolint: errcheck
+
+		if err := o.checkProof(proof, verify, opts, kind, result); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Verified = true
+		}
+
+		results[i] = result
+	}
+
+	return results
+}
+
+func (o *Operation) checkProof(proof verifiable.Proof, verify func(verifiable.Proof) error, opts *proofOptions,
+	kind string, result *ProofResult) error {
+	if err := verify(proof); err != nil {
+		return fmt.Errorf("verifiable %s proof validation error : %w", kind, err)
+	}
+
+	if opts != nil {
+		// validate challenge, a derived proof is bound to it by its nonce
+		if proof["type"] == bbsBlsSignatureProof2020 {
+			if opts.challenge != "" && !nonceBound(proof, opts.challenge) {
+				return fmt.Errorf("invalid nonce in the proof : expected challenge=%s", opts.challenge)
+			}
+		} else if err := validateProofData(proof, challenge, opts.challenge); err != nil {
+			return err
+		}
+
+		// validate domain
+		if err := validateProofData(proof, domain, opts.domain); err != nil {
+			return err
+		}
+	}
+
+	// get the verification method
+	verificationMethod, err := getVerificationMethodFromProof(proof)
+	if err != nil {
+		return err
+	}
+
+	// get the did doc from verification method
+	didDoc, err := getDIDDocFromProof(verificationMethod, o.vdr)
+	if err != nil {
+		return err
+	}
+
+	result.Controller = didDoc.ID
+
+	// validate proof purpose
+	if err = validateProofPurpose(proof, verificationMethod, didDoc); err != nil {
+		return fmt.Errorf("verifiable %s proof purpose validation error : %w", kind, err)
+	}
+
+	return nil
+}
+
+// checkProofPolicy checks the proof results comply with the policy, all proofs must be verified by default. One of
+// the verified proofs must be the signer's, unless it is empty, role tells who the signer is.
+func checkProofPolicy(policy *verifier.ProofPolicy, results []*ProofResult, signer, role string) error {
+	rule := allProofsRule
+
+	var requiredSigners []string
+
+	if policy != nil && policy.Rule != "" {
+		rule = policy.Rule
+		requiredSigners = policy.RequiredSigners
+	}
+
+	verified := make(map[string]bool)
+
+	var firstErr error
+
+	for _, result := range results {
+		if result.Verified {
+			verified[result.Controller] = true
+
+			continue
+		}
+
+		if rule == allProofsRule {
+			return errors.New(result.Error)
+		}
+
+		if firstErr == nil {
+			firstErr = errors.New(result.Error)
+		}
+	}
+
+	if len(verified) == 0 {
+		return firstErr
+	}
+
+	for _, did := range requiredSigners {
+		if !verified[did] {
+			return fmt.Errorf("no verified proof of required signer %s", did)
+		}
+	}
+
+	if signer != "" && !verified[signer] {
+		return fmt.Errorf("controller of verification method doesn't match the %s", role)
+	}
+
+	return nil
+}
+
+// withProof returns a function verifying the signature of the document bearing the given proof alone, parse verifies
+// the signatures of the document.
+func withProof(docBytes []byte, parse func([]byte) error) func(verifiable.Proof) error {
+	return func(proof verifiable.Proof) error {
+		doc := make(map[string]interface{})
+
+		if err := json.Unmarshal(docBytes, &doc); err != nil {
+			return err
+		}
+
+		doc["proof"] = proof
+
+		singleProofBytes, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+
+		return parse(singleProofBytes)
+	}
+}
+
+// presentationProofPolicy returns the policy of the presentation proofs. The required signers sign the credentials,
+// the presentation is signed by its holder: all its proofs must be verified with the requiredSigners rule.
+func presentationProofPolicy(policy *verifier.ProofPolicy) *verifier.ProofPolicy {
+	if policy != nil && policy.Rule == requiredSignersRule {
+		return nil
+	}
+
+	return policy
+}
+
+func validateProofPolicy(policy *verifier.ProofPolicy) error {
+	if policy == nil {
+		return nil
+	}
+
+	switch policy.Rule {
+	case "", allProofsRule, anyProofRule:
+		if len(policy.RequiredSigners) != 0 {
+			return errors.New("required signers are only set with the requiredSigners proof policy rule")
+		}
+	case requiredSignersRule:
+		if len(policy.RequiredSigners) == 0 {
+			return errors.New("requiredSigners proof policy rule requires signers")
+		}
+	default:
+		return fmt.Errorf("invalid proof policy rule - %s", policy.Rule)
+	}
+
+	return nil
+}
@@ -0,0 +1,262 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	vccrypto "github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+func TestMultipleProofs(t *testing.T) {
+	issuerPubKey, issuerPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	notaryPubKey, notaryPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	holderPubKey, holderPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, otherPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	issuerDID := "did:test:issuer"
+	notaryDID := "did:test:notary"
+	holderDID := "did:test:holder"
+
+	docs := map[string]*did.Doc{
+		issuerDID: createDIDDoc(issuerDID, issuerPubKey),
+		notaryDID: createDIDDoc(notaryDID, notaryPubKey),
+		holderDID: createDIDDoc(holderDID, holderPubKey),
+	}
+
+	loader := testutil.DocumentLoader(t)
+
+	op, err := New(&Config{
+		VDRI: &vdrmock.MockVDRegistry{
+			ResolveFunc: func(didID string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+				doc, ok := docs[didID]
+				if !ok {
+					return nil, errors.New("DID not found")
+				}
+
+				return &did.DocResolution{DIDDocument: doc}, nil
+			},
+		},
+		StoreProvider:  ariesmemstorage.NewProvider(),
+		DocumentLoader: loader,
+	})
+	require.NoError(t, err)
+
+	// coSign adds the proof of the notary to the credential signed by the issuer
+	coSign := func(t *testing.T, issuerKey, notaryKey []byte) []byte {
+		t.Helper()
+
+		signedVC := getSignedVC(t, issuerKey, prCardVC, issuerDID, docs[issuerDID].VerificationMethod[0].ID, "", "")
+
+		vc, err := verifiable.ParseCredential(signedVC, verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+
+		created, err := time.Parse(time.RFC3339, "2018-03-15T00:00:00Z")
+		require.NoError(t, err)
+
+		err = vc.AddLinkedDataProof(&verifiable.LinkedDataProofContext{
+			SignatureType: "Ed25519Signature2018",
+			Suite: ed25519signature2018.New(
+				suite.WithSigner(getEd25519TestSigner(notaryKey)),
+				suite.WithCompactProof()),
+			SignatureRepresentation: verifiable.SignatureJWS,
+			Created:                 &created,
+			VerificationMethod:      docs[notaryDID].VerificationMethod[0].ID,
+			Purpose:                 vccrypto.AssertionMethod,
+		}, jsonld.WithDocumentLoader(loader))
+		require.NoError(t, err)
+
+		vcBytes, err := vc.MarshalJSON()
+		require.NoError(t, err)
+
+		return vcBytes
+	}
+
+	verify := func(t *testing.T, policy *verifier.ProofPolicy, vcBytes []byte) (int, []byte) {
+		t.Helper()
+
+		require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+			ID:               "test",
+			Name:             "test verifier",
+			CredentialChecks: []string{proofCheck},
+			ProofPolicy:      policy,
+		}))
+
+		reqBytes, err := json.Marshal(&CredentialsVerificationRequest{Credential: vcBytes})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, credentialsVerificationEndpoint, http.MethodPost),
+			"/test/verifier/credentials/verify", reqBytes, map[string]string{profileIDPathParam: "test"})
+
+		return rr.Code, rr.Body.Bytes()
+	}
+
+	t.Run("all proofs verified", func(t *testing.T) {
+		code, body := verify(t, nil, coSign(t, issuerPrivKey, notaryPrivKey))
+		require.Equal(t, http.StatusOK, code, string(body))
+
+		resp := &CredentialsVerificationSuccessResponse{}
+		require.NoError(t, json.Unmarshal(body, resp))
+		require.Len(t, resp.Proofs, 2)
+
+		for i, signer := range []string{issuerDID, notaryDID} {
+			require.True(t, resp.Proofs[i].Verified)
+			require.Equal(t, signer, resp.Proofs[i].Controller)
+			require.Equal(t, docs[signer].VerificationMethod[0].ID, resp.Proofs[i].VerificationMethod)
+			require.Equal(t, "Ed25519Signature2018", resp.Proofs[i].Type)
+			require.Equal(t, "assertionMethod", resp.Proofs[i].ProofPurpose)
+		}
+
+		code, body = verify(t, &verifier.ProofPolicy{
+			Rule:            requiredSignersRule,
+			RequiredSigners: []string{notaryDID},
+		}, coSign(t, issuerPrivKey, notaryPrivKey))
+		require.Equal(t, http.StatusOK, code, string(body))
+	})
+
+	t.Run("invalid co-signature", func(t *testing.T) {
+		vcBytes := coSign(t, issuerPrivKey, otherPrivKey)
+
+		code, body := verify(t, &verifier.ProofPolicy{Rule: allProofsRule}, vcBytes)
+		require.Equal(t, http.StatusBadRequest, code)
+
+		resp := &CredentialsVerificationFailResponse{}
+		require.NoError(t, json.Unmarshal(body, resp))
+		require.Len(t, resp.Checks, 1)
+		require.Contains(t, resp.Checks[0].Error, "verifiable credential proof validation error")
+		require.Len(t, resp.Checks[0].Proofs, 2)
+		require.True(t, resp.Checks[0].Proofs[0].Verified)
+		require.False(t, resp.Checks[0].Proofs[1].Verified)
+		require.NotEmpty(t, resp.Checks[0].Proofs[1].Error)
+
+		code, body = verify(t, &verifier.ProofPolicy{Rule: anyProofRule}, vcBytes)
+		require.Equal(t, http.StatusOK, code, string(body))
+
+		code, body = verify(t, &verifier.ProofPolicy{
+			Rule:            requiredSignersRule,
+			RequiredSigners: []string{notaryDID},
+		}, vcBytes)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "no verified proof of required signer "+notaryDID)
+	})
+
+	t.Run("issuer proof missing", func(t *testing.T) {
+		code, body := verify(t, &verifier.ProofPolicy{Rule: anyProofRule}, coSign(t, otherPrivKey, notaryPrivKey))
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "controller of verification method doesn't match the issuer")
+	})
+
+	t.Run("presentation of a co-signed credential", func(t *testing.T) {
+		present := func(t *testing.T, vcBytes []byte) []byte {
+			t.Helper()
+
+			vc, err := verifiable.ParseCredential(vcBytes, verifiable.WithDisabledProofCheck(),
+				verifiable.WithJSONLDDocumentLoader(loader))
+			require.NoError(t, err)
+
+			vp, err := verifiable.NewPresentation(verifiable.WithCredentials(vc))
+			require.NoError(t, err)
+
+			vp.Holder = holderDID
+
+			created, err := time.Parse(time.RFC3339, "2018-03-15T00:00:00Z")
+			require.NoError(t, err)
+
+			err = vp.AddLinkedDataProof(&verifiable.LinkedDataProofContext{
+				SignatureType: "Ed25519Signature2018",
+				Suite: ed25519signature2018.New(
+					suite.WithSigner(getEd25519TestSigner(holderPrivKey)),
+					suite.WithCompactProof()),
+				SignatureRepresentation: verifiable.SignatureJWS,
+				Created:                 &created,
+				VerificationMethod:      docs[holderDID].VerificationMethod[0].ID,
+				Purpose:                 vccrypto.Authentication,
+			}, jsonld.WithDocumentLoader(loader))
+			require.NoError(t, err)
+
+			vpBytes, err := vp.MarshalJSON()
+			require.NoError(t, err)
+
+			return vpBytes
+		}
+
+		verifyVP := func(t *testing.T, vpBytes []byte) (int, []byte) {
+			t.Helper()
+
+			require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+				ID:                 "test",
+				Name:               "test verifier",
+				PresentationChecks: []string{proofCheck},
+				ProofPolicy: &verifier.ProofPolicy{
+					Rule:            requiredSignersRule,
+					RequiredSigners: []string{notaryDID},
+				},
+			}))
+
+			reqBytes, err := json.Marshal(&VerifyPresentationRequest{Presentation: vpBytes})
+			require.NoError(t, err)
+
+			rr := serveHTTPMux(t, getHandler(t, op, presentationsVerificationEndpoint, http.MethodPost),
+				"/test/verifier/presentations/verify", reqBytes, map[string]string{profileIDPathParam: "test"})
+
+			return rr.Code, rr.Body.Bytes()
+		}
+
+		// the holder signs the presentation, the notary the credential
+		code, body := verifyVP(t, present(t, coSign(t, issuerPrivKey, notaryPrivKey)))
+		require.Equal(t, http.StatusOK, code, string(body))
+
+		code, body = verifyVP(t, present(t, coSign(t, issuerPrivKey, otherPrivKey)))
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, string(body), "no verified proof of required signer "+notaryDID)
+	})
+
+	t.Run("validate proof policy", func(t *testing.T) {
+		require.NoError(t, validateProofPolicy(nil))
+		require.NoError(t, validateProofPolicy(&verifier.ProofPolicy{Rule: anyProofRule}))
+
+		require.Nil(t, presentationProofPolicy(&verifier.ProofPolicy{
+			Rule:            requiredSignersRule,
+			RequiredSigners: []string{notaryDID},
+		}))
+		require.Equal(t, &verifier.ProofPolicy{Rule: anyProofRule},
+			presentationProofPolicy(&verifier.ProofPolicy{Rule: anyProofRule}))
+
+		require.EqualError(t, validateProofPolicy(&verifier.ProofPolicy{Rule: "some"}),
+			"invalid proof policy rule - some")
+		require.EqualError(t, validateProofPolicy(&verifier.ProofPolicy{Rule: requiredSignersRule}),
+			"requiredSigners proof policy rule requires signers")
+		require.EqualError(t, validateProofPolicy(&verifier.ProofPolicy{RequiredSigners: []string{notaryDID}}),
+			"required signers are only set with the requiredSigners proof policy rule")
+	})
+}
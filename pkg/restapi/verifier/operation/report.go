@@ -0,0 +1,204 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+)
+
+// expiryWarningPeriod is how long before its expiry a credential is reported close to expiry.
+const expiryWarningPeriod = 30 * 24 * time.Hour
+
+// credentialResults collects the outcome of the checks of each credential of a presentation, by the index of the
+// credential in the presentation, as the presentation checks run.
+type credentialResults struct {
+	checks   map[int][]*CheckReport
+	warnings map[int][]string
+}
+
+func newCredentialResults() *credentialResults {
+	return &credentialResults{
+		checks:   make(map[int][]*CheckReport),
+		warnings: make(map[int][]string),
+	}
+}
+
+// add adds the outcome of the check of the credential.
+func (r *credentialResults) add(i int, check string, err error, proofs []*ProofResult) {
+	r.checks[i] = append(r.checks[i], checkReport(check, err, proofs))
+}
+
+// warn adds the warning of the credential, unless it is empty.
+func (r *credentialResults) warn(i int, warning string) {
+	if warning != "" {
+		r.warnings[i] = append(r.warnings[i], warning)
+	}
+}
+
+// allWarnings returns the warnings of all the credentials, in the order of the credentials.
+func (r *credentialResults) allWarnings() []string {
+	indexes := make([]int, 0, len(r.warnings))
+
+	for i := range r.warnings {
+		indexes = append(indexes, i)
+	}
+
+	sort.Ints(indexes)
+
+	var warnings []string
+
+	for _, i := range indexes {
+		warnings = append(warnings, r.warnings[i]...)
+	}
+
+	return warnings
+}
+
+// presentationReport returns the report of the presentation, along with the outcome of the checks of each of its
+// credentials.
+func (o *Operation) presentationReport(vpBytes []byte, verification *presentationVerification) *VerificationReport {
+	failed := make(map[string]string)
+
+	for _, r := range verification.result {
+		failed[r.Check] = r.Error
+	}
+
+	report := &VerificationReport{
+		Verified:           len(verification.result) == 0,
+		Checks:             checkReports(verification.checks, failed, verification.proofs),
+		MatchedCredentials: verification.matched,
+	}
+
+	if _, ok := failed[proofCheck]; !ok {
+		report.Warnings = proofWarnings(verification.proofs)
+	}
+
+	// the checks tell why the credentials of the presentation can't be read
+	if _, vcs, err := o.presentedCredentials(vpBytes); err == nil {
+		report.Credentials = make([]*CredentialReport, 0, len(vcs))
+
+		for i, vc := range vcs {
+			credential := newCredentialReport(vc, verification.credentials.checks[i])
+			credential.Warnings = append(credential.Warnings, verification.credentials.warnings[i]...)
+
+			report.Credentials = append(report.Credentials, credential)
+		}
+	}
+
+	return report
+}
+
+// checkReports returns the outcome of each check, failed maps the failed checks to their error. The proofs are
+// reported with the proof check.
+func checkReports(checks []string, failed map[string]string, proofs []*ProofResult) []*CheckReport {
+	reports := make([]*CheckReport, 0, len(checks))
+
+	for _, check := range checks {
+		report := &CheckReport{Check: check, Verified: true}
+
+		if msg, ok := failed[check]; ok {
+			report.Verified = false
+			report.Error = msg
+		}
+
+		if check == proofCheck {
+			report.Proofs = proofs
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports
+}
+
+func checkReport(check string, err error, proofs []*ProofResult) *CheckReport {
+	report := &CheckReport{Check: check, Verified: err == nil, Proofs: proofs}
+
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	return report
+}
+
+// newCredentialReport returns the report of the credential, verified if all its checks are.
+func newCredentialReport(vc *verifiable.Credential, checks []*CheckReport) *CredentialReport {
+	report := &CredentialReport{
+		ID:       vc.ID,
+		Issuer:   vc.Issuer.ID,
+		Types:    vc.Types,
+		Verified: true,
+		Checks:   checks,
+		Warnings: credentialWarnings(vc),
+	}
+
+	for _, check := range checks {
+		if !check.Verified {
+			report.Verified = false
+
+			continue
+		}
+
+		report.Warnings = append(report.Warnings, proofWarnings(check.Proofs)...)
+	}
+
+	return report
+}
+
+// credentialWarnings warns of a credential expired or close to expiry, or issued in the future.
+func credentialWarnings(vc *verifiable.Credential) []string {
+	var warnings []string
+
+	now := time.Now()
+
+	if vc.Expired != nil {
+		switch expiry := vc.Expired.Time; {
+		case expiry.Before(now):
+			warnings = append(warnings, fmt.Sprintf("credential expired at %s", expiry.Format(time.RFC3339)))
+		case expiry.Before(now.Add(expiryWarningPeriod)):
+			warnings = append(warnings, fmt.Sprintf("credential expires at %s", expiry.Format(time.RFC3339)))
+		}
+	}
+
+	if vc.Issued != nil && vc.Issued.Time.After(now) {
+		warnings = append(warnings, fmt.Sprintf("credential is issued in the future at %s",
+			vc.Issued.Time.Format(time.RFC3339)))
+	}
+
+	return warnings
+}
+
+// proofWarnings warns of the proofs not verified, the proof policy accepted them.
+func proofWarnings(proofs []*ProofResult) []string {
+	var warnings []string
+
+	for _, proof := range proofs {
+		if !proof.Verified {
+			warnings = append(warnings, fmt.Sprintf("proof of %s is not verified : %s", proof.VerificationMethod,
+				proof.Error))
+		}
+	}
+
+	return warnings
+}
+
+func writeReport(rw http.ResponseWriter, report *VerificationReport) {
+	if report.Verified {
+		rw.WriteHeader(http.StatusOK)
+	} else {
+		rw.WriteHeader(http.StatusBadRequest)
+	}
+
+	commhttp.WriteResponse(rw, report)
+}
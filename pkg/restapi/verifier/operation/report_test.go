@@ -0,0 +1,154 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+func TestVerificationReport(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	didID := "did:test:EiBNfNRaz1Ll8BjVsbNv-fWc7K_KIoPuW8GFCh1_Tz_Iuw=="
+	didDoc := createDIDDoc(didID, pubKey)
+	verificationMethod := didDoc.VerificationMethod[0].ID
+
+	loader := testutil.DocumentLoader(t)
+
+	op, err := New(&Config{
+		VDRI:           &vdrmock.MockVDRegistry{ResolveValue: didDoc},
+		StoreProvider:  ariesmemstorage.NewProvider(),
+		DocumentLoader: loader,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+		ID:                 "test",
+		Name:               "test verifier",
+		CredentialChecks:   []string{proofCheck},
+		PresentationChecks: []string{proofCheck, holderBindingCheck},
+	}))
+
+	t.Run("credential report", func(t *testing.T) {
+		vc := getSignedVC(t, privKey, fmt.Sprintf(subjectVC, `{"id": "did:example:ebfeb1f712ebc6f1c276e12ec21"}`),
+			didID, verificationMethod, "", "")
+
+		reqBytes, err := json.Marshal(&CredentialsVerificationRequest{
+			Credential: vc,
+			Opts:       &CredentialsVerificationOptions{Detailed: true},
+		})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, credentialsVerificationEndpoint, http.MethodPost),
+			"/test/verifier/credentials/verify", reqBytes, map[string]string{profileIDPathParam: "test"})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		report := &VerificationReport{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), report))
+		require.True(t, report.Verified)
+		require.Len(t, report.Credentials, 1)
+
+		credential := report.Credentials[0]
+		require.True(t, credential.Verified)
+		require.Equal(t, "http://example.edu/credentials/1872", credential.ID)
+		require.Equal(t, didID, credential.Issuer)
+		require.Equal(t, []string{"VerifiableCredential", "UniversityDegreeCredential"}, credential.Types)
+		require.Len(t, credential.Checks, 1)
+		require.Equal(t, proofCheck, credential.Checks[0].Check)
+		require.True(t, credential.Checks[0].Verified)
+		require.Len(t, credential.Checks[0].Proofs, 1)
+	})
+
+	t.Run("presentation report", func(t *testing.T) {
+		vp := getSignedVP(t, privKey, fmt.Sprintf(subjectVC, `{"id": "did:example:ebfeb1f712ebc6f1c276e12ec21"}`),
+			didID, verificationMethod, didID, verificationMethod, "", "")
+
+		reqBytes, err := json.Marshal(&VerifyPresentationRequest{
+			Presentation: vp,
+			Opts:         &VerifyPresentationOptions{Detailed: true},
+		})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, presentationsVerificationEndpoint, http.MethodPost),
+			"/test/verifier/presentations/verify", reqBytes, map[string]string{profileIDPathParam: "test"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+
+		report := &VerificationReport{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), report))
+		require.False(t, report.Verified)
+		require.Len(t, report.Checks, 2)
+		require.True(t, report.Checks[0].Verified)
+		require.False(t, report.Checks[1].Verified)
+
+		require.Len(t, report.Credentials, 1)
+
+		credential := report.Credentials[0]
+		require.False(t, credential.Verified)
+		require.Equal(t, "http://example.edu/credentials/1872", credential.ID)
+		require.Len(t, credential.Checks, 2)
+		require.Equal(t, proofCheck, credential.Checks[0].Check)
+		require.True(t, credential.Checks[0].Verified)
+		require.Equal(t, holderBindingCheck, credential.Checks[1].Check)
+		require.Equal(t, "credential http://example.edu/credentials/1872 is not bound to the holder "+didID,
+			credential.Checks[1].Error)
+	})
+
+	t.Run("warnings", func(t *testing.T) {
+		vc, err := verifiable.ParseCredential([]byte(fmt.Sprintf(subjectVC, `{"id": "did:example:123"}`)),
+			verifiable.WithDisabledProofCheck(), verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+
+		require.Empty(t, credentialWarnings(vc))
+
+		expiry := time.Now().Add(24 * time.Hour)
+		vc.Expired = util.NewTime(expiry)
+		require.Equal(t, []string{"credential expires at " + expiry.Format(time.RFC3339)}, credentialWarnings(vc))
+
+		expiry = time.Now().Add(-time.Hour)
+		vc.Expired = util.NewTime(expiry)
+		require.Equal(t, []string{"credential expired at " + expiry.Format(time.RFC3339)}, credentialWarnings(vc))
+
+		issued := time.Now().Add(time.Hour)
+		vc.Expired = nil
+		vc.Issued = util.NewTime(issued)
+		require.Equal(t, []string{"credential is issued in the future at " + issued.Format(time.RFC3339)},
+			credentialWarnings(vc))
+
+		report := newCredentialReport(vc, []*CheckReport{{Check: proofCheck, Verified: true, Proofs: []*ProofResult{
+			{VerificationMethod: "did:example:123#key-1", Error: "invalid signature"},
+		}}})
+		require.True(t, report.Verified)
+		require.Contains(t, report.Warnings, "proof of did:example:123#key-1 is not verified : invalid signature")
+	})
+}
+
+func TestCredentialResultsWarnings(t *testing.T) {
+	credentials := newCredentialResults()
+	require.Empty(t, credentials.allWarnings())
+
+	credentials.warn(1, "second")
+	credentials.warn(0, "first")
+	credentials.warn(1, "third")
+
+	require.Equal(t, []string{"first", "second", "third"}, credentials.allWarnings())
+}
@@ -0,0 +1,98 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// statusListMaxAge is how long a status list fetched before is used when it can't be fetched anymore.
+	statusListMaxAge = time.Hour
+	// maxCachedStatusLists bounds the number of status lists kept, the credentials tell which lists to fetch.
+	maxCachedStatusLists = 1000
+)
+
+// cachedStatusList is a status list credential as fetched.
+type cachedStatusList struct {
+	vc      []byte
+	fetched time.Time
+}
+
+// statusListCache keeps the status lists fetched, by their URL.
+type statusListCache struct {
+	mutex sync.Mutex
+	lists map[string]*cachedStatusList
+}
+
+func newStatusListCache() *statusListCache {
+	return &statusListCache{lists: make(map[string]*cachedStatusList)}
+}
+
+func (c *statusListCache) get(url string) (*cachedStatusList, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	list, ok := c.lists[url]
+	if !ok || time.Since(list.fetched) > statusListMaxAge {
+		return nil, false
+	}
+
+	return list, true
+}
+
+// put keeps the status list, the lists older than their maximum age are dropped when the cache is full.
+func (c *statusListCache) put(url string, vc []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.lists[url]; !ok && len(c.lists) >= maxCachedStatusLists {
+		for u, list := range c.lists {
+			if time.Since(list.fetched) > statusListMaxAge {
+				delete(c.lists, u)
+			}
+		}
+
+		if len(c.lists) >= maxCachedStatusLists {
+			return
+		}
+	}
+
+	c.lists[url] = &cachedStatusList{vc: vc, fetched: time.Now()}
+}
+
+// fetchStatusList fetches the status list credential. When it can't be fetched and the cache is allowed, the list
+// fetched last is used if it isn't older than statusListMaxAge, along with a warning that the status may be out of
+// date.
+func (o *Operation) fetchStatusList(url string, allowCached bool) ([]byte, string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	vc, err := o.sendHTTPRequest(req, http.StatusOK, o.requestTokens[cslRequestTokenName])
+	if err != nil {
+		if !allowCached {
+			return nil, "", err
+		}
+
+		list, ok := o.statusLists.get(url)
+		if !ok {
+			return nil, "", err
+		}
+
+		return list.vc, fmt.Sprintf("status list %s fetched from cache at %s : %s", url,
+			list.fetched.Format(time.RFC3339), err), nil
+	}
+
+	o.statusLists.put(url, vc)
+
+	return vc, "", nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+func TestFetchStatusList(t *testing.T) {
+	op, err := New(&Config{
+		StoreProvider:  ariesmemstorage.NewProvider(),
+		DocumentLoader: testutil.DocumentLoader(t),
+	})
+	require.NoError(t, err)
+
+	available := true
+
+	op.httpClient = &handlerClient{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		fmt.Fprint(w, "status list") // nolint: errcheck
+	})}
+
+	const listURL = "https://issuer.example.com/status/1"
+
+	t.Run("fetched", func(t *testing.T) {
+		available = true
+
+		vc, warning, err := op.fetchStatusList(listURL, false)
+		require.NoError(t, err)
+		require.Empty(t, warning)
+		require.Equal(t, "status list", string(vc))
+	})
+
+	t.Run("cache not allowed", func(t *testing.T) {
+		available = false
+
+		_, _, err := op.fetchStatusList(listURL, false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read response body for status 503")
+	})
+
+	t.Run("fetched from cache", func(t *testing.T) {
+		available = false
+
+		vc, warning, err := op.fetchStatusList(listURL, true)
+		require.NoError(t, err)
+		require.Equal(t, "status list", string(vc))
+		require.Contains(t, warning, "status list "+listURL+" fetched from cache at ")
+		require.Contains(t, warning, "failed to read response body for status 503")
+	})
+
+	t.Run("not cached", func(t *testing.T) {
+		available = false
+
+		_, _, err := op.fetchStatusList("https://issuer.example.com/status/2", true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read response body for status 503")
+	})
+
+	t.Run("cached too long ago", func(t *testing.T) {
+		available = false
+
+		op.statusLists.lists[listURL].fetched = time.Now().Add(-statusListMaxAge - time.Minute)
+
+		_, _, err := op.fetchStatusList(listURL, true)
+		require.Error(t, err)
+	})
+
+	t.Run("cache full", func(t *testing.T) {
+		cache := newStatusListCache()
+
+		for i := 0; i < maxCachedStatusLists; i++ {
+			cache.put(fmt.Sprintf("https://issuer.example.com/status/%d", i), []byte("status list"))
+		}
+
+		cache.put("https://issuer.example.com/status/new", []byte("status list"))
+		_, ok := cache.get("https://issuer.example.com/status/new")
+		require.False(t, ok)
+
+		cache.lists["https://issuer.example.com/status/0"].fetched = time.Now().Add(-statusListMaxAge - time.Minute)
+
+		cache.put("https://issuer.example.com/status/new", []byte("status list"))
+		_, ok = cache.get("https://issuer.example.com/status/new")
+		require.True(t, ok)
+		require.Len(t, cache.lists, maxCachedStatusLists)
+	})
+}
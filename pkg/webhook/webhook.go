@@ -0,0 +1,514 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/edge-core/pkg/utils/retry"
+)
+
+const (
+	storeName = "webhook"
+
+	subscriptionKeyPattern = "subscription_%s_%s"
+	deliveryKeyPattern     = "delivery_%s_%s"
+	subscriptionTagKey     = "webhookSubscriptionProfile"
+	deliveryTagKey         = "webhookDeliveryProfile"
+
+	// SignatureHeader carries the hex encoded HMAC-SHA256 of the payload, keyed with the subscription secret.
+	SignatureHeader = "X-Webhook-Signature"
+	// EventTypeHeader carries the type of the event delivered.
+	EventTypeHeader = "X-Webhook-Event"
+
+	signaturePrefix = "sha256="
+	secretSize      = 32
+)
+
+// Event types.
+const (
+	CredentialIssued     = "credential.issued"
+	CredentialStored     = "credential.stored"
+	CredentialRevoked    = "credential.revoked"
+	CredentialSuspended  = "credential.suspended"
+	CredentialReinstated = "credential.reinstated"
+	ProfileChanged       = "profile.changed"
+)
+
+var logger = log.New("edge-service-webhook")
+
+var eventTypes = map[string]bool{ //nolint: gochecknoglobals
+	CredentialIssued: true, CredentialStored: true, CredentialRevoked: true, CredentialSuspended: true,
+	CredentialReinstated: true, ProfileChanged: true,
+}
+
+// Subscription subscribes a URL to the events of a profile.
+type Subscription struct {
+	ID      string `json:"id"`
+	Profile string `json:"profile"`
+	URL     string `json:"url"`
+	// Secret keys the HMAC of the payloads, it is only returned when the subscription is created.
+	Secret string `json:"secret,omitempty"`
+	// Events are the types of the events delivered, all of them if empty.
+	Events  []string  `json:"events,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// Event is the payload delivered to the subscriptions.
+type Event struct {
+	ID      string      `json:"id"`
+	Type    string      `json:"type"`
+	Profile string      `json:"profile"`
+	Time    time.Time   `json:"time"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// ProfileChange is the data of the profile.changed events.
+type ProfileChange struct {
+	Action string `json:"action"`
+}
+
+// Delivery is the log entry of an event delivered to a subscription.
+type Delivery struct {
+	ID           string     `json:"id"`
+	Subscription string     `json:"subscription"`
+	Profile      string     `json:"profile"`
+	URL          string     `json:"url"`
+	EventID      string     `json:"eventID"`
+	EventType    string     `json:"eventType"`
+	Attempts     int        `json:"attempts"`
+	StatusCode   int        `json:"statusCode,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	Delivered    bool       `json:"delivered"`
+	Created      time.Time  `json:"created"`
+	Completed    *time.Time `json:"completed,omitempty"`
+}
+
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Notifier delivers the events of profiles to their webhook subscriptions.
+type Notifier struct {
+	store       ariesstorage.Store
+	httpClient  httpClient
+	retryParams *retry.Params
+	pending     sync.WaitGroup
+	// inFlight counts the deliveries in progress per profile, the cond signals when they complete.
+	inFlight map[string]int
+	cond     *sync.Cond
+}
+
+// New returns a new webhook notifier. The deliveries are retried with backoff as the retry parameters tell, they
+// aren't retried if these are nil.
+func New(provider ariesstorage.Provider, client httpClient, retryParams *retry.Params) (*Notifier, error) {
+	store, err := provider.OpenStore(storeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open webhook store: %w", err)
+	}
+
+	if retryParams == nil {
+		retryParams = &retry.Params{}
+	}
+
+	return &Notifier{
+		store:       store,
+		httpClient:  client,
+		retryParams: retryParams,
+		inFlight:    map[string]int{},
+		cond:        sync.NewCond(&sync.Mutex{}),
+	}, nil
+}
+
+// Subscribe saves the subscription, its ID and secret are generated unless set.
+func (n *Notifier) Subscribe(sub *Subscription) error {
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("invalid webhook url %s", sub.URL)
+	}
+
+	for _, event := range sub.Events {
+		if !eventTypes[event] {
+			return fmt.Errorf("unknown event type %s", event)
+		}
+	}
+
+	if sub.ID == "" {
+		sub.ID = uuid.New().String()
+	}
+
+	if sub.Secret == "" {
+		secret := make([]byte, secretSize)
+
+		if _, err = rand.Read(secret); err != nil {
+			return fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+
+		sub.Secret = base64.RawURLEncoding.EncodeToString(secret)
+	}
+
+	sub.Created = time.Now().UTC()
+
+	return n.put(fmt.Sprintf(subscriptionKeyPattern, sub.Profile, sub.ID), sub, subscriptionTagKey, sub.Profile)
+}
+
+// Unsubscribe deletes the subscription of the profile.
+func (n *Notifier) Unsubscribe(profile, id string) error {
+	key := fmt.Sprintf(subscriptionKeyPattern, profile, id)
+
+	if _, err := n.store.Get(key); err != nil {
+		return err
+	}
+
+	return n.store.Delete(key)
+}
+
+// Subscriptions returns the subscriptions of the profile, oldest first.
+func (n *Notifier) Subscriptions(profile string) ([]*Subscription, error) {
+	var subs []*Subscription
+
+	_, err := n.query(subscriptionTagKey, profile, func(value []byte) error {
+		sub := &Subscription{}
+		if err := json.Unmarshal(value, sub); err != nil {
+			return err
+		}
+
+		subs = append(subs, sub)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook subscriptions: %w", err)
+	}
+
+	sort.SliceStable(subs, func(i, j int) bool {
+		return subs[i].Created.Before(subs[j].Created)
+	})
+
+	return subs, nil
+}
+
+// Deliveries returns a page of the delivery log of the profile, newest first, along with the number of deliveries.
+func (n *Notifier) Deliveries(profile string, offset, limit int) ([]*Delivery, int, error) {
+	deliveries := []*Delivery{}
+
+	_, err := n.query(deliveryTagKey, profile, func(value []byte) error {
+		delivery := &Delivery{}
+		if err := json.Unmarshal(value, delivery); err != nil {
+			return err
+		}
+
+		deliveries = append(deliveries, delivery)
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].Created.After(deliveries[j].Created)
+	})
+
+	total := len(deliveries)
+
+	if offset >= total {
+		return []*Delivery{}, total, nil
+	}
+
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	return deliveries[offset:end], total, nil
+}
+
+// DeleteProfile deletes the subscriptions and the delivery log of the profile. The deliveries in progress, such as
+// the one of the profile's deletion, complete before the log is deleted, so that they don't log an orphan entry.
+func (n *Notifier) DeleteProfile(profile string) error {
+	if err := n.deleteTagged(subscriptionTagKey, profile); err != nil {
+		return err
+	}
+
+	n.cond.L.Lock()
+	for n.inFlight[profile] > 0 {
+		n.cond.Wait()
+	}
+	n.cond.L.Unlock()
+
+	return n.deleteTagged(deliveryTagKey, profile)
+}
+
+func (n *Notifier) deleteTagged(tagKey, profile string) error {
+	keys, err := n.query(tagKey, profile, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err = n.store.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Notify delivers the event to the subscriptions of the profile in the background, each delivery is logged.
+func (n *Notifier) Notify(profile, eventType string, data interface{}) {
+	subs, err := n.Subscriptions(profile)
+	if err != nil {
+		logger.Errorf("failed to notify %s event of profile %s: %s", eventType, profile, err)
+
+		return
+	}
+
+	event := &Event{ID: uuid.New().String(), Type: eventType, Profile: profile, Time: time.Now().UTC(), Data: data}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Errorf("failed to marshal %s event of profile %s: %s", eventType, profile, err)
+
+		return
+	}
+
+	for _, sub := range subs {
+		if !sub.subscribed(eventType) {
+			continue
+		}
+
+		n.pending.Add(1)
+		n.startDelivery(profile)
+
+		go func(sub *Subscription) {
+			defer n.pending.Done()
+			defer n.completeDelivery(profile)
+
+			n.deliver(sub, event, payload)
+		}(sub)
+	}
+}
+
+func (n *Notifier) startDelivery(profile string) {
+	n.cond.L.Lock()
+	defer n.cond.L.Unlock()
+
+	n.inFlight[profile]++
+}
+
+func (n *Notifier) completeDelivery(profile string) {
+	n.cond.L.Lock()
+	defer n.cond.L.Unlock()
+
+	n.inFlight[profile]--
+
+	if n.inFlight[profile] == 0 {
+		delete(n.inFlight, profile)
+	}
+
+	n.cond.Broadcast()
+}
+
+// Callback posts the payload to the URL in the background, retried as the events are. Unlike the events, the
+// callbacks are neither signed nor logged.
+func (n *Notifier) Callback(url string, payload []byte) {
+	n.pending.Add(1)
+
+	go func() {
+		defer n.pending.Done()
+
+		err := retry.Retry(func() error {
+			_, errPost := n.post(url, nil, payload)
+
+			return errPost
+		}, n.retryParams)
+		if err != nil {
+			logger.Warnf("failed to post callback to %s: %s", url, err)
+		}
+	}()
+}
+
+// Wait waits for the deliveries and callbacks in progress to complete.
+func (n *Notifier) Wait() {
+	n.pending.Wait()
+}
+
+func (n *Notifier) deliver(sub *Subscription, event *Event, payload []byte) {
+	delivery := &Delivery{
+		ID:           uuid.New().String(),
+		Subscription: sub.ID,
+		Profile:      sub.Profile,
+		URL:          sub.URL,
+		EventID:      event.ID,
+		EventType:    event.Type,
+		Created:      time.Now().UTC(),
+	}
+
+	err := retry.Retry(func() error {
+		delivery.Attempts++
+
+		var errPost error
+
+		delivery.StatusCode, errPost = n.post(sub.URL, http.Header{
+			EventTypeHeader: {event.Type},
+			SignatureHeader: {Sign(sub.Secret, payload)},
+		}, payload)
+
+		return errPost
+	}, n.retryParams)
+
+	completed := time.Now().UTC()
+	delivery.Completed = &completed
+	delivery.Delivered = err == nil
+
+	if err != nil {
+		delivery.Error = err.Error()
+
+		logger.Warnf("failed to deliver %s event to webhook %s: %s", event.Type, sub.ID, err)
+	}
+
+	key := fmt.Sprintf(deliveryKeyPattern, delivery.Profile, delivery.ID)
+
+	if err = n.put(key, delivery, deliveryTagKey, delivery.Profile); err != nil {
+		logger.Errorf("failed to log webhook delivery: %s", err)
+	}
+}
+
+func (n *Notifier) post(url string, header http.Header, payload []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			logger.Warnf("failed to close response body: %s", errClose)
+		}
+	}()
+
+	// drain the body so that the connection can be reused
+	if _, err = io.Copy(ioutil.Discard, resp.Body); err != nil {
+		logger.Warnf("failed to read response body: %s", err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (n *Notifier) put(key string, v interface{}, tagKey, profile string) error {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return n.store.Put(key, bytes, ariesstorage.Tag{Name: tagKey, Value: tagValue(profile)})
+}
+
+// query calls the function, if set, with the values tagged for the profile and returns their keys.
+func (n *Notifier) query(tagKey, profile string, f func(value []byte) error) ([]string, error) {
+	iter, err := n.store.Query(tagKey + ":" + tagValue(profile))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("failed to close iterator: %s", errClose.Error())
+		}
+	}()
+
+	var keys []string
+
+	more, err := iter.Next()
+
+	for ; err == nil && more; more, err = iter.Next() {
+		key, errKey := iter.Key()
+		if errKey != nil {
+			return nil, errKey
+		}
+
+		keys = append(keys, key)
+
+		if f == nil {
+			continue
+		}
+
+		value, errValue := iter.Value()
+		if errValue != nil {
+			return nil, errValue
+		}
+
+		if errValue = f(value); errValue != nil {
+			return nil, errValue
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("iterator next: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (s *Subscription) subscribed(eventType string) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+
+	for _, event := range s.Events {
+		if event == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sign returns the signature header value of the payload, the subscribers compute it to authenticate the payloads.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload) //nolint: errcheck,gosec // hash writes don't fail
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// tagValue encodes the profile name to be used as a tag value, tag values can't contain colons.
+func tagValue(profile string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(profile))
+}
@@ -0,0 +1,246 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	ariesmockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/edge-core/pkg/utils/retry"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("test open store failure", func(t *testing.T) {
+		notifier, err := New(&ariesmockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")},
+			http.DefaultClient, nil)
+		require.Nil(t, notifier)
+		require.EqualError(t, err, "failed to open webhook store: open error")
+	})
+}
+
+func TestNotifier_Subscribe(t *testing.T) {
+	notifier, err := New(ariesmockstorage.NewMockStoreProvider(), http.DefaultClient, nil)
+	require.NoError(t, err)
+
+	t.Run("test subscribe success", func(t *testing.T) {
+		sub := &Subscription{Profile: "issuer", URL: "https://crm.example.com/events", Events: []string{CredentialIssued}}
+		require.NoError(t, notifier.Subscribe(sub))
+		require.NotEmpty(t, sub.ID)
+		require.NotEmpty(t, sub.Secret)
+		require.False(t, sub.Created.IsZero())
+
+		require.NoError(t, notifier.Subscribe(&Subscription{Profile: "other", URL: "https://crm.example.com/events"}))
+
+		subs, err := notifier.Subscriptions("issuer")
+		require.NoError(t, err)
+		require.Len(t, subs, 1)
+		require.Equal(t, sub.ID, subs[0].ID)
+
+		require.NoError(t, notifier.Unsubscribe("issuer", sub.ID))
+
+		subs, err = notifier.Subscriptions("issuer")
+		require.NoError(t, err)
+		require.Empty(t, subs)
+
+		err = notifier.Unsubscribe("issuer", sub.ID)
+		require.True(t, errors.Is(err, ariesstorage.ErrDataNotFound))
+	})
+
+	t.Run("test invalid url", func(t *testing.T) {
+		err := notifier.Subscribe(&Subscription{Profile: "issuer", URL: "ftp://crm.example.com"})
+		require.EqualError(t, err, "invalid webhook url ftp://crm.example.com")
+	})
+
+	t.Run("test unknown event", func(t *testing.T) {
+		err := notifier.Subscribe(&Subscription{Profile: "issuer", URL: "https://crm.example.com", Events: []string{"x"}})
+		require.EqualError(t, err, "unknown event type x")
+	})
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	var (
+		mutex    sync.Mutex
+		received []*http.Request
+		payloads [][]byte
+		failures = 1
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if failures > 0 {
+			failures--
+
+			rw.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		payload, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		received = append(received, req)
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	notifier, err := New(ariesmockstorage.NewMockStoreProvider(), server.Client(),
+		&retry.Params{MaxRetries: 2, InitialBackoff: time.Millisecond, BackoffFactor: 2})
+	require.NoError(t, err)
+
+	sub := &Subscription{Profile: "issuer", URL: server.URL, Events: []string{CredentialRevoked}}
+	require.NoError(t, notifier.Subscribe(sub))
+
+	notifier.Notify("issuer", CredentialIssued, nil)
+	notifier.Notify("issuer", CredentialRevoked, map[string]string{"id": "urn:uuid:1"})
+	notifier.Wait()
+
+	require.Len(t, received, 1)
+	require.Equal(t, CredentialRevoked, received[0].Header.Get(EventTypeHeader))
+	require.Equal(t, Sign(sub.Secret, payloads[0]), received[0].Header.Get(SignatureHeader))
+
+	event := &Event{}
+	require.NoError(t, json.Unmarshal(payloads[0], event))
+	require.Equal(t, CredentialRevoked, event.Type)
+	require.Equal(t, "issuer", event.Profile)
+	require.Equal(t, map[string]interface{}{"id": "urn:uuid:1"}, event.Data)
+
+	deliveries, total, err := notifier.Deliveries("issuer", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.True(t, deliveries[0].Delivered)
+	require.Equal(t, 2, deliveries[0].Attempts)
+	require.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+	require.Equal(t, event.ID, deliveries[0].EventID)
+
+	t.Run("test delivery failure", func(t *testing.T) {
+		failures = 10
+
+		notifier.Notify("issuer", CredentialRevoked, nil)
+		notifier.Wait()
+
+		deliveries, total, err := notifier.Deliveries("issuer", 0, 1)
+		require.NoError(t, err)
+		require.Equal(t, 2, total)
+		require.Len(t, deliveries, 1)
+		require.False(t, deliveries[0].Delivered)
+		require.Equal(t, 3, deliveries[0].Attempts)
+		require.Equal(t, "webhook responded with status 503", deliveries[0].Error)
+
+		deliveries, _, err = notifier.Deliveries("issuer", 2, 1)
+		require.NoError(t, err)
+		require.Empty(t, deliveries)
+	})
+
+	t.Run("test delete profile", func(t *testing.T) {
+		require.NoError(t, notifier.DeleteProfile("issuer"))
+
+		subs, err := notifier.Subscriptions("issuer")
+		require.NoError(t, err)
+		require.Empty(t, subs)
+
+		_, total, err := notifier.Deliveries("issuer", 0, 10)
+		require.NoError(t, err)
+		require.Zero(t, total)
+	})
+}
+
+func TestNotifier_DeleteProfileDuringDelivery(t *testing.T) {
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	notifier, err := New(ariesmockstorage.NewMockStoreProvider(), server.Client(), nil)
+	require.NoError(t, err)
+
+	require.NoError(t, notifier.Subscribe(&Subscription{Profile: "issuer", URL: server.URL}))
+
+	notifier.Notify("issuer", ProfileChanged, &ProfileChange{Action: "deleted"})
+
+	deleted := make(chan error)
+
+	go func() {
+		deleted <- notifier.DeleteProfile("issuer")
+	}()
+
+	select {
+	case <-deleted:
+		require.Fail(t, "profile deleted while its delivery is in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-deleted)
+
+	// the delivery completed before the log was deleted, it left no entry behind
+	notifier.Wait()
+
+	_, total, err := notifier.Deliveries("issuer", 0, 10)
+	require.NoError(t, err)
+	require.Zero(t, total)
+}
+
+func TestNotifier_Callback(t *testing.T) {
+	var (
+		attempts int
+		received []*http.Request
+		payloads [][]byte
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		payload, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		received = append(received, req)
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	notifier, err := New(ariesmockstorage.NewMockStoreProvider(), server.Client(),
+		&retry.Params{MaxRetries: 2, InitialBackoff: time.Millisecond, BackoffFactor: 2})
+	require.NoError(t, err)
+
+	notifier.Callback(server.URL, []byte(`{"status":"verified"}`))
+	notifier.Wait()
+
+	require.Equal(t, 2, attempts)
+	require.Len(t, received, 1)
+	require.Equal(t, "application/json", received[0].Header.Get("Content-Type"))
+	require.Empty(t, received[0].Header.Get(SignatureHeader))
+	require.Equal(t, `{"status":"verified"}`, string(payloads[0]))
+
+	t.Run("test callback failure", func(t *testing.T) {
+		notifier.Callback("http://[::1]:namedport", nil)
+		notifier.Wait()
+	})
+}
+
+func TestSign(t *testing.T) {
+	require.Equal(t, "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		Sign("key", []byte("The quick brown fox jumps over the lazy dog")))
+}